@@ -0,0 +1,83 @@
+package authclient
+
+import (
+	"sync"
+	"time"
+)
+
+// maxCacheEntries bounds the cache, expired entries are swept once it is reached.
+const maxCacheEntries = 10000
+
+type cacheEntry struct {
+	claims    *Claims
+	err       error
+	expiresAt time.Time
+}
+
+// cache is a small TTL cache of validation results.
+type cache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+	now     func() time.Time
+}
+
+func newCache(ttl time.Duration) *cache {
+	return &cache{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+		now:     time.Now,
+	}
+}
+
+func (c *cache) get(key string) (cacheEntry, bool) {
+	if c.ttl <= 0 {
+		return cacheEntry{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return cacheEntry{}, false
+	}
+
+	return entry, true
+}
+
+// set caches a result for the TTL, but never past the expiry of the token when it is known.
+func (c *cache) set(key string, claims *Claims, err error, tokenExpiresAt time.Time) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+
+	expiresAt := now.Add(c.ttl)
+	if !tokenExpiresAt.IsZero() && tokenExpiresAt.Before(expiresAt) {
+		expiresAt = tokenExpiresAt
+	}
+
+	if len(c.entries) >= maxCacheEntries {
+		for k, e := range c.entries {
+			if !now.Before(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+
+		if len(c.entries) >= maxCacheEntries {
+			c.entries = make(map[string]cacheEntry)
+		}
+	}
+
+	c.entries[key] = cacheEntry{claims: claims, err: err, expiresAt: expiresAt}
+}
@@ -0,0 +1,147 @@
+// Package authclient validates access tokens with the AuthService of the user service,
+// other services use it in place of parsing tokens themselves.
+//
+// Results are cached for a short time so a service doesn't call the user service on
+// every request, a revoked token may still be accepted for up to the cache TTL.
+package authclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/authclient/proto"
+	"google.golang.org/grpc"
+)
+
+// DefaultCacheTTL is how long validation results are cached by default.
+const DefaultCacheTTL = 30 * time.Second
+
+var (
+	ErrMissingToken    = errors.New("authorization header is required")
+	ErrUnauthenticated = errors.New("invalid or revoked token")
+	ErrForbidden       = errors.New("permission denied")
+)
+
+// RoleGuest is the role of guests, they have no email or password and can be
+// upgraded to a full account with the same user ID.
+const RoleGuest = "guest"
+
+// Claims describes the user of a valid access token.
+type Claims struct {
+	UserID    string
+	Role      string
+	ExpiresAt time.Time
+}
+
+// IsGuest reports whether the token belongs to a guest.
+func (c *Claims) IsGuest() bool {
+	return c.Role == RoleGuest
+}
+
+// Client validates tokens and checks permissions with the user service.
+type Client struct {
+	client   proto.AuthServiceClient
+	audience string
+	cache    *cache
+}
+
+// New creates a new client on a connection to the user service, only tokens issued for the
+// audience, the name of the calling service, are accepted. A cacheTTL of zero disables caching.
+func New(conn grpc.ClientConnInterface, audience string, cacheTTL time.Duration) *Client {
+	return &Client{
+		client:   proto.NewAuthServiceClient(conn),
+		audience: audience,
+		cache:    newCache(cacheTTL),
+	}
+}
+
+// BearerToken returns the token of an Authorization header value.
+func BearerToken(header string) (string, error) {
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || strings.TrimSpace(token) == "" {
+		return "", ErrMissingToken
+	}
+
+	return strings.TrimSpace(token), nil
+}
+
+// ValidateToken returns the claims of a valid access token, or ErrUnauthenticated
+// if it is invalid, expired, revoked or its user is disabled.
+func (c *Client) ValidateToken(ctx context.Context, token string) (*Claims, error) {
+	key := "validate:" + hashToken(token)
+
+	if entry, ok := c.cache.get(key); ok {
+		return entry.claims, entry.err
+	}
+
+	res, err := c.client.ValidateToken(ctx, &proto.ValidateTokenRequest{Token: token, Audience: c.audience})
+	if err != nil {
+		return nil, err
+	}
+
+	if !res.Valid {
+		err := fmt.Errorf("%w: %s", ErrUnauthenticated, res.Reason)
+		c.cache.set(key, nil, err, time.Time{})
+		return nil, err
+	}
+
+	claims := &Claims{
+		UserID:    res.UserId,
+		Role:      res.Role,
+		ExpiresAt: time.Unix(res.ExpiresAt, 0),
+	}
+	c.cache.set(key, claims, nil, claims.ExpiresAt)
+
+	return claims, nil
+}
+
+// CheckPermission validates the token and checks that its user has the permission.
+// It returns ErrForbidden together with the claims if the token is valid but the permission is missing.
+func (c *Client) CheckPermission(ctx context.Context, token, permission string) (*Claims, error) {
+	key := "permission:" + permission + ":" + hashToken(token)
+
+	if entry, ok := c.cache.get(key); ok {
+		return entry.claims, entry.err
+	}
+
+	res, err := c.client.CheckPermission(ctx, &proto.CheckPermissionRequest{Token: token, Permission: permission, Audience: c.audience})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.UserId == "" {
+		err := fmt.Errorf("%w: %s", ErrUnauthenticated, res.Reason)
+		c.cache.set(key, nil, err, time.Time{})
+		return nil, err
+	}
+
+	claims := &Claims{
+		UserID:    res.UserId,
+		Role:      res.Role,
+		ExpiresAt: time.Unix(res.ExpiresAt, 0),
+	}
+
+	if !res.Allowed {
+		c.cache.set(key, claims, ErrForbidden, claims.ExpiresAt)
+		return claims, ErrForbidden
+	}
+
+	c.cache.set(key, claims, nil, claims.ExpiresAt)
+	return claims, nil
+}
+
+// GetUser returns a user by ID, it is not cached.
+func (c *Client) GetUser(ctx context.Context, userID string) (*proto.User, error) {
+	return c.client.GetUser(ctx, &proto.GetUserRequest{UserId: userID})
+}
+
+// hashToken keys the cache so that raw tokens are not kept in memory longer than needed.
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,208 @@
+package authclient
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/authclient/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeAuthServer accepts "valid" and "staff" tokens issued for test-service, "staff" has the orders:read permission.
+type fakeAuthServer struct {
+	proto.UnimplementedAuthServiceServer
+
+	calls atomic.Int32
+}
+
+func (s *fakeAuthServer) ValidateToken(ctx context.Context, req *proto.ValidateTokenRequest) (*proto.ValidateTokenResponse, error) {
+	s.calls.Add(1)
+
+	if req.Audience != "test-service" {
+		return &proto.ValidateTokenResponse{Valid: false, Reason: "invalid token"}, nil
+	}
+
+	switch req.Token {
+	case "valid":
+		return &proto.ValidateTokenResponse{Valid: true, UserId: "user-1", Role: "user", ExpiresAt: time.Now().Add(time.Hour).Unix()}, nil
+	case "staff":
+		return &proto.ValidateTokenResponse{Valid: true, UserId: "user-2", Role: "staff", ExpiresAt: time.Now().Add(time.Hour).Unix()}, nil
+	}
+
+	return &proto.ValidateTokenResponse{Valid: false, Reason: "session has been revoked"}, nil
+}
+
+func (s *fakeAuthServer) CheckPermission(ctx context.Context, req *proto.CheckPermissionRequest) (*proto.CheckPermissionResponse, error) {
+	s.calls.Add(1)
+
+	res, _ := s.ValidateToken(ctx, &proto.ValidateTokenRequest{Token: req.Token, Audience: req.Audience})
+	s.calls.Add(-1)
+
+	if !res.Valid {
+		return &proto.CheckPermissionResponse{Allowed: false, Reason: res.Reason}, nil
+	}
+
+	return &proto.CheckPermissionResponse{
+		Allowed:   res.Role == "staff" && req.Permission == "orders:read",
+		UserId:    res.UserId,
+		Role:      res.Role,
+		ExpiresAt: res.ExpiresAt,
+	}, nil
+}
+
+func newTestClient(t *testing.T, ttl time.Duration) (*Client, *fakeAuthServer) {
+	lis := bufconn.Listen(1024 * 1024)
+
+	fake := &fakeAuthServer{}
+	server := grpc.NewServer()
+	proto.RegisterAuthServiceServer(server, fake)
+
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return New(conn, "test-service", ttl), fake
+}
+
+func TestValidateToken(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "Valid token is cached",
+			testFunc: func(t *testing.T) {
+				client, fake := newTestClient(t, time.Minute)
+
+				for range 3 {
+					claims, err := client.ValidateToken(ctx, "valid")
+					require.NoError(t, err)
+					assert.Equal(t, "user-1", claims.UserID)
+					assert.Equal(t, "user", claims.Role)
+				}
+
+				assert.Equal(t, int32(1), fake.calls.Load())
+			},
+		},
+		{
+			name: "Rejected token",
+			testFunc: func(t *testing.T) {
+				client, fake := newTestClient(t, time.Minute)
+
+				claims, err := client.ValidateToken(ctx, "revoked")
+				assert.ErrorIs(t, err, ErrUnauthenticated)
+				assert.Nil(t, claims)
+
+				_, err = client.ValidateToken(ctx, "revoked")
+				assert.ErrorIs(t, err, ErrUnauthenticated)
+				assert.Equal(t, int32(1), fake.calls.Load())
+			},
+		},
+		{
+			name: "Cache expires",
+			testFunc: func(t *testing.T) {
+				client, fake := newTestClient(t, time.Minute)
+
+				now := time.Now()
+				client.cache.now = func() time.Time { return now }
+
+				_, err := client.ValidateToken(ctx, "valid")
+				require.NoError(t, err)
+
+				now = now.Add(2 * time.Minute)
+
+				_, err = client.ValidateToken(ctx, "valid")
+				require.NoError(t, err)
+				assert.Equal(t, int32(2), fake.calls.Load())
+			},
+		},
+		{
+			name: "Cache disabled",
+			testFunc: func(t *testing.T) {
+				client, fake := newTestClient(t, 0)
+
+				for range 2 {
+					_, err := client.ValidateToken(ctx, "valid")
+					require.NoError(t, err)
+				}
+
+				assert.Equal(t, int32(2), fake.calls.Load())
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestCheckPermission(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestClient(t, time.Minute)
+
+	testCases := []struct {
+		name       string
+		token      string
+		permission string
+		userID     string
+		err        error
+	}{
+		{name: "Allowed", token: "staff", permission: "orders:read", userID: "user-2"},
+		{name: "Forbidden", token: "valid", permission: "orders:read", userID: "user-1", err: ErrForbidden},
+		{name: "Other permission", token: "staff", permission: "products:write", userID: "user-2", err: ErrForbidden},
+		{name: "Invalid token", token: "revoked", permission: "orders:read", err: ErrUnauthenticated},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			claims, err := client.CheckPermission(ctx, tc.token, tc.permission)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if tc.userID != "" {
+				require.NotNil(t, claims)
+				assert.Equal(t, tc.userID, claims.UserID)
+			} else {
+				assert.Nil(t, claims)
+			}
+		})
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	testCases := []struct {
+		header string
+		token  string
+		err    error
+	}{
+		{header: "Bearer abc", token: "abc"},
+		{header: "Bearer  abc ", token: "abc"},
+		{header: "", err: ErrMissingToken},
+		{header: "abc", err: ErrMissingToken},
+		{header: "Bearer ", err: ErrMissingToken},
+	}
+
+	for _, tc := range testCases {
+		token, err := BearerToken(tc.header)
+		assert.Equal(t, tc.token, token, tc.header)
+		assert.Equal(t, tc.err, err, tc.header)
+	}
+}
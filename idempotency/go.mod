@@ -0,0 +1,11 @@
+module github.com/NeGat1FF/e-commerce/idempotency
+
+go 1.22.2
+
+require github.com/stretchr/testify v1.9.0
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
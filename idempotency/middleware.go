@@ -0,0 +1,216 @@
+// Package idempotency lets clients safely retry requests that create resources.
+//
+// A client sends an Idempotency-Key header with a unique value. The response to the
+// first request with the key is stored and replayed when the request is retried, and
+// the key cannot be reused for a different request until it expires.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	// Header is the request header carrying the idempotency key
+	Header = "Idempotency-Key"
+	// ReplayedHeader is set on responses replayed from the store
+	ReplayedHeader = "Idempotent-Replayed"
+)
+
+const (
+	maxKeyLength = 255
+	maxBodySize  = 1 << 20
+	// lockTimeout is how long a key stays locked by a request that never completed,
+	// e.g. because the service was restarted while handling it
+	lockTimeout = time.Minute
+)
+
+// ScopeFunc returns the scope the idempotency key of a request belongs to, so
+// that callers cannot see each other's responses. An empty scope is shared.
+type ScopeFunc func(r *http.Request) string
+
+type keyContextKey struct{}
+
+// KeyFromContext returns the idempotency key of the request, e.g. to pass it on to a payment provider.
+func KeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(keyContextKey{}).(string)
+	return key
+}
+
+// Middleware makes handlers idempotent for requests with an Idempotency-Key header.
+type Middleware struct {
+	store Store
+	ttl   time.Duration
+	scope ScopeFunc
+}
+
+// New returns a middleware keeping keys in the store for ttl. The scope may be nil.
+func New(store Store, ttl time.Duration, scope ScopeFunc) *Middleware {
+	return &Middleware{
+		store: store,
+		ttl:   ttl,
+		scope: scope,
+	}
+}
+
+// Wrap returns a handler that replays the stored response when a request is retried with the same key.
+// Only successful responses are stored, after an error the request can be retried as nothing was created.
+func (m *Middleware) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(Header)
+		if key == "" {
+			next(w, r)
+			return
+		}
+
+		if len(key) > maxKeyLength {
+			http.Error(w, "Idempotency-Key must be at most 255 characters", http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Postgres keeps timestamps to the microsecond, the record is later matched by its creation time
+		now := time.Now().Truncate(time.Microsecond)
+		record := &Record{
+			Key:         key,
+			Fingerprint: fingerprint(r, body),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(m.ttl),
+		}
+		if m.scope != nil {
+			record.Scope = m.scope(r)
+		}
+
+		// The stored response must not depend on the client waiting for it
+		ctx := context.WithoutCancel(r.Context())
+
+		existing, err := m.begin(ctx, record)
+		if err != nil {
+			log.Println("failed to save idempotency key: ", err)
+			http.Error(w, "failed to save idempotency key", http.StatusInternalServerError)
+			return
+		}
+
+		if existing != nil {
+			replay(w, record, existing)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r.WithContext(context.WithValue(r.Context(), keyContextKey{}, key)))
+
+		if rec.status < 200 || rec.status >= 300 {
+			if err := m.store.Delete(ctx, record); err != nil {
+				log.Println("failed to release idempotency key: ", err)
+			}
+			return
+		}
+
+		record.Completed = true
+		record.StatusCode = rec.status
+		record.ContentType = rec.Header().Get("Content-Type")
+		record.Body = rec.body.Bytes()
+
+		if err := m.store.Complete(ctx, record); err != nil {
+			log.Println("failed to save idempotent response: ", err)
+		}
+	}
+}
+
+// begin takes the key for the request, or returns the record of the request that holds it.
+// Expired keys and keys of requests that never completed are taken over. Of concurrent
+// requests taking over the same key only one succeeds, the others get its record.
+func (m *Middleware) begin(ctx context.Context, record *Record) (*Record, error) {
+	for attempt := 0; ; attempt++ {
+		existing, ok, err := m.store.Begin(ctx, record)
+		if err != nil || ok {
+			return nil, err
+		}
+
+		stale := existing.ExpiresAt.Before(record.CreatedAt) ||
+			(!existing.Completed && existing.CreatedAt.Before(record.CreatedAt.Add(-lockTimeout)))
+		if !stale || attempt > 0 {
+			return existing, nil
+		}
+
+		taken, err := m.store.TakeOver(ctx, existing, record)
+		if err != nil || taken {
+			return nil, err
+		}
+	}
+}
+
+// StartPurge removes expired keys from the store in the background at the interval.
+func (m *Middleware) StartPurge(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := m.store.DeleteExpired(context.Background()); err != nil {
+				log.Println("failed to delete expired idempotency keys: ", err)
+			}
+		}
+	}()
+}
+
+func replay(w http.ResponseWriter, record, existing *Record) {
+	if existing.Fingerprint != record.Fingerprint {
+		http.Error(w, "Idempotency-Key was already used for a different request", http.StatusUnprocessableEntity)
+		return
+	}
+
+	if !existing.Completed {
+		http.Error(w, "a request with this Idempotency-Key is still being processed", http.StatusConflict)
+		return
+	}
+
+	if existing.ContentType != "" {
+		w.Header().Set("Content-Type", existing.ContentType)
+	}
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(existing.StatusCode)
+	w.Write(existing.Body)
+}
+
+// fingerprint hashes what identifies a request, the key may only be reused for the same request.
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.Path+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder passes the response on to the client and keeps a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,313 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memStore struct {
+	mu      sync.Mutex
+	records map[string]*Record
+}
+
+func newMemStore() *memStore {
+	return &memStore{records: map[string]*Record{}}
+}
+
+func (s *memStore) Begin(ctx context.Context, record *Record) (*Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.records[record.Scope+"/"+record.Key]; ok {
+		copied := *existing
+		return &copied, false, nil
+	}
+
+	copied := *record
+	s.records[record.Scope+"/"+record.Key] = &copied
+	return nil, true, nil
+}
+
+func (s *memStore) TakeOver(ctx context.Context, stale, record *Record) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.records[record.Scope+"/"+record.Key]
+	if !ok || !existing.CreatedAt.Equal(stale.CreatedAt) {
+		return false, nil
+	}
+
+	copied := *record
+	s.records[record.Scope+"/"+record.Key] = &copied
+	return true, nil
+}
+
+func (s *memStore) Complete(ctx context.Context, record *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.records[record.Scope+"/"+record.Key]
+	if !ok || !existing.CreatedAt.Equal(record.CreatedAt) {
+		return ErrNotHeld
+	}
+
+	copied := *record
+	s.records[record.Scope+"/"+record.Key] = &copied
+	return nil
+}
+
+func (s *memStore) Delete(ctx context.Context, record *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.records[record.Scope+"/"+record.Key]
+	if ok && existing.CreatedAt.Equal(record.CreatedAt) {
+		delete(s.records, record.Scope+"/"+record.Key)
+	}
+	return nil
+}
+
+// racingStore lets the first two requests both see the record of a key before either takes it over
+type racingStore struct {
+	*memStore
+	begun atomic.Int32
+	ready sync.WaitGroup
+}
+
+func (s *racingStore) Begin(ctx context.Context, record *Record) (*Record, bool, error) {
+	existing, ok, err := s.memStore.Begin(ctx, record)
+	if s.begun.Add(1) <= 2 {
+		s.ready.Done()
+		s.ready.Wait()
+	}
+	return existing, ok, err
+}
+
+func (s *memStore) DeleteExpired(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for k, record := range s.records {
+		if record.ExpiresAt.Before(time.Now()) {
+			delete(s.records, k)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// countingHandler creates a resource on every call and answers with its number
+func countingHandler(calls *int, status int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"order":` + strconv.Itoa(*calls) + `}`))
+	}
+}
+
+func send(handler http.HandlerFunc, key, user, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/orders", strings.NewReader(body))
+	if key != "" {
+		r.Header.Set(Header, key)
+	}
+	r.Header.Set("X-User", user)
+
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+func userScope(r *http.Request) string {
+	return r.Header.Get("X-User")
+}
+
+func TestReplay(t *testing.T) {
+	var calls int
+	handler := New(newMemStore(), time.Hour, userScope).Wrap(countingHandler(&calls, http.StatusCreated))
+
+	first := send(handler, "key", "alice", `{"products":[1]}`)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get(ReplayedHeader))
+
+	retry := send(handler, "key", "alice", `{"products":[1]}`)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(ReplayedHeader))
+	assert.Equal(t, "application/json", retry.Header().Get("Content-Type"))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, 1, calls)
+
+	// Keys of different callers are independent
+	other := send(handler, "key", "bob", `{"products":[1]}`)
+	assert.Equal(t, http.StatusCreated, other.Code)
+	assert.Empty(t, other.Header().Get(ReplayedHeader))
+	assert.Equal(t, 2, calls)
+}
+
+func TestWithoutKey(t *testing.T) {
+	var calls int
+	handler := New(newMemStore(), time.Hour, userScope).Wrap(countingHandler(&calls, http.StatusCreated))
+
+	send(handler, "", "alice", `{}`)
+	send(handler, "", "alice", `{}`)
+	assert.Equal(t, 2, calls)
+}
+
+func TestKeyReusedForDifferentRequest(t *testing.T) {
+	var calls int
+	handler := New(newMemStore(), time.Hour, userScope).Wrap(countingHandler(&calls, http.StatusCreated))
+
+	send(handler, "key", "alice", `{"products":[1]}`)
+	w := send(handler, "key", "alice", `{"products":[2]}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRequestInProgress(t *testing.T) {
+	store := newMemStore()
+	handler := New(store, time.Hour, userScope).Wrap(func(w http.ResponseWriter, r *http.Request) {
+		// A retry arrives while the first request is still handled
+		retry := send(New(store, time.Hour, userScope).Wrap(countingHandler(new(int), http.StatusCreated)), "key", "alice", `{}`)
+		assert.Equal(t, http.StatusConflict, retry.Code)
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	w := send(handler, "key", "alice", `{}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestErrorsAreNotStored(t *testing.T) {
+	var calls int
+	store := newMemStore()
+	failing := New(store, time.Hour, userScope).Wrap(countingHandler(&calls, http.StatusConflict))
+
+	assert.Equal(t, http.StatusConflict, send(failing, "key", "alice", `{}`).Code)
+	assert.Equal(t, http.StatusConflict, send(failing, "key", "alice", `{}`).Code)
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, store.records)
+}
+
+func TestExpiredAndAbandonedKeys(t *testing.T) {
+	testCases := []struct {
+		name   string
+		record Record
+	}{
+		{
+			name:   "Expired key",
+			record: Record{Completed: true, StatusCode: http.StatusCreated, CreatedAt: time.Now().Add(-2 * time.Hour), ExpiresAt: time.Now().Add(-time.Hour)},
+		},
+		{
+			name:   "Abandoned request",
+			record: Record{CreatedAt: time.Now().Add(-2 * lockTimeout), ExpiresAt: time.Now().Add(time.Hour)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int
+			store := newMemStore()
+			handler := New(store, time.Hour, userScope).Wrap(countingHandler(&calls, http.StatusCreated))
+
+			record := tc.record
+			record.Scope, record.Key, record.Fingerprint = "alice", "key", "other request"
+			store.records["alice/key"] = &record
+
+			w := send(handler, "key", "alice", `{}`)
+			assert.Equal(t, http.StatusCreated, w.Code)
+			assert.Empty(t, w.Header().Get(ReplayedHeader))
+			assert.Equal(t, 1, calls)
+		})
+	}
+}
+
+func TestConcurrentTakeOver(t *testing.T) {
+	store := &racingStore{memStore: newMemStore()}
+	store.ready.Add(2)
+	store.records["alice/key"] = &Record{Scope: "alice", Key: "key", Fingerprint: "other request", CreatedAt: time.Now().Add(-2 * lockTimeout), ExpiresAt: time.Now().Add(time.Hour)}
+
+	var calls atomic.Int32
+	handler := New(store, time.Hour, userScope).Wrap(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	var wg sync.WaitGroup
+	codes := make([]int, 2)
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = send(handler, "key", "alice", `{}`).Code
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load(), "only one of the retries takes the abandoned key over")
+	for _, code := range codes {
+		assert.Contains(t, []int{http.StatusCreated, http.StatusConflict}, code)
+	}
+}
+
+func TestSlowRequestKeepsOffTakenOverKey(t *testing.T) {
+	testCases := []struct {
+		name   string
+		status int
+	}{
+		{name: "Failed request", status: http.StatusInternalServerError},
+		{name: "Completed request", status: http.StatusCreated},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newMemStore()
+			var calls int
+			retry := New(store, time.Hour, userScope).Wrap(countingHandler(&calls, http.StatusCreated))
+
+			slow := New(store, time.Hour, userScope).Wrap(func(w http.ResponseWriter, r *http.Request) {
+				// The request takes so long that its key is taken over by a retry
+				store.mu.Lock()
+				store.records["alice/key"].CreatedAt = time.Now().Add(-2 * lockTimeout)
+				store.mu.Unlock()
+				assert.Equal(t, http.StatusCreated, send(retry, "key", "alice", `{}`).Code)
+
+				w.WriteHeader(tc.status)
+				w.Write([]byte(`{"order":"slow"}`))
+			})
+			send(slow, "key", "alice", `{}`)
+
+			require.Contains(t, store.records, "alice/key")
+			assert.Equal(t, `{"order":1}`, string(store.records["alice/key"].Body))
+		})
+	}
+}
+
+func TestKeyTooLong(t *testing.T) {
+	var calls int
+	handler := New(newMemStore(), time.Hour, userScope).Wrap(countingHandler(&calls, http.StatusCreated))
+
+	w := send(handler, strings.Repeat("k", maxKeyLength+1), "alice", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, 0, calls)
+}
+
+func TestKeyFromContext(t *testing.T) {
+	var key string
+	handler := New(newMemStore(), time.Hour, nil).Wrap(func(w http.ResponseWriter, r *http.Request) {
+		key = KeyFromContext(r.Context())
+	})
+
+	send(handler, "key", "", `{}`)
+	require.Equal(t, "key", key)
+	assert.Empty(t, KeyFromContext(context.Background()))
+}
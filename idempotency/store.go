@@ -0,0 +1,165 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Record is an idempotency key together with the response of the request it was first sent with.
+type Record struct {
+	// Scope keeps the keys of different callers apart, e.g. the ID of the user
+	Scope string
+	Key   string
+	// Fingerprint is a hash of the request, the key cannot be reused for another request
+	Fingerprint string
+	// Completed is false while the first request is still being handled
+	Completed   bool
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	// CreatedAt tells the records of a key apart when it is taken over by another request
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// ErrNotHeld is returned when a record was taken over by another request in the meantime
+var ErrNotHeld = errors.New("idempotency key is held by another request")
+
+// Store persists idempotency records.
+type Store interface {
+	// Begin saves a new, not yet completed record. If the key is already taken
+	// the existing record is returned with false.
+	Begin(ctx context.Context, record *Record) (*Record, bool, error)
+
+	// TakeOver replaces the stale record with a new one for the same key. It returns false
+	// if the stale record is gone, e.g. because another request took the key over first.
+	TakeOver(ctx context.Context, stale, record *Record) (bool, error)
+
+	// Complete saves the response of a record, ErrNotHeld is returned if it was taken over
+	Complete(ctx context.Context, record *Record) error
+
+	// Delete removes a record so its key can be used again, unless it was taken over
+	Delete(ctx context.Context, record *Record) error
+
+	// DeleteExpired removes the expired records and returns how many were removed
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// PostgresStore keeps the records in the idempotency_keys table of a Postgres database.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Begin inserts the record unless the key is taken, in which case the existing record is returned.
+func (s *PostgresStore) Begin(ctx context.Context, record *Record) (*Record, bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+	INSERT INTO idempotency_keys (scope, idempotency_key, fingerprint, created_at, expires_at)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (scope, idempotency_key) DO NOTHING`,
+		record.Scope, record.Key, record.Fingerprint, record.CreatedAt, record.ExpiresAt)
+	if err != nil {
+		return nil, false, err
+	}
+
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if inserted == 1 {
+		return nil, true, nil
+	}
+
+	existing := &Record{Scope: record.Scope, Key: record.Key}
+	var statusCode sql.NullInt64
+	var contentType sql.NullString
+
+	err = s.db.QueryRowContext(ctx, `
+	SELECT fingerprint, completed_at IS NOT NULL, status_code, content_type, response_body, created_at, expires_at
+	FROM idempotency_keys
+	WHERE scope = $1 AND idempotency_key = $2`, record.Scope, record.Key).
+		Scan(&existing.Fingerprint, &existing.Completed, &statusCode, &contentType, &existing.Body, &existing.CreatedAt, &existing.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Deleted in the meantime, the caller tries again
+		existing.ExpiresAt = time.Time{}
+		return existing, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	existing.StatusCode = int(statusCode.Int64)
+	existing.ContentType = contentType.String
+
+	return existing, false, nil
+}
+
+// TakeOver claims the row of the stale record for the new one. The update only matches while
+// the row still holds the stale record, so of concurrent requests only one takes the key over.
+func (s *PostgresStore) TakeOver(ctx context.Context, stale, record *Record) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+	UPDATE idempotency_keys
+	SET fingerprint = $1, created_at = $2, expires_at = $3,
+		status_code = NULL, content_type = NULL, response_body = NULL, completed_at = NULL
+	WHERE scope = $4 AND idempotency_key = $5 AND created_at = $6`,
+		record.Fingerprint, record.CreatedAt, record.ExpiresAt, record.Scope, record.Key, stale.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return updated == 1, nil
+}
+
+// Complete stores the response of the record if the key was not taken over.
+func (s *PostgresStore) Complete(ctx context.Context, record *Record) error {
+	res, err := s.db.ExecContext(ctx, `
+	UPDATE idempotency_keys
+	SET status_code = $1, content_type = $2, response_body = $3, completed_at = $4
+	WHERE scope = $5 AND idempotency_key = $6 AND created_at = $7`,
+		record.StatusCode, record.ContentType, record.Body, time.Now(), record.Scope, record.Key, record.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		return ErrNotHeld
+	}
+
+	return nil
+}
+
+// Delete removes the record of a key if the key was not taken over.
+func (s *PostgresStore) Delete(ctx context.Context, record *Record) error {
+	_, err := s.db.ExecContext(ctx, `
+	DELETE FROM idempotency_keys
+	WHERE scope = $1 AND idempotency_key = $2 AND created_at = $3`, record.Scope, record.Key, record.CreatedAt)
+	return err
+}
+
+// DeleteExpired removes the records whose expiry has passed.
+func (s *PostgresStore) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `
+	DELETE FROM idempotency_keys
+	WHERE expires_at < $1`, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,374 @@
+// Package money holds amounts of money as integer minor units of an ISO 4217 currency, such as
+// cents for USD, so that they add up exactly.
+//
+// Where a fraction of a minor unit comes up, when a rate is applied to an amount or an amount
+// is split, it is rounded half away from zero: 0.5 cents becomes 1 cent and -0.5 cents -1 cent.
+// Rates are taken as the decimal they are written as, 0.07 is exactly 7/100.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("amounts are in different currencies")
+)
+
+// digits are the digits after the decimal point of the currencies whose minor unit is not
+// a hundredth of the major unit
+var digits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// Money is an amount of money in the minor unit of its currency. The zero value is no money
+// in no currency, it can be added to an amount of any currency.
+type Money struct {
+	// Amount in the minor unit of the currency, such as cents
+	Amount int64 `json:"amount"`
+	// Currency is the ISO 4217 code of the currency in upper case
+	Currency string `json:"currency"`
+}
+
+// New returns an amount in the minor unit of the currency
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// Zero returns no money in the currency
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+// Digits returns the number of digits after the decimal point of a currency
+func Digits(currency string) (int, error) {
+	if len(currency) != 3 {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+	for _, c := range currency {
+		if c < 'A' || c > 'Z' {
+			return 0, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+		}
+	}
+
+	if d, ok := digits[currency]; ok {
+		return d, nil
+	}
+	return 2, nil
+}
+
+// Parse parses a decimal amount of the currency, such as "19.99" for 1999 cents. Amounts with
+// more digits after the decimal point than the currency has are rejected, not rounded.
+func Parse(amount, currency string) (Money, error) {
+	currency = strings.ToUpper(currency)
+	d, err := Digits(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	s := strings.TrimSpace(amount)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+
+	whole, frac, hasPoint := strings.Cut(s, ".")
+	if whole == "" || !isDigits(whole) || !isDigits(frac) || (hasPoint && frac == "") {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > d {
+		return Money{}, fmt.Errorf("%w: %q has more than %d decimals", ErrInvalidAmount, amount, d)
+	}
+
+	minor, err := strconv.ParseInt(whole+frac+strings.Repeat("0", d-len(frac)), 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	if negative {
+		minor = -minor
+	}
+
+	return Money{Amount: minor, Currency: currency}, nil
+}
+
+// FromFloat converts a decimal amount kept as a float, such as a price stored as a number,
+// rounding it to the minor unit of the currency
+func FromFloat(amount float64, currency string) (Money, error) {
+	currency = strings.ToUpper(currency)
+	d, err := Digits(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return Money{}, fmt.Errorf("%w: %v", ErrInvalidAmount, amount)
+	}
+
+	r := decimal(amount)
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d)), nil)))
+
+	minor, ok := round(r)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %v", ErrInvalidAmount, amount)
+	}
+
+	return Money{Amount: minor, Currency: currency}, nil
+}
+
+// MustParse is Parse for amounts known to be valid, such as in tests. It panics on an invalid amount.
+func MustParse(amount, currency string) Money {
+	m, err := Parse(amount, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Neg returns the amount with the opposite sign
+func (m Money) Neg() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// Add returns the sum of two amounts. Adding amounts of different currencies is a bug in the
+// caller, it panics with ErrCurrencyMismatch. Amounts that are not known to share a currency,
+// such as rows read from a database, are added with Sum instead.
+func (m Money) Add(o Money) Money {
+	return Money{Amount: m.Amount + o.Amount, Currency: m.currency(o)}
+}
+
+// Sum returns the sum of the amounts, or ErrCurrencyMismatch if they are in different
+// currencies. The sum of no amounts is no money in no currency.
+func Sum(amounts ...Money) (Money, error) {
+	var sum Money
+	for _, a := range amounts {
+		currency, err := sum.sameCurrency(a)
+		if err != nil {
+			return Money{}, err
+		}
+		sum = Money{Amount: sum.Amount + a.Amount, Currency: currency}
+	}
+	return sum, nil
+}
+
+// Sub returns the difference of two amounts, it panics like Add if their currencies differ
+func (m Money) Sub(o Money) Money {
+	return Money{Amount: m.Amount - o.Amount, Currency: m.currency(o)}
+}
+
+// Mul returns the amount times n, such as the price of n items
+func (m Money) Mul(n int64) Money {
+	return Money{Amount: m.Amount * n, Currency: m.Currency}
+}
+
+// MulRate returns the amount times a rate, such as the tax at a tax rate, rounded to the minor unit
+func (m Money) MulRate(rate float64) Money {
+	r := new(big.Rat).SetInt64(m.Amount)
+	r.Mul(r, decimal(rate))
+
+	minor, _ := round(r)
+	return Money{Amount: minor, Currency: m.Currency}
+}
+
+// DivRate returns the amount divided by a rate, rounded to the minor unit. It takes the net
+// amount out of a gross one with DivRate(1 + tax rate). The rate must not be 0.
+func (m Money) DivRate(rate float64) Money {
+	r := new(big.Rat).SetInt64(m.Amount)
+	r.Quo(r, decimal(rate))
+
+	minor, _ := round(r)
+	return Money{Amount: minor, Currency: m.Currency}
+}
+
+// Share returns the part of the amount that falls to part of whole, such as the price of 2
+// of 3 items, rounded to the minor unit. Shares of 0 to n of n add up to the amount when each
+// is taken as Share(k, n) - Share(k-1, n).
+func (m Money) Share(part, whole int64) Money {
+	if whole == 0 {
+		return Money{Currency: m.Currency}
+	}
+
+	minor, _ := round(new(big.Rat).SetFrac(new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(part)), big.NewInt(whole)))
+	return Money{Amount: minor, Currency: m.Currency}
+}
+
+// Allocate splits the amount by the weights, such as a discount over the lines of an order by
+// their prices. The parts add up to the amount, the minor units left over after rounding down
+// go to the parts with the largest remainders. Without weights the amount is not split.
+func (m Money) Allocate(weights []int64) []Money {
+	parts := make([]Money, len(weights))
+	var total int64
+	for i, w := range weights {
+		parts[i].Currency = m.Currency
+		if w > 0 {
+			total += w
+		}
+	}
+	if total == 0 {
+		return parts
+	}
+
+	amount := m.Amount
+	if amount < 0 {
+		amount = -amount
+	}
+
+	remainders := make([]*big.Int, len(weights))
+	var allocated int64
+	for i, w := range weights {
+		if w <= 0 {
+			remainders[i] = big.NewInt(-1)
+			continue
+		}
+		q, r := new(big.Int).QuoRem(new(big.Int).Mul(big.NewInt(amount), big.NewInt(w)), big.NewInt(total), new(big.Int))
+		parts[i].Amount = q.Int64()
+		remainders[i] = r
+		allocated += parts[i].Amount
+	}
+
+	for left := amount - allocated; left > 0; left-- {
+		largest := -1
+		for i, r := range remainders {
+			if largest == -1 || r.Cmp(remainders[largest]) > 0 {
+				largest = i
+			}
+		}
+		parts[largest].Amount++
+		remainders[largest] = big.NewInt(-1)
+	}
+
+	if m.Amount < 0 {
+		for i := range parts {
+			parts[i].Amount = -parts[i].Amount
+		}
+	}
+
+	return parts
+}
+
+// Cmp compares two amounts of the same currency, it returns -1, 0 or 1 if m is less than,
+// equal to or more than o
+func (m Money) Cmp(o Money) int {
+	m.currency(o)
+	switch {
+	case m.Amount < o.Amount:
+		return -1
+	case m.Amount > o.Amount:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Min returns the smaller of two amounts of the same currency
+func Min(a, b Money) Money {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
+
+// Decimal formats the amount as a decimal in the major unit, such as "19.99"
+func (m Money) Decimal() string {
+	d, err := Digits(m.Currency)
+	if err != nil {
+		d = 2
+	}
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	s := strconv.FormatInt(amount, 10)
+	if d == 0 {
+		return sign + s
+	}
+	if len(s) <= d {
+		s = strings.Repeat("0", d-len(s)+1) + s
+	}
+
+	return sign + s[:len(s)-d] + "." + s[len(s)-d:]
+}
+
+// String formats the amount with its currency, such as "19.99 USD"
+func (m Money) String() string {
+	return strings.TrimSpace(m.Decimal() + " " + m.Currency)
+}
+
+// currency returns the currency of the result of an operation on two amounts. No money in
+// no currency takes the currency of the other amount.
+func (m Money) currency(o Money) string {
+	currency, err := m.sameCurrency(o)
+	if err != nil {
+		panic(err)
+	}
+	return currency
+}
+
+// sameCurrency is currency for amounts that may be in different currencies, it returns
+// ErrCurrencyMismatch instead of panicking
+func (m Money) sameCurrency(o Money) (string, error) {
+	switch {
+	case m.Currency == o.Currency:
+		return m.Currency, nil
+	case m.Currency == "" && m.Amount == 0:
+		return o.Currency, nil
+	case o.Currency == "" && o.Amount == 0:
+		return m.Currency, nil
+	}
+	return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+}
+
+// decimal returns the decimal a float is written as, so that 0.07 is 7/100 and not the
+// binary fraction closest to it
+func decimal(f float64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'f', -1, 64))
+	return r
+}
+
+// round rounds a number half away from zero, it reports false if the result does not fit an int64
+func round(r *big.Rat) (int64, bool) {
+	q, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+
+	rem.Abs(rem).Lsh(rem, 1)
+	if rem.Cmp(r.Denom()) >= 0 {
+		if r.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+
+	return q.Int64(), q.IsInt64()
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
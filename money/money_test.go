@@ -0,0 +1,183 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name     string
+		amount   string
+		currency string
+		want     Money
+		err      error
+	}{
+		{name: "Cents", amount: "19.99", currency: "USD", want: New(1999, "USD")},
+		{name: "Whole amount", amount: "20", currency: "usd", want: New(2000, "USD")},
+		{name: "One decimal", amount: "0.5", currency: "EUR", want: New(50, "EUR")},
+		{name: "Trailing zeros", amount: "1.2500", currency: "USD", want: New(125, "USD")},
+		{name: "Negative", amount: "-3.05", currency: "USD", want: New(-305, "USD")},
+		{name: "Zero-decimal currency", amount: "1500", currency: "JPY", want: New(1500, "JPY")},
+		{name: "Three-decimal currency", amount: "1.234", currency: "KWD", want: New(1234, "KWD")},
+		{name: "Too many decimals", amount: "19.999", currency: "USD", err: ErrInvalidAmount},
+		{name: "Decimals of a zero-decimal currency", amount: "1500.5", currency: "JPY", err: ErrInvalidAmount},
+		{name: "Not a number", amount: "abc", currency: "USD", err: ErrInvalidAmount},
+		{name: "Point without decimals", amount: "19.", currency: "USD", err: ErrInvalidAmount},
+		{name: "Empty", amount: "", currency: "USD", err: ErrInvalidAmount},
+		{name: "Unknown currency", amount: "1", currency: "US", err: ErrUnknownCurrency},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Parse(tc.amount, tc.currency)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	testCases := []struct {
+		amount float64
+		want   int64
+	}{
+		{amount: 19.99, want: 1999},
+		{amount: 0.1 + 0.2, want: 30},
+		// 1.005 is stored as 1.00499999999999989..., it is taken as the decimal it is written as
+		{amount: 1.005, want: 101},
+		{amount: -2.675, want: -268},
+		{amount: 100, want: 10000},
+	}
+
+	for _, tc := range testCases {
+		got, err := FromFloat(tc.amount, "USD")
+		require.NoError(t, err)
+		assert.Equal(t, New(tc.want, "USD"), got, "%v", tc.amount)
+	}
+
+	_, err := FromFloat(1e300, "USD")
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+}
+
+func TestDecimal(t *testing.T) {
+	assert.Equal(t, "19.99", New(1999, "USD").Decimal())
+	assert.Equal(t, "0.05", New(5, "USD").Decimal())
+	assert.Equal(t, "-0.50", New(-50, "EUR").Decimal())
+	assert.Equal(t, "1500", New(1500, "JPY").Decimal())
+	assert.Equal(t, "0.007", New(7, "KWD").Decimal())
+	assert.Equal(t, "19.99 USD", New(1999, "USD").String())
+}
+
+func TestArithmetic(t *testing.T) {
+	price := New(1999, "USD")
+
+	assert.Equal(t, New(5997, "USD"), price.Mul(3))
+	assert.Equal(t, New(2499, "USD"), price.Add(New(500, "USD")))
+	assert.Equal(t, New(1499, "USD"), price.Sub(New(500, "USD")))
+	assert.Equal(t, price, Money{}.Add(price), "no money takes the currency of the other amount")
+	assert.Equal(t, 1, price.Cmp(New(1000, "USD")))
+	assert.Equal(t, New(1000, "USD"), Min(price, New(1000, "USD")))
+
+	assert.PanicsWithError(t, "amounts are in different currencies: USD and EUR", func() {
+		price.Add(New(100, "EUR"))
+	})
+}
+
+func TestSum(t *testing.T) {
+	sum, err := Sum(New(1999, "USD"), New(500, "USD"), Money{})
+	require.NoError(t, err)
+	assert.Equal(t, New(2499, "USD"), sum)
+
+	sum, err = Sum()
+	require.NoError(t, err)
+	assert.Equal(t, Money{}, sum)
+
+	_, err = Sum(New(1999, "USD"), New(100, "EUR"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+	assert.EqualError(t, err, "amounts are in different currencies: USD and EUR")
+}
+
+func TestMulRate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		amount int64
+		rate   float64
+		want   int64
+	}{
+		{name: "Exact", amount: 1000, rate: 0.19, want: 190},
+		// 150 * 0.29 is 43.4999... in floating point
+		{name: "Half a cent up", amount: 150, rate: 0.29, want: 44},
+		{name: "Half a cent away from zero", amount: -250, rate: 0.07, want: -18},
+		{name: "Below half a cent", amount: 333, rate: 0.1, want: 33},
+		{name: "Percentage", amount: 4999, rate: 0.15, want: 750},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, New(tc.want, "USD"), New(tc.amount, "USD").MulRate(tc.rate))
+		})
+	}
+
+	// The net amount of a gross price at 19%
+	assert.Equal(t, New(1000, "EUR"), New(1190, "EUR").DivRate(1.19))
+	assert.Equal(t, New(840, "EUR"), New(1000, "EUR").DivRate(1.19))
+}
+
+func TestShare(t *testing.T) {
+	total := New(1000, "USD")
+
+	var sum Money
+	for k := int64(1); k <= 3; k++ {
+		sum = sum.Add(total.Share(k, 3).Sub(total.Share(k-1, 3)))
+	}
+	assert.Equal(t, total, sum)
+	assert.Equal(t, New(667, "USD"), total.Share(2, 3))
+	assert.Equal(t, Zero("USD"), total.Share(1, 0))
+}
+
+func TestAllocate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		amount  int64
+		weights []int64
+		want    []int64
+	}{
+		{name: "Even", amount: 1000, weights: []int64{1, 1}, want: []int64{500, 500}},
+		{name: "Left over cent to the largest remainder", amount: 1000, weights: []int64{1, 1, 1}, want: []int64{334, 333, 333}},
+		{name: "By price", amount: 500, weights: []int64{3000, 1000}, want: []int64{375, 125}},
+		{name: "Remainders", amount: 100, weights: []int64{1, 2, 4}, want: []int64{14, 29, 57}},
+		{name: "Without weight", amount: 100, weights: []int64{0, 2}, want: []int64{0, 100}},
+		{name: "Negative", amount: -1000, weights: []int64{1, 1, 1}, want: []int64{-334, -333, -333}},
+		{name: "Nothing to split by", amount: 100, weights: []int64{0, 0}, want: []int64{0, 0}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parts := New(tc.amount, "USD").Allocate(tc.weights)
+			got := make([]int64, len(parts))
+			for i, p := range parts {
+				got[i] = p.Amount
+				assert.Equal(t, "USD", p.Currency)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(New(1999, "USD"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount": 1999, "currency": "USD"}`, string(data))
+
+	var m Money
+	require.NoError(t, json.Unmarshal(data, &m))
+	assert.Equal(t, New(1999, "USD"), m)
+}
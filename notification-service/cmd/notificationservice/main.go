@@ -8,7 +8,9 @@ import (
 	"github.com/NeGat1FF/e-commerce/notification-service/internal/config"
 	"github.com/NeGat1FF/e-commerce/notification-service/internal/email"
 	"github.com/NeGat1FF/e-commerce/notification-service/internal/server"
+	"github.com/NeGat1FF/e-commerce/notification-service/internal/sms"
 	mail "github.com/NeGat1FF/e-commerce/notification-service/proto"
+	"github.com/NeGat1FF/e-commerce/svcauth"
 	"google.golang.org/grpc"
 )
 
@@ -25,16 +27,49 @@ func main() {
 		panic(err)
 	}
 
-	server := server.NewServer(sender)
+	tlsConfig := svcauth.Config{CertFile: cfg.TLSCertFile, KeyFile: cfg.TLSKeyFile, CAFile: cfg.TLSCAFile}
+
+	creds, err := svcauth.DialOption(tlsConfig)
+	if err != nil {
+		log.Fatalf("failed to load service certificates: %v", err)
+	}
+
+	conn, err := grpc.NewClient(cfg.UserServiceURL, creds)
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+
+	mailServer := server.NewServer(sender, mail.NewPreferencesServiceClient(conn))
+
+	var provider sms.Provider
+	switch cfg.SMSProvider {
+	case "twilio":
+		provider = sms.NewTwilioProvider(client, sms.TwilioAPIURL, cfg.TwilioAccountSID, cfg.TwilioAuthToken, cfg.TwilioFrom)
+	case "log":
+		provider = sms.NewLogProvider()
+	default:
+		log.Fatalf("unknown sms provider %q", cfg.SMSProvider)
+	}
 
 	lis, err := net.Listen("tcp", ":50051")
 	if err != nil {
 		panic(err)
 	}
 
-	s := grpc.NewServer()
+	// Only the user service sends email and SMS for now
+	serverOpts, err := svcauth.ServerOptions(tlsConfig, svcauth.Policy{
+		"/proto.MailService/SendMail": {"user-service"},
+		"/proto.SmsService/SendSms":   {"user-service"},
+	})
+	if err != nil {
+		log.Fatalf("failed to load service certificates: %v", err)
+	}
+
+	s := grpc.NewServer(serverOpts...)
 
-	mail.RegisterMailServiceServer(s, server)
+	mail.RegisterMailServiceServer(s, mailServer)
+	mail.RegisterSmsServiceServer(s, server.NewSmsServer(sms.NewSmsSender(provider)))
 
 	log.Printf("server listening at %v", lis.Addr())
 
@@ -5,6 +5,7 @@ go 1.22.7
 toolchain go1.22.9
 
 require (
+	github.com/NeGat1FF/e-commerce/svcauth v0.0.0
 	github.com/joho/godotenv v1.5.1
 	google.golang.org/grpc v1.68.0
 	google.golang.org/protobuf v1.35.2
@@ -16,3 +17,5 @@ require (
 	golang.org/x/text v0.18.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240903143218-8af14fe29dc1 // indirect
 )
+
+replace github.com/NeGat1FF/e-commerce/svcauth => ../svcauth
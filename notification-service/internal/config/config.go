@@ -8,7 +8,19 @@ import (
 
 // Config holds all the configuration values for the application
 type Config struct {
-	JWTSecret string
+	JWTSecret      string
+	UserServiceURL string
+
+	// Certificate of the service for mutual TLS with the other services
+	TLSCertFile string
+	TLSKeyFile  string
+	TLSCAFile   string
+
+	// SMSProvider is either "log", which only logs messages, or "twilio"
+	SMSProvider      string
+	TwilioAccountSID string
+	TwilioAuthToken  string
+	TwilioFrom       string
 }
 
 // LoadConfig reads configuration from config file and environment variables
@@ -16,7 +28,21 @@ func LoadConfig() *Config {
 	godotenv.Load()
 
 	cfg := Config{
-		JWTSecret: os.Getenv("JWT_SECRET"),
+		JWTSecret:      os.Getenv("JWT_SECRET"),
+		UserServiceURL: os.Getenv("USER_SERVICE"),
+
+		TLSCertFile: os.Getenv("TLS_CERT_FILE"),
+		TLSKeyFile:  os.Getenv("TLS_KEY_FILE"),
+		TLSCAFile:   os.Getenv("TLS_CA_FILE"),
+
+		SMSProvider:      os.Getenv("SMS_PROVIDER"),
+		TwilioAccountSID: os.Getenv("TWILIO_ACCOUNT_SID"),
+		TwilioAuthToken:  os.Getenv("TWILIO_AUTH_TOKEN"),
+		TwilioFrom:       os.Getenv("TWILIO_FROM"),
+	}
+	if cfg.SMSProvider == "" {
+		cfg.SMSProvider = "log"
 	}
+
 	return &cfg
 }
@@ -21,6 +21,11 @@ type EmailType string
 const (
 	EmailConfirmation EmailType = "email_confirm"
 	PasswordReset     EmailType = "password_reset"
+
+	EmailChangeConfirmation EmailType = "email_change_confirm"
+	EmailChangeNotice       EmailType = "email_change_notice"
+	MagicLink               EmailType = "magic_link"
+	NewDeviceLogin          EmailType = "new_device_login"
 )
 
 type EmailSender struct {
@@ -46,9 +51,11 @@ type EmailPayload struct {
 	Text     string      `json:"text"`
 	HTML     string      `json:"html"`
 	Category string      `json:"category"`
+
+	Headers map[string]string `json:"headers,omitempty"`
 }
 
-func NewEmailPayload(toEmail []string, subject, text, html, category string) (*bytes.Reader, error) {
+func NewEmailPayload(toEmail []string, subject, text, html, category string, headers map[string]string) (*bytes.Reader, error) {
 	// Convert toEmail to ToPayload
 	to := make([]ToPayload, 0)
 	for _, email := range toEmail {
@@ -65,6 +72,7 @@ func NewEmailPayload(toEmail []string, subject, text, html, category string) (*b
 		Text:     text,
 		HTML:     html,
 		Category: category,
+		Headers:  headers,
 	}
 
 	data, err := json.Marshal(&p)
@@ -124,9 +132,27 @@ func (m *EmailSender) SendMail(to []string, tp EmailType, data map[string]string
 		subject = "Email Confirmation"
 	case PasswordReset:
 		subject = "Password Reset"
+	case EmailChangeConfirmation:
+		subject = "Confirm Your New Email Address"
+	case EmailChangeNotice:
+		subject = "Email Change Requested"
+	case MagicLink:
+		subject = "Your Sign-In Link"
+	case NewDeviceLogin:
+		subject = "New Sign-In to Your Account"
+	}
+
+	var headers map[string]string
+
+	// Lets mail clients show their own unsubscribe button, posting to the link unsubscribes in one click
+	if link := data["UnsubscribeLink"]; link != "" {
+		headers = map[string]string{
+			"List-Unsubscribe":      "<" + link + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		}
 	}
 
-	payload, err := NewEmailPayload(to, subject, text.String(), text.String(), "category")
+	payload, err := NewEmailPayload(to, subject, text.String(), text.String(), "category", headers)
 	if err != nil {
 		return err
 	}
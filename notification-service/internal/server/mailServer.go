@@ -5,17 +5,21 @@ import (
 
 	"github.com/NeGat1FF/e-commerce/notification-service/internal/email"
 	mail "github.com/NeGat1FF/e-commerce/notification-service/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Server struct {
 	mail.UnimplementedMailServiceServer
 
-	sender *email.EmailSender
+	sender      *email.EmailSender
+	preferences mail.PreferencesServiceClient
 }
 
-func NewServer(sender *email.EmailSender) *Server {
+func NewServer(sender *email.EmailSender, preferences mail.PreferencesServiceClient) *Server {
 	return &Server{
-		sender: sender,
+		sender:      sender,
+		preferences: preferences,
 	}
 }
 
@@ -27,9 +31,47 @@ func (s *Server) SendMail(ctx context.Context, req *mail.MailRequest) (res *mail
 		emailType = email.EmailConfirmation
 	case 1:
 		emailType = email.PasswordReset
+	case 2:
+		emailType = email.EmailChangeConfirmation
+	case 3:
+		emailType = email.EmailChangeNotice
+	case 4:
+		emailType = email.MagicLink
+	case 5:
+		emailType = email.NewDeviceLogin
 	}
 
-	err = s.sender.SendMail(req.To, emailType, req.Data)
+	data := req.Data
+
+	// Messages with a category are only sent if the user agreed to it, account
+	// and security messages have no category and are always sent
+	if req.Category != "" {
+		if req.UserId == "" {
+			return nil, status.Error(codes.InvalidArgument, "user_id is required for messages with a category")
+		}
+
+		check, err := s.preferences.CheckConsent(ctx, &mail.CheckConsentRequest{
+			UserId:   req.UserId,
+			Channel:  "email",
+			Category: req.Category,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if !check.Allowed {
+			return &mail.MailResponse{Suppressed: true}, nil
+		}
+
+		data = make(map[string]string, len(req.Data)+2)
+		for k, v := range req.Data {
+			data[k] = v
+		}
+		data["UnsubscribeLink"] = check.UnsubscribeUrl
+		data["Language"] = check.Language
+	}
+
+	err = s.sender.SendMail(req.To, emailType, data)
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+
+	"github.com/NeGat1FF/e-commerce/notification-service/internal/sms"
+	mail "github.com/NeGat1FF/e-commerce/notification-service/proto"
+)
+
+type SmsServer struct {
+	mail.UnimplementedSmsServiceServer
+
+	sender *sms.SmsSender
+}
+
+func NewSmsServer(sender *sms.SmsSender) *SmsServer {
+	return &SmsServer{
+		sender: sender,
+	}
+}
+
+func (s *SmsServer) SendSms(ctx context.Context, req *mail.SmsRequest) (res *mail.SmsResponse, err error) {
+	var smsType sms.SmsType
+
+	switch req.Type {
+	case mail.SmsType_PHONE_VERIFICATION:
+		smsType = sms.PhoneVerification
+	case mail.SmsType_LOGIN_CODE:
+		smsType = sms.LoginCode
+	case mail.SmsType_TWO_FACTOR_CODE:
+		smsType = sms.TwoFactorCode
+	}
+
+	err = s.sender.SendSms(ctx, req.To, smsType, req.Data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res = &mail.SmsResponse{
+		Success: true,
+	}
+
+	return
+}
@@ -0,0 +1,55 @@
+package sms
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// Provider delivers text messages to phone numbers.
+type Provider interface {
+	Send(ctx context.Context, to, body string) error
+}
+
+// Message is a text message sent through a provider.
+type Message struct {
+	To   string
+	Body string
+}
+
+// maxLoggedMessages bounds how many sent messages a LogProvider keeps.
+const maxLoggedMessages = 100
+
+// LogProvider only logs messages instead of sending them, it is meant for
+// development and tests. The latest messages are kept so tests can read them.
+type LogProvider struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewLogProvider creates a new log-only provider.
+func NewLogProvider() *LogProvider {
+	return &LogProvider{}
+}
+
+// Send logs the message.
+func (p *LogProvider) Send(ctx context.Context, to, body string) error {
+	log.Printf("sms to %s: %s", to, body)
+
+	p.mu.Lock()
+	p.messages = append(p.messages, Message{To: to, Body: body})
+	if len(p.messages) > maxLoggedMessages {
+		p.messages = p.messages[len(p.messages)-maxLoggedMessages:]
+	}
+	p.mu.Unlock()
+
+	return nil
+}
+
+// Messages returns the messages sent so far.
+func (p *LogProvider) Messages() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Message(nil), p.messages...)
+}
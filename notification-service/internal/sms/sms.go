@@ -0,0 +1,48 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/template"
+)
+
+type SmsType string
+
+const (
+	PhoneVerification SmsType = "phone_verification"
+	LoginCode         SmsType = "login_code"
+	TwoFactorCode     SmsType = "two_factor_code"
+)
+
+// Text messages are short, so unlike emails they are kept here instead of template files
+var messages = map[SmsType]*template.Template{
+	PhoneVerification: template.Must(template.New(string(PhoneVerification)).Parse("Your verification code is {{.Code}}. It expires in {{.Expiration}}.")),
+	LoginCode:         template.Must(template.New(string(LoginCode)).Parse("Your sign-in code is {{.Code}}. It expires in {{.Expiration}}. Don't share it with anyone.")),
+	TwoFactorCode:     template.Must(template.New(string(TwoFactorCode)).Parse("Your security code is {{.Code}}. It expires in {{.Expiration}}. Don't share it with anyone.")),
+}
+
+// SmsSender renders text messages and sends them through a provider.
+type SmsSender struct {
+	provider Provider
+}
+
+func NewSmsSender(provider Provider) *SmsSender {
+	return &SmsSender{
+		provider: provider,
+	}
+}
+
+func (s *SmsSender) SendSms(ctx context.Context, to string, tp SmsType, data map[string]string) error {
+	tmpl, ok := messages[tp]
+	if !ok {
+		return fmt.Errorf("message %s not found", tp)
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return err
+	}
+
+	return s.provider.Send(ctx, to, body.String())
+}
@@ -0,0 +1,72 @@
+package sms_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/notification-service/internal/sms"
+)
+
+func TestSmsSender(t *testing.T) {
+	provider := sms.NewLogProvider()
+	sender := sms.NewSmsSender(provider)
+
+	err := sender.SendSms(context.Background(), "+14155552671", sms.PhoneVerification, map[string]string{"Code": "123456", "Expiration": "10 minutes"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	messages := provider.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(messages))
+	}
+
+	if messages[0].To != "+14155552671" {
+		t.Errorf("unexpected recipient %q", messages[0].To)
+	}
+
+	if messages[0].Body != "Your verification code is 123456. It expires in 10 minutes." {
+		t.Errorf("unexpected body %q", messages[0].Body)
+	}
+
+	if err := sender.SendSms(context.Background(), "+14155552671", "unknown", nil); err == nil {
+		t.Error("expected error for unknown message type")
+	}
+}
+
+func TestTwilioProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "AC123" || pass != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.URL.Path != "/2010-04-01/Accounts/AC123/Messages.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.FormValue("To") != "+14155552671" || r.FormValue("From") != "+15005550006" || r.FormValue("Body") != "hello" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	provider := sms.NewTwilioProvider(server.Client(), server.URL, "AC123", "token", "+15005550006")
+
+	if err := provider.Send(context.Background(), "+14155552671", "hello"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wrong := sms.NewTwilioProvider(server.Client(), server.URL, "AC123", "wrong", "+15005550006")
+
+	if err := wrong.Send(context.Background(), "+14155552671", "hello"); err == nil {
+		t.Error("expected error for rejected credentials")
+	}
+}
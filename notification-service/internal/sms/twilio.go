@@ -0,0 +1,63 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const TwilioAPIURL = "https://api.twilio.com"
+
+// TwilioProvider sends messages through the Twilio Messaging API.
+type TwilioProvider struct {
+	client     *http.Client
+	apiURL     string
+	accountSID string
+	authToken  string
+	from       string
+}
+
+// NewTwilioProvider creates a new Twilio provider sending from the given number, apiURL is usually TwilioAPIURL.
+func NewTwilioProvider(client *http.Client, apiURL, accountSID, authToken, from string) *TwilioProvider {
+	return &TwilioProvider{
+		client:     client,
+		apiURL:     apiURL,
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+	}
+}
+
+// Send sends the message.
+func (p *TwilioProvider) Send(ctx context.Context, to, body string) error {
+	form := url.Values{}
+	form.Set("To", to)
+	form.Set("From", p.from)
+	form.Set("Body", body)
+
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", p.apiURL, p.accountSID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.SetBasicAuth(p.accountSID, p.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("twilio responded with %d: %s", res.StatusCode, msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,298 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.1
+// 	protoc        v3.21.12
+// source: proto/sms.proto
+
+package mail
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SmsType int32
+
+const (
+	SmsType_PHONE_VERIFICATION SmsType = 0
+	SmsType_LOGIN_CODE         SmsType = 1
+	SmsType_TWO_FACTOR_CODE    SmsType = 2
+)
+
+// Enum value maps for SmsType.
+var (
+	SmsType_name = map[int32]string{
+		0: "PHONE_VERIFICATION",
+		1: "LOGIN_CODE",
+		2: "TWO_FACTOR_CODE",
+	}
+	SmsType_value = map[string]int32{
+		"PHONE_VERIFICATION": 0,
+		"LOGIN_CODE":         1,
+		"TWO_FACTOR_CODE":    2,
+	}
+)
+
+func (x SmsType) Enum() *SmsType {
+	p := new(SmsType)
+	*p = x
+	return p
+}
+
+func (x SmsType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SmsType) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_sms_proto_enumTypes[0].Descriptor()
+}
+
+func (SmsType) Type() protoreflect.EnumType {
+	return &file_proto_sms_proto_enumTypes[0]
+}
+
+func (x SmsType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SmsType.Descriptor instead.
+func (SmsType) EnumDescriptor() ([]byte, []int) {
+	return file_proto_sms_proto_rawDescGZIP(), []int{0}
+}
+
+type SmsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// E.164 phone number
+	To   string            `protobuf:"bytes,1,opt,name=to,proto3" json:"to,omitempty"`
+	Type SmsType           `protobuf:"varint,2,opt,name=type,proto3,enum=proto.SmsType" json:"type,omitempty"`
+	Data map[string]string `protobuf:"bytes,3,rep,name=data,proto3" json:"data,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *SmsRequest) Reset() {
+	*x = SmsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sms_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SmsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SmsRequest) ProtoMessage() {}
+
+func (x *SmsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sms_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SmsRequest.ProtoReflect.Descriptor instead.
+func (*SmsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_sms_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SmsRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *SmsRequest) GetType() SmsType {
+	if x != nil {
+		return x.Type
+	}
+	return SmsType_PHONE_VERIFICATION
+}
+
+func (x *SmsRequest) GetData() map[string]string {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type SmsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+}
+
+func (x *SmsResponse) Reset() {
+	*x = SmsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_sms_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SmsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SmsResponse) ProtoMessage() {}
+
+func (x *SmsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_sms_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SmsResponse.ProtoReflect.Descriptor instead.
+func (*SmsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_sms_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SmsResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+var File_proto_sms_proto protoreflect.FileDescriptor
+
+var file_proto_sms_proto_rawDesc = []byte{
+	0x0a, 0x0f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x73, 0x6d, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x05, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xaa, 0x01, 0x0a, 0x0a, 0x53, 0x6d, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x22, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x53, 0x6d,
+	0x73, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x2f, 0x0a, 0x04, 0x64,
+	0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2e, 0x53, 0x6d, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x44, 0x61, 0x74,
+	0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x1a, 0x37, 0x0a, 0x09,
+	0x44, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x27, 0x0a, 0x0b, 0x53, 0x6d, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x2a, 0x46,
+	0x0a, 0x07, 0x53, 0x6d, 0x73, 0x54, 0x79, 0x70, 0x65, 0x12, 0x16, 0x0a, 0x12, 0x50, 0x48, 0x4f,
+	0x4e, 0x45, 0x5f, 0x56, 0x45, 0x52, 0x49, 0x46, 0x49, 0x43, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x10,
+	0x00, 0x12, 0x0e, 0x0a, 0x0a, 0x4c, 0x4f, 0x47, 0x49, 0x4e, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x10,
+	0x01, 0x12, 0x13, 0x0a, 0x0f, 0x54, 0x57, 0x4f, 0x5f, 0x46, 0x41, 0x43, 0x54, 0x4f, 0x52, 0x5f,
+	0x43, 0x4f, 0x44, 0x45, 0x10, 0x02, 0x32, 0x40, 0x0a, 0x0a, 0x53, 0x6d, 0x73, 0x53, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x12, 0x32, 0x0a, 0x07, 0x53, 0x65, 0x6e, 0x64, 0x53, 0x6d, 0x73, 0x12,
+	0x11, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x53, 0x6d, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x12, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x53, 0x6d, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x35, 0x5a, 0x33, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x4e, 0x65, 0x47, 0x61, 0x74, 0x31, 0x46, 0x46, 0x2f,
+	0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2d, 0x73, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6d, 0x61, 0x69, 0x6c, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_proto_sms_proto_rawDescOnce sync.Once
+	file_proto_sms_proto_rawDescData = file_proto_sms_proto_rawDesc
+)
+
+func file_proto_sms_proto_rawDescGZIP() []byte {
+	file_proto_sms_proto_rawDescOnce.Do(func() {
+		file_proto_sms_proto_rawDescData = protoimpl.X.CompressGZIP(file_proto_sms_proto_rawDescData)
+	})
+	return file_proto_sms_proto_rawDescData
+}
+
+var file_proto_sms_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_sms_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_proto_sms_proto_goTypes = []interface{}{
+	(SmsType)(0),        // 0: proto.SmsType
+	(*SmsRequest)(nil),  // 1: proto.SmsRequest
+	(*SmsResponse)(nil), // 2: proto.SmsResponse
+	nil,                 // 3: proto.SmsRequest.DataEntry
+}
+var file_proto_sms_proto_depIdxs = []int32{
+	0, // 0: proto.SmsRequest.type:type_name -> proto.SmsType
+	3, // 1: proto.SmsRequest.data:type_name -> proto.SmsRequest.DataEntry
+	1, // 2: proto.SmsService.SendSms:input_type -> proto.SmsRequest
+	2, // 3: proto.SmsService.SendSms:output_type -> proto.SmsResponse
+	3, // [3:4] is the sub-list for method output_type
+	2, // [2:3] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_proto_sms_proto_init() }
+func file_proto_sms_proto_init() {
+	if File_proto_sms_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_proto_sms_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SmsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_sms_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SmsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_proto_sms_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_sms_proto_goTypes,
+		DependencyIndexes: file_proto_sms_proto_depIdxs,
+		EnumInfos:         file_proto_sms_proto_enumTypes,
+		MessageInfos:      file_proto_sms_proto_msgTypes,
+	}.Build()
+	File_proto_sms_proto = out.File
+	file_proto_sms_proto_rawDesc = nil
+	file_proto_sms_proto_goTypes = nil
+	file_proto_sms_proto_depIdxs = nil
+}
@@ -1,16 +1,27 @@
 package main
 
 import (
+	"fmt"
+	"log"
+	"net"
 	"net/http"
+	"time"
 
+	"github.com/NeGat1FF/e-commerce/authclient"
+	"github.com/NeGat1FF/e-commerce/idempotency"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/carrier"
 	"github.com/NeGat1FF/e-commerce/order-service/internal/config"
 	"github.com/NeGat1FF/e-commerce/order-service/internal/db"
 	"github.com/NeGat1FF/e-commerce/order-service/internal/handlers"
+	messagequeue "github.com/NeGat1FF/e-commerce/order-service/internal/messageQueue"
 	"github.com/NeGat1FF/e-commerce/order-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/server"
 	"github.com/NeGat1FF/e-commerce/order-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/shipping"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/tax"
 	"github.com/NeGat1FF/e-commerce/order-service/proto"
+	"github.com/NeGat1FF/e-commerce/svcauth"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 func main() {
@@ -24,29 +35,163 @@ func main() {
 
 	repo := repository.NewOrderRepo(db)
 
-	var opts []grpc.DialOption
-	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	tlsConfig := svcauth.Config{
+		CertFile: config.GetConfig().TLS_CERT_FILE,
+		KeyFile:  config.GetConfig().TLS_KEY_FILE,
+		CAFile:   config.GetConfig().TLS_CA_FILE,
+	}
+
+	creds, err := svcauth.DialOption(tlsConfig)
+	if err != nil {
+		panic(err)
+	}
 
-	grpcClient, err := grpc.NewClient(config.GetConfig().PRICE_SERVICE, opts...)
+	grpcClient, err := grpc.NewClient(config.GetConfig().PRICE_SERVICE, creds)
 	if err != nil {
 		panic(err)
 	}
 
 	priceService := proto.NewPriceServiceClient(grpcClient)
 
-	orderService := service.NewOrderService(repo, priceService, config.GetConfig().JWTSecret)
+	userClient, err := grpc.NewClient(config.GetConfig().USER_SERVICE, creds)
+	if err != nil {
+		panic(err)
+	}
+
+	addressService := proto.NewAddressServiceClient(userClient)
+
+	// Access tokens are validated and permissions checked by the user service
+	authClient := authclient.New(userClient, "order-service", authclient.DefaultCacheTTL)
+
+	cartClient, err := grpc.NewClient(config.GetConfig().CART_SERVICE, creds)
+	if err != nil {
+		panic(err)
+	}
+
+	cartService := proto.NewCartServiceClient(cartClient)
+
+	paymentClient, err := grpc.NewClient(config.GetConfig().PAYMENT_SERVICE, creds)
+	if err != nil {
+		panic(err)
+	}
+
+	paymentService := proto.NewPaymentServiceClient(paymentClient)
+
+	// Stock is reserved in the product service, which also serves the prices
+	stockService := proto.NewStockServiceClient(grpcClient)
+
+	taxEngine, err := newTaxEngine()
+	if err != nil {
+		panic(err)
+	}
+
+	shippingConfig, err := shipping.LoadConfig(config.GetConfig().SHIPPING_CONFIG_FILE)
+	if err != nil {
+		panic(err)
+	}
+
+	shippingCalculator, err := shipping.NewCalculator(shippingConfig, config.GetConfig().CURRENCY)
+	if err != nil {
+		panic(err)
+	}
+
+	orderService := service.NewOrderService(repo, priceService, addressService, cartService, stockService, paymentService, taxEngine, shippingCalculator, config.GetConfig().CURRENCY, authClient)
+
+	// Placed orders go through the saga that reserves the stock and takes the payment
+	service.NewSagaOrchestrator(orderService, repo, config.GetConfig().SAGA_PAYMENT_TIMEOUT).Start(2 * time.Second)
+
+	conn, err := messagequeue.ConnectRabbitMQ(config.GetConfig().MESSAGE_BROKER_URL)
+	if err != nil {
+		panic(err)
+	}
+
+	mqClient, err := messagequeue.NewRabbitMQClient(conn)
+	if err != nil {
+		panic(err)
+	}
+
+	// Scrub personal data when users are erased and move guest orders to accounts
+	err = service.NewUserEventsConsumer(mqClient, repo, config.GetConfig().USER_EVENTS_QUEUE).Start()
+	if err != nil {
+		panic(err)
+	}
+
+	// Order events are written to the outbox with the orders and relayed to the exchange from there
+	publisher, err := messagequeue.NewRabbitMQClient(conn)
+	if err != nil {
+		panic(err)
+	}
+	service.NewOutboxRelay(repo, publisher, config.GetConfig().MESSAGE_BROKER_EXCHANGE).Start(time.Second)
+
+	// The user service reads orders to export the data of a user, the shopping cart service
+	// shows the discounts a cart gets
+	serverOpts, err := svcauth.ServerOptions(tlsConfig, svcauth.Policy{
+		"/proto.OrderService/GetUserOrders":        {"user-service"},
+		"/proto.PromotionService/PreviewDiscounts": {"shopping-cart-service"},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	grpcServer := grpc.NewServer(serverOpts...)
+	proto.RegisterOrderServiceServer(grpcServer, server.NewOrderServer(orderService))
+	proto.RegisterPromotionServiceServer(grpcServer, server.NewPromotionServer(orderService))
+
+	lis, err := net.Listen("tcp", ":50051")
+	if err != nil {
+		panic(err)
+	}
+
+	go func() {
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Println("failed to serve gRPC: ", err)
+		}
+	}()
+
+	// Carriers send tracking updates of shipments to their webhooks
+	var carriers []carrier.Carrier
+	if config.GetConfig().FAKE_CARRIER_SECRET != "" {
+		carriers = append(carriers, carrier.NewFake(config.GetConfig().FAKE_CARRIER_SECRET))
+	}
 
 	// Create a new OrderHandler
-	orderHandler := handlers.NewOrderHandler(orderService)
+	orderHandler := handlers.NewOrderHandler(orderService, carrier.NewRegistry(carriers...))
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		panic(err)
+	}
+
+	// Retried requests with the same Idempotency-Key get the order created by the first one
+	idem := idempotency.New(idempotency.NewPostgresStore(sqlDB), config.GetConfig().IDEMPOTENCY_KEY_TTL, handlers.IdempotencyScope())
+	idem.StartPurge(time.Hour)
 
 	// Create a new server mux
 	mux := http.NewServeMux()
 
-	// Register the handler functions
-	mux.HandleFunc("POST api/v1/orders", orderHandler.CreateOrder)
-	mux.HandleFunc("GET api/v1/orders", orderHandler.GetOrders)
-	mux.HandleFunc("GET api/v1/orders/{id}", orderHandler.GetOrder)
-	mux.HandleFunc("PUT api/v1/orders/{id}", orderHandler.UpdateOrder)
+	// Register the handler functions, every route but the carrier webhooks requires an access token
+	auth := handlers.Auth(authClient)
+	mux.HandleFunc("POST api/v1/orders", auth(idem.Wrap(orderHandler.CreateOrder)))
+	mux.HandleFunc("POST api/v1/orders/checkout", auth(idem.Wrap(orderHandler.Checkout)))
+	mux.HandleFunc("POST api/v1/shipping/quote", auth(orderHandler.QuoteShipping))
+	mux.HandleFunc("GET api/v1/orders", auth(orderHandler.GetOrders))
+	mux.HandleFunc("GET api/v1/orders/{id}", auth(orderHandler.GetOrder))
+	mux.HandleFunc("PUT api/v1/orders/{id}", auth(orderHandler.UpdateOrder))
+	mux.HandleFunc("GET api/v1/orders/{id}/history", auth(orderHandler.GetStatusHistory))
+	mux.HandleFunc("GET api/v1/orders/{id}/saga", auth(orderHandler.GetSaga))
+	mux.HandleFunc("POST api/v1/orders/{id}/cancel", auth(orderHandler.CancelOrder))
+	mux.HandleFunc("POST api/v1/orders/{id}/returns", auth(orderHandler.CreateReturn))
+	mux.HandleFunc("GET api/v1/orders/{id}/returns", auth(orderHandler.GetReturns))
+	mux.HandleFunc("PUT api/v1/returns/{id}", auth(orderHandler.UpdateReturn))
+	mux.HandleFunc("POST api/v1/orders/{id}/shipments", auth(orderHandler.CreateShipment))
+	mux.HandleFunc("GET api/v1/orders/{id}/shipments", auth(orderHandler.GetShipments))
+	mux.HandleFunc("PUT api/v1/shipments/{id}", auth(orderHandler.UpdateShipment))
+	mux.HandleFunc("POST api/v1/promotions", auth(orderHandler.CreatePromotion))
+	mux.HandleFunc("GET api/v1/promotions", auth(orderHandler.GetPromotions))
+	mux.HandleFunc("GET api/v1/promotions/{id}", auth(orderHandler.GetPromotion))
+	mux.HandleFunc("PUT api/v1/promotions/{id}", auth(orderHandler.UpdatePromotion))
+	// Carriers sign their webhooks instead of sending a token
+	mux.HandleFunc("POST api/v1/carriers/{carrier}/webhook", orderHandler.CarrierWebhook)
 
 	// Create a new server
 	err = http.ListenAndServe(":8080", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -64,3 +209,19 @@ func main() {
 		panic(err)
 	}
 }
+
+// newTaxEngine returns the tax engine selected in the config, external tax providers are added here
+func newTaxEngine() (tax.Engine, error) {
+	switch config.GetConfig().TAX_ENGINE {
+	case "rules":
+		rules, err := tax.LoadRules(config.GetConfig().TAX_RULES_FILE)
+		if err != nil {
+			return nil, err
+		}
+		return tax.NewRuleEngine(rules)
+	case "fake":
+		return tax.NewFake(config.GetConfig().FAKE_TAX_RATE), nil
+	default:
+		return nil, fmt.Errorf("unknown tax engine %q", config.GetConfig().TAX_ENGINE)
+	}
+}
@@ -1,11 +1,16 @@
 module github.com/NeGat1FF/e-commerce/order-service
 
-go 1.22.2
+go 1.22.7
 
 require (
-	github.com/golang-jwt/jwt v3.2.2+incompatible
+	github.com/NeGat1FF/e-commerce/authclient v0.0.0
+	github.com/NeGat1FF/e-commerce/idempotency v0.0.0
+	github.com/NeGat1FF/e-commerce/money v0.0.0
+	github.com/NeGat1FF/e-commerce/svcauth v0.0.0
 	github.com/google/uuid v1.6.0
 	github.com/joho/godotenv v1.5.1
+	github.com/rabbitmq/amqp091-go v1.10.0
+	github.com/stretchr/testify v1.9.0
 	google.golang.org/grpc v1.68.1
 	google.golang.org/protobuf v1.35.2
 	gorm.io/driver/postgres v1.5.11
@@ -13,16 +18,27 @@ require (
 )
 
 require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/jackc/pgpassfile v1.0.0 // indirect
 	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
 	github.com/jackc/pgx/v5 v5.5.5 // indirect
 	github.com/jackc/puddle/v2 v2.2.1 // indirect
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/jinzhu/now v1.1.5 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/crypto v0.27.0 // indirect
 	golang.org/x/net v0.29.0 // indirect
 	golang.org/x/sync v0.8.0 // indirect
 	golang.org/x/sys v0.25.0 // indirect
 	golang.org/x/text v0.18.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240903143218-8af14fe29dc1 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/NeGat1FF/e-commerce/authclient => ../authclient
+
+replace github.com/NeGat1FF/e-commerce/svcauth => ../svcauth
+
+replace github.com/NeGat1FF/e-commerce/idempotency => ../idempotency
+
+replace github.com/NeGat1FF/e-commerce/money => ../money
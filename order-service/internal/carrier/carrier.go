@@ -0,0 +1,40 @@
+// Package carrier turns the tracking webhooks of shipping carriers into tracking updates of shipments.
+// Every carrier gets an adapter that verifies and parses its webhooks.
+package carrier
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// TrackingUpdate is a tracking event of a shipment, Status is one of the shipment statuses of the order service
+type TrackingUpdate struct {
+	TrackingNumber string
+	Status         string
+	Description    string
+	Location       string
+	OccurredAt     time.Time
+}
+
+// Carrier is the adapter of a shipping carrier
+type Carrier interface {
+	// Name is the name shipments of the carrier are created with
+	Name() string
+	// ParseWebhook verifies a webhook request of the carrier and returns the tracking updates it contains
+	ParseWebhook(r *http.Request) ([]TrackingUpdate, error)
+}
+
+// Registry holds the carriers webhooks are accepted from by name
+type Registry map[string]Carrier
+
+// NewRegistry returns a registry of the carriers
+func NewRegistry(carriers ...Carrier) Registry {
+	registry := make(Registry, len(carriers))
+	for _, c := range carriers {
+		registry[c.Name()] = c
+	}
+	return registry
+}
@@ -0,0 +1,73 @@
+package carrier
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+)
+
+// FakeName is the name of the fake carrier
+const FakeName = "fake"
+
+// FakeSignatureHeader carries the hex HMAC-SHA256 of the body signed with the secret of the fake carrier
+const FakeSignatureHeader = "X-Fake-Signature"
+
+// Fake is a carrier for tests and local development, its webhooks post tracking updates
+// in the statuses of the order service:
+//
+//	[{"tracking_number": "...", "status": "IN_TRANSIT", "description": "...", "location": "...", "occurred_at": "..."}]
+type Fake struct {
+	secret []byte
+}
+
+func NewFake(secret string) *Fake {
+	return &Fake{secret: []byte(secret)}
+}
+
+func (f *Fake) Name() string {
+	return FakeName
+}
+
+func (f *Fake) ParseWebhook(r *http.Request) ([]TrackingUpdate, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := hex.DecodeString(r.Header.Get(FakeSignatureHeader))
+	if err != nil || !hmac.Equal(signature, f.sign(body)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var events []struct {
+		TrackingNumber string    `json:"tracking_number"`
+		Status         string    `json:"status"`
+		Description    string    `json:"description"`
+		Location       string    `json:"location"`
+		OccurredAt     time.Time `json:"occurred_at"`
+	}
+	if err := json.Unmarshal(body, &events); err != nil {
+		return nil, err
+	}
+
+	updates := make([]TrackingUpdate, 0, len(events))
+	for _, e := range events {
+		updates = append(updates, TrackingUpdate(e))
+	}
+	return updates, nil
+}
+
+// Sign returns the signature header value of a webhook body
+func (f *Fake) Sign(body []byte) string {
+	return hex.EncodeToString(f.sign(body))
+}
+
+func (f *Fake) sign(body []byte) []byte {
+	mac := hmac.New(sha256.New, f.secret)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,45 @@
+package carrier_test
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/order-service/internal/carrier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeParseWebhook(t *testing.T) {
+	fake := carrier.NewFake("secret")
+	body := []byte(`[{"tracking_number": "TRK1", "status": "IN_TRANSIT", "description": "Left the warehouse", "location": "Berlin", "occurred_at": "2025-01-02T10:00:00Z"}]`)
+
+	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(body))
+	req.Header.Set(carrier.FakeSignatureHeader, fake.Sign(body))
+
+	updates, err := fake.ParseWebhook(req)
+	require.NoError(t, err)
+	assert.Equal(t, []carrier.TrackingUpdate{{
+		TrackingNumber: "TRK1",
+		Status:         "IN_TRANSIT",
+		Description:    "Left the warehouse",
+		Location:       "Berlin",
+		OccurredAt:     time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC),
+	}}, updates)
+}
+
+func TestFakeParseWebhookInvalidSignature(t *testing.T) {
+	body := []byte(`[{"tracking_number": "TRK1", "status": "DELIVERED"}]`)
+
+	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(body))
+	req.Header.Set(carrier.FakeSignatureHeader, carrier.NewFake("other").Sign(body))
+
+	_, err := carrier.NewFake("secret").ParseWebhook(req)
+	assert.Equal(t, carrier.ErrInvalidSignature, err)
+}
+
+func TestNewRegistry(t *testing.T) {
+	registry := carrier.NewRegistry(carrier.NewFake("secret"))
+	assert.Contains(t, registry, carrier.FakeName)
+}
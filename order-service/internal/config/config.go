@@ -2,14 +2,50 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DB_URL        string
-	JWTSecret     string
-	PRICE_SERVICE string
+	DB_URL          string
+	PRICE_SERVICE   string
+	USER_SERVICE    string
+	CART_SERVICE    string
+	PAYMENT_SERVICE string
+
+	// Certificate of the service for mutual TLS with the other services
+	TLS_CERT_FILE string
+	TLS_KEY_FILE  string
+	TLS_CA_FILE   string
+
+	MESSAGE_BROKER_URL string
+	USER_EVENTS_QUEUE  string
+	// Topic exchange the order events are published to
+	MESSAGE_BROKER_EXCHANGE string
+
+	// How long responses to requests with an Idempotency-Key are kept
+	IDEMPOTENCY_KEY_TTL time.Duration
+
+	// How long an order waits for its payment before it is cancelled
+	SAGA_PAYMENT_TIMEOUT time.Duration
+
+	// Webhooks of the fake carrier are accepted if its secret is set, for tests and local development
+	FAKE_CARRIER_SECRET string
+
+	// Engine orders are taxed with, "rules" for the rules from TAX_RULES_FILE or "fake" for
+	// a flat FAKE_TAX_RATE in tests and local development
+	TAX_ENGINE     string
+	TAX_RULES_FILE string
+	FAKE_TAX_RATE  float64
+
+	// JSON file with the shipping zones and methods, orders are not charged for shipping without it
+	SHIPPING_CONFIG_FILE string
+
+	// ISO 4217 code of the currency the shop sells in, products priced in another one cannot be ordered
+	CURRENCY string
 }
 
 var config *Config
@@ -18,10 +54,60 @@ func InitConfig() {
 	godotenv.Load()
 
 	config = &Config{
-		DB_URL:        os.Getenv("DATABASE_URL"),
-		JWTSecret:     os.Getenv("JWT_SECRET"),
-		PRICE_SERVICE: os.Getenv("PRICE_SERVICE"),
+		DB_URL:          os.Getenv("DATABASE_URL"),
+		PRICE_SERVICE:   os.Getenv("PRICE_SERVICE"),
+		USER_SERVICE:    os.Getenv("USER_SERVICE"),
+		CART_SERVICE:    os.Getenv("CART_SERVICE"),
+		PAYMENT_SERVICE: os.Getenv("PAYMENT_SERVICE"),
+
+		TLS_CERT_FILE: os.Getenv("TLS_CERT_FILE"),
+		TLS_KEY_FILE:  os.Getenv("TLS_KEY_FILE"),
+		TLS_CA_FILE:   os.Getenv("TLS_CA_FILE"),
+
+		MESSAGE_BROKER_URL:      os.Getenv("MESSAGE_BROKER_URL"),
+		USER_EVENTS_QUEUE:       os.Getenv("USER_EVENTS_QUEUE"),
+		MESSAGE_BROKER_EXCHANGE: os.Getenv("MESSAGE_BROKER_EXCHANGE"),
+
+		IDEMPOTENCY_KEY_TTL: getDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+
+		SAGA_PAYMENT_TIMEOUT: getDuration("SAGA_PAYMENT_TIMEOUT", 30*time.Minute),
+
+		FAKE_CARRIER_SECRET: os.Getenv("FAKE_CARRIER_SECRET"),
+
+		TAX_ENGINE:     getString("TAX_ENGINE", "rules"),
+		TAX_RULES_FILE: os.Getenv("TAX_RULES_FILE"),
+		FAKE_TAX_RATE:  getFloat("FAKE_TAX_RATE", 0.1),
+
+		SHIPPING_CONFIG_FILE: os.Getenv("SHIPPING_CONFIG_FILE"),
+
+		CURRENCY: strings.ToUpper(getString("CURRENCY", "USD")),
+	}
+}
+
+// getDuration parses a duration from the environment, falling back to the default if it is unset or invalid.
+func getDuration(key string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return d
+}
+
+// getString reads a string from the environment, falling back to the default if it is unset.
+func getString(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// getFloat parses a number from the environment, falling back to the default if it is unset or invalid.
+func getFloat(key string, def float64) float64 {
+	f, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return def
 	}
+	return f
 }
 
 func GetConfig() *Config {
@@ -0,0 +1 @@
+ALTER TABLE orders ADD COLUMN shipping_address JSONB;
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS order_status_history;
+
+-- Orders past payment fall back to approved, refunded and returned ones to rejected
+UPDATE orders SET status = 2 WHERE status IN (4, 5, 6, 7);
+UPDATE orders SET status = 3 WHERE status IN (8, 9);
+
+DELETE FROM order_statuses WHERE id > 3;
+
+UPDATE order_statuses SET name = 'PENDING' WHERE id = 1;
+UPDATE order_statuses SET name = 'APPROVED' WHERE id = 2;
+UPDATE order_statuses SET name = 'REJECTED' WHERE id = 3;
@@ -0,0 +1,26 @@
+UPDATE order_statuses SET name = 'PENDING_PAYMENT' WHERE id = 1;
+UPDATE order_statuses SET name = 'PAID' WHERE id = 2;
+UPDATE order_statuses SET name = 'CANCELLED' WHERE id = 3;
+
+INSERT INTO order_statuses (id, name) VALUES
+(4, 'PROCESSING'),
+(5, 'PARTIALLY_SHIPPED'),
+(6, 'SHIPPED'),
+(7, 'DELIVERED'),
+(8, 'REFUNDED'),
+(9, 'RETURNED');
+
+SELECT setval('order_statuses_id_seq', (SELECT MAX(id) FROM order_statuses));
+
+CREATE TABLE order_status_history
+(
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id),
+    from_status INTEGER REFERENCES order_statuses(id),
+    to_status INTEGER NOT NULL REFERENCES order_statuses(id),
+    actor VARCHAR(255) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX order_status_history_order_id_index ON order_status_history(order_id, created_at);
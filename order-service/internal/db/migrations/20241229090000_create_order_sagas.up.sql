@@ -0,0 +1,29 @@
+CREATE TABLE order_sagas
+(
+    order_id UUID PRIMARY KEY REFERENCES orders(id),
+    status VARCHAR(32) NOT NULL,
+    step VARCHAR(32) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    reason TEXT NOT NULL DEFAULT '',
+    payment_id VARCHAR(255) NOT NULL DEFAULT '',
+    deadline TIMESTAMP,
+    next_run_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX order_sagas_next_run_at_index ON order_sagas(next_run_at) WHERE status IN ('RUNNING', 'COMPENSATING');
+
+CREATE TABLE order_saga_steps
+(
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES order_sagas(order_id),
+    step VARCHAR(32) NOT NULL,
+    compensation BOOLEAN NOT NULL,
+    outcome VARCHAR(32) NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX order_saga_steps_order_id_index ON order_saga_steps(order_id, created_at);
@@ -0,0 +1,11 @@
+CREATE TABLE order_outbox
+(
+    id UUID PRIMARY KEY,
+    routing_key VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    published_at TIMESTAMP
+);
+
+CREATE INDEX order_outbox_unpublished_index ON order_outbox(created_at) WHERE published_at IS NULL;
+CREATE INDEX order_outbox_published_at_index ON order_outbox(published_at);
@@ -0,0 +1,15 @@
+CREATE TABLE order_returns
+(
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id),
+    user_id UUID NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    reason TEXT NOT NULL,
+    items JSONB NOT NULL,
+    refund_amount DECIMAL(10, 2) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX order_returns_order_id_index ON order_returns(order_id, created_at);
@@ -0,0 +1,27 @@
+CREATE TABLE order_shipments
+(
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id),
+    carrier VARCHAR(64) NOT NULL,
+    tracking_number VARCHAR(255) NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    items JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    UNIQUE (carrier, tracking_number)
+);
+
+CREATE INDEX order_shipments_order_id_index ON order_shipments(order_id, created_at);
+
+CREATE TABLE shipment_events
+(
+    id UUID PRIMARY KEY,
+    shipment_id UUID NOT NULL REFERENCES order_shipments(id),
+    status VARCHAR(32) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    location VARCHAR(255) NOT NULL DEFAULT '',
+    occurred_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX shipment_events_shipment_id_index ON shipment_events(shipment_id, occurred_at);
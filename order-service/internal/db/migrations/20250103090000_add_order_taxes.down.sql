@@ -0,0 +1,11 @@
+ALTER TABLE order_items
+    DROP COLUMN IF EXISTS total,
+    DROP COLUMN IF EXISTS tax,
+    DROP COLUMN IF EXISTS tax_rate,
+    DROP COLUMN IF EXISTS tax_category;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS prices_include_tax,
+    DROP COLUMN IF EXISTS tax_breakdown,
+    DROP COLUMN IF EXISTS tax,
+    DROP COLUMN IF EXISTS subtotal;
@@ -0,0 +1,18 @@
+ALTER TABLE orders
+    ADD COLUMN subtotal DECIMAL(10, 2),
+    ADD COLUMN tax DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN tax_breakdown JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN prices_include_tax BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Orders placed before taxes were calculated were not taxed
+UPDATE orders SET subtotal = total;
+ALTER TABLE orders ALTER COLUMN subtotal SET NOT NULL;
+
+ALTER TABLE order_items
+    ADD COLUMN tax_category VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN tax_rate DECIMAL(6, 4) NOT NULL DEFAULT 0,
+    ADD COLUMN tax DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN total DECIMAL(10, 2);
+
+UPDATE order_items SET total = price * quantity;
+ALTER TABLE order_items ALTER COLUMN total SET NOT NULL;
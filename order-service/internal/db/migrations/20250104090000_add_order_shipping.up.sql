@@ -0,0 +1,3 @@
+ALTER TABLE orders
+    ADD COLUMN shipping_method VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN shipping_cost DECIMAL(10, 2) NOT NULL DEFAULT 0;
@@ -0,0 +1,57 @@
+CREATE TABLE promotions
+(
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    code VARCHAR(64) NOT NULL DEFAULT '',
+    type VARCHAR(32) NOT NULL,
+    value DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    buy_quantity INT NOT NULL DEFAULT 0,
+    get_quantity INT NOT NULL DEFAULT 0,
+    product_ids JSONB NOT NULL DEFAULT '[]',
+    categories JSONB NOT NULL DEFAULT '[]',
+    min_subtotal DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    usage_limit INT NOT NULL DEFAULT 0,
+    per_customer_limit INT NOT NULL DEFAULT 0,
+    usage_count INT NOT NULL DEFAULT 0,
+    stackable BOOLEAN NOT NULL DEFAULT FALSE,
+    priority INT NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- Coupon codes are unique, automatic promotions have none
+CREATE UNIQUE INDEX promotions_code_index ON promotions(code) WHERE code <> '';
+
+-- One row per order a promotion was used for, for the limits per customer
+CREATE TABLE promotion_redemptions
+(
+    promotion_id UUID NOT NULL REFERENCES promotions(id),
+    order_id UUID NOT NULL REFERENCES orders(id),
+    user_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (promotion_id, order_id)
+);
+
+CREATE INDEX promotion_redemptions_user_id_index ON promotion_redemptions(promotion_id, user_id);
+CREATE INDEX promotion_redemptions_order_id_index ON promotion_redemptions(order_id);
+
+CREATE TABLE order_discounts
+(
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id),
+    promotion_id UUID NOT NULL REFERENCES promotions(id),
+    code VARCHAR(64) NOT NULL DEFAULT '',
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX order_discounts_order_id_index ON order_discounts(order_id);
+CREATE INDEX order_discounts_promotion_id_index ON order_discounts(promotion_id, created_at);
+
+ALTER TABLE orders ADD COLUMN discount DECIMAL(10, 2) NOT NULL DEFAULT 0;
+ALTER TABLE order_items ADD COLUMN discount DECIMAL(10, 2) NOT NULL DEFAULT 0;
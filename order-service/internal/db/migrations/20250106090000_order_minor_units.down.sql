@@ -0,0 +1,62 @@
+UPDATE promotions SET value = fixed_amount / 100.0 WHERE type = 'FIXED';
+
+ALTER TABLE promotions
+    DROP COLUMN fixed_currency,
+    DROP COLUMN fixed_amount,
+    DROP COLUMN min_subtotal_currency,
+    ALTER COLUMN min_subtotal_amount TYPE DECIMAL(10, 2) USING min_subtotal_amount / 100.0;
+ALTER TABLE promotions RENAME COLUMN min_subtotal_amount TO min_subtotal;
+
+UPDATE order_returns SET items = (
+    SELECT COALESCE(jsonb_agg(i || jsonb_build_object('price', (i->'price'->>'amount')::numeric / 100) ORDER BY n), '[]')
+    FROM jsonb_array_elements(items) WITH ORDINALITY AS r(i, n)
+);
+
+ALTER TABLE order_returns
+    DROP COLUMN refund_currency,
+    ALTER COLUMN refund_amount TYPE DECIMAL(10, 2) USING refund_amount / 100.0;
+
+ALTER TABLE order_discounts
+    DROP COLUMN currency,
+    ALTER COLUMN amount TYPE DECIMAL(10, 2) USING amount / 100.0;
+
+ALTER TABLE order_items
+    DROP COLUMN total_currency,
+    DROP COLUMN tax_currency,
+    DROP COLUMN discount_currency,
+    DROP COLUMN price_currency,
+    ALTER COLUMN total_amount TYPE DECIMAL(10, 2) USING total_amount / 100.0,
+    ALTER COLUMN tax_amount TYPE DECIMAL(10, 2) USING tax_amount / 100.0,
+    ALTER COLUMN discount_amount TYPE DECIMAL(10, 2) USING discount_amount / 100.0,
+    ALTER COLUMN price_amount TYPE DECIMAL(10, 2) USING price_amount / 100.0;
+
+ALTER TABLE order_items RENAME COLUMN total_amount TO total;
+ALTER TABLE order_items RENAME COLUMN tax_amount TO tax;
+ALTER TABLE order_items RENAME COLUMN discount_amount TO discount;
+ALTER TABLE order_items RENAME COLUMN price_amount TO price;
+
+UPDATE orders SET tax_breakdown = (
+    SELECT COALESCE(jsonb_agg(c || jsonb_build_object(
+        'taxable', (c->'taxable'->>'amount')::numeric / 100,
+        'amount', (c->'amount'->>'amount')::numeric / 100
+    ) ORDER BY n), '[]')
+    FROM jsonb_array_elements(tax_breakdown) WITH ORDINALITY AS b(c, n)
+);
+
+ALTER TABLE orders
+    DROP COLUMN shipping_cost_currency,
+    DROP COLUMN total_currency,
+    DROP COLUMN discount_currency,
+    DROP COLUMN tax_currency,
+    DROP COLUMN subtotal_currency,
+    ALTER COLUMN shipping_cost_amount TYPE DECIMAL(10, 2) USING shipping_cost_amount / 100.0,
+    ALTER COLUMN total_amount TYPE DECIMAL(10, 2) USING total_amount / 100.0,
+    ALTER COLUMN discount_amount TYPE DECIMAL(10, 2) USING discount_amount / 100.0,
+    ALTER COLUMN tax_amount TYPE DECIMAL(10, 2) USING tax_amount / 100.0,
+    ALTER COLUMN subtotal_amount TYPE DECIMAL(10, 2) USING subtotal_amount / 100.0;
+
+ALTER TABLE orders RENAME COLUMN shipping_cost_amount TO shipping_cost;
+ALTER TABLE orders RENAME COLUMN total_amount TO total;
+ALTER TABLE orders RENAME COLUMN discount_amount TO discount;
+ALTER TABLE orders RENAME COLUMN tax_amount TO tax;
+ALTER TABLE orders RENAME COLUMN subtotal_amount TO subtotal;
@@ -0,0 +1,86 @@
+-- Amounts are kept in the minor unit of their currency. All amounts so far were in USD.
+ALTER TABLE orders RENAME COLUMN subtotal TO subtotal_amount;
+ALTER TABLE orders RENAME COLUMN tax TO tax_amount;
+ALTER TABLE orders RENAME COLUMN discount TO discount_amount;
+ALTER TABLE orders RENAME COLUMN total TO total_amount;
+ALTER TABLE orders RENAME COLUMN shipping_cost TO shipping_cost_amount;
+
+ALTER TABLE orders
+    ALTER COLUMN subtotal_amount TYPE BIGINT USING ROUND(subtotal_amount * 100),
+    ALTER COLUMN tax_amount TYPE BIGINT USING ROUND(tax_amount * 100),
+    ALTER COLUMN discount_amount TYPE BIGINT USING ROUND(discount_amount * 100),
+    ALTER COLUMN total_amount TYPE BIGINT USING ROUND(total_amount * 100),
+    ALTER COLUMN shipping_cost_amount TYPE BIGINT USING ROUND(shipping_cost_amount * 100),
+    ADD COLUMN subtotal_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN tax_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN discount_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN total_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN shipping_cost_currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE orders
+    ALTER COLUMN subtotal_currency DROP DEFAULT,
+    ALTER COLUMN tax_currency DROP DEFAULT,
+    ALTER COLUMN discount_currency DROP DEFAULT,
+    ALTER COLUMN total_currency DROP DEFAULT,
+    ALTER COLUMN shipping_cost_currency DROP DEFAULT;
+
+UPDATE orders SET tax_breakdown = (
+    SELECT COALESCE(jsonb_agg(c || jsonb_build_object(
+        'taxable', jsonb_build_object('amount', ROUND((c->>'taxable')::numeric * 100)::bigint, 'currency', 'USD'),
+        'amount', jsonb_build_object('amount', ROUND((c->>'amount')::numeric * 100)::bigint, 'currency', 'USD')
+    ) ORDER BY n), '[]')
+    FROM jsonb_array_elements(tax_breakdown) WITH ORDINALITY AS b(c, n)
+);
+
+ALTER TABLE order_items RENAME COLUMN price TO price_amount;
+ALTER TABLE order_items RENAME COLUMN discount TO discount_amount;
+ALTER TABLE order_items RENAME COLUMN tax TO tax_amount;
+ALTER TABLE order_items RENAME COLUMN total TO total_amount;
+
+ALTER TABLE order_items
+    ALTER COLUMN price_amount TYPE BIGINT USING ROUND(price_amount * 100),
+    ALTER COLUMN discount_amount TYPE BIGINT USING ROUND(discount_amount * 100),
+    ALTER COLUMN tax_amount TYPE BIGINT USING ROUND(tax_amount * 100),
+    ALTER COLUMN total_amount TYPE BIGINT USING ROUND(total_amount * 100),
+    ADD COLUMN price_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN discount_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN tax_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN total_currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE order_items
+    ALTER COLUMN price_currency DROP DEFAULT,
+    ALTER COLUMN discount_currency DROP DEFAULT,
+    ALTER COLUMN tax_currency DROP DEFAULT,
+    ALTER COLUMN total_currency DROP DEFAULT;
+
+ALTER TABLE order_discounts
+    ALTER COLUMN amount TYPE BIGINT USING ROUND(amount * 100),
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE order_discounts ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE order_returns
+    ALTER COLUMN refund_amount TYPE BIGINT USING ROUND(refund_amount * 100),
+    ADD COLUMN refund_currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE order_returns ALTER COLUMN refund_currency DROP DEFAULT;
+
+UPDATE order_returns SET items = (
+    SELECT COALESCE(jsonb_agg(i || jsonb_build_object(
+        'price', jsonb_build_object('amount', ROUND((i->>'price')::numeric * 100)::bigint, 'currency', 'USD')
+    ) ORDER BY n), '[]')
+    FROM jsonb_array_elements(items) WITH ORDINALITY AS r(i, n)
+);
+
+-- The value of a promotion is the percentage, fixed amounts and the minimum subtotal are money
+ALTER TABLE promotions RENAME COLUMN min_subtotal TO min_subtotal_amount;
+
+ALTER TABLE promotions
+    ALTER COLUMN min_subtotal_amount TYPE BIGINT USING ROUND(min_subtotal_amount * 100),
+    ADD COLUMN min_subtotal_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN fixed_amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN fixed_currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+UPDATE promotions SET fixed_amount = ROUND(value * 100), value = 0 WHERE type = 'FIXED';
+
+ALTER TABLE promotions
+    ALTER COLUMN min_subtotal_currency DROP DEFAULT,
+    ALTER COLUMN fixed_currency DROP DEFAULT;
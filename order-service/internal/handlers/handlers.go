@@ -2,23 +2,42 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"strings"
 
+	"github.com/NeGat1FF/e-commerce/idempotency"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/carrier"
 	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/repository"
 	"github.com/NeGat1FF/e-commerce/order-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/shipping"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/utils"
 )
 
 // OrderHandler is a struct that holds the order service
 type OrderHandler struct {
 	OrderService *service.OrderService
+	// Carriers tracking webhooks are accepted from
+	Carriers carrier.Registry
 }
 
 // NewOrderHandler creates a new OrderHandler
-func NewOrderHandler(orderService *service.OrderService) *OrderHandler {
+func NewOrderHandler(orderService *service.OrderService, carriers carrier.Registry) *OrderHandler {
 	return &OrderHandler{
 		OrderService: orderService,
+		Carriers:     carriers,
+	}
+}
+
+// IdempotencyScope keeps the idempotency keys of users apart, it runs behind the Auth middleware
+func IdempotencyScope() idempotency.ScopeFunc {
+	return func(r *http.Request) string {
+		claims := ClaimsFromContext(r.Context())
+		if claims == nil {
+			return ""
+		}
+		return claims.UserID
 	}
 }
 
@@ -31,25 +50,107 @@ func (oh *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	auth := r.Header.Get("Authorization")
-	if auth == "" {
-		http.Error(w, "Authorization header is required", http.StatusBadRequest)
+	jwt := bearerToken(r)
+
+	resp, err := oh.OrderService.CreateOrder(r.Context(), jwt, orderReq)
+	if err != nil {
+		fmt.Println(err)
+		if err == service.ErrAddressNotFound || err == service.ErrShippingMethodRequired || err == shipping.ErrMethodUnavailable ||
+			errors.Is(err, service.ErrInvalidCoupon) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err == repository.ErrPromotionUsedUp || errors.Is(err, service.ErrPriceCurrency) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	jwt := strings.Split(auth, " ")[1]
-
-	resp, err := oh.OrderService.CreateOrder(r.Context(), jwt, orderReq.Products)
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(resp)
 	if err != nil {
 		fmt.Println(err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+}
+
+// Checkout places an order with the items of the caller's shopping cart
+func (oh *OrderHandler) Checkout(w http.ResponseWriter, r *http.Request) {
+	var checkoutReq models.CheckoutRequest
+	err := json.NewDecoder(r.Body).Decode(&checkoutReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	jwt := bearerToken(r)
+
+	resp, err := oh.OrderService.Checkout(r.Context(), jwt, checkoutReq)
+	if err != nil {
+		var changedErr *service.CheckoutChangedError
+		switch {
+		case errors.As(err, &changedErr):
+			// The client shows the changes and repeats the checkout with the lines of the review
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":  changedErr.Error(),
+				"review": changedErr.Review,
+			})
+		case err == utils.ErrInvalidToken:
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case err == service.ErrCartEmpty, err == service.ErrAddressNotFound,
+			err == service.ErrShippingMethodRequired, err == shipping.ErrMethodUnavailable,
+			errors.Is(err, service.ErrInvalidCoupon):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case err == service.ErrNothingAvailable, err == repository.ErrPromotionUsedUp,
+			errors.Is(err, service.ErrPriceCurrency):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
 
 	w.WriteHeader(http.StatusCreated)
 	err = json.NewEncoder(w).Encode(resp)
 	if err != nil {
-		fmt.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// QuoteShipping returns the shipping methods and their prices for the products or the shopping cart
+func (oh *OrderHandler) QuoteShipping(w http.ResponseWriter, r *http.Request) {
+	var quoteReq models.ShippingQuoteRequest
+	err := json.NewDecoder(r.Body).Decode(&quoteReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	jwt := bearerToken(r)
+
+	resp, err := oh.OrderService.QuoteShipping(r.Context(), jwt, quoteReq)
+	if err != nil {
+		switch {
+		case err == utils.ErrInvalidToken:
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case err == service.ErrCartEmpty, err == service.ErrAddressNotFound, err == service.ErrDestinationRequired:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrPriceCurrency):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -59,9 +160,18 @@ func (oh *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 func (oh *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 
-	resp, err := oh.OrderService.GetOrder(r.Context(), id)
+	jwt := bearerToken(r)
+
+	resp, err := oh.OrderService.GetOrder(r.Context(), jwt, id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		switch {
+		case err == utils.ErrInvalidToken:
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case err == repository.ErrOrderNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
 
@@ -73,16 +183,14 @@ func (oh *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
 }
 
 func (oh *OrderHandler) GetOrders(w http.ResponseWriter, r *http.Request) {
-	auth := r.Header.Get("Authorization")
-	if auth == "" {
-		http.Error(w, "Authorization header is required", http.StatusBadRequest)
-		return
-	}
-
-	jwt := strings.Split(auth, " ")[1]
+	jwt := bearerToken(r)
 
 	resp, err := oh.OrderService.GetOrders(r.Context(), jwt)
 	if err != nil {
+		if err == utils.ErrInvalidToken {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -94,12 +202,13 @@ func (oh *OrderHandler) GetOrders(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// UpdateOrder updates an order
+// UpdateOrder moves an order to another status of its lifecycle
 func (oh *OrderHandler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 
 	var orderReq struct {
-		Status int `json:"status"`
+		Status string `json:"status"`
+		Reason string `json:"reason"`
 	}
 	err := json.NewDecoder(r.Body).Decode(&orderReq)
 	if err != nil {
@@ -107,11 +216,324 @@ func (oh *OrderHandler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := oh.OrderService.UpdateOrderStatus(r.Context(), id, orderReq.Status)
+	jwt := bearerToken(r)
+
+	resp, err := oh.OrderService.UpdateOrderStatus(r.Context(), jwt, id, orderReq.Status, orderReq.Reason)
+	if err != nil {
+		var transitionErr *service.TransitionError
+		switch {
+		case errors.As(err, &transitionErr):
+			writeTransitionError(w, transitionErr)
+		case err == repository.ErrStatusChanged:
+			http.Error(w, err.Error(), http.StatusConflict)
+		case err == utils.ErrInvalidToken:
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case err == service.ErrUnknownStatus:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case err == repository.ErrOrderNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetStatusHistory lists the status changes of an order
+func (oh *OrderHandler) GetStatusHistory(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	jwt := bearerToken(r)
+
+	resp, err := oh.OrderService.GetStatusHistory(r.Context(), jwt, id)
+	if err != nil {
+		switch {
+		case err == utils.ErrInvalidToken:
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case err == repository.ErrOrderNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetSaga shows how far the saga of an order got and the steps it took
+func (oh *OrderHandler) GetSaga(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	jwt := bearerToken(r)
+
+	resp, err := oh.OrderService.GetSaga(r.Context(), jwt, id)
+	if err != nil {
+		switch {
+		case err == utils.ErrInvalidToken:
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case err == repository.ErrOrderNotFound, err == repository.ErrSagaNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// CancelOrder cancels an order of the caller that was not fulfilled yet
+func (oh *OrderHandler) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var cancelReq struct {
+		Reason string `json:"reason"`
+	}
+	// The reason is optional, so is the body
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&cancelReq); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	jwt := bearerToken(r)
+
+	resp, err := oh.OrderService.CancelOrder(r.Context(), jwt, id, cancelReq.Reason)
+	if err != nil {
+		var transitionErr *service.TransitionError
+		switch {
+		case errors.As(err, &transitionErr):
+			writeTransitionError(w, transitionErr)
+		case err == service.ErrNotCancellable, err == repository.ErrStatusChanged:
+			http.Error(w, err.Error(), http.StatusConflict)
+		case err == utils.ErrInvalidToken:
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case err == repository.ErrOrderNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// CreateReturn opens a return of items of a delivered order of the caller
+func (oh *OrderHandler) CreateReturn(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var returnReq models.CreateReturnRequest
+	err := json.NewDecoder(r.Body).Decode(&returnReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	jwt := bearerToken(r)
+
+	resp, err := oh.OrderService.RequestReturn(r.Context(), jwt, id, returnReq)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidReturnItems:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case service.ErrNotReturnable:
+			http.Error(w, err.Error(), http.StatusConflict)
+		case utils.ErrInvalidToken:
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case repository.ErrOrderNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetReturns lists the returns of an order
+func (oh *OrderHandler) GetReturns(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	jwt := bearerToken(r)
+
+	resp, err := oh.OrderService.GetReturns(r.Context(), jwt, id)
+	if err != nil {
+		switch err {
+		case utils.ErrInvalidToken:
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case repository.ErrOrderNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// UpdateReturn moves a return to another status, only staff can handle returns
+func (oh *OrderHandler) UpdateReturn(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var returnReq models.UpdateReturnRequest
+	err := json.NewDecoder(r.Body).Decode(&returnReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	jwt := bearerToken(r)
+
+	resp, err := oh.OrderService.UpdateReturn(r.Context(), jwt, id, returnReq)
+	if err != nil {
+		var transitionErr *service.TransitionError
+		switch {
+		case errors.As(err, &transitionErr):
+			writeTransitionError(w, transitionErr)
+		case err == repository.ErrReturnChanged:
+			http.Error(w, err.Error(), http.StatusConflict)
+		case err == utils.ErrInvalidToken:
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case err == repository.ErrReturnNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// CreateShipment adds a shipment to an order, only staff can ship orders
+func (oh *OrderHandler) CreateShipment(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var shipmentReq models.CreateShipmentRequest
+	err := json.NewDecoder(r.Body).Decode(&shipmentReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	jwt := bearerToken(r)
+
+	resp, err := oh.OrderService.CreateShipment(r.Context(), jwt, id, shipmentReq)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidShipment, service.ErrInvalidShipmentItems:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case service.ErrNotShippable, repository.ErrShipmentExists:
+			http.Error(w, err.Error(), http.StatusConflict)
+		case utils.ErrInvalidToken:
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case repository.ErrOrderNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetShipments lists the shipments of an order with their tracking timelines
+func (oh *OrderHandler) GetShipments(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	jwt := bearerToken(r)
+
+	resp, err := oh.OrderService.GetShipments(r.Context(), jwt, id)
+	if err != nil {
+		switch err {
+		case utils.ErrInvalidToken:
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case repository.ErrOrderNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(resp)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+}
+
+// UpdateShipment records a tracking update of a shipment, only staff can update shipments
+func (oh *OrderHandler) UpdateShipment(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var shipmentReq models.UpdateShipmentRequest
+	err := json.NewDecoder(r.Body).Decode(&shipmentReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	jwt := bearerToken(r)
+
+	resp, err := oh.OrderService.UpdateShipment(r.Context(), jwt, id, shipmentReq)
+	if err != nil {
+		switch err {
+		case service.ErrUnknownShipmentStatus:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case service.ErrShipmentClosed, repository.ErrShipmentChanged:
+			http.Error(w, err.Error(), http.StatusConflict)
+		case utils.ErrInvalidToken:
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case repository.ErrShipmentNotFound:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
 
 	err = json.NewEncoder(w).Encode(resp)
 	if err != nil {
@@ -119,3 +541,142 @@ func (oh *OrderHandler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// CarrierWebhook receives the tracking updates of a carrier, the carrier retries the webhook on an error
+func (oh *OrderHandler) CarrierWebhook(w http.ResponseWriter, r *http.Request) {
+	c, ok := oh.Carriers[r.PathValue("carrier")]
+	if !ok {
+		http.Error(w, "unknown carrier", http.StatusNotFound)
+		return
+	}
+
+	updates, err := c.ParseWebhook(r)
+	if err != nil {
+		if err == carrier.ErrInvalidSignature {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := oh.OrderService.TrackShipments(r.Context(), c.Name(), updates); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// CreatePromotion creates a promotion or a coupon code, only staff can manage promotions
+func (oh *OrderHandler) CreatePromotion(w http.ResponseWriter, r *http.Request) {
+	var promotionReq models.PromotionRequest
+	err := json.NewDecoder(r.Body).Decode(&promotionReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	jwt := bearerToken(r)
+
+	resp, err := oh.OrderService.CreatePromotion(r.Context(), jwt, promotionReq)
+	if err != nil {
+		writePromotionError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetPromotions gets all promotions
+func (oh *OrderHandler) GetPromotions(w http.ResponseWriter, r *http.Request) {
+	jwt := bearerToken(r)
+
+	resp, err := oh.OrderService.GetPromotions(r.Context(), jwt)
+	if err != nil {
+		writePromotionError(w, err)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetPromotion gets a promotion
+func (oh *OrderHandler) GetPromotion(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	jwt := bearerToken(r)
+
+	resp, err := oh.OrderService.GetPromotion(r.Context(), jwt, id)
+	if err != nil {
+		writePromotionError(w, err)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// UpdatePromotion replaces a promotion, it is ended by setting active to false
+func (oh *OrderHandler) UpdatePromotion(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var promotionReq models.PromotionRequest
+	err := json.NewDecoder(r.Body).Decode(&promotionReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	jwt := bearerToken(r)
+
+	resp, err := oh.OrderService.UpdatePromotion(r.Context(), jwt, id, promotionReq)
+	if err != nil {
+		writePromotionError(w, err)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func writePromotionError(w http.ResponseWriter, err error) {
+	switch {
+	case err == utils.ErrInvalidToken:
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case err == service.ErrPermissionDenied:
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err == repository.ErrPromotionNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case err == service.ErrInvalidPromotion:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case err == repository.ErrPromotionCodeExists:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// writeTransitionError tells the client which statuses are allowed instead of the requested one
+func writeTransitionError(w http.ResponseWriter, err *service.TransitionError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":            err.Error(),
+		"status":           err.From,
+		"allowed_statuses": err.Allowed,
+	})
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/NeGat1FF/e-commerce/authclient"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/service"
+)
+
+type claimsKey struct{}
+
+// Auth requires a valid access token on every request to the handler, the user service
+// validates it and its claims are put in the request context
+func Auth(auth service.Authenticator) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			token, err := authclient.BearerToken(r.Header.Get("Authorization"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			claims, err := auth.ValidateToken(r.Context(), token)
+			if err != nil {
+				if errors.Is(err, authclient.ErrUnauthenticated) {
+					http.Error(w, "invalid token", http.StatusUnauthorized)
+				} else {
+					log.Println("failed to validate token: ", err)
+					http.Error(w, "failed to validate token", http.StatusServiceUnavailable)
+				}
+				return
+			}
+
+			next(w, r.WithContext(context.WithValue(r.Context(), claimsKey{}, claims)))
+		}
+	}
+}
+
+// ClaimsFromContext returns the claims the Auth middleware put in the context, nil without them
+func ClaimsFromContext(ctx context.Context) *authclient.Claims {
+	claims, _ := ctx.Value(claimsKey{}).(*authclient.Claims)
+	return claims
+}
+
+// bearerToken returns the access token of a request the Auth middleware let through
+func bearerToken(r *http.Request) string {
+	token, _ := authclient.BearerToken(r.Header.Get("Authorization"))
+	return token
+}
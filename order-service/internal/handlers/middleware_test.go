@@ -0,0 +1,137 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/authclient"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/handlers"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAuth accepts the tokens it knows, none of their users are staff. With err set the
+// user service is unavailable.
+type fakeAuth struct {
+	users map[string]*authclient.Claims
+	err   error
+}
+
+func (a *fakeAuth) ValidateToken(ctx context.Context, token string) (*authclient.Claims, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	claims, ok := a.users[token]
+	if !ok {
+		return nil, authclient.ErrUnauthenticated
+	}
+	return claims, nil
+}
+
+func (a *fakeAuth) CheckPermission(ctx context.Context, token, permission string) (*authclient.Claims, error) {
+	claims, err := a.ValidateToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return claims, authclient.ErrForbidden
+}
+
+type fakeRepo struct {
+	repository.OrderRepoInterface
+	orders map[string]*models.OrderResponse
+}
+
+func (r *fakeRepo) GetOrderByID(ctx context.Context, orderID string) (*models.OrderResponse, error) {
+	order, ok := r.orders[orderID]
+	if !ok {
+		return nil, repository.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func TestAuth(t *testing.T) {
+	claims := &authclient.Claims{UserID: uuid.NewString()}
+
+	testCases := []struct {
+		name   string
+		header string
+		err    error
+		status int
+	}{
+		{name: "Valid token", header: "Bearer valid", status: http.StatusOK},
+		{name: "Missing header", status: http.StatusUnauthorized},
+		{name: "Not a bearer token", header: "Basic dXNlcjpwYXNz", status: http.StatusUnauthorized},
+		{name: "Invalid token", header: "Bearer forged", status: http.StatusUnauthorized},
+		{name: "User service unavailable", header: "Bearer valid", err: errors.New("connection refused"), status: http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := &fakeAuth{users: map[string]*authclient.Claims{"valid": claims}, err: tc.err}
+
+			var got *authclient.Claims
+			handler := handlers.Auth(auth)(func(w http.ResponseWriter, r *http.Request) {
+				got = handlers.ClaimsFromContext(r.Context())
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+			if tc.header != "" {
+				r.Header.Set("Authorization", tc.header)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			assert.Equal(t, tc.status, w.Code)
+			if tc.status == http.StatusOK {
+				assert.Equal(t, claims, got)
+			} else {
+				assert.Nil(t, got)
+			}
+		})
+	}
+}
+
+func TestGetOrderOfAnotherUser(t *testing.T) {
+	ownerID := uuid.New()
+	order := &models.OrderResponse{ID: uuid.New(), UserID: ownerID, Status: "PAID"}
+
+	auth := &fakeAuth{users: map[string]*authclient.Claims{
+		"owner": {UserID: ownerID.String()},
+		"other": {UserID: uuid.NewString()},
+	}}
+	repo := &fakeRepo{orders: map[string]*models.OrderResponse{order.ID.String(): order}}
+	orderHandler := handlers.NewOrderHandler(service.NewOrderService(repo, nil, nil, nil, nil, nil, nil, nil, "USD", auth), nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/orders/{id}", handlers.Auth(auth)(orderHandler.GetOrder))
+
+	testCases := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{name: "Owner", token: "owner", status: http.StatusOK},
+		// Not 403, other users cannot tell whether the order exists
+		{name: "Another user", token: "other", status: http.StatusNotFound},
+		{name: "Without token", status: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/orders/"+order.ID.String(), nil)
+			if tc.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.status, w.Code)
+		})
+	}
+}
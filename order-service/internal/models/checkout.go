@@ -0,0 +1,73 @@
+package models
+
+import "github.com/NeGat1FF/e-commerce/money"
+
+type CheckoutRequest struct {
+	ShippingAddressID string `json:"shipping_address_id"`
+	ShippingMethod    string `json:"shipping_method"`
+	// ReviewedLines are the lines of the review the previous attempt was rejected with, sent back
+	// to confirm them. The order is only placed if they still match the current prices.
+	ReviewedLines []ReviewedLine `json:"reviewed_lines"`
+}
+
+// ReviewedLine is a line of a checkout review as the client confirmed it
+type ReviewedLine struct {
+	ProductID int64       `json:"product_id"`
+	Quantity  int         `json:"quantity"`
+	Price     money.Money `json:"price"`
+	Available bool        `json:"available"`
+}
+
+// CheckoutLine is a line of the shopping cart re-priced at checkout
+type CheckoutLine struct {
+	ProductID    int64       `json:"product_id"`
+	Quantity     int         `json:"quantity"`
+	CartPrice    money.Money `json:"cart_price"`
+	Price        money.Money `json:"price"`
+	PriceChanged bool        `json:"price_changed"`
+	Available    bool        `json:"available"`
+	Category     string      `json:"-"`
+	TaxCategory  string      `json:"-"`
+	Size         ItemSize    `json:"-"`
+}
+
+// CheckoutReview is the shopping cart at current prices, unavailable lines are not part of the total.
+// Taxes and shipping are added to the total when the order is placed.
+type CheckoutReview struct {
+	Lines []CheckoutLine `json:"lines"`
+	Total money.Money    `json:"total"`
+}
+
+// HasChanges reports whether the client has to confirm the review before the order is placed
+func (r *CheckoutReview) HasChanges() bool {
+	for _, line := range r.Lines {
+		if line.PriceChanged || !line.Available {
+			return true
+		}
+	}
+	return false
+}
+
+// Confirmed reports whether the reviewed lines are the lines of the review, so the client
+// confirmed the prices and unavailable items the order is placed with
+func (r *CheckoutReview) Confirmed(reviewed []ReviewedLine) bool {
+	if len(reviewed) != len(r.Lines) {
+		return false
+	}
+
+	confirmed := make(map[int64]ReviewedLine, len(reviewed))
+	for _, line := range reviewed {
+		confirmed[line.ProductID] = line
+	}
+
+	for _, line := range r.Lines {
+		c, ok := confirmed[line.ProductID]
+		if !ok || c.Quantity != line.Quantity || c.Available != line.Available {
+			return false
+		}
+		if line.Available && money.New(c.Price.Amount, c.Price.Currency) != line.Price {
+			return false
+		}
+	}
+	return true
+}
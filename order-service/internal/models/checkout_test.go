@@ -0,0 +1,27 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckoutReviewHasChanges(t *testing.T) {
+	testCases := []struct {
+		name    string
+		lines   []models.CheckoutLine
+		changes bool
+	}{
+		{name: "Unchanged cart", lines: []models.CheckoutLine{{ProductID: 1, Available: true}, {ProductID: 2, Available: true}}},
+		{name: "Price changed", lines: []models.CheckoutLine{{ProductID: 1, Available: true}, {ProductID: 2, Available: true, PriceChanged: true}}, changes: true},
+		{name: "Item unavailable", lines: []models.CheckoutLine{{ProductID: 1, Available: true}, {ProductID: 2}}, changes: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			review := &models.CheckoutReview{Lines: tc.lines}
+			assert.Equal(t, tc.changes, review.HasChanges())
+		})
+	}
+}
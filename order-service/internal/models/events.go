@@ -0,0 +1,172 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/google/uuid"
+)
+
+// Routing keys of the order events
+const (
+	OrderCreatedRoutingKey       = "order.created"
+	OrderStatusChangedRoutingKey = "order.status_changed"
+	OrderCancelledRoutingKey     = "order.cancelled"
+	OrderRefundedRoutingKey      = "order.refunded"
+)
+
+// OrderEventVersion is the schema version of the order events. Fields may be added
+// without a new version, removing or changing the meaning of one requires a new version.
+// Version 2 carries amounts as money objects in minor units instead of decimal numbers.
+const OrderEventVersion = 2
+
+// OrderEvent is the envelope of every order event, Data depends on the routing key.
+// Events can be delivered more than once, consumers deduplicate them by ID.
+type OrderEvent struct {
+	ID         uuid.UUID `json:"id"`
+	Type       string    `json:"type"`
+	Version    int       `json:"version"`
+	OrderID    uuid.UUID `json:"order_id"`
+	UserID     uuid.UUID `json:"user_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// OrderCreatedData is the data of order.created
+type OrderCreatedData struct {
+	Status   string           `json:"status"`
+	Subtotal money.Money      `json:"subtotal"`
+	Tax      money.Money      `json:"tax"`
+	Discount money.Money      `json:"discount"`
+	Total    money.Money      `json:"total"`
+	Items    []OrderEventItem `json:"items"`
+	// ShippingMethod is empty for orders that are not shipped
+	ShippingMethod string      `json:"shipping_method,omitempty"`
+	ShippingCost   money.Money `json:"shipping_cost"`
+}
+
+type OrderEventItem struct {
+	ProductID int64       `json:"product_id"`
+	Quantity  int         `json:"quantity"`
+	Price     money.Money `json:"price"`
+	Discount  money.Money `json:"discount"`
+	Tax       money.Money `json:"tax"`
+}
+
+// OrderStatusChangedData is the data of order.status_changed, published for every status change
+type OrderStatusChangedData struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Actor  string `json:"actor"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// OrderCancelledData is the data of order.cancelled
+type OrderCancelledData struct {
+	Actor  string `json:"actor"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// OrderRefundedData is the data of order.refunded
+type OrderRefundedData struct {
+	Amount money.Money `json:"amount"`
+	Actor  string      `json:"actor"`
+	Reason string      `json:"reason,omitempty"`
+}
+
+// OutboxMessage is an event waiting in the order_outbox table to be published.
+// It is written in the transaction of the change it describes, so only committed changes are published.
+type OutboxMessage struct {
+	ID          uuid.UUID       `gorm:"type:uuid;primaryKey"`
+	RoutingKey  string          `gorm:"column:routing_key"`
+	Payload     json.RawMessage `gorm:"type:jsonb"`
+	CreatedAt   time.Time
+	PublishedAt *time.Time
+}
+
+func newOutboxMessage(routingKey string, orderID, userID uuid.UUID, occurredAt time.Time, data any) (*OutboxMessage, error) {
+	event := OrderEvent{
+		ID:         uuid.New(),
+		Type:       routingKey,
+		Version:    OrderEventVersion,
+		OrderID:    orderID,
+		UserID:     userID,
+		OccurredAt: occurredAt,
+		Data:       data,
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxMessage{
+		ID:         event.ID,
+		RoutingKey: routingKey,
+		Payload:    payload,
+		CreatedAt:  occurredAt,
+	}, nil
+}
+
+// OrderCreatedEvents returns the events of a new order
+func OrderCreatedEvents(order *Order, items []*OrderItem, occurredAt time.Time) ([]*OutboxMessage, error) {
+	data := OrderCreatedData{
+		Status:   OrderStatusName(order.Status),
+		Subtotal: order.Subtotal,
+		Tax:      order.Tax,
+		Discount: order.Discount,
+		Total:    order.Total,
+		Items:    make([]OrderEventItem, 0, len(items)),
+
+		ShippingMethod: order.ShippingMethod,
+		ShippingCost:   order.ShippingCost,
+	}
+	for _, item := range items {
+		data.Items = append(data.Items, OrderEventItem{ProductID: item.ProductID, Quantity: item.Quantity, Price: item.Price, Discount: item.Discount, Tax: item.Tax})
+	}
+
+	msg, err := newOutboxMessage(OrderCreatedRoutingKey, order.ID, order.UserID, occurredAt, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*OutboxMessage{msg}, nil
+}
+
+// StatusChangedEvents returns the events of a status change of an order, cancelled and
+// refunded orders get an event of their own next to order.status_changed
+func StatusChangedEvents(change *OrderStatusChange, userID uuid.UUID, total money.Money) ([]*OutboxMessage, error) {
+	data := OrderStatusChangedData{
+		To:     OrderStatusName(change.ToStatus),
+		Actor:  change.Actor,
+		Reason: change.Reason,
+	}
+	if change.FromStatus != nil {
+		data.From = OrderStatusName(*change.FromStatus)
+	}
+
+	msg, err := newOutboxMessage(OrderStatusChangedRoutingKey, change.OrderID, userID, change.CreatedAt, data)
+	if err != nil {
+		return nil, err
+	}
+	messages := []*OutboxMessage{msg}
+
+	var routingKey string
+	var extra any
+	switch change.ToStatus {
+	case OrderStatusCancelled:
+		routingKey, extra = OrderCancelledRoutingKey, OrderCancelledData{Actor: change.Actor, Reason: change.Reason}
+	case OrderStatusRefunded:
+		routingKey, extra = OrderRefundedRoutingKey, OrderRefundedData{Amount: total, Actor: change.Actor, Reason: change.Reason}
+	default:
+		return messages, nil
+	}
+
+	msg, err = newOutboxMessage(routingKey, change.OrderID, userID, change.CreatedAt, extra)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(messages, msg), nil
+}
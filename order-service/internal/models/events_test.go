@@ -0,0 +1,70 @@
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusChangedEvents(t *testing.T) {
+	from := models.OrderStatusPendingPayment
+	change := &models.OrderStatusChange{
+		ID:         uuid.New(),
+		OrderID:    uuid.New(),
+		FromStatus: &from,
+		ToStatus:   models.OrderStatusPaid,
+		Actor:      models.ActorSystem,
+		Reason:     "payment succeeded",
+		CreatedAt:  time.Now(),
+	}
+
+	messages, err := models.StatusChangedEvents(change, uuid.New(), money.New(1000, "USD"))
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, models.OrderStatusChangedRoutingKey, messages[0].RoutingKey)
+
+	var event struct {
+		ID      uuid.UUID                     `json:"id"`
+		Type    string                        `json:"type"`
+		Version int                           `json:"version"`
+		OrderID uuid.UUID                     `json:"order_id"`
+		Data    models.OrderStatusChangedData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(messages[0].Payload, &event))
+	assert.Equal(t, messages[0].ID, event.ID)
+	assert.Equal(t, models.OrderStatusChangedRoutingKey, event.Type)
+	assert.Equal(t, models.OrderEventVersion, event.Version)
+	assert.Equal(t, change.OrderID, event.OrderID)
+	assert.Equal(t, models.OrderStatusChangedData{From: "PENDING_PAYMENT", To: "PAID", Actor: "system", Reason: "payment succeeded"}, event.Data)
+}
+
+func TestStatusChangedEventsOfFinalStatuses(t *testing.T) {
+	testCases := []struct {
+		name       string
+		from       int
+		to         int
+		routingKey string
+	}{
+		{name: "Cancelled", from: models.OrderStatusPendingPayment, to: models.OrderStatusCancelled, routingKey: models.OrderCancelledRoutingKey},
+		{name: "Refunded", from: models.OrderStatusPaid, to: models.OrderStatusRefunded, routingKey: models.OrderRefundedRoutingKey},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			change := &models.OrderStatusChange{OrderID: uuid.New(), FromStatus: &tc.from, ToStatus: tc.to, Actor: "user", CreatedAt: time.Now()}
+
+			messages, err := models.StatusChangedEvents(change, uuid.New(), money.New(1000, "USD"))
+			require.NoError(t, err)
+			require.Len(t, messages, 2)
+			assert.Equal(t, models.OrderStatusChangedRoutingKey, messages[0].RoutingKey)
+			assert.Equal(t, tc.routingKey, messages[1].RoutingKey)
+			assert.NotEqual(t, messages[0].ID, messages[1].ID)
+		})
+	}
+}
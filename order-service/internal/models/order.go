@@ -3,28 +3,53 @@ package models
 import (
 	"time"
 
+	"github.com/NeGat1FF/e-commerce/money"
 	"github.com/google/uuid"
 )
 
+// Order is an order of a user. Discount is what promotions took off the items and the shipping,
+// Subtotal is the amount of the items after discounts without tax and Total the amount to pay
+// including the shipping cost. PricesIncludeTax records whether the prices of the items were
+// listed with the tax included.
 type Order struct {
-	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
-	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid"`
-	Status    int       `json:"status" gorm:"type:int;references:order_status(id)"`
-	Total     float64   `json:"total"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID               uuid.UUID    `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID           uuid.UUID    `json:"user_id" gorm:"type:uuid"`
+	Status           int          `json:"status" gorm:"type:int;references:order_status(id)"`
+	Subtotal         money.Money  `json:"subtotal" gorm:"embedded;embeddedPrefix:subtotal_"`
+	Tax              money.Money  `json:"tax" gorm:"embedded;embeddedPrefix:tax_"`
+	Discount         money.Money  `json:"discount" gorm:"embedded;embeddedPrefix:discount_"`
+	Total            money.Money  `json:"total" gorm:"embedded;embeddedPrefix:total_"`
+	TaxBreakdown     TaxBreakdown `json:"tax_breakdown" gorm:"type:jsonb"`
+	PricesIncludeTax bool         `json:"prices_include_tax"`
+	ShippingMethod   string       `json:"shipping_method"`
+	ShippingCost     money.Money  `json:"shipping_cost" gorm:"embedded;embeddedPrefix:shipping_cost_"`
+	ShippingAddress  *Address     `json:"shipping_address" gorm:"type:jsonb"`
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
 }
 
 type CreateOrderRequest struct {
-	Products Products `json:"products"`
+	Products          Products `json:"products"`
+	ShippingAddressID string   `json:"shipping_address_id"`
+	ShippingMethod    string   `json:"shipping_method"`
+	CouponCodes       []string `json:"coupon_codes"`
 }
 
 type OrderResponse struct {
-	ID        uuid.UUID `json:"id"`
-	UserID    uuid.UUID `json:"user_id"`
-	Products  Products  `json:"products"`
-	Total     float64   `json:"total"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID               uuid.UUID      `json:"id"`
+	UserID           uuid.UUID      `json:"user_id"`
+	Products         Products       `json:"products"`
+	Subtotal         money.Money    `json:"subtotal" gorm:"embedded;embeddedPrefix:subtotal_"`
+	Tax              money.Money    `json:"tax" gorm:"embedded;embeddedPrefix:tax_"`
+	Discount         money.Money    `json:"discount" gorm:"embedded;embeddedPrefix:discount_"`
+	Discounts        OrderDiscounts `json:"discounts"`
+	Total            money.Money    `json:"total" gorm:"embedded;embeddedPrefix:total_"`
+	TaxBreakdown     TaxBreakdown   `json:"tax_breakdown"`
+	PricesIncludeTax bool           `json:"prices_include_tax"`
+	ShippingMethod   string         `json:"shipping_method"`
+	ShippingCost     money.Money    `json:"shipping_cost" gorm:"embedded;embeddedPrefix:shipping_cost_"`
+	Status           string         `json:"status"`
+	ShippingAddress  *Address       `json:"shipping_address"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
 }
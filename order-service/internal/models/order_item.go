@@ -3,14 +3,32 @@ package models
 import (
 	"time"
 
+	"github.com/NeGat1FF/e-commerce/money"
 	"github.com/google/uuid"
 )
 
+// OrderItem is a line of an order. Price is the listed price of one item, Discount what promotions
+// took off the whole line, Tax the tax of the whole line and Total what the customer pays for it.
 type OrderItem struct {
-	OrderID   uuid.UUID `json:"order_id" gorm:"type:uuid;references:orders(id);primaryKey"`
-	ProductID int64     `json:"product_id" gorm:"primaryKey"`
-	Quantity  int       `json:"quantity"`
-	Price     float64   `json:"price"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	OrderID     uuid.UUID   `json:"order_id" gorm:"type:uuid;references:orders(id);primaryKey"`
+	ProductID   int64       `json:"product_id" gorm:"primaryKey"`
+	Quantity    int         `json:"quantity"`
+	Price       money.Money `json:"price" gorm:"embedded;embeddedPrefix:price_"`
+	Discount    money.Money `json:"discount" gorm:"embedded;embeddedPrefix:discount_"`
+	TaxCategory string      `json:"tax_category"`
+	TaxRate     float64     `json:"tax_rate"`
+	Tax         money.Money `json:"tax" gorm:"embedded;embeddedPrefix:tax_"`
+	Total       money.Money `json:"total" gorm:"embedded;embeddedPrefix:total_"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	// Category and Size are only known while the order is placed, promotions and shipping are priced by them
+	Category string   `json:"-" gorm:"-"`
+	Size     ItemSize `json:"-" gorm:"-"`
+}
+
+// RefundFor returns the amount paid for some of the items of the line, given how many of
+// them were returned before. The amounts of all returns of a line add up to its total.
+func (i *OrderItem) RefundFor(returned, quantity int) money.Money {
+	whole := int64(i.Quantity)
+	return i.Total.Share(int64(returned+quantity), whole).Sub(i.Total.Share(int64(returned), whole))
 }
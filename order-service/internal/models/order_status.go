@@ -1,12 +1,118 @@
 package models
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
 type OrderStatus struct {
 	ID   int    `json:"id" gorm:"type:int;primaryKey"`
 	Name string `json:"name"`
 }
 
 const (
-	OrderStatusPending  = 1
-	OrderStatusAccepted = 2
-	OrderStatusRejected = 3
+	OrderStatusPendingPayment   = 1
+	OrderStatusPaid             = 2
+	OrderStatusCancelled        = 3
+	OrderStatusProcessing       = 4
+	OrderStatusPartiallyShipped = 5
+	OrderStatusShipped          = 6
+	OrderStatusDelivered        = 7
+	OrderStatusRefunded         = 8
+	OrderStatusReturned         = 9
 )
+
+// ActorSystem is the actor of status changes made by the order service itself
+const ActorSystem = "system"
+
+// orderStatusNames are the names of the statuses as stored in the order_statuses table
+var orderStatusNames = map[int]string{
+	OrderStatusPendingPayment:   "PENDING_PAYMENT",
+	OrderStatusPaid:             "PAID",
+	OrderStatusCancelled:        "CANCELLED",
+	OrderStatusProcessing:       "PROCESSING",
+	OrderStatusPartiallyShipped: "PARTIALLY_SHIPPED",
+	OrderStatusShipped:          "SHIPPED",
+	OrderStatusDelivered:        "DELIVERED",
+	OrderStatusRefunded:         "REFUNDED",
+	OrderStatusReturned:         "RETURNED",
+}
+
+// orderTransitions lists the statuses an order can move to from each status.
+// Cancelled and refunded orders are final, paid orders are refunded when they are cancelled.
+var orderTransitions = map[int][]int{
+	OrderStatusPendingPayment:   {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:             {OrderStatusProcessing, OrderStatusCancelled, OrderStatusRefunded},
+	OrderStatusProcessing:       {OrderStatusPartiallyShipped, OrderStatusShipped, OrderStatusRefunded},
+	OrderStatusPartiallyShipped: {OrderStatusShipped},
+	OrderStatusShipped:          {OrderStatusDelivered},
+	OrderStatusDelivered:        {OrderStatusReturned},
+	OrderStatusReturned:         {OrderStatusRefunded},
+}
+
+// OrderStatusName returns the name of a status, or an empty string for an unknown status
+func OrderStatusName(status int) string {
+	return orderStatusNames[status]
+}
+
+// ParseOrderStatus returns the status with the given name
+func ParseOrderStatus(name string) (int, bool) {
+	for status, n := range orderStatusNames {
+		if n == name {
+			return status, true
+		}
+	}
+	return 0, false
+}
+
+// NextOrderStatuses returns the names of the statuses an order can move to from the status
+func NextOrderStatuses(status int) []string {
+	names := []string{}
+	for _, next := range orderTransitions[status] {
+		names = append(names, orderStatusNames[next])
+	}
+	return names
+}
+
+// CanTransition reports whether an order can move from one status to the other
+func CanTransition(from, to int) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CustomerCanCancel reports whether the customer can still cancel an order in the status,
+// which is the case until its fulfilment starts
+func CustomerCanCancel(status int) bool {
+	return status == OrderStatusPendingPayment || status == OrderStatusPaid
+}
+
+// CanReturn reports whether items of an order in the status can be returned
+func CanReturn(status int) bool {
+	return status == OrderStatusDelivered || status == OrderStatusReturned
+}
+
+// OrderStatusChange is a row of the status history of an order,
+// FromStatus is nil for the status the order was created with
+type OrderStatusChange struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	OrderID    uuid.UUID `json:"order_id" gorm:"type:uuid"`
+	FromStatus *int      `json:"from_status"`
+	ToStatus   int       `json:"to_status"`
+	Actor      string    `json:"actor"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// StatusHistoryEntry is a status change as returned to clients
+type StatusHistoryEntry struct {
+	From      string    `json:"from,omitempty"`
+	To        string    `json:"to"`
+	Actor     string    `json:"actor"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
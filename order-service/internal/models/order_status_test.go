@@ -0,0 +1,50 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransition(t *testing.T) {
+	testCases := []struct {
+		name    string
+		from    int
+		to      int
+		allowed bool
+	}{
+		{name: "Pay a pending order", from: models.OrderStatusPendingPayment, to: models.OrderStatusPaid, allowed: true},
+		{name: "Cancel a pending order", from: models.OrderStatusPendingPayment, to: models.OrderStatusCancelled, allowed: true},
+		{name: "Cancel a paid order", from: models.OrderStatusPaid, to: models.OrderStatusCancelled, allowed: true},
+		{name: "Cancel a processing order", from: models.OrderStatusProcessing, to: models.OrderStatusCancelled},
+		{name: "Ship a partially shipped order", from: models.OrderStatusPartiallyShipped, to: models.OrderStatusShipped, allowed: true},
+		{name: "Refund a returned order", from: models.OrderStatusReturned, to: models.OrderStatusRefunded, allowed: true},
+		{name: "Ship an unpaid order", from: models.OrderStatusPendingPayment, to: models.OrderStatusShipped},
+		{name: "Deliver before shipping", from: models.OrderStatusProcessing, to: models.OrderStatusDelivered},
+		{name: "Reopen a cancelled order", from: models.OrderStatusCancelled, to: models.OrderStatusPendingPayment},
+		{name: "Stay in the same status", from: models.OrderStatusPaid, to: models.OrderStatusPaid},
+		{name: "Unknown status", from: 42, to: models.OrderStatusPaid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.allowed, models.CanTransition(tc.from, tc.to))
+		})
+	}
+}
+
+func TestNextOrderStatuses(t *testing.T) {
+	assert.Equal(t, []string{"PAID", "CANCELLED"}, models.NextOrderStatuses(models.OrderStatusPendingPayment))
+	assert.Empty(t, models.NextOrderStatuses(models.OrderStatusRefunded))
+}
+
+func TestParseOrderStatus(t *testing.T) {
+	status, ok := models.ParseOrderStatus("PARTIALLY_SHIPPED")
+	assert.True(t, ok)
+	assert.Equal(t, models.OrderStatusPartiallyShipped, status)
+	assert.Equal(t, "PARTIALLY_SHIPPED", models.OrderStatusName(status))
+
+	_, ok = models.ParseOrderStatus("APPROVED")
+	assert.False(t, ok)
+}
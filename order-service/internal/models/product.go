@@ -4,11 +4,18 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+
+	"github.com/NeGat1FF/e-commerce/money"
 )
 
+// Product is a product and its quantity in a request. In orders it also carries the
+// listed price of one item and the discount and tax of the line.
 type Product struct {
-	ID       int64 `json:"product_id" gorm:"type:uuid;primaryKey"`
-	Quantity int   `json:"quantity" gorm:"type:int"`
+	ID       int64        `json:"product_id" gorm:"type:uuid;primaryKey"`
+	Quantity int          `json:"quantity" gorm:"type:int"`
+	Price    *money.Money `json:"price,omitempty" gorm:"-"`
+	Discount *money.Money `json:"discount,omitempty" gorm:"-"`
+	Tax      *money.Money `json:"tax,omitempty" gorm:"-"`
 }
 
 type Products []Product
@@ -0,0 +1,174 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/google/uuid"
+)
+
+const (
+	// PromotionPercentage takes Value percent off the eligible items
+	PromotionPercentage = "PERCENTAGE"
+	// PromotionFixed takes Amount off the eligible items
+	PromotionFixed = "FIXED"
+	// PromotionBuyXGetY gives GetQuantity items of an eligible product for free for every BuyQuantity bought
+	PromotionBuyXGetY = "BUY_X_GET_Y"
+	// PromotionFreeShipping takes the shipping cost off the order
+	PromotionFreeShipping = "FREE_SHIPPING"
+)
+
+// Promotion is a discount that applies automatically, or when its coupon code is entered if it has one.
+// Promotions apply to the products and categories they are scoped to or to all items if they are not scoped.
+// Stackable promotions combine with each other, one that is not stackable only applies alone.
+type Promotion struct {
+	ID   uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	Name string    `json:"name"`
+	// Code is the coupon code in upper case, empty for automatic promotions
+	Code string `json:"code,omitempty"`
+	Type string `json:"type"`
+	// Value is the percentage of a PromotionPercentage, Amount the amount of a PromotionFixed
+	Value       float64     `json:"value,omitempty"`
+	Amount      money.Money `json:"amount" gorm:"embedded;embeddedPrefix:fixed_"`
+	BuyQuantity int         `json:"buy_quantity,omitempty"`
+	GetQuantity int         `json:"get_quantity,omitempty"`
+	ProductIDs  ProductIDs  `json:"product_ids,omitempty" gorm:"type:jsonb"`
+	Categories  StringSlice `json:"categories,omitempty" gorm:"type:jsonb"`
+	// MinSubtotal is the value of the items an order needs for the promotion to apply
+	MinSubtotal money.Money `json:"min_subtotal" gorm:"embedded;embeddedPrefix:min_subtotal_"`
+	StartsAt    *time.Time  `json:"starts_at,omitempty"`
+	EndsAt      *time.Time  `json:"ends_at,omitempty"`
+	// UsageLimit and PerCustomerLimit bound the orders the promotion applies to, 0 for no limit
+	UsageLimit       int       `json:"usage_limit,omitempty"`
+	PerCustomerLimit int       `json:"per_customer_limit,omitempty"`
+	UsageCount       int       `json:"usage_count"`
+	Stackable        bool      `json:"stackable"`
+	Priority         int       `json:"priority"`
+	Active           bool      `json:"active"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// PromotionRequest creates or replaces a promotion
+type PromotionRequest struct {
+	Name             string      `json:"name"`
+	Code             string      `json:"code"`
+	Type             string      `json:"type"`
+	Value            float64     `json:"value"`
+	Amount           money.Money `json:"amount"`
+	BuyQuantity      int         `json:"buy_quantity"`
+	GetQuantity      int         `json:"get_quantity"`
+	ProductIDs       ProductIDs  `json:"product_ids"`
+	Categories       []string    `json:"categories"`
+	MinSubtotal      money.Money `json:"min_subtotal"`
+	StartsAt         *time.Time  `json:"starts_at"`
+	EndsAt           *time.Time  `json:"ends_at"`
+	UsageLimit       int         `json:"usage_limit"`
+	PerCustomerLimit int         `json:"per_customer_limit"`
+	Stackable        bool        `json:"stackable"`
+	Priority         int         `json:"priority"`
+	Active           bool        `json:"active"`
+}
+
+// Valid reports whether the request describes a promotion that can be applied. Whether its amounts
+// are in the currency of the shop is checked when it is saved.
+func (r *PromotionRequest) Valid() bool {
+	if r.Name == "" || r.MinSubtotal.IsNegative() || r.UsageLimit < 0 || r.PerCustomerLimit < 0 {
+		return false
+	}
+	if r.StartsAt != nil && r.EndsAt != nil && !r.EndsAt.After(*r.StartsAt) {
+		return false
+	}
+
+	switch r.Type {
+	case PromotionPercentage:
+		return r.Value > 0 && r.Value <= 100
+	case PromotionFixed:
+		return r.Amount.IsPositive()
+	case PromotionBuyXGetY:
+		return r.BuyQuantity > 0 && r.GetQuantity > 0
+	case PromotionFreeShipping:
+		return true
+	default:
+		return false
+	}
+}
+
+// InWindow reports whether the promotion is active and valid at the time
+func (p *Promotion) InWindow(at time.Time) bool {
+	return p.Active && (p.StartsAt == nil || !at.Before(*p.StartsAt)) && (p.EndsAt == nil || at.Before(*p.EndsAt))
+}
+
+// Applies reports whether the promotion applies to items of the product and category
+func (p *Promotion) Applies(productID int64, category string) bool {
+	if len(p.ProductIDs) == 0 && len(p.Categories) == 0 {
+		return true
+	}
+	return slices.Contains(p.ProductIDs, productID) || (category != "" && slices.Contains(p.Categories, category))
+}
+
+// OrderDiscount is the discount a promotion gave an order. The discount of every item is also
+// kept with the item, so that refunds pay back what was paid for it.
+type OrderDiscount struct {
+	ID          uuid.UUID   `json:"-" gorm:"type:uuid;primaryKey"`
+	OrderID     uuid.UUID   `json:"-" gorm:"type:uuid"`
+	PromotionID uuid.UUID   `json:"promotion_id" gorm:"type:uuid"`
+	Code        string      `json:"code,omitempty"`
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Amount      money.Money `json:"amount" gorm:"embedded"`
+	CreatedAt   time.Time   `json:"-"`
+}
+
+type OrderDiscounts []OrderDiscount
+
+// Scan implements the sql.Scanner interface for reading from the database.
+func (d *OrderDiscounts) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, d)
+}
+
+type ProductIDs []int64
+
+// Value implements the driver.Valuer interface for saving into the database.
+func (p ProductIDs) Value() (driver.Value, error) {
+	if p == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(p)
+}
+
+// Scan implements the sql.Scanner interface for reading from the database.
+func (p *ProductIDs) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, p)
+}
+
+type StringSlice []string
+
+// Value implements the driver.Valuer interface for saving into the database.
+func (s StringSlice) Value() (driver.Value, error) {
+	if s == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(s)
+}
+
+// Scan implements the sql.Scanner interface for reading from the database.
+func (s *StringSlice) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, s)
+}
@@ -0,0 +1,65 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromotionRequestValid(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	testCases := []struct {
+		name  string
+		req   models.PromotionRequest
+		valid bool
+	}{
+		{name: "Percentage", req: models.PromotionRequest{Name: "Sale", Type: models.PromotionPercentage, Value: 10}, valid: true},
+		{name: "Percentage over 100", req: models.PromotionRequest{Name: "Sale", Type: models.PromotionPercentage, Value: 110}},
+		{name: "Fixed without amount", req: models.PromotionRequest{Name: "Sale", Type: models.PromotionFixed}},
+		{name: "Fixed with a negative amount", req: models.PromotionRequest{Name: "Sale", Type: models.PromotionFixed, Amount: money.New(-500, "USD")}},
+		{name: "Negative minimum subtotal", req: models.PromotionRequest{Name: "Sale", Type: models.PromotionPercentage, Value: 10, MinSubtotal: money.New(-1, "USD")}},
+		{name: "Buy X get Y", req: models.PromotionRequest{Name: "3 for 2", Type: models.PromotionBuyXGetY, BuyQuantity: 2, GetQuantity: 1}, valid: true},
+		{name: "Buy X get Y without quantities", req: models.PromotionRequest{Name: "3 for 2", Type: models.PromotionBuyXGetY}},
+		{name: "Free shipping", req: models.PromotionRequest{Name: "Free shipping", Code: "SHIPFREE", Type: models.PromotionFreeShipping}, valid: true},
+		{name: "Without name", req: models.PromotionRequest{Type: models.PromotionFreeShipping}},
+		{name: "Unknown type", req: models.PromotionRequest{Name: "Sale", Type: "BOGUS", Value: 10}},
+		{name: "Window", req: models.PromotionRequest{Name: "Sale", Type: models.PromotionFixed, Amount: money.New(500, "USD"), StartsAt: &start, EndsAt: &end}, valid: true},
+		{name: "Window ends before it starts", req: models.PromotionRequest{Name: "Sale", Type: models.PromotionFixed, Amount: money.New(500, "USD"), StartsAt: &end, EndsAt: &start}},
+		{name: "Negative usage limit", req: models.PromotionRequest{Name: "Sale", Type: models.PromotionFixed, Amount: money.New(500, "USD"), UsageLimit: -1}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.valid, tc.req.Valid())
+		})
+	}
+}
+
+func TestPromotionInWindow(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	p := models.Promotion{Active: true, StartsAt: &start, EndsAt: &end}
+
+	assert.False(t, p.InWindow(start.Add(-time.Second)))
+	assert.True(t, p.InWindow(start))
+	assert.True(t, p.InWindow(end.Add(-time.Second)))
+	assert.False(t, p.InWindow(end))
+
+	p.Active = false
+	assert.False(t, p.InWindow(start))
+}
+
+func TestPromotionApplies(t *testing.T) {
+	assert.True(t, (&models.Promotion{}).Applies(1, ""))
+
+	p := models.Promotion{ProductIDs: models.ProductIDs{1}, Categories: models.StringSlice{"books"}}
+	assert.True(t, p.Applies(1, "toys"))
+	assert.True(t, p.Applies(2, "books"))
+	assert.False(t, p.Applies(2, "toys"))
+	assert.False(t, p.Applies(2, ""))
+}
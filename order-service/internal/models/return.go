@@ -0,0 +1,111 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/google/uuid"
+)
+
+// Statuses of a return request
+const (
+	ReturnStatusRequested = "REQUESTED"
+	ReturnStatusApproved  = "APPROVED"
+	ReturnStatusReceived  = "RECEIVED"
+	ReturnStatusRefunded  = "REFUNDED"
+	ReturnStatusRejected  = "REJECTED"
+)
+
+// returnTransitions lists the statuses a return can move to from each status.
+// Received items are restocked and refunded returns are paid back, both are final.
+var returnTransitions = map[string][]string{
+	ReturnStatusRequested: {ReturnStatusApproved, ReturnStatusRejected},
+	ReturnStatusApproved:  {ReturnStatusReceived, ReturnStatusRejected},
+	ReturnStatusReceived:  {ReturnStatusRefunded},
+}
+
+// NextReturnStatuses returns the statuses a return can move to from the status
+func NextReturnStatuses(status string) []string {
+	return append([]string{}, returnTransitions[status]...)
+}
+
+// CanTransitionReturn reports whether a return can move from one status to the other
+func CanTransitionReturn(from, to string) bool {
+	for _, next := range returnTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Return is a request of the customer to send back items of a delivered order
+type Return struct {
+	ID           uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey"`
+	OrderID      uuid.UUID   `json:"order_id" gorm:"type:uuid"`
+	UserID       uuid.UUID   `json:"user_id" gorm:"type:uuid"`
+	Status       string      `json:"status"`
+	Reason       string      `json:"reason"`
+	Items        ReturnItems `json:"items" gorm:"type:jsonb"`
+	RefundAmount money.Money `json:"refund_amount" gorm:"embedded;embeddedPrefix:refund_"`
+	// Note is left by the staff member who handled the return
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ReturnItem is a line of a return, the price is what the customer paid for one item
+type ReturnItem struct {
+	ProductID int64       `json:"product_id"`
+	Quantity  int         `json:"quantity"`
+	Price     money.Money `json:"price"`
+}
+
+type ReturnItems []ReturnItem
+
+// Value implements the driver.Valuer interface for saving into the database.
+func (r ReturnItems) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// Scan implements the sql.Scanner interface for reading from the database.
+func (r *ReturnItems) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, r)
+}
+
+type CreateReturnRequest struct {
+	Items  Products `json:"items"`
+	Reason string   `json:"reason"`
+}
+
+type UpdateReturnRequest struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+// ReturnableQuantities returns how many of each product of an order can still be returned,
+// items in returns that were not rejected are not returnable again
+func ReturnableQuantities(items []*OrderItem, returns []Return) map[int64]int {
+	returnable := make(map[int64]int, len(items))
+	for _, item := range items {
+		returnable[item.ProductID] += item.Quantity
+	}
+
+	for _, ret := range returns {
+		if ret.Status == ReturnStatusRejected {
+			continue
+		}
+		for _, item := range ret.Items {
+			returnable[item.ProductID] -= item.Quantity
+		}
+	}
+
+	return returnable
+}
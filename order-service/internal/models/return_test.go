@@ -0,0 +1,58 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransitionReturn(t *testing.T) {
+	testCases := []struct {
+		name    string
+		from    string
+		to      string
+		allowed bool
+	}{
+		{name: "Approve a requested return", from: models.ReturnStatusRequested, to: models.ReturnStatusApproved, allowed: true},
+		{name: "Reject an approved return", from: models.ReturnStatusApproved, to: models.ReturnStatusRejected, allowed: true},
+		{name: "Refund a received return", from: models.ReturnStatusReceived, to: models.ReturnStatusRefunded, allowed: true},
+		{name: "Refund before receiving", from: models.ReturnStatusApproved, to: models.ReturnStatusRefunded},
+		{name: "Reject a received return", from: models.ReturnStatusReceived, to: models.ReturnStatusRejected},
+		{name: "Reopen a rejected return", from: models.ReturnStatusRejected, to: models.ReturnStatusRequested},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.allowed, models.CanTransitionReturn(tc.from, tc.to))
+		})
+	}
+}
+
+func TestReturnableQuantities(t *testing.T) {
+	items := []*models.OrderItem{
+		{ProductID: 1, Quantity: 3},
+		{ProductID: 2, Quantity: 1},
+	}
+	returns := []models.Return{
+		{Status: models.ReturnStatusRequested, Items: models.ReturnItems{{ProductID: 1, Quantity: 2}}},
+		{Status: models.ReturnStatusRejected, Items: models.ReturnItems{{ProductID: 2, Quantity: 1}}},
+	}
+
+	assert.Equal(t, map[int64]int{1: 1, 2: 1}, models.ReturnableQuantities(items, returns))
+}
+
+func TestRefundFor(t *testing.T) {
+	item := &models.OrderItem{ProductID: 1, Quantity: 3, Price: money.New(840, "USD"), Tax: money.New(80, "USD"), Total: money.New(1000, "USD")}
+
+	first := item.RefundFor(0, 1)
+	second := item.RefundFor(1, 1)
+	third := item.RefundFor(2, 1)
+
+	assert.Equal(t, money.New(333, "USD"), first)
+	assert.Equal(t, money.New(334, "USD"), second)
+	assert.Equal(t, money.New(333, "USD"), third)
+	assert.Equal(t, item.Total, first.Add(second).Add(third))
+	assert.Equal(t, item.Total, item.RefundFor(0, 3))
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Statuses of an order saga. A running saga moves forward through the steps,
+// a compensating one undoes them after a step failed for good.
+const (
+	SagaRunning      = "RUNNING"
+	SagaCompleted    = "COMPLETED"
+	SagaCompensating = "COMPENSATING"
+	SagaCompensated  = "COMPENSATED"
+)
+
+// Steps of an order saga
+const (
+	SagaStepReserveStock  = "RESERVE_STOCK"
+	SagaStepCreatePayment = "CREATE_PAYMENT"
+	SagaStepAwaitPayment  = "AWAIT_PAYMENT"
+	SagaStepMarkPaid      = "MARK_PAID"
+
+	SagaStepCancelPayment = "CANCEL_PAYMENT"
+	SagaStepReleaseStock  = "RELEASE_STOCK"
+	SagaStepCancelOrder   = "CANCEL_ORDER"
+)
+
+// Outcomes of a saga step attempt
+const (
+	SagaStepSucceeded = "SUCCEEDED"
+	SagaStepFailed    = "FAILED"
+	SagaStepRetrying  = "RETRYING"
+)
+
+var (
+	sagaSteps         = []string{SagaStepReserveStock, SagaStepCreatePayment, SagaStepAwaitPayment, SagaStepMarkPaid}
+	sagaCompensations = []string{SagaStepCancelPayment, SagaStepReleaseStock, SagaStepCancelOrder}
+)
+
+// NextSagaStep returns the step that follows a forward or compensation step,
+// or an empty string after the last one
+func NextSagaStep(step string) string {
+	for _, steps := range [][]string{sagaSteps, sagaCompensations} {
+		for i, s := range steps {
+			if s == step && i+1 < len(steps) {
+				return steps[i+1]
+			}
+		}
+	}
+	return ""
+}
+
+// CompensationFor returns the compensation step to start from when a forward step failed.
+// Nothing was paid before the payment was created, so only the stock and the order are undone.
+func CompensationFor(step string) string {
+	if step == SagaStepReserveStock {
+		return SagaStepReleaseStock
+	}
+	return SagaStepCancelPayment
+}
+
+// IsCompensation reports whether the step undoes a forward step
+func IsCompensation(step string) bool {
+	for _, s := range sagaCompensations {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+// Saga is the persisted state of the saga that takes an order from placed to paid.
+// Sagas are picked up by whichever replica claims them first, LockedUntil keeps others away meanwhile.
+type Saga struct {
+	OrderID   uuid.UUID `json:"order_id" gorm:"type:uuid;primaryKey"`
+	Status    string    `json:"status"`
+	Step      string    `json:"step"`
+	Attempts  int       `json:"attempts"`
+	Reason    string    `json:"reason"`
+	PaymentID string    `json:"payment_id"`
+	// Deadline is when an unpaid payment is given up on, it is set once the payment is created
+	Deadline    *time.Time `json:"deadline"`
+	NextRunAt   time.Time  `json:"next_run_at"`
+	LockedUntil *time.Time `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// SagaStep is a recorded attempt of a saga step
+type SagaStep struct {
+	ID           uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	OrderID      uuid.UUID `json:"-" gorm:"type:uuid"`
+	Step         string    `json:"step"`
+	Compensation bool      `json:"compensation"`
+	Outcome      string    `json:"outcome"`
+	Error        string    `json:"error,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// SagaResponse is the state of the saga of an order with the steps taken so far
+type SagaResponse struct {
+	Saga
+	Steps []SagaStep `json:"steps"`
+}
@@ -0,0 +1,38 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextSagaStep(t *testing.T) {
+	testCases := []struct {
+		name string
+		step string
+		next string
+	}{
+		{name: "Pay after reserving stock", step: models.SagaStepReserveStock, next: models.SagaStepCreatePayment},
+		{name: "Mark paid after the payment", step: models.SagaStepAwaitPayment, next: models.SagaStepMarkPaid},
+		{name: "Done after marking paid", step: models.SagaStepMarkPaid, next: ""},
+		{name: "Release stock after cancelling the payment", step: models.SagaStepCancelPayment, next: models.SagaStepReleaseStock},
+		{name: "Done after cancelling the order", step: models.SagaStepCancelOrder, next: ""},
+		{name: "Unknown step", step: "SHIP", next: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.next, models.NextSagaStep(tc.step))
+		})
+	}
+}
+
+func TestCompensationFor(t *testing.T) {
+	assert.Equal(t, models.SagaStepReleaseStock, models.CompensationFor(models.SagaStepReserveStock))
+	assert.Equal(t, models.SagaStepCancelPayment, models.CompensationFor(models.SagaStepCreatePayment))
+	assert.Equal(t, models.SagaStepCancelPayment, models.CompensationFor(models.SagaStepAwaitPayment))
+
+	assert.True(t, models.IsCompensation(models.SagaStepReleaseStock))
+	assert.False(t, models.IsCompensation(models.SagaStepAwaitPayment))
+}
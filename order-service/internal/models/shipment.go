@@ -0,0 +1,187 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Statuses of a shipment. Delivered and cancelled shipments are final.
+const (
+	ShipmentStatusPending        = "PENDING"
+	ShipmentStatusInTransit      = "IN_TRANSIT"
+	ShipmentStatusOutForDelivery = "OUT_FOR_DELIVERY"
+	ShipmentStatusException      = "EXCEPTION"
+	ShipmentStatusDelivered      = "DELIVERED"
+	ShipmentStatusCancelled      = "CANCELLED"
+)
+
+var shipmentStatuses = []string{
+	ShipmentStatusPending,
+	ShipmentStatusInTransit,
+	ShipmentStatusOutForDelivery,
+	ShipmentStatusException,
+	ShipmentStatusDelivered,
+	ShipmentStatusCancelled,
+}
+
+// IsShipmentStatus reports whether the name is a shipment status
+func IsShipmentStatus(status string) bool {
+	for _, s := range shipmentStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ShipmentClosed reports whether a shipment in the status takes no more updates
+func ShipmentClosed(status string) bool {
+	return status == ShipmentStatusDelivered || status == ShipmentStatusCancelled
+}
+
+// shipmentLeftWarehouse reports whether the items of a shipment in the status were handed to the carrier
+func shipmentLeftWarehouse(status string) bool {
+	return status != ShipmentStatusPending && status != ShipmentStatusCancelled
+}
+
+// Shipment is a parcel with some or all of the lines of an order
+type Shipment struct {
+	ID             uuid.UUID     `json:"id" gorm:"type:uuid;primaryKey"`
+	OrderID        uuid.UUID     `json:"order_id" gorm:"type:uuid"`
+	Carrier        string        `json:"carrier"`
+	TrackingNumber string        `json:"tracking_number"`
+	Status         string        `json:"status"`
+	Items          ShipmentItems `json:"items" gorm:"type:jsonb"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	// Timeline lists the tracking events of the shipment, oldest first
+	Timeline []ShipmentEvent `json:"timeline" gorm:"-"`
+}
+
+type ShipmentItem struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int   `json:"quantity"`
+}
+
+type ShipmentItems []ShipmentItem
+
+// Value implements the driver.Valuer interface for saving into the database.
+func (s ShipmentItems) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Scan implements the sql.Scanner interface for reading from the database.
+func (s *ShipmentItems) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+// ShipmentEvent is an entry of the status timeline of a shipment
+type ShipmentEvent struct {
+	ID          uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	ShipmentID  uuid.UUID `json:"-" gorm:"type:uuid"`
+	Status      string    `json:"status"`
+	Description string    `json:"description,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+	CreatedAt   time.Time `json:"-"`
+}
+
+type CreateShipmentRequest struct {
+	Carrier        string        `json:"carrier"`
+	TrackingNumber string        `json:"tracking_number"`
+	Items          ShipmentItems `json:"items"`
+}
+
+type UpdateShipmentRequest struct {
+	Status      string `json:"status"`
+	Description string `json:"description"`
+	Location    string `json:"location"`
+}
+
+// UnshippedQuantities returns how many of each product of an order are not in a shipment yet,
+// the items of cancelled shipments have to be shipped again
+func UnshippedQuantities(items []*OrderItem, shipments []Shipment) map[int64]int {
+	unshipped := make(map[int64]int, len(items))
+	for _, item := range items {
+		unshipped[item.ProductID] += item.Quantity
+	}
+
+	for _, shipment := range shipments {
+		if shipment.Status == ShipmentStatusCancelled {
+			continue
+		}
+		for _, item := range shipment.Items {
+			unshipped[item.ProductID] -= item.Quantity
+		}
+	}
+
+	return unshipped
+}
+
+// FulfilmentStatus rolls the shipments of an order up into the status of the order: processing
+// until items leave the warehouse, partially shipped until all of them did, shipped until all are delivered
+func FulfilmentStatus(items []*OrderItem, shipments []Shipment) int {
+	ordered, shipped, delivered := 0, 0, 0
+	for _, item := range items {
+		ordered += item.Quantity
+	}
+
+	for _, shipment := range shipments {
+		for _, item := range shipment.Items {
+			if shipmentLeftWarehouse(shipment.Status) {
+				shipped += item.Quantity
+			}
+			if shipment.Status == ShipmentStatusDelivered {
+				delivered += item.Quantity
+			}
+		}
+	}
+
+	switch {
+	case ordered > 0 && delivered >= ordered:
+		return OrderStatusDelivered
+	case ordered > 0 && shipped >= ordered:
+		return OrderStatusShipped
+	case shipped > 0:
+		return OrderStatusPartiallyShipped
+	default:
+		return OrderStatusProcessing
+	}
+}
+
+// TransitionPath returns the statuses an order goes through to get from one status to another,
+// the shortest way the lifecycle allows. It is empty if the order cannot get there.
+func TransitionPath(from, to int) []int {
+	previous := map[int]int{from: from}
+	queue := []int{from}
+
+	for len(queue) > 0 {
+		status := queue[0]
+		queue = queue[1:]
+
+		if status == to {
+			var path []int
+			for s := to; s != from; s = previous[s] {
+				path = append([]int{s}, path...)
+			}
+			return path
+		}
+
+		for _, next := range orderTransitions[status] {
+			if _, seen := previous[next]; !seen {
+				previous[next] = status
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return nil
+}
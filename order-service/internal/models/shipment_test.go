@@ -0,0 +1,78 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFulfilmentStatus(t *testing.T) {
+	items := []*models.OrderItem{
+		{ProductID: 1, Quantity: 2},
+		{ProductID: 2, Quantity: 1},
+	}
+
+	testCases := []struct {
+		name      string
+		shipments []models.Shipment
+		status    int
+	}{
+		{name: "No shipments", status: models.OrderStatusProcessing},
+		{
+			name:      "Shipment not picked up",
+			shipments: []models.Shipment{{Status: models.ShipmentStatusPending, Items: models.ShipmentItems{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 1}}}},
+			status:    models.OrderStatusProcessing,
+		},
+		{
+			name:      "Some items in transit",
+			shipments: []models.Shipment{{Status: models.ShipmentStatusInTransit, Items: models.ShipmentItems{{ProductID: 1, Quantity: 2}}}},
+			status:    models.OrderStatusPartiallyShipped,
+		},
+		{
+			name: "All items shipped",
+			shipments: []models.Shipment{
+				{Status: models.ShipmentStatusDelivered, Items: models.ShipmentItems{{ProductID: 1, Quantity: 2}}},
+				{Status: models.ShipmentStatusOutForDelivery, Items: models.ShipmentItems{{ProductID: 2, Quantity: 1}}},
+			},
+			status: models.OrderStatusShipped,
+		},
+		{
+			name: "All items delivered",
+			shipments: []models.Shipment{
+				{Status: models.ShipmentStatusDelivered, Items: models.ShipmentItems{{ProductID: 1, Quantity: 2}}},
+				{Status: models.ShipmentStatusDelivered, Items: models.ShipmentItems{{ProductID: 2, Quantity: 1}}},
+			},
+			status: models.OrderStatusDelivered,
+		},
+		{
+			name:      "Cancelled shipment",
+			shipments: []models.Shipment{{Status: models.ShipmentStatusCancelled, Items: models.ShipmentItems{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 1}}}},
+			status:    models.OrderStatusProcessing,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.status, models.FulfilmentStatus(items, tc.shipments))
+		})
+	}
+}
+
+func TestUnshippedQuantities(t *testing.T) {
+	items := []*models.OrderItem{{ProductID: 1, Quantity: 3}}
+	shipments := []models.Shipment{
+		{Status: models.ShipmentStatusInTransit, Items: models.ShipmentItems{{ProductID: 1, Quantity: 1}}},
+		{Status: models.ShipmentStatusCancelled, Items: models.ShipmentItems{{ProductID: 1, Quantity: 2}}},
+	}
+
+	assert.Equal(t, map[int64]int{1: 2}, models.UnshippedQuantities(items, shipments))
+}
+
+func TestTransitionPath(t *testing.T) {
+	assert.Equal(t, []int{models.OrderStatusProcessing, models.OrderStatusShipped, models.OrderStatusDelivered},
+		models.TransitionPath(models.OrderStatusPaid, models.OrderStatusDelivered))
+	assert.Equal(t, []int{models.OrderStatusShipped}, models.TransitionPath(models.OrderStatusPartiallyShipped, models.OrderStatusShipped))
+	assert.Empty(t, models.TransitionPath(models.OrderStatusShipped, models.OrderStatusShipped))
+	assert.Nil(t, models.TransitionPath(models.OrderStatusDelivered, models.OrderStatusShipped))
+}
@@ -0,0 +1,27 @@
+package models
+
+import "github.com/NeGat1FF/e-commerce/money"
+
+// ItemSize is the weight in kg and the dimensions in cm of one item of a product
+type ItemSize struct {
+	Weight float64
+	Length float64
+	Width  float64
+	Height float64
+}
+
+// ShippingQuoteRequest asks for the shipping methods of the products, or of the shopping cart
+// if there are none, to a saved address or to a country and region
+type ShippingQuoteRequest struct {
+	Products          Products `json:"products"`
+	ShippingAddressID string   `json:"shipping_address_id"`
+	Country           string   `json:"country"`
+	Region            string   `json:"region"`
+}
+
+// ShippingQuote is the price of shipping with a method
+type ShippingQuote struct {
+	Method string      `json:"method"`
+	Name   string      `json:"name"`
+	Amount money.Money `json:"amount"`
+}
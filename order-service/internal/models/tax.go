@@ -0,0 +1,36 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+
+	"github.com/NeGat1FF/e-commerce/money"
+)
+
+// TaxAmount is the total of one tax at one rate over the items of an order it was applied to
+type TaxAmount struct {
+	Name    string      `json:"name"`
+	Rate    float64     `json:"rate"`
+	Taxable money.Money `json:"taxable"`
+	Amount  money.Money `json:"amount"`
+}
+
+type TaxBreakdown []TaxAmount
+
+// Value implements the driver.Valuer interface for saving into the database.
+func (t TaxBreakdown) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
+
+// Scan implements the sql.Scanner interface for reading from the database.
+func (t *TaxBreakdown) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(bytes, t)
+}
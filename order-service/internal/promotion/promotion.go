@@ -0,0 +1,168 @@
+// Package promotion works out the discounts promotions give an order or a shopping cart.
+// Whether a promotion is valid and has uses left is checked before, the package only applies them.
+package promotion
+
+import (
+	"sort"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+)
+
+// Line is a line of an order or a shopping cart
+type Line struct {
+	ProductID int64
+	Category  string
+	Quantity  int
+	UnitPrice money.Money
+}
+
+// Discount is what a promotion takes off the items and the shipping
+type Discount struct {
+	Promotion models.Promotion
+	Amount    money.Money
+}
+
+// Result holds the discounts of the promotions that apply, LineDiscounts are the discounts
+// of the lines in the order they were given
+type Result struct {
+	Discounts        []Discount
+	LineDiscounts    []money.Money
+	ShippingDiscount money.Money
+	Total            money.Money
+}
+
+// Apply applies the promotions to the lines and the shipping cost. Stackable promotions are
+// applied one after the other by priority, each to what is left to pay after the ones before.
+// A promotion that is not stackable applies alone, it is chosen over the stackable ones if
+// it gives the larger discount. The lines and the shipping cost are in the same currency,
+// promotions with amounts in another one do not apply.
+func Apply(promotions []models.Promotion, lines []Line, shippingCost money.Money) *Result {
+	sorted := make([]models.Promotion, len(promotions))
+	copy(sorted, promotions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+
+	var stackable []models.Promotion
+	var exclusive []models.Promotion
+	for _, p := range sorted {
+		if p.Stackable {
+			stackable = append(stackable, p)
+		} else {
+			exclusive = append(exclusive, p)
+		}
+	}
+
+	best := apply(stackable, lines, shippingCost)
+	for _, p := range exclusive {
+		res := apply([]models.Promotion{p}, lines, shippingCost)
+		if res.Total.Cmp(best.Total) > 0 || (len(best.Discounts) == 0 && len(res.Discounts) > 0) {
+			best = res
+		}
+	}
+
+	return best
+}
+
+func apply(promotions []models.Promotion, lines []Line, shippingCost money.Money) *Result {
+	currency := shippingCost.Currency
+	res := &Result{
+		Discounts:        []Discount{},
+		LineDiscounts:    make([]money.Money, len(lines)),
+		ShippingDiscount: money.Zero(currency),
+		Total:            money.Zero(currency),
+	}
+
+	subtotal := money.Zero(currency)
+	remaining := make([]money.Money, len(lines))
+	for i, line := range lines {
+		remaining[i] = line.UnitPrice.Mul(int64(line.Quantity))
+		res.LineDiscounts[i] = money.Zero(line.UnitPrice.Currency)
+		subtotal = subtotal.Add(remaining[i])
+	}
+	shipping := shippingCost
+
+	for _, p := range promotions {
+		discounts, shippingDiscount, ok := applyOne(p, lines, remaining, shipping, subtotal)
+		if !ok {
+			continue
+		}
+
+		amount := shippingDiscount
+		for i, d := range discounts {
+			remaining[i] = remaining[i].Sub(d)
+			res.LineDiscounts[i] = res.LineDiscounts[i].Add(d)
+			amount = amount.Add(d)
+		}
+		shipping = shipping.Sub(shippingDiscount)
+		res.ShippingDiscount = res.ShippingDiscount.Add(shippingDiscount)
+
+		res.Discounts = append(res.Discounts, Discount{Promotion: p, Amount: amount})
+		res.Total = res.Total.Add(amount)
+	}
+
+	return res
+}
+
+// applyOne returns the discounts of a promotion on what is left to pay for the lines and the
+// shipping, it does not apply if the order is too small or none of the lines are eligible
+func applyOne(p models.Promotion, lines []Line, remaining []money.Money, shipping, subtotal money.Money) ([]money.Money, money.Money, bool) {
+	none := money.Zero(subtotal.Currency)
+	if !p.MinSubtotal.IsZero() && (p.MinSubtotal.Currency != subtotal.Currency || subtotal.Cmp(p.MinSubtotal) < 0) {
+		return nil, none, false
+	}
+
+	var eligible []int
+	var weights []int64
+	pool := none
+	for i, line := range lines {
+		if p.Applies(line.ProductID, line.Category) && remaining[i].IsPositive() {
+			eligible = append(eligible, i)
+			weights = append(weights, remaining[i].Amount)
+			pool = pool.Add(remaining[i])
+		}
+	}
+
+	if len(eligible) == 0 {
+		return nil, none, false
+	}
+
+	discounts := make([]money.Money, len(lines))
+	for i := range discounts {
+		discounts[i] = none
+	}
+
+	switch p.Type {
+	case models.PromotionPercentage:
+		for _, i := range eligible {
+			discounts[i] = remaining[i].MulRate(p.Value / 100)
+		}
+	case models.PromotionFixed:
+		if p.Amount.Currency != pool.Currency {
+			return nil, none, false
+		}
+		// The amount is spread over the lines by what is left to pay for them
+		parts := money.Min(p.Amount, pool).Allocate(weights)
+		for n, i := range eligible {
+			discounts[i] = parts[n]
+		}
+	case models.PromotionBuyXGetY:
+		for _, i := range eligible {
+			free := lines[i].Quantity / (p.BuyQuantity + p.GetQuantity) * p.GetQuantity
+			discounts[i] = money.Min(remaining[i], lines[i].UnitPrice.Mul(int64(free)))
+		}
+	case models.PromotionFreeShipping:
+		// Free shipping applies even while the shipping cost is not known yet, such as in the shopping cart
+		return discounts, shipping, true
+	default:
+		return nil, none, false
+	}
+
+	total := none
+	for _, d := range discounts {
+		total = total.Add(d)
+	}
+
+	return discounts, none, total.IsPositive()
+}
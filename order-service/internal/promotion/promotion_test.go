@@ -0,0 +1,124 @@
+package promotion_test
+
+import (
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/promotion"
+	"github.com/stretchr/testify/assert"
+)
+
+func usd(cents int64) money.Money {
+	return money.New(cents, "USD")
+}
+
+var lines = []promotion.Line{
+	{ProductID: 1, Category: "shoes", Quantity: 2, UnitPrice: usd(5000)},
+	{ProductID: 2, Category: "socks", Quantity: 4, UnitPrice: usd(500)},
+}
+
+func TestApply(t *testing.T) {
+	testCases := []struct {
+		name          string
+		promotion     models.Promotion
+		lineDiscounts []money.Money
+		shipping      money.Money
+		total         money.Money
+	}{
+		{
+			name:          "Percentage off a category",
+			promotion:     models.Promotion{Type: models.PromotionPercentage, Value: 10, Categories: models.StringSlice{"shoes"}},
+			lineDiscounts: []money.Money{usd(1000), usd(0)},
+			shipping:      usd(0),
+			total:         usd(1000),
+		},
+		{
+			name:          "Fixed amount spread over the lines",
+			promotion:     models.Promotion{Type: models.PromotionFixed, Amount: usd(1200)},
+			lineDiscounts: []money.Money{usd(1000), usd(200)},
+			shipping:      usd(0),
+			total:         usd(1200),
+		},
+		{
+			name:          "Fixed amount with a cent left over",
+			promotion:     models.Promotion{Type: models.PromotionFixed, Amount: usd(1001)},
+			lineDiscounts: []money.Money{usd(834), usd(167)},
+			shipping:      usd(0),
+			total:         usd(1001),
+		},
+		{
+			name:          "Fixed amount larger than the product",
+			promotion:     models.Promotion{Type: models.PromotionFixed, Amount: usd(5000), ProductIDs: models.ProductIDs{2}},
+			lineDiscounts: []money.Money{usd(0), usd(2000)},
+			shipping:      usd(0),
+			total:         usd(2000),
+		},
+		{
+			name:          "Buy 3 get 1 free",
+			promotion:     models.Promotion{Type: models.PromotionBuyXGetY, BuyQuantity: 3, GetQuantity: 1, ProductIDs: models.ProductIDs{2}},
+			lineDiscounts: []money.Money{usd(0), usd(500)},
+			shipping:      usd(0),
+			total:         usd(500),
+		},
+		{
+			name:          "Free shipping",
+			promotion:     models.Promotion{Type: models.PromotionFreeShipping},
+			lineDiscounts: []money.Money{usd(0), usd(0)},
+			shipping:      usd(1000),
+			total:         usd(1000),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := promotion.Apply([]models.Promotion{tc.promotion}, lines, usd(1000))
+
+			assert.Equal(t, tc.lineDiscounts, res.LineDiscounts)
+			assert.Equal(t, tc.shipping, res.ShippingDiscount)
+			assert.Equal(t, tc.total, res.Total)
+			assert.Equal(t, []promotion.Discount{{Promotion: tc.promotion, Amount: tc.total}}, res.Discounts)
+		})
+	}
+}
+
+func TestApplyMinSubtotal(t *testing.T) {
+	res := promotion.Apply([]models.Promotion{{Type: models.PromotionPercentage, Value: 10, MinSubtotal: usd(20000)}}, lines, usd(0))
+
+	assert.Empty(t, res.Discounts)
+	assert.Equal(t, usd(0), res.Total)
+
+	res = promotion.Apply([]models.Promotion{{Type: models.PromotionPercentage, Value: 10, MinSubtotal: usd(12000)}}, lines, usd(0))
+	assert.Equal(t, usd(1200), res.Total)
+}
+
+func TestApplyOtherCurrency(t *testing.T) {
+	promotions := []models.Promotion{
+		{Type: models.PromotionFixed, Amount: money.New(500, "EUR")},
+		{Type: models.PromotionPercentage, Value: 10, MinSubtotal: money.New(100, "EUR")},
+	}
+
+	res := promotion.Apply(promotions, lines, usd(0))
+	assert.Empty(t, res.Discounts)
+	assert.Equal(t, usd(0), res.Total)
+}
+
+func TestApplyStacking(t *testing.T) {
+	percentage := models.Promotion{Name: "10% off", Type: models.PromotionPercentage, Value: 10, Stackable: true, Priority: 1}
+	fixed := models.Promotion{Name: "5 off", Type: models.PromotionFixed, Amount: usd(500), Stackable: true, Priority: 2}
+
+	// The fixed amount comes first by priority, the percentage is taken off what is left
+	res := promotion.Apply([]models.Promotion{percentage, fixed}, lines, usd(0))
+	assert.Equal(t, []promotion.Discount{{Promotion: fixed, Amount: usd(500)}, {Promotion: percentage, Amount: usd(1150)}}, res.Discounts)
+	assert.Equal(t, []money.Money{usd(1375), usd(275)}, res.LineDiscounts)
+	assert.Equal(t, usd(1650), res.Total)
+
+	smaller := models.Promotion{Name: "Spring sale", Type: models.PromotionPercentage, Value: 10}
+	res = promotion.Apply([]models.Promotion{percentage, fixed, smaller}, lines, usd(0))
+	assert.Equal(t, usd(1650), res.Total)
+
+	larger := models.Promotion{Name: "Black friday", Type: models.PromotionPercentage, Value: 20}
+	res = promotion.Apply([]models.Promotion{percentage, fixed, larger}, lines, usd(0))
+	assert.Equal(t, []promotion.Discount{{Promotion: larger, Amount: usd(2400)}}, res.Discounts)
+	assert.Equal(t, usd(2400), res.Total)
+}
@@ -2,12 +2,18 @@ package repository
 
 import (
 	"context"
-	"fmt"
+	"errors"
 
 	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrOrderNotFound = errors.New("order not found")
+	// ErrStatusChanged is returned when the status of an order was changed by someone else in the meantime
+	ErrStatusChanged = errors.New("order status was changed concurrently")
+)
+
 type OrderRepo struct {
 	db *gorm.DB
 }
@@ -16,9 +22,11 @@ func NewOrderRepo(db *gorm.DB) *OrderRepo {
 	return &OrderRepo{db: db}
 }
 
-// CreateOrder creates a new order
-func (r *OrderRepo) CreateOrder(ctx context.Context, order *models.Order, items []*models.OrderItem) (*models.OrderResponse, error) {
-	r.db.Transaction(func(tx *gorm.DB) error {
+// CreateOrder creates a new order together with the first entry of its status history, the saga that takes it to paid
+// and its order.created event. The promotions of its discounts are used up, ErrPromotionUsedUp is returned if one has
+// no uses left.
+func (r *OrderRepo) CreateOrder(ctx context.Context, order *models.Order, items []*models.OrderItem, discounts []*models.OrderDiscount, change *models.OrderStatusChange, saga *models.Saga) (*models.OrderResponse, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(order).Error; err != nil {
 			return err
 		}
@@ -27,9 +35,28 @@ func (r *OrderRepo) CreateOrder(ctx context.Context, order *models.Order, items
 			return err
 		}
 
-		return nil
+		if err := redeemPromotions(tx, order, discounts); err != nil {
+			return err
+		}
+
+		if err := tx.Table("order_status_history").Create(change).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Table("order_sagas").Create(saga).Error; err != nil {
+			return err
+		}
+
+		events, err := models.OrderCreatedEvents(order, items, change.CreatedAt)
+		if err != nil {
+			return err
+		}
 
+		return tx.Table("order_outbox").Create(events).Error
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	return r.GetOrderByID(ctx, order.ID.String())
 }
@@ -45,7 +72,10 @@ WITH product_details AS (
         JSON_AGG(
             JSON_BUILD_OBJECT(
                 'product_id', oi.product_id,
-                'quantity', oi.quantity
+                'quantity', oi.quantity,
+                'price', JSON_BUILD_OBJECT('amount', oi.price_amount, 'currency', oi.price_currency),
+                'discount', JSON_BUILD_OBJECT('amount', oi.discount_amount, 'currency', oi.discount_currency),
+                'tax', JSON_BUILD_OBJECT('amount', oi.tax_amount, 'currency', oi.tax_currency)
             )
         ) AS products
     FROM
@@ -57,7 +87,25 @@ SELECT
     o.id AS id,
     o.user_id AS user_id,
     pd.products AS products,
-    o.total AS total,
+    o.subtotal_amount AS subtotal_amount,
+    o.subtotal_currency AS subtotal_currency,
+    o.tax_amount AS tax_amount,
+    o.tax_currency AS tax_currency,
+    o.discount_amount AS discount_amount,
+    o.discount_currency AS discount_currency,
+    COALESCE((
+        SELECT JSON_AGG(JSON_BUILD_OBJECT('promotion_id', d.promotion_id, 'code', d.code, 'name', d.name, 'type', d.type, 'amount', JSON_BUILD_OBJECT('amount', d.amount, 'currency', d.currency)) ORDER BY d.created_at)
+        FROM order_discounts d
+        WHERE d.order_id = o.id
+    ), '[]') AS discounts,
+    o.total_amount AS total_amount,
+    o.total_currency AS total_currency,
+    o.tax_breakdown AS tax_breakdown,
+    o.prices_include_tax AS prices_include_tax,
+    o.shipping_method AS shipping_method,
+    o.shipping_cost_amount AS shipping_cost_amount,
+    o.shipping_cost_currency AS shipping_cost_currency,
+    o.shipping_address AS shipping_address,
 	st.name as status,
     o.created_at AS created_at,
     o.updated_at AS updated_at
@@ -78,6 +126,10 @@ WHERE o.id = ?;
 		return nil, tx.Error
 	}
 
+	if tx.RowsAffected == 0 {
+		return nil, ErrOrderNotFound
+	}
+
 	return order, nil
 }
 
@@ -92,7 +144,10 @@ WITH product_details AS (
         JSON_AGG(
             JSON_BUILD_OBJECT(
                 'product_id', oi.product_id,
-                'quantity', oi.quantity
+                'quantity', oi.quantity,
+                'price', JSON_BUILD_OBJECT('amount', oi.price_amount, 'currency', oi.price_currency),
+                'discount', JSON_BUILD_OBJECT('amount', oi.discount_amount, 'currency', oi.discount_currency),
+                'tax', JSON_BUILD_OBJECT('amount', oi.tax_amount, 'currency', oi.tax_currency)
             )
         ) AS products
     FROM
@@ -104,7 +159,25 @@ SELECT
     o.id AS id,
     o.user_id AS user_id,
     pd.products AS products,
-    o.total AS total,
+    o.subtotal_amount AS subtotal_amount,
+    o.subtotal_currency AS subtotal_currency,
+    o.tax_amount AS tax_amount,
+    o.tax_currency AS tax_currency,
+    o.discount_amount AS discount_amount,
+    o.discount_currency AS discount_currency,
+    COALESCE((
+        SELECT JSON_AGG(JSON_BUILD_OBJECT('promotion_id', d.promotion_id, 'code', d.code, 'name', d.name, 'type', d.type, 'amount', JSON_BUILD_OBJECT('amount', d.amount, 'currency', d.currency)) ORDER BY d.created_at)
+        FROM order_discounts d
+        WHERE d.order_id = o.id
+    ), '[]') AS discounts,
+    o.total_amount AS total_amount,
+    o.total_currency AS total_currency,
+    o.tax_breakdown AS tax_breakdown,
+    o.prices_include_tax AS prices_include_tax,
+    o.shipping_method AS shipping_method,
+    o.shipping_cost_amount AS shipping_cost_amount,
+    o.shipping_cost_currency AS shipping_cost_currency,
+    o.shipping_address AS shipping_address,
 	st.name as status,
     o.created_at AS created_at,
     o.updated_at AS updated_at
@@ -128,19 +201,86 @@ WHERE o.user_id = ?;
 	return orders, nil
 }
 
-// UpdateOrder updates an order
-func (r *OrderRepo) UpdateOrderStatus(ctx context.Context, orderId string, status int) (*models.OrderResponse, error) {
-
-	fmt.Println(orderId, status)
+// UpdateOrderStatus moves an order from one status to another and records the change in its history and events.
+// Cancelling an order makes its saga undo the steps taken so far and gives the uses of its promotions back.
+// ErrStatusChanged is returned if the order is no longer in the status the change starts from.
+func (r *OrderRepo) UpdateOrderStatus(ctx context.Context, change *models.OrderStatusChange) (*models.OrderResponse, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order models.Order
 
-	tx := r.db.Exec(`
+		res := tx.Raw(`
 	UPDATE orders
-	SET status = ?
-	WHERE id = ?;`, status, orderId)
+	SET status = ?, updated_at = ?
+	WHERE id = ? AND status = ?
+	RETURNING user_id, total_amount, total_currency;`, change.ToStatus, change.CreatedAt, change.OrderID, change.FromStatus).Scan(&order)
+		if res.Error != nil {
+			return res.Error
+		}
 
-	if tx.Error != nil {
-		return nil, tx.Error
+		if res.RowsAffected == 0 {
+			return ErrStatusChanged
+		}
+
+		if err := tx.Table("order_status_history").Create(change).Error; err != nil {
+			return err
+		}
+
+		events, err := models.StatusChangedEvents(change, order.UserID, order.Total)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Table("order_outbox").Create(events).Error; err != nil {
+			return err
+		}
+
+		if change.ToStatus != models.OrderStatusCancelled {
+			return nil
+		}
+
+		if err := releasePromotions(tx, change.OrderID); err != nil {
+			return err
+		}
+
+		// The saga of a cancelled order releases its stock and cancels or refunds its payment
+		return tx.Exec(`
+	UPDATE order_sagas
+	SET status = ?, step = ?, attempts = 0, reason = ?, next_run_at = ?, updated_at = ?
+	WHERE order_id = ? AND status IN (?, ?);`, models.SagaCompensating, models.SagaStepCancelPayment, "order was cancelled", change.CreatedAt, change.CreatedAt,
+			change.OrderID, models.SagaRunning, models.SagaCompleted).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetOrderByID(ctx, change.OrderID.String())
+}
+
+// GetStatusHistory gets the status changes of an order, oldest first
+func (r *OrderRepo) GetStatusHistory(ctx context.Context, orderID string) ([]models.OrderStatusChange, error) {
+	var history []models.OrderStatusChange
+
+	err := r.db.WithContext(ctx).Table("order_status_history").Where("order_id = ?", orderID).Order("created_at").Find(&history).Error
+	if err != nil {
+		return nil, err
 	}
 
-	return r.GetOrderByID(ctx, orderId)
+	return history, nil
+}
+
+// ReassignOrders moves the orders of a guest to the account the guest logged into
+func (r *OrderRepo) ReassignOrders(ctx context.Context, fromUserID, toUserID string) error {
+	return r.db.WithContext(ctx).Exec(`
+	UPDATE orders
+	SET user_id = ?
+	WHERE user_id = ?;`, toUserID, fromUserID).Error
+}
+
+// ScrubUserData removes personal data of an erased user from the orders,
+// only the destination country is kept for tax reporting
+func (r *OrderRepo) ScrubUserData(ctx context.Context, userID string) error {
+	return r.db.WithContext(ctx).Exec(`
+	UPDATE orders
+	SET shipping_address = jsonb_build_object('country', shipping_address->'country')
+	WHERE user_id = ? AND shipping_address IS NOT NULL;`, userID).Error
 }
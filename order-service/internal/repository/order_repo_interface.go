@@ -2,14 +2,42 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/google/uuid"
 )
 
 // OrderRepoInterface is an interface for order repository
 type OrderRepoInterface interface {
-	CreateOrder(ctx context.Context, order *models.Order, items []*models.OrderItem) (*models.OrderResponse, error)
+	CreateOrder(ctx context.Context, order *models.Order, items []*models.OrderItem, discounts []*models.OrderDiscount, change *models.OrderStatusChange, saga *models.Saga) (*models.OrderResponse, error)
 	GetOrderByID(ctx context.Context, orderID string) (*models.OrderResponse, error)
 	GetOrders(ctx context.Context, userID string) ([]*models.OrderResponse, error)
-	UpdateOrderStatus(ctx context.Context, orderId string, status int) (*models.OrderResponse, error)
+	UpdateOrderStatus(ctx context.Context, change *models.OrderStatusChange) (*models.OrderResponse, error)
+	GetStatusHistory(ctx context.Context, orderID string) ([]models.OrderStatusChange, error)
+	ScrubUserData(ctx context.Context, userID string) error
+	ReassignOrders(ctx context.Context, fromUserID, toUserID string) error
+	ClaimSagas(ctx context.Context, limit int, lease time.Duration) ([]models.Saga, error)
+	SaveSaga(ctx context.Context, saga *models.Saga, step *models.SagaStep) error
+	GetSaga(ctx context.Context, orderID string) (*models.Saga, error)
+	GetSagaSteps(ctx context.Context, orderID string) ([]models.SagaStep, error)
+	PublishOutbox(ctx context.Context, limit int, publish func(*models.OutboxMessage) error) (int, error)
+	DeletePublishedOutbox(ctx context.Context, before time.Time) (int64, error)
+	GetOrderItems(ctx context.Context, orderID string) ([]*models.OrderItem, error)
+	CreateReturn(ctx context.Context, ret *models.Return) error
+	GetReturn(ctx context.Context, returnID string) (*models.Return, error)
+	GetReturns(ctx context.Context, orderID string) ([]models.Return, error)
+	UpdateReturn(ctx context.Context, ret *models.Return, fromStatus string) error
+	CreateShipment(ctx context.Context, shipment *models.Shipment, event *models.ShipmentEvent) error
+	GetShipment(ctx context.Context, shipmentID string) (*models.Shipment, error)
+	GetShipmentByTracking(ctx context.Context, carrier, trackingNumber string) (*models.Shipment, error)
+	GetShipments(ctx context.Context, orderID string) ([]models.Shipment, error)
+	GetShipmentEvents(ctx context.Context, shipmentID string) ([]models.ShipmentEvent, error)
+	UpdateShipment(ctx context.Context, shipment *models.Shipment, fromStatus string, event *models.ShipmentEvent) error
+	CreatePromotion(ctx context.Context, promotion *models.Promotion) error
+	UpdatePromotion(ctx context.Context, promotion *models.Promotion) error
+	GetPromotion(ctx context.Context, promotionID string) (*models.Promotion, error)
+	GetPromotions(ctx context.Context) ([]models.Promotion, error)
+	GetAvailablePromotions(ctx context.Context, at time.Time, codes []string) ([]models.Promotion, error)
+	GetRedemptions(ctx context.Context, userID string, promotionIDs []uuid.UUID) (map[uuid.UUID]int, error)
 }
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"gorm.io/gorm"
+)
+
+// PublishOutbox hands the oldest unpublished events to publish and marks the ones it accepted as published.
+// The events stay locked until then, so replicas relaying at the same time skip them. An event is published
+// again if the transaction is not committed, consumers deduplicate events by their ID.
+func (r *OrderRepo) PublishOutbox(ctx context.Context, limit int, publish func(*models.OutboxMessage) error) (int, error) {
+	published := 0
+	var publishErr error
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var messages []*models.OutboxMessage
+
+		err := tx.Raw(`
+	SELECT *
+	FROM order_outbox
+	WHERE published_at IS NULL
+	ORDER BY created_at
+	LIMIT ?
+	FOR UPDATE SKIP LOCKED;`, limit).Scan(&messages).Error
+		if err != nil {
+			return err
+		}
+
+		var ids []string
+		for _, msg := range messages {
+			if publishErr = publish(msg); publishErr != nil {
+				break
+			}
+			ids = append(ids, msg.ID.String())
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		published = len(ids)
+		return tx.Exec(`
+	UPDATE order_outbox
+	SET published_at = ?
+	WHERE id IN ?;`, time.Now(), ids).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return published, publishErr
+}
+
+// DeletePublishedOutbox removes the events published before the given time
+func (r *OrderRepo) DeletePublishedOutbox(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Exec(`
+	DELETE FROM order_outbox
+	WHERE published_at < ?;`, before)
+
+	return res.RowsAffected, res.Error
+}
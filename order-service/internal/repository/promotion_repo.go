@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPromotionNotFound   = errors.New("promotion not found")
+	ErrPromotionCodeExists = errors.New("promotion with this code already exists")
+	// ErrPromotionUsedUp is returned when an order would take a promotion over one of its usage limits
+	ErrPromotionUsedUp = errors.New("promotion has no uses left")
+)
+
+// CreatePromotion creates a new promotion
+func (r *OrderRepo) CreatePromotion(ctx context.Context, promotion *models.Promotion) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := codeTaken(tx, promotion); err != nil {
+			return err
+		}
+
+		return tx.Table("promotions").Create(promotion).Error
+	})
+}
+
+// UpdatePromotion replaces a promotion, its usage count is kept
+func (r *OrderRepo) UpdatePromotion(ctx context.Context, promotion *models.Promotion) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := codeTaken(tx, promotion); err != nil {
+			return err
+		}
+
+		res := tx.Table("promotions").Where("id = ?", promotion.ID).Select("*").Omit("id", "usage_count", "created_at").Updates(promotion)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrPromotionNotFound
+		}
+
+		return nil
+	})
+}
+
+// codeTaken returns ErrPromotionCodeExists if another promotion has the coupon code of the promotion
+func codeTaken(tx *gorm.DB, promotion *models.Promotion) error {
+	if promotion.Code == "" {
+		return nil
+	}
+
+	var exists bool
+	err := tx.Raw(`SELECT EXISTS (SELECT 1 FROM promotions WHERE code = ? AND id <> ?);`, promotion.Code, promotion.ID).Scan(&exists).Error
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return ErrPromotionCodeExists
+	}
+
+	return nil
+}
+
+// GetPromotion gets a promotion by its ID
+func (r *OrderRepo) GetPromotion(ctx context.Context, promotionID string) (*models.Promotion, error) {
+	promotion := &models.Promotion{}
+
+	tx := r.db.WithContext(ctx).Table("promotions").Where("id = ?", promotionID).Limit(1).Find(promotion)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	if tx.RowsAffected == 0 {
+		return nil, ErrPromotionNotFound
+	}
+
+	return promotion, nil
+}
+
+// GetPromotions gets all promotions, newest first
+func (r *OrderRepo) GetPromotions(ctx context.Context) ([]models.Promotion, error) {
+	var promotions []models.Promotion
+
+	err := r.db.WithContext(ctx).Table("promotions").Order("created_at DESC").Find(&promotions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return promotions, nil
+}
+
+// GetAvailablePromotions gets the automatic promotions and those with one of the coupon codes
+// that are active at the time and have uses left
+func (r *OrderRepo) GetAvailablePromotions(ctx context.Context, at time.Time, codes []string) ([]models.Promotion, error) {
+	var promotions []models.Promotion
+
+	query := r.db.WithContext(ctx).Table("promotions").
+		Where("active AND (starts_at IS NULL OR starts_at <= ?) AND (ends_at IS NULL OR ends_at > ?)", at, at).
+		Where("usage_limit = 0 OR usage_count < usage_limit")
+
+	if len(codes) > 0 {
+		query = query.Where("code = '' OR code IN ?", codes)
+	} else {
+		query = query.Where("code = ''")
+	}
+
+	if err := query.Find(&promotions).Error; err != nil {
+		return nil, err
+	}
+
+	return promotions, nil
+}
+
+// GetRedemptions counts the orders of the user each of the promotions was used for
+func (r *OrderRepo) GetRedemptions(ctx context.Context, userID string, promotionIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	redemptions := make(map[uuid.UUID]int, len(promotionIDs))
+	if len(promotionIDs) == 0 {
+		return redemptions, nil
+	}
+
+	var rows []struct {
+		PromotionID uuid.UUID
+		Count       int
+	}
+
+	err := r.db.WithContext(ctx).Raw(`
+	SELECT promotion_id, COUNT(*) AS count
+	FROM promotion_redemptions
+	WHERE user_id = ? AND promotion_id IN ?
+	GROUP BY promotion_id;`, userID, promotionIDs).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		redemptions[row.PromotionID] = row.Count
+	}
+
+	return redemptions, nil
+}
+
+// redeemPromotions uses the promotions of the discounts for an order and records the discounts.
+// The update of the usage count locks the promotion, so concurrent orders of a customer cannot
+// both pass the limit per customer.
+func redeemPromotions(tx *gorm.DB, order *models.Order, discounts []*models.OrderDiscount) error {
+	for _, discount := range discounts {
+		var perCustomerLimit int
+		res := tx.Raw(`
+	UPDATE promotions
+	SET usage_count = usage_count + 1
+	WHERE id = ? AND (usage_limit = 0 OR usage_count < usage_limit)
+	RETURNING per_customer_limit;`, discount.PromotionID).Scan(&perCustomerLimit)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrPromotionUsedUp
+		}
+
+		if perCustomerLimit > 0 {
+			var used int
+			err := tx.Raw(`SELECT COUNT(*) FROM promotion_redemptions WHERE promotion_id = ? AND user_id = ?;`,
+				discount.PromotionID, order.UserID).Scan(&used).Error
+			if err != nil {
+				return err
+			}
+
+			if used >= perCustomerLimit {
+				return ErrPromotionUsedUp
+			}
+		}
+
+		err := tx.Exec(`INSERT INTO promotion_redemptions (promotion_id, order_id, user_id, created_at) VALUES (?, ?, ?, ?);`,
+			discount.PromotionID, order.ID, order.UserID, discount.CreatedAt).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(discounts) == 0 {
+		return nil
+	}
+
+	return tx.Table("order_discounts").Create(discounts).Error
+}
+
+// releasePromotions gives the uses of the promotions of a cancelled order back, its discounts are kept
+func releasePromotions(tx *gorm.DB, orderID uuid.UUID) error {
+	err := tx.Exec(`
+	UPDATE promotions p
+	SET usage_count = p.usage_count - 1
+	FROM promotion_redemptions r
+	WHERE r.order_id = ? AND p.id = r.promotion_id;`, orderID).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Exec(`DELETE FROM promotion_redemptions WHERE order_id = ?;`, orderID).Error
+}
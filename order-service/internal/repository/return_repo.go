@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+)
+
+var (
+	ErrReturnNotFound = errors.New("return not found")
+	// ErrReturnChanged is returned when the status of a return was changed by someone else in the meantime
+	ErrReturnChanged = errors.New("return status was changed concurrently")
+)
+
+// GetOrderItems gets the items of an order with the prices they were ordered at
+func (r *OrderRepo) GetOrderItems(ctx context.Context, orderID string) ([]*models.OrderItem, error) {
+	var items []*models.OrderItem
+
+	err := r.db.WithContext(ctx).Table("order_items").Where("order_id = ?", orderID).Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// CreateReturn creates a new return request
+func (r *OrderRepo) CreateReturn(ctx context.Context, ret *models.Return) error {
+	return r.db.WithContext(ctx).Table("order_returns").Create(ret).Error
+}
+
+// GetReturn gets a return by its ID
+func (r *OrderRepo) GetReturn(ctx context.Context, returnID string) (*models.Return, error) {
+	ret := &models.Return{}
+
+	tx := r.db.WithContext(ctx).Table("order_returns").Where("id = ?", returnID).Limit(1).Find(ret)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	if tx.RowsAffected == 0 {
+		return nil, ErrReturnNotFound
+	}
+
+	return ret, nil
+}
+
+// GetReturns gets the returns of an order, oldest first
+func (r *OrderRepo) GetReturns(ctx context.Context, orderID string) ([]models.Return, error) {
+	var returns []models.Return
+
+	err := r.db.WithContext(ctx).Table("order_returns").Where("order_id = ?", orderID).Order("created_at").Find(&returns).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return returns, nil
+}
+
+// UpdateReturn saves the status and note of a return.
+// ErrReturnChanged is returned if the return is no longer in the given status.
+func (r *OrderRepo) UpdateReturn(ctx context.Context, ret *models.Return, fromStatus string) error {
+	res := r.db.WithContext(ctx).Exec(`
+	UPDATE order_returns
+	SET status = ?, note = ?, updated_at = ?
+	WHERE id = ? AND status = ?;`, ret.Status, ret.Note, ret.UpdatedAt, ret.ID, fromStatus)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrReturnChanged
+	}
+
+	return nil
+}
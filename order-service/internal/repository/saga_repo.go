@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"gorm.io/gorm"
+)
+
+var ErrSagaNotFound = errors.New("saga not found")
+
+// ClaimSagas locks the running and compensating sagas that are due for the lease duration.
+// Sagas locked by another replica are skipped, an expired lease means the replica died mid-step.
+func (r *OrderRepo) ClaimSagas(ctx context.Context, limit int, lease time.Duration) ([]models.Saga, error) {
+	var sagas []models.Saga
+
+	now := time.Now()
+
+	err := r.db.WithContext(ctx).Raw(`
+	UPDATE order_sagas
+	SET locked_until = ?
+	WHERE order_id IN (
+		SELECT order_id
+		FROM order_sagas
+		WHERE status IN (?, ?) AND next_run_at <= ? AND (locked_until IS NULL OR locked_until < ?)
+		ORDER BY next_run_at
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING *;`, now.Add(lease), models.SagaRunning, models.SagaCompensating, now, now, limit).Scan(&sagas).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return sagas, nil
+}
+
+// SaveSaga stores the new state of a saga and releases its lock, the step attempt is recorded with it if given
+func (r *OrderRepo) SaveSaga(ctx context.Context, saga *models.Saga, step *models.SagaStep) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+	UPDATE order_sagas
+	SET status = ?, step = ?, attempts = ?, reason = ?, payment_id = ?, deadline = ?, next_run_at = ?, locked_until = NULL, updated_at = ?
+	WHERE order_id = ?;`, saga.Status, saga.Step, saga.Attempts, saga.Reason, saga.PaymentID, saga.Deadline, saga.NextRunAt, saga.UpdatedAt, saga.OrderID).Error
+		if err != nil {
+			return err
+		}
+
+		if step == nil {
+			return nil
+		}
+
+		return tx.Table("order_saga_steps").Create(step).Error
+	})
+}
+
+// GetSaga gets the saga of an order
+func (r *OrderRepo) GetSaga(ctx context.Context, orderID string) (*models.Saga, error) {
+	saga := &models.Saga{}
+
+	tx := r.db.WithContext(ctx).Table("order_sagas").Where("order_id = ?", orderID).Limit(1).Find(saga)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	if tx.RowsAffected == 0 {
+		return nil, ErrSagaNotFound
+	}
+
+	return saga, nil
+}
+
+// GetSagaSteps gets the step attempts of the saga of an order, oldest first
+func (r *OrderRepo) GetSagaSteps(ctx context.Context, orderID string) ([]models.SagaStep, error) {
+	var steps []models.SagaStep
+
+	err := r.db.WithContext(ctx).Table("order_saga_steps").Where("order_id = ?", orderID).Order("created_at").Find(&steps).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return steps, nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrShipmentNotFound = errors.New("shipment not found")
+	ErrShipmentExists   = errors.New("shipment with this tracking number already exists")
+	// ErrShipmentChanged is returned when the status of a shipment was changed by someone else in the meantime
+	ErrShipmentChanged = errors.New("shipment status was changed concurrently")
+)
+
+// CreateShipment creates a new shipment with the first event of its timeline
+func (r *OrderRepo) CreateShipment(ctx context.Context, shipment *models.Shipment, event *models.ShipmentEvent) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var exists bool
+		err := tx.Raw(`SELECT EXISTS (SELECT 1 FROM order_shipments WHERE carrier = ? AND tracking_number = ?);`,
+			shipment.Carrier, shipment.TrackingNumber).Scan(&exists).Error
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return ErrShipmentExists
+		}
+
+		if err := tx.Table("order_shipments").Create(shipment).Error; err != nil {
+			return err
+		}
+
+		return tx.Table("shipment_events").Create(event).Error
+	})
+}
+
+// GetShipment gets a shipment by its ID
+func (r *OrderRepo) GetShipment(ctx context.Context, shipmentID string) (*models.Shipment, error) {
+	return r.findShipment(ctx, "id = ?", shipmentID)
+}
+
+// GetShipmentByTracking gets a shipment by its carrier and tracking number
+func (r *OrderRepo) GetShipmentByTracking(ctx context.Context, carrier, trackingNumber string) (*models.Shipment, error) {
+	return r.findShipment(ctx, "carrier = ? AND tracking_number = ?", carrier, trackingNumber)
+}
+
+func (r *OrderRepo) findShipment(ctx context.Context, query string, args ...interface{}) (*models.Shipment, error) {
+	shipment := &models.Shipment{}
+
+	tx := r.db.WithContext(ctx).Table("order_shipments").Where(query, args...).Limit(1).Find(shipment)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	if tx.RowsAffected == 0 {
+		return nil, ErrShipmentNotFound
+	}
+
+	return shipment, nil
+}
+
+// GetShipments gets the shipments of an order, oldest first
+func (r *OrderRepo) GetShipments(ctx context.Context, orderID string) ([]models.Shipment, error) {
+	var shipments []models.Shipment
+
+	err := r.db.WithContext(ctx).Table("order_shipments").Where("order_id = ?", orderID).Order("created_at").Find(&shipments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return shipments, nil
+}
+
+// GetShipmentEvents gets the timeline of a shipment, oldest first
+func (r *OrderRepo) GetShipmentEvents(ctx context.Context, shipmentID string) ([]models.ShipmentEvent, error) {
+	var events []models.ShipmentEvent
+
+	err := r.db.WithContext(ctx).Table("shipment_events").Where("shipment_id = ?", shipmentID).Order("occurred_at, created_at").Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// UpdateShipment saves the status of a shipment and adds the event to its timeline.
+// ErrShipmentChanged is returned if the shipment is no longer in the given status.
+func (r *OrderRepo) UpdateShipment(ctx context.Context, shipment *models.Shipment, fromStatus string, event *models.ShipmentEvent) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Exec(`
+	UPDATE order_shipments
+	SET status = ?, updated_at = ?
+	WHERE id = ? AND status = ?;`, shipment.Status, shipment.UpdatedAt, shipment.ID, fromStatus)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrShipmentChanged
+		}
+
+		return tx.Table("shipment_events").Create(event).Error
+	})
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/order-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/order-service/proto"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// OrderServer exposes orders to other services
+type OrderServer struct {
+	proto.UnimplementedOrderServiceServer
+
+	service *service.OrderService
+}
+
+func NewOrderServer(service *service.OrderService) *OrderServer {
+	return &OrderServer{
+		service: service,
+	}
+}
+
+// GetUserOrders returns the order history of a user
+func (s *OrderServer) GetUserOrders(ctx context.Context, req *proto.UserOrdersRequest) (*proto.UserOrdersResponse, error) {
+	if uuid.Validate(req.UserId) != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+
+	orders, err := s.service.GetUserOrders(ctx, req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	res := &proto.UserOrdersResponse{}
+
+	for _, order := range orders {
+		o := &proto.Order{
+			Id:        order.ID.String(),
+			Status:    order.Status,
+			Total:     order.Total.Decimal(),
+			CreatedAt: order.CreatedAt.Format(time.RFC3339),
+			UpdatedAt: order.UpdatedAt.Format(time.RFC3339),
+		}
+
+		if order.ShippingAddress != nil {
+			address, err := json.Marshal(order.ShippingAddress)
+			if err != nil {
+				return nil, status.Error(codes.Internal, err.Error())
+			}
+			o.ShippingAddress = string(address)
+		}
+
+		for _, item := range order.Products {
+			o.Items = append(o.Items, &proto.OrderItem{
+				ProductId: item.ID,
+				Quantity:  int32(item.Quantity),
+			})
+		}
+
+		res.Orders = append(res.Orders, o)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/order-service/proto"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PromotionServer lets the shopping cart show the discounts it would get
+type PromotionServer struct {
+	proto.UnimplementedPromotionServiceServer
+
+	service *service.OrderService
+}
+
+func NewPromotionServer(service *service.OrderService) *PromotionServer {
+	return &PromotionServer{
+		service: service,
+	}
+}
+
+// PreviewDiscounts returns the discounts of the promotions that apply to the items of a shopping cart
+func (s *PromotionServer) PreviewDiscounts(ctx context.Context, req *proto.PreviewDiscountsRequest) (*proto.PreviewDiscountsResponse, error) {
+	if uuid.Validate(req.UserId) != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+
+	items := make([]*models.OrderItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		price := money.New(item.Price.GetAmount(), item.Price.GetCurrency())
+		if _, err := money.Digits(price.Currency); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid price")
+		}
+		items = append(items, &models.OrderItem{
+			ProductID: item.ProductId,
+			Quantity:  int(item.Quantity),
+			Price:     price,
+		})
+	}
+
+	res, invalid, err := s.service.PreviewDiscounts(ctx, req.UserId, items, req.CouponCodes)
+	if errors.Is(err, service.ErrPriceCurrency) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &proto.PreviewDiscountsResponse{
+		Total:        &proto.Money{Amount: res.Total.Amount, Currency: res.Total.Currency},
+		InvalidCodes: invalid,
+	}
+
+	for _, d := range res.Discounts {
+		resp.Discounts = append(resp.Discounts, &proto.Discount{
+			PromotionId: d.Promotion.ID.String(),
+			Code:        d.Promotion.Code,
+			Name:        d.Promotion.Name,
+			Type:        d.Promotion.Type,
+			Amount:      &proto.Money{Amount: d.Amount.Amount, Currency: d.Amount.Currency},
+		})
+	}
+
+	return resp, nil
+}
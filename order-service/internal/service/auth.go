@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/NeGat1FF/e-commerce/authclient"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/utils"
+)
+
+const (
+	// PermissionReadOrders lets staff read the orders of all customers
+	PermissionReadOrders = "orders:read"
+	// PermissionWriteOrders lets staff change orders, handle returns and ship orders
+	PermissionWriteOrders = "orders:write"
+)
+
+// Authenticator validates access tokens and checks the permissions of their users, it is
+// implemented by the client of the user service
+type Authenticator interface {
+	ValidateToken(ctx context.Context, token string) (*authclient.Claims, error)
+	CheckPermission(ctx context.Context, token, permission string) (*authclient.Claims, error)
+}
+
+// authenticate returns the claims of a valid token, tokens the user service rejects are invalid
+func (s *OrderService) authenticate(ctx context.Context, jwt string) (*authclient.Claims, error) {
+	claims, err := s.auth.ValidateToken(ctx, jwt)
+	if errors.Is(err, authclient.ErrUnauthenticated) {
+		return nil, utils.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// can reports whether the user of the token has the permission
+func (s *OrderService) can(ctx context.Context, jwt, permission string) (*authclient.Claims, bool, error) {
+	claims, err := s.auth.CheckPermission(ctx, jwt, permission)
+	switch {
+	case errors.Is(err, authclient.ErrForbidden):
+		return claims, false, nil
+	case errors.Is(err, authclient.ErrUnauthenticated):
+		return nil, false, utils.ErrInvalidToken
+	case err != nil:
+		return nil, false, err
+	}
+
+	return claims, true, nil
+}
+
+// readableOrder returns an order to its customer or to staff that can read orders. To anyone
+// else it is not found, so that they cannot tell whether it exists.
+func (s *OrderService) readableOrder(ctx context.Context, jwt, orderID string) (*models.OrderResponse, error) {
+	claims, allowed, err := s.can(ctx, jwt, PermissionReadOrders)
+	if err != nil {
+		return nil, err
+	}
+
+	if allowed {
+		return s.getOrder(ctx, orderID)
+	}
+
+	return s.ownOrder(ctx, claims.UserID, orderID)
+}
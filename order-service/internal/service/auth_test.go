@@ -0,0 +1,168 @@
+package service_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/authclient"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ownerID = uuid.New()
+	otherID = uuid.New()
+	staffID = uuid.New()
+)
+
+// fakeAuth accepts the tokens it knows, each with a user and the permissions of the user
+type fakeAuth struct {
+	users       map[string]*authclient.Claims
+	permissions map[string][]string
+}
+
+func newFakeAuth() *fakeAuth {
+	return &fakeAuth{
+		users: map[string]*authclient.Claims{
+			"owner":  {UserID: ownerID.String(), Role: "user"},
+			"other":  {UserID: otherID.String(), Role: "user"},
+			"reader": {UserID: staffID.String(), Role: "support"},
+			"writer": {UserID: staffID.String(), Role: "admin"},
+		},
+		permissions: map[string][]string{
+			"reader": {service.PermissionReadOrders},
+			"writer": {service.PermissionReadOrders, service.PermissionWriteOrders},
+		},
+	}
+}
+
+func (a *fakeAuth) ValidateToken(ctx context.Context, token string) (*authclient.Claims, error) {
+	claims, ok := a.users[token]
+	if !ok {
+		return nil, authclient.ErrUnauthenticated
+	}
+	return claims, nil
+}
+
+func (a *fakeAuth) CheckPermission(ctx context.Context, token, permission string) (*authclient.Claims, error) {
+	claims, err := a.ValidateToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(a.permissions[token], permission) {
+		return claims, authclient.ErrForbidden
+	}
+	return claims, nil
+}
+
+// fakeRepo keeps orders in memory, the methods the tests do not need are left unimplemented
+type fakeRepo struct {
+	repository.OrderRepoInterface
+	orders     map[string]*models.OrderResponse
+	changes    []*models.OrderStatusChange
+	promotions []*models.Promotion
+	sagas      map[string]*models.Saga
+	sagaSteps  []models.SagaStep
+	placed     [][]*models.OrderItem
+	placeErr   error
+	items      map[string][]*models.OrderItem
+	returns    []*models.Return
+	shipments  []*models.Shipment
+	events     []models.ShipmentEvent
+}
+
+func (r *fakeRepo) GetOrderByID(ctx context.Context, orderID string) (*models.OrderResponse, error) {
+	order, ok := r.orders[orderID]
+	if !ok {
+		return nil, repository.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func (r *fakeRepo) UpdateOrderStatus(ctx context.Context, change *models.OrderStatusChange) (*models.OrderResponse, error) {
+	r.changes = append(r.changes, change)
+	order := r.orders[change.OrderID.String()]
+	order.Status = models.OrderStatusName(change.ToStatus)
+	return order, nil
+}
+
+func newTestService() (*service.OrderService, *fakeRepo, string) {
+	order := &models.OrderResponse{ID: uuid.New(), UserID: ownerID, Status: models.OrderStatusName(models.OrderStatusPaid)}
+	repo := &fakeRepo{orders: map[string]*models.OrderResponse{order.ID.String(): order}}
+	return service.NewOrderService(repo, nil, nil, nil, nil, nil, nil, nil, "USD", newFakeAuth()), repo, order.ID.String()
+}
+
+func TestGetOrderAccess(t *testing.T) {
+	testCases := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{name: "Owner", token: "owner"},
+		{name: "Staff that reads orders", token: "reader"},
+		// Other users cannot tell whether the order exists
+		{name: "Another user", token: "other", err: repository.ErrOrderNotFound},
+		{name: "Invalid token", token: "forged", err: utils.ErrInvalidToken},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, _, orderID := newTestService()
+
+			order, err := s.GetOrder(context.Background(), tc.token, orderID)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, orderID, order.ID.String())
+		})
+	}
+}
+
+func TestGetOrderNotFound(t *testing.T) {
+	s, _, _ := newTestService()
+
+	_, err := s.GetOrder(context.Background(), "reader", uuid.NewString())
+	assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+
+	_, err = s.GetOrder(context.Background(), "owner", "not an id")
+	assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+}
+
+func TestUpdateOrderStatusPermission(t *testing.T) {
+	testCases := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{name: "Staff that changes orders", token: "writer"},
+		{name: "Staff that only reads orders", token: "reader", err: repository.ErrOrderNotFound},
+		{name: "Owner", token: "owner", err: repository.ErrOrderNotFound},
+		{name: "Invalid token", token: "forged", err: utils.ErrInvalidToken},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, repo, orderID := newTestService()
+
+			order, err := s.UpdateOrderStatus(context.Background(), tc.token, orderID, "PROCESSING", "picked")
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				assert.Empty(t, repo.changes)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "PROCESSING", order.Status)
+			require.Len(t, repo.changes, 1)
+			assert.Equal(t, staffID.String(), repo.changes[0].Actor)
+			assert.Equal(t, "picked", repo.changes[0].Reason)
+		})
+	}
+}
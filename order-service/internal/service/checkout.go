@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/order-service/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrCartEmpty        = errors.New("shopping cart is empty")
+	ErrNothingAvailable = errors.New("none of the items in the shopping cart are available")
+)
+
+// CheckoutChangedError is returned when prices changed or items became unavailable since they were
+// added to the cart, the checkout has to be repeated with the lines of the review
+type CheckoutChangedError struct {
+	Review *models.CheckoutReview
+}
+
+func (e *CheckoutChangedError) Error() string {
+	return "shopping cart changed since the items were added"
+}
+
+// Checkout turns the shopping cart of the user into an order at current prices.
+// When prices changed or items are unavailable, the order is only placed if the request confirms
+// the lines of the current review, otherwise the review is returned with a CheckoutChangedError.
+// Ordered items are taken off the cart once the order is created.
+func (s *OrderService) Checkout(ctx context.Context, jwt string, req models.CheckoutRequest) (*models.OrderResponse, error) {
+	claims, err := s.authenticate(ctx, jwt)
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := s.cartService.GetCart(ctx, &proto.GetCartRequest{UserId: claims.UserID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cart.Items) == 0 {
+		return nil, ErrCartEmpty
+	}
+
+	review, err := s.reviewCart(ctx, cart)
+	if err != nil {
+		return nil, err
+	}
+
+	if review.HasChanges() && !review.Confirmed(req.ReviewedLines) {
+		return nil, &CheckoutChangedError{Review: review}
+	}
+
+	items := availableItems(review)
+	if len(items) == 0 {
+		return nil, ErrNothingAvailable
+	}
+
+	var ordered []*proto.CartItem
+	for _, item := range items {
+		ordered = append(ordered, &proto.CartItem{
+			ProductId: item.ProductID,
+			Quantity:  int32(item.Quantity),
+		})
+	}
+
+	// Coupons entered in the shopping cart are redeemed with the order
+	order, err := s.placeOrder(ctx, claims.UserID, items, req.ShippingAddressID, req.ShippingMethod, cart.CouponCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	// The order is placed at this point, a cart left behind is only logged
+	_, err = s.cartService.RemoveItems(ctx, &proto.RemoveItemsRequest{UserId: claims.UserID, Items: ordered, CouponCodes: cart.CouponCodes})
+	if err != nil {
+		log.Println("failed to clear shopping cart after checkout: ", err)
+	}
+
+	return order, nil
+}
+
+// reviewCart re-prices every line of the cart and checks it is in stock
+func (s *OrderService) reviewCart(ctx context.Context, cart *proto.Cart) (*models.CheckoutReview, error) {
+	review := &models.CheckoutReview{Total: money.Zero(s.currency)}
+
+	for _, item := range cart.Items {
+		line := models.CheckoutLine{
+			ProductID: item.ProductId,
+			Quantity:  int(item.Quantity),
+			CartPrice: money.New(item.Price.GetAmount(), item.Price.GetCurrency()),
+		}
+
+		priceRes, err := s.priceService.GetPrice(ctx, &proto.PriceRequest{ProductId: fmt.Sprintf("%d", item.ProductId)})
+		if err != nil && status.Code(err) != codes.NotFound {
+			return nil, err
+		}
+
+		// Products removed from the catalog are unavailable
+		if err == nil {
+			line.Price, err = s.price(priceRes.Price)
+			if err != nil {
+				return nil, err
+			}
+
+			line.Category = priceRes.Category
+			line.TaxCategory = priceRes.TaxCategory
+			line.Size = itemSize(priceRes)
+			line.PriceChanged = line.Price != line.CartPrice
+			line.Available = priceRes.Stock >= int64(item.Quantity)
+		}
+
+		if line.Available {
+			review.Total = review.Total.Add(line.Price.Mul(int64(line.Quantity)))
+		}
+
+		review.Lines = append(review.Lines, line)
+	}
+
+	return review, nil
+}
+
+// availableItems returns the available lines of a reviewed cart as order items
+func availableItems(review *models.CheckoutReview) []*models.OrderItem {
+	var items []*models.OrderItem
+	for _, line := range review.Lines {
+		if !line.Available {
+			continue
+		}
+
+		items = append(items, &models.OrderItem{
+			ProductID:   line.ProductID,
+			Quantity:    line.Quantity,
+			Price:       line.Price,
+			Category:    line.Category,
+			TaxCategory: line.TaxCategory,
+			Size:        line.Size,
+		})
+	}
+	return items
+}
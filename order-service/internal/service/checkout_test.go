@@ -0,0 +1,205 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/tax"
+	"github.com/NeGat1FF/e-commerce/order-service/proto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (r *fakeRepo) GetAvailablePromotions(ctx context.Context, at time.Time, codes []string) ([]models.Promotion, error) {
+	return nil, nil
+}
+
+func (r *fakeRepo) GetRedemptions(ctx context.Context, userID string, promotionIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	return map[uuid.UUID]int{}, nil
+}
+
+func (r *fakeRepo) CreateOrder(ctx context.Context, order *models.Order, items []*models.OrderItem, discounts []*models.OrderDiscount, change *models.OrderStatusChange, saga *models.Saga) (*models.OrderResponse, error) {
+	if r.placeErr != nil {
+		return nil, r.placeErr
+	}
+	r.placed = append(r.placed, items)
+	return &models.OrderResponse{ID: order.ID, UserID: order.UserID, Total: order.Total, Status: models.OrderStatusName(order.Status)}, nil
+}
+
+// fakeCart holds the shopping cart of the owner and records what is removed from it,
+// together with the number of orders placed at the time
+type fakeCart struct {
+	proto.CartServiceClient
+	repo          *fakeRepo
+	items         []*proto.CartItem
+	removed       []*proto.CartItem
+	placedAtClear int
+}
+
+func (c *fakeCart) GetCart(ctx context.Context, in *proto.GetCartRequest, opts ...grpc.CallOption) (*proto.Cart, error) {
+	return &proto.Cart{UserId: in.UserId, Items: c.items}, nil
+}
+
+func (c *fakeCart) RemoveItems(ctx context.Context, in *proto.RemoveItemsRequest, opts ...grpc.CallOption) (*proto.RemoveItemsResponse, error) {
+	c.removed = append(c.removed, in.Items...)
+	c.placedAtClear = len(c.repo.placed)
+	return &proto.RemoveItemsResponse{}, nil
+}
+
+// catalogItem is the current price and stock of a product
+type catalogItem struct {
+	price int64
+	stock int64
+}
+
+// fakeCatalog prices the products it has, the others are not found
+type fakeCatalog map[int64]catalogItem
+
+func (c fakeCatalog) GetPrice(ctx context.Context, in *proto.PriceRequest, opts ...grpc.CallOption) (*proto.PriceResponse, error) {
+	id, err := strconv.ParseInt(in.ProductId, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	item, ok := c[id]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "product not found")
+	}
+	return &proto.PriceResponse{Price: &proto.Money{Amount: item.price, Currency: "USD"}, Stock: item.stock}, nil
+}
+
+func TestCheckout(t *testing.T) {
+	// Product 1 was added at 10.00 and product 2 at 5.00
+	cart := []*proto.CartItem{
+		{ProductId: 1, Quantity: 2, Price: &proto.Money{Amount: 1000, Currency: "USD"}},
+		{ProductId: 2, Quantity: 1, Price: &proto.Money{Amount: 500, Currency: "USD"}},
+	}
+
+	errDatabase := errors.New("database is down")
+
+	line := func(productID int64, quantity int, price int64, available bool) models.ReviewedLine {
+		return models.ReviewedLine{ProductID: productID, Quantity: quantity, Price: money.New(price, "USD"), Available: available}
+	}
+
+	testCases := []struct {
+		name          string
+		catalog       fakeCatalog
+		reviewed      []models.ReviewedLine
+		placeErr      error
+		expectedError error
+		wantChanged   bool
+		wantItems     map[int64]int64
+	}{
+		{
+			name:      "Cart at current prices",
+			catalog:   fakeCatalog{1: {price: 1000, stock: 5}, 2: {price: 500, stock: 5}},
+			wantItems: map[int64]int64{1: 1000, 2: 500},
+		},
+		{
+			name:        "Changed price is not confirmed",
+			catalog:     fakeCatalog{1: {price: 1200, stock: 5}, 2: {price: 500, stock: 5}},
+			wantChanged: true,
+		},
+		{
+			name:      "Changed price is confirmed",
+			catalog:   fakeCatalog{1: {price: 1200, stock: 5}, 2: {price: 500, stock: 5}},
+			reviewed:  []models.ReviewedLine{line(1, 2, 1200, true), line(2, 1, 500, true)},
+			wantItems: map[int64]int64{1: 1200, 2: 500},
+		},
+		{
+			name:        "Price changed again after the review",
+			catalog:     fakeCatalog{1: {price: 1300, stock: 5}, 2: {price: 500, stock: 5}},
+			reviewed:    []models.ReviewedLine{line(1, 2, 1200, true), line(2, 1, 500, true)},
+			wantChanged: true,
+		},
+		{
+			name:        "Quantity changed after the review",
+			catalog:     fakeCatalog{1: {price: 1200, stock: 5}, 2: {price: 500, stock: 5}},
+			reviewed:    []models.ReviewedLine{line(1, 1, 1200, true), line(2, 1, 500, true)},
+			wantChanged: true,
+		},
+		{
+			name:        "Unavailable line is not confirmed",
+			catalog:     fakeCatalog{1: {price: 1000, stock: 1}, 2: {price: 500, stock: 5}},
+			reviewed:    []models.ReviewedLine{line(1, 2, 1000, true), line(2, 1, 500, true)},
+			wantChanged: true,
+		},
+		{
+			name:      "Confirmed out of stock line is dropped",
+			catalog:   fakeCatalog{1: {price: 1000, stock: 1}, 2: {price: 500, stock: 5}},
+			reviewed:  []models.ReviewedLine{line(1, 2, 0, false), line(2, 1, 500, true)},
+			wantItems: map[int64]int64{2: 500},
+		},
+		{
+			name:      "Confirmed product that left the catalog is dropped",
+			catalog:   fakeCatalog{2: {price: 500, stock: 5}},
+			reviewed:  []models.ReviewedLine{line(1, 2, 0, false), line(2, 1, 500, true)},
+			wantItems: map[int64]int64{2: 500},
+		},
+		{
+			name:          "Nothing available",
+			catalog:       fakeCatalog{},
+			reviewed:      []models.ReviewedLine{line(1, 2, 0, false), line(2, 1, 0, false)},
+			expectedError: service.ErrNothingAvailable,
+		},
+		{
+			name:          "Order is not created",
+			catalog:       fakeCatalog{1: {price: 1000, stock: 5}, 2: {price: 500, stock: 5}},
+			placeErr:      errDatabase,
+			expectedError: errDatabase,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &fakeRepo{placeErr: tc.placeErr}
+			cartService := &fakeCart{repo: repo, items: cart}
+			s := service.NewOrderService(repo, tc.catalog, nil, cartService, nil, nil, tax.NewFake(0), nil, "USD", newFakeAuth())
+
+			order, err := s.Checkout(context.Background(), "owner", models.CheckoutRequest{ReviewedLines: tc.reviewed})
+
+			if tc.wantChanged || tc.expectedError != nil {
+				var changedErr *service.CheckoutChangedError
+				if tc.wantChanged {
+					require.ErrorAs(t, err, &changedErr)
+					assert.True(t, changedErr.Review.HasChanges())
+				} else {
+					assert.ErrorIs(t, err, tc.expectedError)
+				}
+				assert.Nil(t, order)
+				assert.Empty(t, repo.placed, "no order is placed")
+				assert.Empty(t, cartService.removed, "the cart is left as it was")
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, repo.placed, 1)
+
+			items := map[int64]int64{}
+			for _, item := range repo.placed[0] {
+				items[item.ProductID] = item.Price.Amount
+			}
+			assert.Equal(t, tc.wantItems, items)
+
+			// Only the ordered items are taken off the cart, once the order exists
+			var ordered, removed []int64
+			for productID := range tc.wantItems {
+				ordered = append(ordered, productID)
+			}
+			for _, item := range cartService.removed {
+				removed = append(removed, item.ProductId)
+			}
+			assert.ElementsMatch(t, ordered, removed)
+			assert.Equal(t, 1, cartService.placedAtClear)
+		})
+	}
+}
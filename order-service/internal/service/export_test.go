@@ -0,0 +1,4 @@
+package service
+
+// SagaMaxAttempts lets the tests fail a step as often as the orchestrator tries it
+const SagaMaxAttempts = sagaMaxAttempts
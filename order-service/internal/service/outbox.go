@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	messagequeue "github.com/NeGat1FF/e-commerce/order-service/internal/messageQueue"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/repository"
+)
+
+const (
+	// outboxBatchSize is the number of events relayed in one transaction
+	outboxBatchSize = 100
+	// outboxRetention is how long published events are kept for troubleshooting
+	outboxRetention = 7 * 24 * time.Hour
+)
+
+// OutboxRelay publishes the order events written to the outbox to the order exchange
+type OutboxRelay struct {
+	repo         repository.OrderRepoInterface
+	messageQueue *messagequeue.RabbitMQClient
+	exchangeName string
+}
+
+func NewOutboxRelay(repo repository.OrderRepoInterface, mq *messagequeue.RabbitMQClient, exchangeName string) *OutboxRelay {
+	return &OutboxRelay{
+		repo:         repo,
+		messageQueue: mq,
+		exchangeName: exchangeName,
+	}
+}
+
+// Start relays the pending events every interval and removes old published ones hourly
+func (r *OutboxRelay) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		cleanup := time.NewTicker(time.Hour)
+		defer cleanup.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.Relay(context.Background())
+			case <-cleanup.C:
+				n, err := r.repo.DeletePublishedOutbox(context.Background(), time.Now().Add(-outboxRetention))
+				if err != nil {
+					log.Println("failed to delete published order events: ", err)
+				} else if n > 0 {
+					log.Printf("deleted %d published order events", n)
+				}
+			}
+		}
+	}()
+}
+
+// Relay publishes pending events until the outbox is drained or publishing fails
+func (r *OutboxRelay) Relay(ctx context.Context) {
+	for {
+		n, err := r.repo.PublishOutbox(ctx, outboxBatchSize, func(msg *models.OutboxMessage) error {
+			return r.messageQueue.PublishMessage(ctx, r.exchangeName, msg.RoutingKey, msg.Payload)
+		})
+		if err != nil {
+			log.Println("failed to publish order events: ", err)
+			return
+		}
+
+		if n < outboxBatchSize {
+			return
+		}
+	}
+}
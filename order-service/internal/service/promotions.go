@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/promotion"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/order-service/proto"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrInvalidPromotion = errors.New("invalid promotion")
+	ErrInvalidCoupon    = errors.New("coupon code is invalid, expired or used up")
+)
+
+// CreatePromotion creates a promotion on behalf of staff
+func (s *OrderService) CreatePromotion(ctx context.Context, jwt string, req models.PromotionRequest) (*models.Promotion, error) {
+	_, allowed, err := s.can(ctx, jwt, PermissionWriteOrders)
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowed {
+		return nil, ErrPermissionDenied
+	}
+
+	if !req.Valid() {
+		return nil, ErrInvalidPromotion
+	}
+
+	now := time.Now()
+	p := &models.Promotion{ID: uuid.New(), CreatedAt: now}
+	if err := s.setPromotion(p, req, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreatePromotion(ctx, p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// UpdatePromotion replaces a promotion on behalf of staff, promotions are ended by deactivating them
+func (s *OrderService) UpdatePromotion(ctx context.Context, jwt, promotionID string, req models.PromotionRequest) (*models.Promotion, error) {
+	_, allowed, err := s.can(ctx, jwt, PermissionWriteOrders)
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowed {
+		return nil, ErrPermissionDenied
+	}
+
+	if uuid.Validate(promotionID) != nil {
+		return nil, repository.ErrPromotionNotFound
+	}
+
+	if !req.Valid() {
+		return nil, ErrInvalidPromotion
+	}
+
+	p, err := s.repo.GetPromotion(ctx, promotionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.setPromotion(p, req, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdatePromotion(ctx, p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// GetPromotions returns all promotions to staff
+func (s *OrderService) GetPromotions(ctx context.Context, jwt string) ([]models.Promotion, error) {
+	_, allowed, err := s.can(ctx, jwt, PermissionReadOrders)
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowed {
+		return nil, ErrPermissionDenied
+	}
+
+	return s.repo.GetPromotions(ctx)
+}
+
+// GetPromotion returns a promotion to staff
+func (s *OrderService) GetPromotion(ctx context.Context, jwt, promotionID string) (*models.Promotion, error) {
+	_, allowed, err := s.can(ctx, jwt, PermissionReadOrders)
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowed {
+		return nil, ErrPermissionDenied
+	}
+
+	if uuid.Validate(promotionID) != nil {
+		return nil, repository.ErrPromotionNotFound
+	}
+
+	return s.repo.GetPromotion(ctx, promotionID)
+}
+
+// setPromotion sets the fields of a promotion from a request, its amounts have to be in the currency of the shop
+func (s *OrderService) setPromotion(p *models.Promotion, req models.PromotionRequest, now time.Time) error {
+	amount, ok := s.shopAmount(req.Amount)
+	if !ok {
+		return ErrInvalidPromotion
+	}
+	minSubtotal, ok := s.shopAmount(req.MinSubtotal)
+	if !ok {
+		return ErrInvalidPromotion
+	}
+
+	p.Name = req.Name
+	p.Code = normalizeCode(req.Code)
+	p.Type = req.Type
+	p.Value = req.Value
+	p.Amount = amount
+	p.BuyQuantity = req.BuyQuantity
+	p.GetQuantity = req.GetQuantity
+	p.ProductIDs = req.ProductIDs
+	p.Categories = req.Categories
+	p.MinSubtotal = minSubtotal
+	p.StartsAt = req.StartsAt
+	p.EndsAt = req.EndsAt
+	p.UsageLimit = req.UsageLimit
+	p.PerCustomerLimit = req.PerCustomerLimit
+	p.Stackable = req.Stackable
+	p.Priority = req.Priority
+	p.Active = req.Active
+	p.UpdatedAt = now
+
+	return nil
+}
+
+// shopAmount returns an amount in the currency of the shop, amounts without a currency are taken in it
+func (s *OrderService) shopAmount(m money.Money) (money.Money, bool) {
+	m = money.New(m.Amount, m.Currency)
+	if m.Currency == "" {
+		return money.New(m.Amount, s.currency), true
+	}
+	if m.Currency != s.currency {
+		return money.Money{}, false
+	}
+	return m, true
+}
+
+// PreviewDiscounts returns the discounts the items of a shopping cart get with the coupon codes,
+// and the codes that cannot be used. Shipping is not known yet, free shipping shows at no amount.
+func (s *OrderService) PreviewDiscounts(ctx context.Context, userID string, items []*models.OrderItem, couponCodes []string) (*promotion.Result, []string, error) {
+	for _, item := range items {
+		if item.Price.Currency != s.currency {
+			return nil, nil, fmt.Errorf("%w: %s", ErrPriceCurrency, item.Price)
+		}
+
+		priceRes, err := s.priceService.GetPrice(ctx, &proto.PriceRequest{ProductId: fmt.Sprintf("%d", item.ProductID)})
+		if err != nil && status.Code(err) != codes.NotFound {
+			return nil, nil, err
+		}
+		if err == nil {
+			item.Category = priceRes.Category
+		}
+	}
+
+	promotions, invalid, err := s.availablePromotions(ctx, userID, couponCodes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return promotion.Apply(promotions, promotionLines(items), money.Zero(s.currency)), invalid, nil
+}
+
+// applyPromotions sets the discounts of the promotions on an order and its items and returns
+// them with the discount of the shipping. Coupon codes that cannot be used fail the order.
+func (s *OrderService) applyPromotions(ctx context.Context, order *models.Order, items []*models.OrderItem, couponCodes []string) ([]*models.OrderDiscount, money.Money, error) {
+	promotions, invalid, err := s.availablePromotions(ctx, order.UserID.String(), couponCodes)
+	if err != nil {
+		return nil, money.Money{}, err
+	}
+
+	if len(invalid) > 0 {
+		return nil, money.Money{}, fmt.Errorf("%w: %s", ErrInvalidCoupon, strings.Join(invalid, ", "))
+	}
+
+	res := promotion.Apply(promotions, promotionLines(items), order.ShippingCost)
+
+	for i, item := range items {
+		item.Discount = res.LineDiscounts[i]
+	}
+	order.Discount = res.Total
+
+	now := time.Now()
+	var discounts []*models.OrderDiscount
+	for _, d := range res.Discounts {
+		discounts = append(discounts, &models.OrderDiscount{
+			ID:          uuid.New(),
+			OrderID:     order.ID,
+			PromotionID: d.Promotion.ID,
+			Code:        d.Promotion.Code,
+			Name:        d.Promotion.Name,
+			Type:        d.Promotion.Type,
+			Amount:      d.Amount,
+			CreatedAt:   now,
+		})
+	}
+
+	return discounts, res.ShippingDiscount, nil
+}
+
+// availablePromotions returns the automatic promotions and those of the coupon codes the user can
+// use now, and the coupon codes that are unknown, expired or used up for the user
+func (s *OrderService) availablePromotions(ctx context.Context, userID string, couponCodes []string) ([]models.Promotion, []string, error) {
+	var normalized []string
+	for _, code := range couponCodes {
+		code = normalizeCode(code)
+		if code != "" && !slices.Contains(normalized, code) {
+			normalized = append(normalized, code)
+		}
+	}
+
+	promotions, err := s.repo.GetAvailablePromotions(ctx, time.Now(), normalized)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ids []uuid.UUID
+	for _, p := range promotions {
+		if p.PerCustomerLimit > 0 {
+			ids = append(ids, p.ID)
+		}
+	}
+
+	redemptions, err := s.repo.GetRedemptions(ctx, userID, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var usable []models.Promotion
+	for _, p := range promotions {
+		if p.PerCustomerLimit > 0 && redemptions[p.ID] >= p.PerCustomerLimit {
+			continue
+		}
+		usable = append(usable, p)
+	}
+
+	invalid := []string{}
+	for _, code := range normalized {
+		found := slices.ContainsFunc(usable, func(p models.Promotion) bool {
+			return p.Code == code
+		})
+		if !found {
+			invalid = append(invalid, code)
+		}
+	}
+
+	return usable, invalid, nil
+}
+
+func promotionLines(items []*models.OrderItem) []promotion.Line {
+	lines := make([]promotion.Line, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, promotion.Line{
+			ProductID: item.ProductID,
+			Category:  item.Category,
+			Quantity:  item.Quantity,
+			UnitPrice: item.Price,
+		})
+	}
+	return lines
+}
+
+// normalizeCode makes coupon codes case insensitive
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,81 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (r *fakeRepo) CreatePromotion(ctx context.Context, p *models.Promotion) error {
+	r.promotions = append(r.promotions, p)
+	return nil
+}
+
+func TestCreatePromotionAmounts(t *testing.T) {
+	testCases := []struct {
+		name        string
+		amount      money.Money
+		minSubtotal money.Money
+		want        money.Money
+		wantMin     money.Money
+		err         error
+	}{
+		{
+			name:    "Amount in the shop currency",
+			amount:  money.New(500, "USD"),
+			want:    money.New(500, "USD"),
+			wantMin: money.Zero("USD"),
+		},
+		{
+			name:        "Amounts without a currency are in the shop currency",
+			amount:      money.Money{Amount: 500},
+			minSubtotal: money.Money{Amount: 2000},
+			want:        money.New(500, "USD"),
+			wantMin:     money.New(2000, "USD"),
+		},
+		{
+			name:   "Amount in a foreign currency",
+			amount: money.New(500, "EUR"),
+			err:    service.ErrInvalidPromotion,
+		},
+		{
+			name:        "Minimum subtotal in a foreign currency",
+			amount:      money.New(500, "USD"),
+			minSubtotal: money.New(2000, "EUR"),
+			err:         service.ErrInvalidPromotion,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, repo, _ := newTestService()
+
+			req := models.PromotionRequest{
+				Name:        "Five off",
+				Type:        models.PromotionFixed,
+				Amount:      tc.amount,
+				MinSubtotal: tc.minSubtotal,
+				Active:      true,
+			}
+
+			var p *models.Promotion
+			var err error
+			assert.NotPanics(t, func() {
+				p, err = s.CreatePromotion(context.Background(), "writer", req)
+			})
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				assert.Empty(t, repo.promotions)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, p.Amount)
+			assert.Equal(t, tc.wantMin, p.MinSubtotal)
+		})
+	}
+}
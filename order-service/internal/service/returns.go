@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/order-service/proto"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotReturnable      = errors.New("order cannot be returned")
+	ErrInvalidReturnItems = errors.New("items are not part of the order or were already returned")
+)
+
+// RequestReturn opens a return of items of a delivered order of the user of the token
+func (s *OrderService) RequestReturn(ctx context.Context, jwt, orderID string, req models.CreateReturnRequest) (*models.Return, error) {
+	claims, err := s.authenticate(ctx, jwt)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.ownOrder(ctx, claims.UserID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	status, _ := models.ParseOrderStatus(order.Status)
+	if !models.CanReturn(status) {
+		return nil, ErrNotReturnable
+	}
+
+	if len(req.Items) == 0 {
+		return nil, ErrInvalidReturnItems
+	}
+
+	items, err := s.repo.GetOrderItems(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	returns, err := s.repo.GetReturns(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(map[int64]*models.OrderItem, len(items))
+	for _, item := range items {
+		lines[item.ProductID] = item
+	}
+
+	returnable := models.ReturnableQuantities(items, returns)
+
+	now := time.Now()
+	ret := &models.Return{
+		ID:           uuid.New(),
+		OrderID:      order.ID,
+		UserID:       order.UserID,
+		Status:       models.ReturnStatusRequested,
+		Reason:       req.Reason,
+		RefundAmount: money.Zero(s.currency),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	for _, item := range req.Items {
+		if item.Quantity < 1 || item.Quantity > returnable[item.ID] {
+			return nil, ErrInvalidReturnItems
+		}
+		line := lines[item.ID]
+		refund := line.RefundFor(line.Quantity-returnable[item.ID], item.Quantity)
+		returnable[item.ID] -= item.Quantity
+
+		// The refund includes the tax paid for the items
+		ret.Items = append(ret.Items, models.ReturnItem{ProductID: item.ID, Quantity: item.Quantity, Price: refund.Share(1, int64(item.Quantity))})
+		ret.RefundAmount = ret.RefundAmount.Add(refund)
+	}
+
+	if err := s.repo.CreateReturn(ctx, ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// GetReturns returns the returns of an order to its customer or to staff
+func (s *OrderService) GetReturns(ctx context.Context, jwt, orderID string) ([]models.Return, error) {
+	if _, err := s.readableOrder(ctx, jwt, orderID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetReturns(ctx, orderID)
+}
+
+// UpdateReturn moves a return to another status on behalf of staff. Received items are put
+// back in stock and refunded returns are paid back, both can be retried if they fail.
+func (s *OrderService) UpdateReturn(ctx context.Context, jwt, returnID string, req models.UpdateReturnRequest) (*models.Return, error) {
+	claims, allowed, err := s.can(ctx, jwt, PermissionWriteOrders)
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowed {
+		return nil, repository.ErrReturnNotFound
+	}
+
+	if uuid.Validate(returnID) != nil {
+		return nil, repository.ErrReturnNotFound
+	}
+
+	ret, err := s.repo.GetReturn(ctx, returnID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !models.CanTransitionReturn(ret.Status, req.Status) {
+		return nil, &TransitionError{
+			From:    ret.Status,
+			To:      req.Status,
+			Allowed: models.NextReturnStatuses(ret.Status),
+		}
+	}
+
+	switch req.Status {
+	case models.ReturnStatusReceived:
+		if err := s.restock(ctx, ret); err != nil {
+			return nil, err
+		}
+	case models.ReturnStatusRefunded:
+		_, err := s.paymentService.RefundPayment(ctx, &proto.RefundPaymentRequest{
+			OrderId:   ret.OrderID.String(),
+			Amount:    toProtoMoney(ret.RefundAmount),
+			Reference: "return-" + ret.ID.String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	from := ret.Status
+	ret.Status = req.Status
+	ret.Note = req.Note
+	ret.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateReturn(ctx, ret, from); err != nil {
+		return nil, err
+	}
+
+	switch ret.Status {
+	case models.ReturnStatusReceived:
+		s.updateReturnedOrder(ctx, ret, models.OrderStatusReturned, claims.UserID, "items returned")
+	case models.ReturnStatusRefunded:
+		if s.fullyRefunded(ctx, ret.OrderID.String()) {
+			s.updateReturnedOrder(ctx, ret, models.OrderStatusRefunded, claims.UserID, "all items returned and refunded")
+		}
+	}
+
+	return ret, nil
+}
+
+// restock puts the items of a received return back in stock
+func (s *OrderService) restock(ctx context.Context, ret *models.Return) error {
+	req := &proto.ReturnStockRequest{ReturnId: ret.ID.String()}
+	for _, item := range ret.Items {
+		req.Items = append(req.Items, &proto.StockItem{ProductId: item.ProductID, Quantity: int64(item.Quantity)})
+	}
+
+	_, err := s.stockService.ReturnStock(ctx, req)
+	return err
+}
+
+// fullyRefunded reports whether every item of an order was returned and refunded
+func (s *OrderService) fullyRefunded(ctx context.Context, orderID string) bool {
+	items, err := s.repo.GetOrderItems(ctx, orderID)
+	if err != nil {
+		log.Printf("order %s: failed to get items: %v", orderID, err)
+		return false
+	}
+
+	returns, err := s.repo.GetReturns(ctx, orderID)
+	if err != nil {
+		log.Printf("order %s: failed to get returns: %v", orderID, err)
+		return false
+	}
+
+	var refunded []models.Return
+	for _, ret := range returns {
+		if ret.Status == models.ReturnStatusRefunded {
+			refunded = append(refunded, ret)
+		}
+	}
+
+	for _, quantity := range models.ReturnableQuantities(items, refunded) {
+		if quantity > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// updateReturnedOrder follows the return in the status of its order. The return is already
+// saved, so an order that cannot move, such as one that is returned already, is only logged.
+func (s *OrderService) updateReturnedOrder(ctx context.Context, ret *models.Return, to int, actor, reason string) {
+	_, err := s.transition(ctx, ret.OrderID.String(), to, actor, reason)
+
+	var transitionErr *TransitionError
+	if err != nil && !errors.As(err, &transitionErr) {
+		log.Printf("order %s: failed to follow return %s: %v", ret.OrderID, ret.ID, err)
+	}
+}
@@ -0,0 +1,265 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (r *fakeRepo) GetOrderItems(ctx context.Context, orderID string) ([]*models.OrderItem, error) {
+	return r.items[orderID], nil
+}
+
+func (r *fakeRepo) CreateReturn(ctx context.Context, ret *models.Return) error {
+	r.returns = append(r.returns, ret)
+	return nil
+}
+
+func (r *fakeRepo) GetReturn(ctx context.Context, returnID string) (*models.Return, error) {
+	for _, ret := range r.returns {
+		if ret.ID.String() == returnID {
+			copied := *ret
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrReturnNotFound
+}
+
+func (r *fakeRepo) GetReturns(ctx context.Context, orderID string) ([]models.Return, error) {
+	var returns []models.Return
+	for _, ret := range r.returns {
+		if ret.OrderID.String() == orderID {
+			returns = append(returns, *ret)
+		}
+	}
+	return returns, nil
+}
+
+func (r *fakeRepo) UpdateReturn(ctx context.Context, ret *models.Return, fromStatus string) error {
+	for i := range r.returns {
+		if r.returns[i].ID == ret.ID {
+			r.returns[i] = ret
+		}
+	}
+	return nil
+}
+
+// newReturnsTest returns a service with an order of the owner in the status. Its one line is three
+// items at 10.00 with 3.00 off, so 9.00 was paid for each before tax and 9.90 with tax.
+func newReturnsTest(orderStatus int) (*service.OrderService, *fakeRepo, string) {
+	s, repo, orderID := newTestService()
+	repo.orders[orderID].Status = models.OrderStatusName(orderStatus)
+	repo.items = map[string][]*models.OrderItem{orderID: {{
+		ProductID: 1,
+		Quantity:  3,
+		Price:     money.New(1000, "USD"),
+		Discount:  money.New(300, "USD"),
+		Tax:       money.New(270, "USD"),
+		Total:     money.New(2970, "USD"),
+	}}}
+	return s, repo, orderID
+}
+
+func TestRequestReturn(t *testing.T) {
+	testCases := []struct {
+		name          string
+		token         string
+		orderStatus   int
+		returned      int
+		quantity      int
+		productID     int64
+		expectedError error
+		wantRefund    money.Money
+	}{
+		{
+			name:        "Owner returns an item",
+			token:       "owner",
+			orderStatus: models.OrderStatusDelivered,
+			quantity:    1,
+			productID:   1,
+			wantRefund:  money.New(990, "USD"),
+		},
+		{
+			name:        "Owner returns more items of a returned order",
+			token:       "owner",
+			orderStatus: models.OrderStatusReturned,
+			returned:    2,
+			quantity:    1,
+			productID:   1,
+			wantRefund:  money.New(990, "USD"),
+		},
+		{
+			name:          "Another user",
+			token:         "other",
+			orderStatus:   models.OrderStatusDelivered,
+			quantity:      1,
+			productID:     1,
+			expectedError: repository.ErrOrderNotFound,
+		},
+		{
+			name:          "Staff cannot return items of a customer",
+			token:         "writer",
+			orderStatus:   models.OrderStatusDelivered,
+			quantity:      1,
+			productID:     1,
+			expectedError: repository.ErrOrderNotFound,
+		},
+		{
+			name:          "Invalid token",
+			token:         "forged",
+			orderStatus:   models.OrderStatusDelivered,
+			quantity:      1,
+			productID:     1,
+			expectedError: utils.ErrInvalidToken,
+		},
+		{
+			name:          "Order that was not delivered",
+			token:         "owner",
+			orderStatus:   models.OrderStatusShipped,
+			quantity:      1,
+			productID:     1,
+			expectedError: service.ErrNotReturnable,
+		},
+		{
+			name:          "Order that was refunded",
+			token:         "owner",
+			orderStatus:   models.OrderStatusRefunded,
+			quantity:      1,
+			productID:     1,
+			expectedError: service.ErrNotReturnable,
+		},
+		{
+			name:          "More items than were ordered",
+			token:         "owner",
+			orderStatus:   models.OrderStatusDelivered,
+			quantity:      4,
+			productID:     1,
+			expectedError: service.ErrInvalidReturnItems,
+		},
+		{
+			name:          "Items that were already returned",
+			token:         "owner",
+			orderStatus:   models.OrderStatusReturned,
+			returned:      2,
+			quantity:      2,
+			productID:     1,
+			expectedError: service.ErrInvalidReturnItems,
+		},
+		{
+			name:          "Product that is not part of the order",
+			token:         "owner",
+			orderStatus:   models.OrderStatusDelivered,
+			quantity:      1,
+			productID:     2,
+			expectedError: service.ErrInvalidReturnItems,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, repo, orderID := newReturnsTest(tc.orderStatus)
+			if tc.returned > 0 {
+				repo.returns = append(repo.returns, &models.Return{
+					ID:      uuid.New(),
+					OrderID: repo.orders[orderID].ID,
+					Status:  models.ReturnStatusRequested,
+					Items:   models.ReturnItems{{ProductID: 1, Quantity: tc.returned}},
+				})
+			}
+
+			ret, err := s.RequestReturn(context.Background(), tc.token, orderID, models.CreateReturnRequest{
+				Items: models.Products{{ID: tc.productID, Quantity: tc.quantity}},
+			})
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Len(t, repo.returns, min(tc.returned, 1), "no return is opened")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, models.ReturnStatusRequested, ret.Status)
+			assert.Equal(t, ownerID, ret.UserID)
+			assert.Equal(t, tc.wantRefund, ret.RefundAmount)
+		})
+	}
+}
+
+func TestReturnRefundsAddUpToWhatWasPaid(t *testing.T) {
+	s, repo, orderID := newReturnsTest(models.OrderStatusDelivered)
+	// 10.00 for three items does not split evenly
+	repo.items[orderID][0].Total = money.New(1000, "USD")
+
+	refunded := money.Zero("USD")
+	for range 3 {
+		ret, err := s.RequestReturn(context.Background(), "owner", orderID, models.CreateReturnRequest{
+			Items: models.Products{{ID: 1, Quantity: 1}},
+		})
+		require.NoError(t, err)
+		assert.LessOrEqual(t, ret.RefundAmount.Amount, int64(334))
+		refunded = refunded.Add(ret.RefundAmount)
+	}
+
+	assert.Equal(t, money.New(1000, "USD"), refunded)
+}
+
+func TestUpdateReturn(t *testing.T) {
+	testCases := []struct {
+		name          string
+		token         string
+		status        string
+		expectedError error
+	}{
+		{name: "Staff approves", token: "writer", status: models.ReturnStatusApproved},
+		{name: "Staff rejects", token: "writer", status: models.ReturnStatusRejected},
+		// Anyone else cannot tell whether the return exists
+		{name: "Owner cannot approve", token: "owner", status: models.ReturnStatusApproved, expectedError: repository.ErrReturnNotFound},
+		{name: "Owner cannot reject", token: "owner", status: models.ReturnStatusRejected, expectedError: repository.ErrReturnNotFound},
+		{name: "Staff that only reads orders", token: "reader", status: models.ReturnStatusApproved, expectedError: repository.ErrReturnNotFound},
+		{name: "Invalid token", token: "forged", status: models.ReturnStatusApproved, expectedError: utils.ErrInvalidToken},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, repo, orderID := newReturnsTest(models.OrderStatusDelivered)
+			ret := &models.Return{
+				ID:           uuid.New(),
+				OrderID:      repo.orders[orderID].ID,
+				UserID:       ownerID,
+				Status:       models.ReturnStatusRequested,
+				Items:        models.ReturnItems{{ProductID: 1, Quantity: 1}},
+				RefundAmount: money.New(990, "USD"),
+			}
+			repo.returns = append(repo.returns, ret)
+
+			updated, err := s.UpdateReturn(context.Background(), tc.token, ret.ID.String(), models.UpdateReturnRequest{Status: tc.status, Note: "checked"})
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Equal(t, models.ReturnStatusRequested, repo.returns[0].Status)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.status, updated.Status)
+			assert.Equal(t, tc.status, repo.returns[0].Status)
+			assert.Equal(t, "checked", repo.returns[0].Note)
+		})
+	}
+}
+
+func TestUpdateReturnTransition(t *testing.T) {
+	s, repo, orderID := newReturnsTest(models.OrderStatusDelivered)
+	ret := &models.Return{ID: uuid.New(), OrderID: repo.orders[orderID].ID, Status: models.ReturnStatusRequested}
+	repo.returns = append(repo.returns, ret)
+
+	_, err := s.UpdateReturn(context.Background(), "writer", ret.ID.String(), models.UpdateReturnRequest{Status: models.ReturnStatusRefunded})
+
+	var transitionErr *service.TransitionError
+	require.ErrorAs(t, err, &transitionErr)
+	assert.Equal(t, []string{models.ReturnStatusApproved, models.ReturnStatusRejected}, transitionErr.Allowed)
+}
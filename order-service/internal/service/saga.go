@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/order-service/proto"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// sagaBatchSize is the number of sagas a replica claims at once
+	sagaBatchSize = 20
+	// sagaLease is how long a claimed saga is kept from the other replicas
+	sagaLease = time.Minute
+	// sagaMaxAttempts is the number of times a forward step is tried before the saga is compensated,
+	// compensations are retried until they succeed
+	sagaMaxAttempts = 5
+	// sagaMaxBackoff caps the delay between retries
+	sagaMaxBackoff = 5 * time.Minute
+	// paymentPollInterval is how often a pending payment is checked
+	paymentPollInterval = 10 * time.Second
+)
+
+var (
+	errPaymentPending  = errors.New("payment is pending")
+	errPaymentCaptured = errors.New("payment already succeeded")
+)
+
+// sagaFailure is a step failure retrying cannot fix, the saga is compensated right away
+type sagaFailure struct {
+	reason string
+}
+
+func (e *sagaFailure) Error() string {
+	return e.reason
+}
+
+// SagaOrchestrator takes placed orders through their sagas: it reserves the stock, creates
+// the payment and marks the order paid once the payment succeeds. When a step fails for good
+// or the payment is not made in time, the payment is cancelled, the stock released and the order cancelled.
+type SagaOrchestrator struct {
+	orders         *OrderService
+	repo           repository.OrderRepoInterface
+	paymentTimeout time.Duration
+}
+
+func NewSagaOrchestrator(orders *OrderService, repo repository.OrderRepoInterface, paymentTimeout time.Duration) *SagaOrchestrator {
+	return &SagaOrchestrator{
+		orders:         orders,
+		repo:           repo,
+		paymentTimeout: paymentTimeout,
+	}
+}
+
+// Start runs the due sagas every interval until the process exits.
+// Sagas in flight when the service stopped are resumed from their persisted step.
+func (o *SagaOrchestrator) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			o.RunDue(context.Background())
+		}
+	}()
+}
+
+// RunDue claims the sagas that are due and runs one step of each
+func (o *SagaOrchestrator) RunDue(ctx context.Context) {
+	sagas, err := o.repo.ClaimSagas(ctx, sagaBatchSize, sagaLease)
+	if err != nil {
+		log.Println("failed to claim sagas: ", err)
+		return
+	}
+
+	for i := range sagas {
+		o.runStep(ctx, &sagas[i])
+	}
+}
+
+// runStep runs the current step of a saga and moves it on according to the outcome
+func (o *SagaOrchestrator) runStep(ctx context.Context, saga *models.Saga) {
+	step := saga.Step
+	compensation := models.IsCompensation(step)
+
+	stepCtx, cancel := context.WithTimeout(ctx, sagaLease/2)
+	err := o.execute(stepCtx, saga)
+	cancel()
+
+	now := time.Now()
+	saga.UpdatedAt = now
+
+	if errors.Is(err, errPaymentPending) {
+		// Polling is not recorded as a step attempt
+		saga.NextRunAt = now.Add(paymentPollInterval)
+		o.save(ctx, saga, nil)
+		return
+	}
+
+	record := &models.SagaStep{
+		ID:           uuid.New(),
+		OrderID:      saga.OrderID,
+		Step:         step,
+		Compensation: compensation,
+		Outcome:      models.SagaStepSucceeded,
+		CreatedAt:    now,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	var failure *sagaFailure
+
+	switch {
+	case err == nil:
+		saga.Attempts = 0
+		saga.NextRunAt = now
+		saga.Step = models.NextSagaStep(step)
+		if saga.Step == "" {
+			saga.Step = step
+			if compensation {
+				saga.Status = models.SagaCompensated
+			} else {
+				saga.Status = models.SagaCompleted
+			}
+		}
+	case errors.Is(err, errPaymentCaptured):
+		// The customer paid while the saga was giving up, so the order goes through after all
+		record.Outcome = models.SagaStepFailed
+		saga.Status = models.SagaRunning
+		saga.Step = models.SagaStepAwaitPayment
+		saga.Attempts = 0
+		saga.Reason = ""
+		saga.NextRunAt = now
+	case errors.As(err, &failure) && !compensation:
+		record.Outcome = models.SagaStepFailed
+		o.compensate(saga, step, failure.reason, now)
+	case !compensation && saga.Attempts+1 >= sagaMaxAttempts:
+		record.Outcome = models.SagaStepFailed
+		o.compensate(saga, step, fmt.Sprintf("%s failed: %v", step, err), now)
+	default:
+		record.Outcome = models.SagaStepRetrying
+		saga.Attempts++
+		saga.NextRunAt = now.Add(backoff(saga.Attempts))
+	}
+
+	log.Printf("saga %s: %s %s, saga is %s at %s", saga.OrderID, step, record.Outcome, saga.Status, saga.Step)
+	if err != nil {
+		log.Printf("saga %s: %s error: %v", saga.OrderID, step, err)
+	}
+
+	o.save(ctx, saga, record)
+}
+
+// compensate starts undoing the saga after the forward step failed for good
+func (o *SagaOrchestrator) compensate(saga *models.Saga, step, reason string, now time.Time) {
+	saga.Status = models.SagaCompensating
+	saga.Step = models.CompensationFor(step)
+	saga.Attempts = 0
+	saga.Reason = reason
+	saga.NextRunAt = now
+}
+
+func (o *SagaOrchestrator) save(ctx context.Context, saga *models.Saga, step *models.SagaStep) {
+	// If this fails the lease runs out and the step is run again, every step can be repeated safely
+	if err := o.repo.SaveSaga(ctx, saga, step); err != nil {
+		log.Printf("saga %s: failed to save: %v", saga.OrderID, err)
+	}
+}
+
+func (o *SagaOrchestrator) execute(ctx context.Context, saga *models.Saga) error {
+	if !models.IsCompensation(saga.Step) {
+		// An order cancelled while the saga was running has nothing left to go forward to
+		cancelled, err := o.orderCancelled(ctx, saga)
+		if err != nil {
+			return err
+		}
+		if cancelled {
+			return &sagaFailure{reason: "order was cancelled"}
+		}
+	}
+
+	switch saga.Step {
+	case models.SagaStepReserveStock:
+		return o.reserveStock(ctx, saga)
+	case models.SagaStepCreatePayment:
+		return o.createPayment(ctx, saga)
+	case models.SagaStepAwaitPayment:
+		return o.awaitPayment(ctx, saga)
+	case models.SagaStepMarkPaid:
+		return o.markPaid(ctx, saga)
+	case models.SagaStepCancelPayment:
+		return o.cancelPayment(ctx, saga)
+	case models.SagaStepReleaseStock:
+		return o.releaseStock(ctx, saga)
+	case models.SagaStepCancelOrder:
+		return o.cancelOrder(ctx, saga)
+	default:
+		return fmt.Errorf("unknown saga step %s", saga.Step)
+	}
+}
+
+func (o *SagaOrchestrator) reserveStock(ctx context.Context, saga *models.Saga) error {
+	order, err := o.repo.GetOrderByID(ctx, saga.OrderID.String())
+	if err != nil {
+		return err
+	}
+
+	req := &proto.ReserveStockRequest{OrderId: saga.OrderID.String()}
+	for _, product := range order.Products {
+		req.Items = append(req.Items, &proto.StockItem{ProductId: product.ID, Quantity: int64(product.Quantity)})
+	}
+
+	_, err = o.orders.stockService.ReserveStock(ctx, req)
+	if status.Code(err) == codes.FailedPrecondition || status.Code(err) == codes.InvalidArgument {
+		return &sagaFailure{reason: "stock could not be reserved: " + status.Convert(err).Message()}
+	}
+	return err
+}
+
+func (o *SagaOrchestrator) createPayment(ctx context.Context, saga *models.Saga) error {
+	order, err := o.repo.GetOrderByID(ctx, saga.OrderID.String())
+	if err != nil {
+		return err
+	}
+
+	payment, err := o.orders.paymentService.CreatePayment(ctx, &proto.CreatePaymentRequest{
+		OrderId: saga.OrderID.String(),
+		Amount:  toProtoMoney(order.Total),
+	})
+	if status.Code(err) == codes.InvalidArgument {
+		return &sagaFailure{reason: "payment could not be created: " + status.Convert(err).Message()}
+	}
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(o.paymentTimeout)
+	saga.PaymentID = payment.Id
+	saga.Deadline = &deadline
+
+	return nil
+}
+
+func (o *SagaOrchestrator) awaitPayment(ctx context.Context, saga *models.Saga) error {
+	payment, err := o.orders.paymentService.GetPayment(ctx, &proto.GetPaymentRequest{OrderId: saga.OrderID.String()})
+	if err != nil {
+		return err
+	}
+
+	switch payment.Status {
+	case "SUCCEEDED":
+		return nil
+	case "FAILED", "CANCELLED":
+		return &sagaFailure{reason: "payment " + payment.Status}
+	}
+
+	if saga.Deadline != nil && time.Now().After(*saga.Deadline) {
+		return &sagaFailure{reason: "payment timed out"}
+	}
+
+	return errPaymentPending
+}
+
+func (o *SagaOrchestrator) markPaid(ctx context.Context, saga *models.Saga) error {
+	_, err := o.orders.transition(ctx, saga.OrderID.String(), models.OrderStatusPaid, models.ActorSystem, "payment succeeded")
+
+	var transitionErr *TransitionError
+	if errors.As(err, &transitionErr) {
+		// Already paid when the step is repeated
+		if transitionErr.From == models.OrderStatusName(models.OrderStatusPaid) {
+			return nil
+		}
+		return &sagaFailure{reason: "order was " + transitionErr.From + " when the payment succeeded"}
+	}
+	return err
+}
+
+func (o *SagaOrchestrator) cancelPayment(ctx context.Context, saga *models.Saga) error {
+	_, err := o.orders.paymentService.CancelPayment(ctx, &proto.CancelPaymentRequest{OrderId: saga.OrderID.String()})
+	switch status.Code(err) {
+	case codes.FailedPrecondition:
+		// A paid order that was cancelled is refunded, otherwise the order goes through after all
+		cancelled, err := o.orderCancelled(ctx, saga)
+		if err != nil {
+			return err
+		}
+		if !cancelled {
+			return errPaymentCaptured
+		}
+		return o.refundPayment(ctx, saga)
+	case codes.NotFound:
+		// The payment was never created
+		return nil
+	}
+	return err
+}
+
+// refundPayment refunds all of the payment of a cancelled order
+func (o *SagaOrchestrator) refundPayment(ctx context.Context, saga *models.Saga) error {
+	order, err := o.repo.GetOrderByID(ctx, saga.OrderID.String())
+	if err != nil {
+		return err
+	}
+
+	_, err = o.orders.paymentService.RefundPayment(ctx, &proto.RefundPaymentRequest{
+		OrderId:   saga.OrderID.String(),
+		Amount:    toProtoMoney(order.Total),
+		Reference: "cancellation",
+	})
+	return err
+}
+
+func (o *SagaOrchestrator) orderCancelled(ctx context.Context, saga *models.Saga) (bool, error) {
+	order, err := o.repo.GetOrderByID(ctx, saga.OrderID.String())
+	if err != nil {
+		return false, err
+	}
+	return order.Status == models.OrderStatusName(models.OrderStatusCancelled), nil
+}
+
+func (o *SagaOrchestrator) releaseStock(ctx context.Context, saga *models.Saga) error {
+	_, err := o.orders.stockService.ReleaseStock(ctx, &proto.ReleaseStockRequest{OrderId: saga.OrderID.String()})
+	return err
+}
+
+func (o *SagaOrchestrator) cancelOrder(ctx context.Context, saga *models.Saga) error {
+	_, err := o.orders.transition(ctx, saga.OrderID.String(), models.OrderStatusCancelled, models.ActorSystem, saga.Reason)
+
+	// Already cancelled when the step is repeated
+	var transitionErr *TransitionError
+	if errors.As(err, &transitionErr) {
+		return nil
+	}
+	return err
+}
+
+// backoff doubles the delay between retries of a step, starting at a second
+func backoff(attempts int) time.Duration {
+	d := time.Second << min(attempts-1, 16)
+	if d > sagaMaxBackoff {
+		return sagaMaxBackoff
+	}
+	return d
+}
@@ -0,0 +1,258 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/order-service/proto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (r *fakeRepo) ClaimSagas(ctx context.Context, limit int, lease time.Duration) ([]models.Saga, error) {
+	var sagas []models.Saga
+	for _, saga := range r.sagas {
+		if saga.Status == models.SagaRunning || saga.Status == models.SagaCompensating {
+			sagas = append(sagas, *saga)
+		}
+	}
+	return sagas, nil
+}
+
+func (r *fakeRepo) SaveSaga(ctx context.Context, saga *models.Saga, step *models.SagaStep) error {
+	copied := *saga
+	r.sagas[saga.OrderID.String()] = &copied
+	if step != nil {
+		r.sagaSteps = append(r.sagaSteps, *step)
+	}
+	return nil
+}
+
+// fakeStock reserves and releases stock, or fails with reserveErr
+type fakeStock struct {
+	proto.StockServiceClient
+	reserveErr error
+	reserved   int
+	released   int
+}
+
+func (s *fakeStock) ReserveStock(ctx context.Context, in *proto.ReserveStockRequest, opts ...grpc.CallOption) (*proto.ReserveStockResponse, error) {
+	if s.reserveErr != nil {
+		return nil, s.reserveErr
+	}
+	s.reserved++
+	return &proto.ReserveStockResponse{}, nil
+}
+
+func (s *fakeStock) ReleaseStock(ctx context.Context, in *proto.ReleaseStockRequest, opts ...grpc.CallOption) (*proto.ReleaseStockResponse, error) {
+	s.released++
+	return &proto.ReleaseStockResponse{}, nil
+}
+
+// fakePayments answers CancelPayment with cancelErr and records the refunds
+type fakePayments struct {
+	proto.PaymentServiceClient
+	cancelErr error
+	cancelled int
+	refunds   []*proto.RefundPaymentRequest
+}
+
+func (p *fakePayments) CancelPayment(ctx context.Context, in *proto.CancelPaymentRequest, opts ...grpc.CallOption) (*proto.Payment, error) {
+	if p.cancelErr != nil {
+		return nil, p.cancelErr
+	}
+	p.cancelled++
+	return &proto.Payment{OrderId: in.OrderId, Status: "CANCELLED"}, nil
+}
+
+func (p *fakePayments) RefundPayment(ctx context.Context, in *proto.RefundPaymentRequest, opts ...grpc.CallOption) (*proto.Refund, error) {
+	p.refunds = append(p.refunds, in)
+	return &proto.Refund{}, nil
+}
+
+// newSagaTest returns an orchestrator with an order in the status and its saga at the step
+func newSagaTest(orderStatus int, sagaStatus, step string) (*service.SagaOrchestrator, *fakeRepo, *fakeStock, *fakePayments, string) {
+	order := &models.OrderResponse{ID: uuid.New(), UserID: ownerID, Status: models.OrderStatusName(orderStatus), Total: money.New(2500, "USD")}
+	repo := &fakeRepo{
+		orders: map[string]*models.OrderResponse{order.ID.String(): order},
+		sagas:  map[string]*models.Saga{order.ID.String(): {OrderID: order.ID, Status: sagaStatus, Step: step}},
+	}
+	stock := &fakeStock{}
+	payments := &fakePayments{}
+	orders := service.NewOrderService(repo, nil, nil, nil, stock, payments, nil, nil, "USD", newFakeAuth())
+
+	return service.NewSagaOrchestrator(orders, repo, time.Hour), repo, stock, payments, order.ID.String()
+}
+
+func TestSagaRetriesStepBeforeCompensating(t *testing.T) {
+	o, repo, stock, _, orderID := newSagaTest(models.OrderStatusPendingPayment, models.SagaRunning, models.SagaStepReserveStock)
+	stock.reserveErr = status.Error(codes.Unavailable, "stock service is down")
+
+	for i := 1; i < service.SagaMaxAttempts; i++ {
+		o.RunDue(context.Background())
+
+		saga := repo.sagas[orderID]
+		require.Equal(t, models.SagaRunning, saga.Status)
+		assert.Equal(t, models.SagaStepReserveStock, saga.Step)
+		assert.Equal(t, i, saga.Attempts)
+		assert.True(t, saga.NextRunAt.After(time.Now()), "the step is retried after a backoff")
+		assert.Equal(t, models.SagaStepRetrying, repo.sagaSteps[len(repo.sagaSteps)-1].Outcome)
+	}
+
+	o.RunDue(context.Background())
+
+	saga := repo.sagas[orderID]
+	assert.Equal(t, models.SagaCompensating, saga.Status)
+	assert.Equal(t, models.SagaStepReleaseStock, saga.Step, "nothing was paid, so only the stock and the order are undone")
+	assert.Equal(t, 0, saga.Attempts)
+	assert.Contains(t, saga.Reason, "stock service is down")
+	assert.Equal(t, models.SagaStepFailed, repo.sagaSteps[len(repo.sagaSteps)-1].Outcome)
+	assert.Len(t, repo.sagaSteps, service.SagaMaxAttempts)
+
+	// The compensation runs to the end
+	o.RunDue(context.Background())
+	o.RunDue(context.Background())
+
+	saga = repo.sagas[orderID]
+	assert.Equal(t, models.SagaCompensated, saga.Status)
+	assert.Equal(t, 1, stock.released)
+	assert.Equal(t, models.OrderStatusName(models.OrderStatusCancelled), repo.orders[orderID].Status)
+}
+
+func TestSagaFailureCompensatesRightAway(t *testing.T) {
+	o, repo, stock, _, orderID := newSagaTest(models.OrderStatusPendingPayment, models.SagaRunning, models.SagaStepReserveStock)
+	stock.reserveErr = status.Error(codes.FailedPrecondition, "out of stock")
+
+	o.RunDue(context.Background())
+
+	saga := repo.sagas[orderID]
+	assert.Equal(t, models.SagaCompensating, saga.Status)
+	assert.Equal(t, models.SagaStepReleaseStock, saga.Step)
+	assert.Equal(t, "stock could not be reserved: out of stock", saga.Reason)
+	require.Len(t, repo.sagaSteps, 1)
+	assert.Equal(t, models.SagaStepFailed, repo.sagaSteps[0].Outcome)
+}
+
+func TestSagaCancelPayment(t *testing.T) {
+	captured := status.Error(codes.FailedPrecondition, "payment already succeeded")
+
+	testCases := []struct {
+		name        string
+		orderStatus int
+		cancelErr   error
+		wantStatus  string
+		wantStep    string
+		wantRefunds int
+	}{
+		{
+			name:        "Pending payment is cancelled",
+			orderStatus: models.OrderStatusPendingPayment,
+			wantStatus:  models.SagaCompensating,
+			wantStep:    models.SagaStepReleaseStock,
+		},
+		{
+			// The customer paid while the saga was giving up on the payment
+			name:        "Payment captured meanwhile resumes the saga",
+			orderStatus: models.OrderStatusPendingPayment,
+			cancelErr:   captured,
+			wantStatus:  models.SagaRunning,
+			wantStep:    models.SagaStepAwaitPayment,
+		},
+		{
+			name:        "Paid order that was cancelled is refunded",
+			orderStatus: models.OrderStatusCancelled,
+			cancelErr:   captured,
+			wantStatus:  models.SagaCompensating,
+			wantStep:    models.SagaStepReleaseStock,
+			wantRefunds: 1,
+		},
+		{
+			name:        "Payment that was never created",
+			orderStatus: models.OrderStatusPendingPayment,
+			cancelErr:   status.Error(codes.NotFound, "payment not found"),
+			wantStatus:  models.SagaCompensating,
+			wantStep:    models.SagaStepReleaseStock,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o, repo, _, payments, orderID := newSagaTest(tc.orderStatus, models.SagaCompensating, models.SagaStepCancelPayment)
+			repo.sagas[orderID].Reason = "payment timed out"
+			payments.cancelErr = tc.cancelErr
+
+			o.RunDue(context.Background())
+
+			saga := repo.sagas[orderID]
+			assert.Equal(t, tc.wantStatus, saga.Status)
+			assert.Equal(t, tc.wantStep, saga.Step)
+			require.Len(t, payments.refunds, tc.wantRefunds)
+			for _, refund := range payments.refunds {
+				assert.Equal(t, int64(2500), refund.Amount.Amount, "the whole payment is refunded")
+				assert.Equal(t, "USD", refund.Amount.Currency)
+				assert.Equal(t, "cancellation", refund.Reference)
+			}
+			if tc.wantStatus == models.SagaRunning {
+				assert.Empty(t, saga.Reason)
+			}
+		})
+	}
+}
+
+func TestSagaStepsCanBeRepeated(t *testing.T) {
+	testCases := []struct {
+		name        string
+		orderStatus int
+		sagaStatus  string
+		step        string
+		wantOrder   int
+		wantSaga    string
+	}{
+		{
+			name:        "Mark paid",
+			orderStatus: models.OrderStatusPendingPayment,
+			sagaStatus:  models.SagaRunning,
+			step:        models.SagaStepMarkPaid,
+			wantOrder:   models.OrderStatusPaid,
+			wantSaga:    models.SagaCompleted,
+		},
+		{
+			name:        "Cancel order",
+			orderStatus: models.OrderStatusPendingPayment,
+			sagaStatus:  models.SagaCompensating,
+			step:        models.SagaStepCancelOrder,
+			wantOrder:   models.OrderStatusCancelled,
+			wantSaga:    models.SagaCompensated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o, repo, _, _, orderID := newSagaTest(tc.orderStatus, tc.sagaStatus, tc.step)
+
+			for range 2 {
+				// As if saving the saga failed and the step was claimed again after the lease
+				repo.sagas[orderID].Status = tc.sagaStatus
+				repo.sagas[orderID].Step = tc.step
+
+				o.RunDue(context.Background())
+
+				assert.Equal(t, tc.wantSaga, repo.sagas[orderID].Status)
+				assert.Equal(t, models.OrderStatusName(tc.wantOrder), repo.orders[orderID].Status)
+			}
+
+			assert.Len(t, repo.changes, 1, "the status is changed once")
+			for _, step := range repo.sagaSteps {
+				assert.Equal(t, models.SagaStepSucceeded, step.Outcome)
+			}
+		})
+	}
+}
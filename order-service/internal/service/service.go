@@ -2,87 +2,247 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strconv"
+	"time"
 
+	"github.com/NeGat1FF/e-commerce/money"
 	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
 	"github.com/NeGat1FF/e-commerce/order-service/internal/repository"
-	"github.com/NeGat1FF/e-commerce/order-service/internal/utils"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/shipping"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/tax"
 	"github.com/NeGat1FF/e-commerce/order-service/proto"
 	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+var (
+	ErrAddressNotFound  = errors.New("shipping address not found")
+	ErrUnknownStatus    = errors.New("unknown order status")
+	ErrNotCancellable   = errors.New("order can no longer be cancelled")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrPriceCurrency    = errors.New("price is not in the currency of the shop")
+)
+
+// TransitionError is returned when an order or a return cannot move to the requested status,
+// it lists the statuses it can move to instead
+type TransitionError struct {
+	From    string
+	To      string
+	Allowed []string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot go from %s to %s", e.From, e.To)
+}
+
 type PriceServiceInterface interface {
-	GetPrice(ctx context.Context, productID int64) (float64, error)
+	GetPrice(ctx context.Context, productID int64) (money.Money, error)
 }
 
 type OrderService struct {
-	repo         repository.OrderRepoInterface
-	priceService proto.PriceServiceClient
-	secret       string
+	repo           repository.OrderRepoInterface
+	priceService   proto.PriceServiceClient
+	addressService proto.AddressServiceClient
+	cartService    proto.CartServiceClient
+	stockService   proto.StockServiceClient
+	paymentService proto.PaymentServiceClient
+	taxEngine      tax.Engine
+	shipping       *shipping.Calculator
+	currency       string
+	auth           Authenticator
 }
 
-func NewOrderService(repo repository.OrderRepoInterface, priceService proto.PriceServiceClient, secret string) *OrderService {
+func NewOrderService(repo repository.OrderRepoInterface, priceService proto.PriceServiceClient, addressService proto.AddressServiceClient, cartService proto.CartServiceClient, stockService proto.StockServiceClient, paymentService proto.PaymentServiceClient, taxEngine tax.Engine, shippingCalculator *shipping.Calculator, currency string, auth Authenticator) *OrderService {
 	return &OrderService{
-		repo:         repo,
-		priceService: priceService,
-		secret:       secret,
+		repo:           repo,
+		priceService:   priceService,
+		addressService: addressService,
+		cartService:    cartService,
+		stockService:   stockService,
+		paymentService: paymentService,
+		taxEngine:      taxEngine,
+		shipping:       shippingCalculator,
+		currency:       currency,
+		auth:           auth,
 	}
 }
 
 // CreateOrder creates a new order
-func (s *OrderService) CreateOrder(ctx context.Context, jwt string, items models.Products) (*models.OrderResponse, error) {
-	claims, err := utils.ValidateToken(jwt, s.secret)
+func (s *OrderService) CreateOrder(ctx context.Context, jwt string, req models.CreateOrderRequest) (*models.OrderResponse, error) {
+	claims, err := s.authenticate(ctx, jwt)
 	if err != nil {
 		return nil, err
 	}
 
-	var OrderItems []*models.OrderItem
-
-	var total float64
-
-	userUid, err := uuid.Parse(claims["uid"].(string))
+	OrderItems, err := s.priceItems(ctx, req.Products)
 	if err != nil {
 		return nil, err
 	}
 
-	order := &models.Order{
-		ID:     uuid.New(),
-		UserID: userUid,
-		Status: models.OrderStatusPending,
-	}
+	return s.placeOrder(ctx, claims.UserID, OrderItems, req.ShippingAddressID, req.ShippingMethod, req.CouponCodes)
+}
 
-	for _, item := range items {
+// priceItems returns the products as order items at their current prices
+func (s *OrderService) priceItems(ctx context.Context, products models.Products) ([]*models.OrderItem, error) {
+	var items []*models.OrderItem
+
+	for _, item := range products {
 		priceRes, err := s.priceService.GetPrice(ctx, &proto.PriceRequest{ProductId: fmt.Sprintf("%d", item.ID)})
 		if err != nil {
 			return nil, err
 		}
-		price, err := strconv.ParseFloat(priceRes.Price, 64)
+		price, err := s.price(priceRes.Price)
 		if err != nil {
 			return nil, err
 		}
-		OrderItems = append(OrderItems, &models.OrderItem{
-			OrderID:   order.ID,
-			ProductID: item.ID,
-			Quantity:  item.Quantity,
-			Price:     price,
+		items = append(items, &models.OrderItem{
+			ProductID:   item.ID,
+			Quantity:    item.Quantity,
+			Price:       price,
+			Category:    priceRes.Category,
+			TaxCategory: priceRes.TaxCategory,
+			Size:        itemSize(priceRes),
 		})
+	}
+
+	return items, nil
+}
+
+// price returns a price of the product service, products priced in another currency than the
+// one of the shop cannot be ordered
+func (s *OrderService) price(p *proto.Money) (money.Money, error) {
+	price := money.New(p.GetAmount(), p.GetCurrency())
+	if price.Currency != s.currency {
+		return money.Money{}, fmt.Errorf("%w: %s", ErrPriceCurrency, price)
+	}
+	return price, nil
+}
+
+func toProtoMoney(m money.Money) *proto.Money {
+	return &proto.Money{Amount: m.Amount, Currency: m.Currency}
+}
+
+// placeOrder creates a pending order of the user with the given items at their prices, discounted by
+// the promotions that apply, taxed for the address the order is shipped to and with the cost of the
+// shipping method
+func (s *OrderService) placeOrder(ctx context.Context, userID string, items []*models.OrderItem, shippingAddressID, shippingMethod string, couponCodes []string) (*models.OrderResponse, error) {
+	userUid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &models.Order{
+		ID:           uuid.New(),
+		UserID:       userUid,
+		Status:       models.OrderStatusPendingPayment,
+		ShippingCost: money.Zero(s.currency),
+	}
 
-		total += price * float64(item.Quantity)
+	if shippingAddressID != "" {
+		order.ShippingAddress, err = s.getAddress(ctx, shippingAddressID, userUid.String())
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	order.Total = total
+	if err := s.calculateShipping(order, items, shippingMethod); err != nil {
+		return nil, err
+	}
 
-	res, err := s.repo.CreateOrder(ctx, order, OrderItems)
+	discounts, shippingDiscount, err := s.applyPromotions(ctx, order, items, couponCodes)
 	if err != nil {
 		return nil, err
 	}
 
-	return res, nil
+	if err := s.calculateTax(ctx, order, items); err != nil {
+		return nil, err
+	}
+	order.Total = order.Total.Add(order.ShippingCost).Sub(shippingDiscount)
+
+	now := time.Now()
+
+	change := &models.OrderStatusChange{
+		ID:        uuid.New(),
+		OrderID:   order.ID,
+		ToStatus:  order.Status,
+		Actor:     userID,
+		Reason:    "order placed",
+		CreatedAt: now,
+	}
+
+	// The saga reserves the stock and takes the payment, it is picked up by the orchestrator
+	saga := &models.Saga{
+		OrderID:   order.ID,
+		Status:    models.SagaRunning,
+		Step:      models.SagaStepReserveStock,
+		NextRunAt: now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	return s.repo.CreateOrder(ctx, order, items, discounts, change, saga)
 }
 
-// GetOrder returns an order by its ID
-func (s *OrderService) GetOrder(ctx context.Context, orderID string) (*models.OrderResponse, error) {
+// calculateTax sets the taxes and totals of an order and its items
+func (s *OrderService) calculateTax(ctx context.Context, order *models.Order, items []*models.OrderItem) error {
+	req := tax.Request{OrderID: order.ID.String()}
+	if order.ShippingAddress != nil {
+		req.Country = order.ShippingAddress.Country
+		req.Region = order.ShippingAddress.Region
+	}
+	for _, item := range items {
+		req.Lines = append(req.Lines, tax.Line{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			UnitPrice: item.Price,
+			Discount:  item.Discount,
+			Category:  item.TaxCategory,
+		})
+	}
+
+	res, err := s.taxEngine.Calculate(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if len(res.Lines) != len(items) {
+		return fmt.Errorf("tax engine returned %d lines for %d items", len(res.Lines), len(items))
+	}
+
+	for i, item := range items {
+		item.OrderID = order.ID
+		item.TaxRate = res.Lines[i].Rate
+		item.Tax = res.Lines[i].Tax
+		item.Total = res.Lines[i].Total
+	}
+
+	// The result has no amounts without lines, they are zero in the currency of the shop then
+	zero := money.Zero(s.currency)
+	order.Subtotal = zero.Add(res.Subtotal)
+	order.Tax = zero.Add(res.Tax)
+	order.Total = zero.Add(res.Total)
+	order.PricesIncludeTax = res.PricesIncludeTax
+	order.TaxBreakdown = models.TaxBreakdown{}
+	for _, c := range res.Breakdown {
+		order.TaxBreakdown = append(order.TaxBreakdown, models.TaxAmount{Name: c.Name, Rate: c.Rate, Taxable: c.Taxable, Amount: c.Amount})
+	}
+
+	return nil
+}
+
+// GetOrder returns an order to its customer or to staff that can read orders
+func (s *OrderService) GetOrder(ctx context.Context, jwt, orderID string) (*models.OrderResponse, error) {
+	return s.readableOrder(ctx, jwt, orderID)
+}
+
+// getOrder returns an order by its ID
+func (s *OrderService) getOrder(ctx context.Context, orderID string) (*models.OrderResponse, error) {
+	if uuid.Validate(orderID) != nil {
+		return nil, repository.ErrOrderNotFound
+	}
+
 	res, err := s.repo.GetOrderByID(ctx, orderID)
 	if err != nil {
 		return nil, err
@@ -93,25 +253,181 @@ func (s *OrderService) GetOrder(ctx context.Context, orderID string) (*models.Or
 
 // GetOrders returns all orders
 func (s *OrderService) GetOrders(ctx context.Context, jwt string) ([]*models.OrderResponse, error) {
-	claims, err := utils.ValidateToken(jwt, s.secret)
+	claims, err := s.authenticate(ctx, jwt)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.repo.GetOrders(ctx, claims.UserID)
 	if err != nil {
 		return nil, err
 	}
 
-	uid, ok := claims["uid"].(string)
+	return res, nil
+}
+
+// UpdateOrderStatus moves an order to the status with the given name on behalf of staff.
+// To users that cannot change orders the order is not found.
+func (s *OrderService) UpdateOrderStatus(ctx context.Context, jwt, orderID, status, reason string) (*models.OrderResponse, error) {
+	claims, allowed, err := s.can(ctx, jwt, PermissionWriteOrders)
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowed {
+		return nil, repository.ErrOrderNotFound
+	}
+
+	to, ok := models.ParseOrderStatus(status)
 	if !ok {
-		return nil, fmt.Errorf("uid not found in claims")
+		return nil, ErrUnknownStatus
 	}
 
-	res, err := s.repo.GetOrders(ctx, uid)
+	return s.transition(ctx, orderID, to, claims.UserID, reason)
+}
+
+// CancelOrder cancels an order of the user of the token before its fulfilment starts.
+// The saga of the order releases its stock and refunds it if it was paid.
+func (s *OrderService) CancelOrder(ctx context.Context, jwt, orderID, reason string) (*models.OrderResponse, error) {
+	claims, err := s.authenticate(ctx, jwt)
 	if err != nil {
 		return nil, err
 	}
 
-	return res, nil
+	order, err := s.ownOrder(ctx, claims.UserID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, _ := models.ParseOrderStatus(order.Status)
+	if !models.CustomerCanCancel(from) {
+		return nil, ErrNotCancellable
+	}
+
+	if reason == "" {
+		reason = "cancelled by customer"
+	}
+
+	return s.transition(ctx, orderID, models.OrderStatusCancelled, claims.UserID, reason)
+}
+
+// GetStatusHistory returns the status changes of an order to its customer or to staff, oldest first
+func (s *OrderService) GetStatusHistory(ctx context.Context, jwt, orderID string) ([]models.StatusHistoryEntry, error) {
+	if _, err := s.readableOrder(ctx, jwt, orderID); err != nil {
+		return nil, err
+	}
+
+	history, err := s.repo.GetStatusHistory(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.StatusHistoryEntry, 0, len(history))
+	for _, change := range history {
+		entry := models.StatusHistoryEntry{
+			To:        models.OrderStatusName(change.ToStatus),
+			Actor:     change.Actor,
+			Reason:    change.Reason,
+			CreatedAt: change.CreatedAt,
+		}
+		if change.FromStatus != nil {
+			entry.From = models.OrderStatusName(*change.FromStatus)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// GetSaga returns the saga of an order with the steps it took so far to its customer or to staff
+func (s *OrderService) GetSaga(ctx context.Context, jwt, orderID string) (*models.SagaResponse, error) {
+	if _, err := s.readableOrder(ctx, jwt, orderID); err != nil {
+		return nil, err
+	}
+
+	saga, err := s.repo.GetSaga(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := s.repo.GetSagaSteps(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SagaResponse{Saga: *saga, Steps: steps}, nil
+}
+
+// transition moves an order to a status if the lifecycle allows it and records who did it and why
+func (s *OrderService) transition(ctx context.Context, orderID string, to int, actor, reason string) (*models.OrderResponse, error) {
+	order, err := s.getOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, ok := models.ParseOrderStatus(order.Status)
+	if !ok {
+		return nil, fmt.Errorf("order %s has unknown status %s", orderID, order.Status)
+	}
+
+	if !models.CanTransition(from, to) {
+		return nil, &TransitionError{
+			From:    order.Status,
+			To:      models.OrderStatusName(to),
+			Allowed: models.NextOrderStatuses(from),
+		}
+	}
+
+	return s.repo.UpdateOrderStatus(ctx, &models.OrderStatusChange{
+		ID:         uuid.New(),
+		OrderID:    order.ID,
+		FromStatus: &from,
+		ToStatus:   to,
+		Actor:      actor,
+		Reason:     reason,
+		CreatedAt:  time.Now(),
+	})
+}
+
+// ownOrder returns an order of the user, the orders of other users are not found
+func (s *OrderService) ownOrder(ctx context.Context, userID, orderID string) (*models.OrderResponse, error) {
+	order, err := s.getOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.UserID.String() != userID {
+		return nil, repository.ErrOrderNotFound
+	}
+
+	return order, nil
+}
+
+// getAddress takes a snapshot of the user's address from the user service
+func (s *OrderService) getAddress(ctx context.Context, addressID, userID string) (*models.Address, error) {
+	address, err := s.addressService.GetAddress(ctx, &proto.GetAddressRequest{AddressId: addressID, UserId: userID})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, ErrAddressNotFound
+		}
+		return nil, err
+	}
+
+	return &models.Address{
+		ID:         address.Id,
+		Label:      address.Label,
+		FullName:   address.FullName,
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		Region:     address.Region,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+		Phone:      address.Phone,
+	}, nil
 }
 
-// UpdateOrderStatus updates the status of an order
-func (s *OrderService) UpdateOrderStatus(ctx context.Context, orderID string, status int) (*models.OrderResponse, error) {
-	return s.repo.UpdateOrderStatus(ctx, orderID, status)
+// GetUserOrders returns all orders of a user by user ID
+func (s *OrderService) GetUserOrders(ctx context.Context, userID string) ([]*models.OrderResponse, error) {
+	return s.repo.GetOrders(ctx, userID)
 }
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/order-service/internal/carrier"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotShippable          = errors.New("order cannot be shipped")
+	ErrInvalidShipmentItems  = errors.New("items are not part of the order or were already shipped")
+	ErrInvalidShipment       = errors.New("carrier and tracking number are required")
+	ErrUnknownShipmentStatus = errors.New("unknown shipment status")
+	ErrShipmentClosed        = errors.New("shipment was delivered or cancelled")
+)
+
+// CreateShipment adds a shipment with some or all of the unshipped lines of a paid order on behalf of staff
+func (s *OrderService) CreateShipment(ctx context.Context, jwt, orderID string, req models.CreateShipmentRequest) (*models.Shipment, error) {
+	_, allowed, err := s.can(ctx, jwt, PermissionWriteOrders)
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowed {
+		return nil, repository.ErrOrderNotFound
+	}
+
+	order, err := s.getOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	status, _ := models.ParseOrderStatus(order.Status)
+	if status != models.OrderStatusPaid && status != models.OrderStatusProcessing && status != models.OrderStatusPartiallyShipped {
+		return nil, ErrNotShippable
+	}
+
+	if req.Carrier == "" || req.TrackingNumber == "" {
+		return nil, ErrInvalidShipment
+	}
+
+	if len(req.Items) == 0 {
+		return nil, ErrInvalidShipmentItems
+	}
+
+	items, err := s.repo.GetOrderItems(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	shipments, err := s.repo.GetShipments(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	unshipped := models.UnshippedQuantities(items, shipments)
+	for _, item := range req.Items {
+		if item.Quantity < 1 || item.Quantity > unshipped[item.ProductID] {
+			return nil, ErrInvalidShipmentItems
+		}
+		unshipped[item.ProductID] -= item.Quantity
+	}
+
+	now := time.Now()
+	shipment := &models.Shipment{
+		ID:             uuid.New(),
+		OrderID:        order.ID,
+		Carrier:        req.Carrier,
+		TrackingNumber: req.TrackingNumber,
+		Status:         models.ShipmentStatusPending,
+		Items:          req.Items,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	event := models.ShipmentEvent{
+		ID:          uuid.New(),
+		ShipmentID:  shipment.ID,
+		Status:      shipment.Status,
+		Description: "shipment created",
+		OccurredAt:  now,
+		CreatedAt:   now,
+	}
+
+	if err := s.repo.CreateShipment(ctx, shipment, &event); err != nil {
+		return nil, err
+	}
+	shipment.Timeline = []models.ShipmentEvent{event}
+
+	s.rollUpFulfilment(ctx, orderID)
+
+	return shipment, nil
+}
+
+// UpdateShipment records a tracking update of a shipment entered by staff
+func (s *OrderService) UpdateShipment(ctx context.Context, jwt, shipmentID string, req models.UpdateShipmentRequest) (*models.Shipment, error) {
+	_, allowed, err := s.can(ctx, jwt, PermissionWriteOrders)
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowed {
+		return nil, repository.ErrShipmentNotFound
+	}
+
+	if uuid.Validate(shipmentID) != nil {
+		return nil, repository.ErrShipmentNotFound
+	}
+
+	shipment, err := s.repo.GetShipment(ctx, shipmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.trackShipment(ctx, shipment, carrier.TrackingUpdate{
+		TrackingNumber: shipment.TrackingNumber,
+		Status:         req.Status,
+		Description:    req.Description,
+		Location:       req.Location,
+		OccurredAt:     time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withTimeline(ctx, shipment)
+}
+
+// TrackShipments records the tracking updates a carrier sent in a webhook. Updates of unknown
+// shipments and of delivered or cancelled ones are skipped, so the carrier does not retry them.
+func (s *OrderService) TrackShipments(ctx context.Context, carrierName string, updates []carrier.TrackingUpdate) error {
+	for _, update := range updates {
+		shipment, err := s.repo.GetShipmentByTracking(ctx, carrierName, update.TrackingNumber)
+		if err == repository.ErrShipmentNotFound {
+			log.Printf("carrier %s: tracking update of unknown shipment %s", carrierName, update.TrackingNumber)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		err = s.trackShipment(ctx, shipment, update)
+		if err == ErrShipmentClosed || err == ErrUnknownShipmentStatus {
+			log.Printf("carrier %s: skipped tracking update of shipment %s: %v", carrierName, update.TrackingNumber, err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetShipments returns the shipments of an order with their timelines to its customer or to staff
+func (s *OrderService) GetShipments(ctx context.Context, jwt, orderID string) ([]models.Shipment, error) {
+	if _, err := s.readableOrder(ctx, jwt, orderID); err != nil {
+		return nil, err
+	}
+
+	shipments, err := s.repo.GetShipments(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range shipments {
+		shipments[i].Timeline, err = s.repo.GetShipmentEvents(ctx, shipments[i].ID.String())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return shipments, nil
+}
+
+// trackShipment adds a tracking update to the timeline of a shipment. The status of the shipment
+// only follows updates newer than its timeline, carriers do not always send them in order.
+// An update already on the timeline is a retried webhook and is not added again.
+func (s *OrderService) trackShipment(ctx context.Context, shipment *models.Shipment, update carrier.TrackingUpdate) error {
+	if !models.IsShipmentStatus(update.Status) {
+		return ErrUnknownShipmentStatus
+	}
+
+	if models.ShipmentClosed(shipment.Status) {
+		return ErrShipmentClosed
+	}
+
+	timeline, err := s.repo.GetShipmentEvents(ctx, shipment.ID.String())
+	if err != nil {
+		return err
+	}
+
+	if slices.ContainsFunc(timeline, func(event models.ShipmentEvent) bool {
+		return event.Status == update.Status && event.Description == update.Description &&
+			event.Location == update.Location && sameInstant(event.OccurredAt, update.OccurredAt)
+	}) {
+		return nil
+	}
+
+	from := shipment.Status
+	if len(timeline) == 0 || !update.OccurredAt.Before(timeline[len(timeline)-1].OccurredAt) {
+		shipment.Status = update.Status
+	}
+	shipment.UpdatedAt = time.Now()
+
+	err = s.repo.UpdateShipment(ctx, shipment, from, &models.ShipmentEvent{
+		ID:          uuid.New(),
+		ShipmentID:  shipment.ID,
+		Status:      update.Status,
+		Description: update.Description,
+		Location:    update.Location,
+		OccurredAt:  update.OccurredAt,
+		CreatedAt:   shipment.UpdatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	if shipment.Status != from {
+		s.rollUpFulfilment(ctx, shipment.OrderID.String())
+	}
+
+	return nil
+}
+
+// sameInstant reports whether the times are equal at the microsecond precision the database keeps
+func sameInstant(a, b time.Time) bool {
+	return a.Round(time.Microsecond).Equal(b.Round(time.Microsecond))
+}
+
+func (s *OrderService) withTimeline(ctx context.Context, shipment *models.Shipment) (*models.Shipment, error) {
+	timeline, err := s.repo.GetShipmentEvents(ctx, shipment.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	shipment.Timeline = timeline
+	return shipment, nil
+}
+
+// rollUpFulfilment moves the order to the status its shipments add up to. The shipments are
+// already saved, so an order that cannot move is only logged and catches up with the next update.
+func (s *OrderService) rollUpFulfilment(ctx context.Context, orderID string) {
+	items, err := s.repo.GetOrderItems(ctx, orderID)
+	if err != nil {
+		log.Printf("order %s: failed to get items: %v", orderID, err)
+		return
+	}
+
+	shipments, err := s.repo.GetShipments(ctx, orderID)
+	if err != nil {
+		log.Printf("order %s: failed to get shipments: %v", orderID, err)
+		return
+	}
+
+	order, err := s.getOrder(ctx, orderID)
+	if err != nil {
+		log.Printf("order %s: failed to get order: %v", orderID, err)
+		return
+	}
+
+	from, _ := models.ParseOrderStatus(order.Status)
+	for _, status := range models.TransitionPath(from, models.FulfilmentStatus(items, shipments)) {
+		if _, err := s.transition(ctx, orderID, status, models.ActorSystem, "shipments updated"); err != nil {
+			log.Printf("order %s: failed to roll up shipments: %v", orderID, err)
+			return
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/order-service/internal/carrier"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/handlers"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (r *fakeRepo) CreateShipment(ctx context.Context, shipment *models.Shipment, event *models.ShipmentEvent) error {
+	copied := *shipment
+	r.shipments = append(r.shipments, &copied)
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *fakeRepo) GetShipment(ctx context.Context, shipmentID string) (*models.Shipment, error) {
+	for _, shipment := range r.shipments {
+		if shipment.ID.String() == shipmentID {
+			copied := *shipment
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrShipmentNotFound
+}
+
+func (r *fakeRepo) GetShipmentByTracking(ctx context.Context, carrierName, trackingNumber string) (*models.Shipment, error) {
+	for _, shipment := range r.shipments {
+		if shipment.Carrier == carrierName && shipment.TrackingNumber == trackingNumber {
+			copied := *shipment
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrShipmentNotFound
+}
+
+func (r *fakeRepo) GetShipments(ctx context.Context, orderID string) ([]models.Shipment, error) {
+	var shipments []models.Shipment
+	for _, shipment := range r.shipments {
+		if shipment.OrderID.String() == orderID {
+			shipments = append(shipments, *shipment)
+		}
+	}
+	return shipments, nil
+}
+
+func (r *fakeRepo) GetShipmentEvents(ctx context.Context, shipmentID string) ([]models.ShipmentEvent, error) {
+	var events []models.ShipmentEvent
+	for _, event := range r.events {
+		if event.ShipmentID.String() == shipmentID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r *fakeRepo) UpdateShipment(ctx context.Context, shipment *models.Shipment, fromStatus string, event *models.ShipmentEvent) error {
+	for i := range r.shipments {
+		if r.shipments[i].ID != shipment.ID {
+			continue
+		}
+		if r.shipments[i].Status != fromStatus {
+			return repository.ErrShipmentChanged
+		}
+		copied := *shipment
+		r.shipments[i] = &copied
+		r.events = append(r.events, *event)
+		return nil
+	}
+	return repository.ErrShipmentNotFound
+}
+
+// newShipmentsTest returns a service with a paid order of two of product 1 and one of product 2,
+// and a handler that takes the webhooks of the fake carrier signed with "secret"
+func newShipmentsTest() (*service.OrderService, http.Handler, *fakeRepo, string) {
+	s, repo, orderID := newTestService()
+	repo.items = map[string][]*models.OrderItem{orderID: {
+		{ProductID: 1, Quantity: 2},
+		{ProductID: 2, Quantity: 1},
+	}}
+
+	h := handlers.NewOrderHandler(s, carrier.NewRegistry(carrier.NewFake("secret")))
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /carriers/{carrier}/webhook", h.CarrierWebhook)
+
+	return s, mux, repo, orderID
+}
+
+// postWebhook posts a tracking update of the fake carrier signed with the secret
+func postWebhook(handler http.Handler, secret, trackingNumber, status string, occurredAt time.Time) int {
+	body := []byte(fmt.Sprintf(`[{"tracking_number": %q, "status": %q, "location": "Berlin", "occurred_at": %q}]`,
+		trackingNumber, status, occurredAt.Format(time.RFC3339)))
+
+	req := httptest.NewRequest(http.MethodPost, "/carriers/"+carrier.FakeName+"/webhook", bytes.NewReader(body))
+	req.Header.Set(carrier.FakeSignatureHeader, carrier.NewFake(secret).Sign(body))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestShipmentsRollUpOrderStatus(t *testing.T) {
+	s, handler, repo, orderID := newShipmentsTest()
+	start := time.Now().UTC().Truncate(time.Second)
+
+	ship := func(trackingNumber string, productID int64, quantity int) func() {
+		return func() {
+			_, err := s.CreateShipment(context.Background(), "writer", orderID, models.CreateShipmentRequest{
+				Carrier:        carrier.FakeName,
+				TrackingNumber: trackingNumber,
+				Items:          models.ShipmentItems{{ProductID: productID, Quantity: quantity}},
+			})
+			require.NoError(t, err)
+		}
+	}
+	track := func(trackingNumber, status string, after time.Duration) func() {
+		return func() {
+			require.Equal(t, http.StatusOK, postWebhook(handler, "secret", trackingNumber, status, start.Add(after)))
+		}
+	}
+
+	steps := []struct {
+		name       string
+		do         func()
+		wantStatus int
+	}{
+		{name: "Part of the order is packed", do: ship("TRK1", 1, 2), wantStatus: models.OrderStatusProcessing},
+		{name: "Part of the order left the warehouse", do: track("TRK1", models.ShipmentStatusInTransit, time.Hour), wantStatus: models.OrderStatusPartiallyShipped},
+		{name: "Rest of the order is packed", do: ship("TRK2", 2, 1), wantStatus: models.OrderStatusPartiallyShipped},
+		{name: "Rest of the order left the warehouse", do: track("TRK2", models.ShipmentStatusInTransit, 2*time.Hour), wantStatus: models.OrderStatusShipped},
+		{name: "Part of the order is delivered", do: track("TRK1", models.ShipmentStatusDelivered, 3*time.Hour), wantStatus: models.OrderStatusShipped},
+		{name: "Whole order is delivered", do: track("TRK2", models.ShipmentStatusDelivered, 4*time.Hour), wantStatus: models.OrderStatusDelivered},
+	}
+
+	for _, step := range steps {
+		step.do()
+		assert.Equal(t, models.OrderStatusName(step.wantStatus), repo.orders[orderID].Status, step.name)
+	}
+
+	// Every status from paid to delivered is in the history once
+	var statuses []int
+	for _, change := range repo.changes {
+		statuses = append(statuses, change.ToStatus)
+		assert.Equal(t, models.ActorSystem, change.Actor)
+	}
+	assert.Equal(t, []int{
+		models.OrderStatusProcessing,
+		models.OrderStatusPartiallyShipped,
+		models.OrderStatusShipped,
+		models.OrderStatusDelivered,
+	}, statuses)
+}
+
+func TestShipmentsWebhookInvalidSignature(t *testing.T) {
+	s, handler, repo, orderID := newShipmentsTest()
+	_, err := s.CreateShipment(context.Background(), "writer", orderID, models.CreateShipmentRequest{
+		Carrier:        carrier.FakeName,
+		TrackingNumber: "TRK1",
+		Items:          models.ShipmentItems{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 1}},
+	})
+	require.NoError(t, err)
+
+	code := postWebhook(handler, "forged", "TRK1", models.ShipmentStatusDelivered, time.Now())
+
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Equal(t, models.ShipmentStatusPending, repo.shipments[0].Status)
+	assert.Len(t, repo.events, 1, "only the creation is on the timeline")
+	assert.Equal(t, models.OrderStatusName(models.OrderStatusProcessing), repo.orders[orderID].Status)
+}
+
+func TestShipmentsWebhookRetried(t *testing.T) {
+	s, handler, repo, orderID := newShipmentsTest()
+	_, err := s.CreateShipment(context.Background(), "writer", orderID, models.CreateShipmentRequest{
+		Carrier:        carrier.FakeName,
+		TrackingNumber: "TRK1",
+		Items:          models.ShipmentItems{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 1}},
+	})
+	require.NoError(t, err)
+
+	inTransit := time.Now().UTC().Add(time.Hour)
+	delivered := inTransit.Add(time.Hour)
+
+	// The carrier sends every update twice, and the delivery once more after the shipment closed
+	for _, update := range []struct {
+		status     string
+		occurredAt time.Time
+	}{
+		{models.ShipmentStatusInTransit, inTransit},
+		{models.ShipmentStatusInTransit, inTransit},
+		{models.ShipmentStatusDelivered, delivered},
+		{models.ShipmentStatusDelivered, delivered},
+	} {
+		require.Equal(t, http.StatusOK, postWebhook(handler, "secret", "TRK1", update.status, update.occurredAt))
+	}
+
+	shipments, err := s.GetShipments(context.Background(), "owner", orderID)
+	require.NoError(t, err)
+	require.Len(t, shipments, 1)
+	assert.Equal(t, models.ShipmentStatusDelivered, shipments[0].Status)
+
+	var timeline []string
+	for _, event := range shipments[0].Timeline {
+		timeline = append(timeline, event.Status)
+	}
+	assert.Equal(t, []string{models.ShipmentStatusPending, models.ShipmentStatusInTransit, models.ShipmentStatusDelivered}, timeline)
+
+	assert.Equal(t, models.OrderStatusName(models.OrderStatusDelivered), repo.orders[orderID].Status)
+	assert.Len(t, repo.changes, 3, "the order moves to shipped and delivered once")
+}
+
+func TestTrackShipmentsSkipsDuplicateUpdateInSameWebhook(t *testing.T) {
+	s, _, repo, orderID := newShipmentsTest()
+	_, err := s.CreateShipment(context.Background(), "writer", orderID, models.CreateShipmentRequest{
+		Carrier:        carrier.FakeName,
+		TrackingNumber: "TRK1",
+		Items:          models.ShipmentItems{{ProductID: 1, Quantity: 1}},
+	})
+	require.NoError(t, err)
+
+	update := carrier.TrackingUpdate{
+		TrackingNumber: "TRK1",
+		Status:         models.ShipmentStatusInTransit,
+		Description:    "Left the warehouse",
+		OccurredAt:     time.Now().Add(time.Hour),
+	}
+	err = s.TrackShipments(context.Background(), carrier.FakeName, []carrier.TrackingUpdate{update, update})
+	require.NoError(t, err)
+
+	assert.Len(t, repo.events, 2, "the update is on the timeline once")
+	assert.Equal(t, models.OrderStatusName(models.OrderStatusPartiallyShipped), repo.orders[orderID].Status)
+}
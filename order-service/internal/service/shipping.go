@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/NeGat1FF/e-commerce/order-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/shipping"
+	"github.com/NeGat1FF/e-commerce/order-service/proto"
+)
+
+var (
+	ErrShippingMethodRequired = errors.New("a shipping method is required for orders with a shipping address")
+	ErrDestinationRequired    = errors.New("a shipping address or country is required")
+)
+
+// QuoteShipping returns the shipping methods available for the products, or the shopping cart
+// of the user if none are given, to the destination with their prices
+func (s *OrderService) QuoteShipping(ctx context.Context, jwt string, req models.ShippingQuoteRequest) ([]models.ShippingQuote, error) {
+	claims, err := s.authenticate(ctx, jwt)
+	if err != nil {
+		return nil, err
+	}
+
+	dest := shipping.Destination{Country: req.Country, Region: req.Region}
+	if req.ShippingAddressID != "" {
+		address, err := s.getAddress(ctx, req.ShippingAddressID, claims.UserID)
+		if err != nil {
+			return nil, err
+		}
+		dest = destination(address)
+	}
+
+	if dest.Country == "" {
+		return nil, ErrDestinationRequired
+	}
+
+	var items []*models.OrderItem
+	if len(req.Products) > 0 {
+		items, err = s.priceItems(ctx, req.Products)
+	} else {
+		items, err = s.cartItems(ctx, claims.UserID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := []models.ShippingQuote{}
+	for _, quote := range s.shipping.Quote(dest, shippingItems(items)) {
+		quotes = append(quotes, models.ShippingQuote{Method: quote.Code, Name: quote.Name, Amount: quote.Amount})
+	}
+
+	return quotes, nil
+}
+
+// cartItems returns the available items of the shopping cart of the user at current prices
+func (s *OrderService) cartItems(ctx context.Context, userID string) ([]*models.OrderItem, error) {
+	cart, err := s.cartService.GetCart(ctx, &proto.GetCartRequest{UserId: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cart.Items) == 0 {
+		return nil, ErrCartEmpty
+	}
+
+	review, err := s.reviewCart(ctx, cart)
+	if err != nil {
+		return nil, err
+	}
+
+	return availableItems(review), nil
+}
+
+// calculateShipping sets the shipping method and cost of an order. Orders without a shipping
+// address are not shipped, the others need a method once shipping methods are configured.
+func (s *OrderService) calculateShipping(order *models.Order, items []*models.OrderItem, method string) error {
+	if order.ShippingAddress == nil {
+		if method != "" {
+			return shipping.ErrMethodUnavailable
+		}
+		return nil
+	}
+
+	if method == "" {
+		if s.shipping.HasMethods() {
+			return ErrShippingMethodRequired
+		}
+		return nil
+	}
+
+	quote, err := s.shipping.Price(method, destination(order.ShippingAddress), shippingItems(items))
+	if err != nil {
+		return err
+	}
+
+	order.ShippingMethod = quote.Code
+	order.ShippingCost = quote.Amount
+
+	return nil
+}
+
+func destination(address *models.Address) shipping.Destination {
+	return shipping.Destination{Country: address.Country, Region: address.Region}
+}
+
+func shippingItems(items []*models.OrderItem) []shipping.Item {
+	parcel := make([]shipping.Item, 0, len(items))
+	for _, item := range items {
+		parcel = append(parcel, shipping.Item{
+			Quantity:  item.Quantity,
+			UnitPrice: item.Price,
+			Weight:    item.Size.Weight,
+			Length:    item.Size.Length,
+			Width:     item.Size.Width,
+			Height:    item.Size.Height,
+		})
+	}
+	return parcel
+}
+
+func itemSize(price *proto.PriceResponse) models.ItemSize {
+	return models.ItemSize{
+		Weight: price.Weight,
+		Length: price.Length,
+		Width:  price.Width,
+		Height: price.Height,
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	messagequeue "github.com/NeGat1FF/e-commerce/order-service/internal/messageQueue"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/repository"
+	"github.com/google/uuid"
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// UserDeletedEvent is published by the user service when a user's personal data is erased
+type UserDeletedEvent struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// UserMergedEvent is published by the user service when a guest logs into an existing account
+type UserMergedEvent struct {
+	GuestID uuid.UUID `json:"guest_id"`
+	UserID  uuid.UUID `json:"user_id"`
+}
+
+// UserEventsConsumer reacts to events published by the user service
+type UserEventsConsumer struct {
+	messageQueue *messagequeue.RabbitMQClient
+	repo         repository.OrderRepoInterface
+	queueName    string
+}
+
+func NewUserEventsConsumer(mq *messagequeue.RabbitMQClient, repo repository.OrderRepoInterface, queueName string) *UserEventsConsumer {
+	return &UserEventsConsumer{
+		messageQueue: mq,
+		repo:         repo,
+		queueName:    queueName,
+	}
+}
+
+// Start starts consuming user events in the background
+func (c *UserEventsConsumer) Start() error {
+	ch, err := c.messageQueue.GetConsumeChannel(c.queueName, "", false, false, false)
+	if err != nil {
+		return err
+	}
+	go c.processMessages(ch)
+	return nil
+}
+
+func (c *UserEventsConsumer) processMessages(ch <-chan amqp091.Delivery) {
+	for msg := range ch {
+		switch msg.RoutingKey {
+		case "user.deleted":
+			c.userDeleted(msg)
+		case "user.merged":
+			c.userMerged(msg)
+		default:
+			// Other user events are of no interest to orders
+			msg.Ack(false)
+		}
+	}
+}
+
+// userDeleted removes personal data from the user's orders, the orders themselves are kept for accounting
+func (c *UserEventsConsumer) userDeleted(msg amqp091.Delivery) {
+	var event UserDeletedEvent
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		log.Println("failed to unmarshal user.deleted event: ", err)
+		msg.Reject(false)
+		return
+	}
+
+	if err := c.repo.ScrubUserData(context.Background(), event.UserID.String()); err != nil {
+		log.Println("failed to scrub user data: ", err)
+		msg.Nack(false, !msg.Redelivered)
+		return
+	}
+
+	msg.Ack(false)
+}
+
+// userMerged moves the orders of a guest to the account the guest logged into
+func (c *UserEventsConsumer) userMerged(msg amqp091.Delivery) {
+	var event UserMergedEvent
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		log.Println("failed to unmarshal user.merged event: ", err)
+		msg.Reject(false)
+		return
+	}
+
+	if err := c.repo.ReassignOrders(context.Background(), event.GuestID.String(), event.UserID.String()); err != nil {
+		log.Println("failed to reassign orders: ", err)
+		msg.Nack(false, !msg.Redelivered)
+		return
+	}
+
+	msg.Ack(false)
+}
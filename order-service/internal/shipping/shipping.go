@@ -0,0 +1,237 @@
+// Package shipping prices the shipping of orders. Shipping methods are priced by the zone of
+// the destination and the weight and value of the parcel.
+package shipping
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/NeGat1FF/e-commerce/money"
+)
+
+var (
+	ErrMethodUnavailable = errors.New("shipping method is not available for the destination")
+	ErrInvalidConfig     = errors.New("invalid shipping config")
+)
+
+const (
+	// RateFlat charges the amount of the rate for every parcel
+	RateFlat = "flat"
+	// RateWeight charges the amount of the rate plus the price per kg of the weight rounded up to whole kg
+	RateWeight = "weight"
+)
+
+// defaultVolumetricDivisor turns the volume of an item in cm³ into the weight it is charged as in kg
+const defaultVolumetricDivisor = 5000
+
+// Zone is a group of destinations, entries are country codes such as "DE" or
+// a country and region such as "US-CA"
+type Zone struct {
+	Code         string   `json:"code"`
+	Destinations []string `json:"destinations"`
+}
+
+// Rate is the price of a method in a zone, or anywhere without a zone. Parcels heavier than
+// MaxWeight are not shipped at the rate and parcels worth at least FreeOver ship for free.
+// Amounts are decimals in the currency of the calculator, such as 4.9 for 4.90 EUR.
+type Rate struct {
+	Zone      string  `json:"zone,omitempty"`
+	Type      string  `json:"type"`
+	Amount    float64 `json:"amount"`
+	PerKg     float64 `json:"per_kg,omitempty"`
+	MaxWeight float64 `json:"max_weight,omitempty"`
+	FreeOver  float64 `json:"free_over,omitempty"`
+}
+
+// Method is a shipping method, the first of its rates that ships the parcel to the destination applies
+type Method struct {
+	Code  string `json:"code"`
+	Name  string `json:"name"`
+	Rates []Rate `json:"rates"`
+}
+
+// Config configures the shipping methods:
+//
+//	{"zones": [{"code": "eu", "destinations": ["DE", "FR"]}], "methods": [{"code": "standard", "name": "Standard", "rates": [{"zone": "eu", "type": "weight", "amount": 4.9, "per_kg": 1, "free_over": 50}]}]}
+type Config struct {
+	Zones   []Zone   `json:"zones"`
+	Methods []Method `json:"methods"`
+	// VolumetricDivisor turns the volume of an item in cm³ into its weight in kg, bulky items
+	// are charged by the larger of both. 5000 if unset.
+	VolumetricDivisor float64 `json:"volumetric_divisor,omitempty"`
+}
+
+// Destination is where a parcel is shipped to
+type Destination struct {
+	Country string
+	Region  string
+}
+
+// Item is a line of the parcel, the weight in kg and dimensions in cm are of one item
+type Item struct {
+	Quantity  int
+	UnitPrice money.Money
+	Weight    float64
+	Length    float64
+	Width     float64
+	Height    float64
+}
+
+// Quote is the price of shipping a parcel with a method
+type Quote struct {
+	Code   string
+	Name   string
+	Amount money.Money
+}
+
+type Calculator struct {
+	config   Config
+	currency string
+}
+
+// NewCalculator returns a calculator that prices shipping in the currency, the amounts of the config are in it
+func NewCalculator(config Config, currency string) (*Calculator, error) {
+	if _, err := money.Digits(currency); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	zones := make(map[string]bool, len(config.Zones))
+	for _, zone := range config.Zones {
+		zones[zone.Code] = true
+	}
+
+	codes := make(map[string]bool, len(config.Methods))
+	for _, method := range config.Methods {
+		if method.Code == "" || codes[method.Code] {
+			return nil, fmt.Errorf("%w: methods need a unique code", ErrInvalidConfig)
+		}
+		codes[method.Code] = true
+
+		for _, rate := range method.Rates {
+			if rate.Type != RateFlat && rate.Type != RateWeight {
+				return nil, fmt.Errorf("%w: unknown rate type %q of method %s", ErrInvalidConfig, rate.Type, method.Code)
+			}
+			if rate.Zone != "" && !zones[rate.Zone] {
+				return nil, fmt.Errorf("%w: unknown zone %q of method %s", ErrInvalidConfig, rate.Zone, method.Code)
+			}
+			for _, amount := range []float64{rate.Amount, rate.PerKg, rate.FreeOver} {
+				if _, err := money.FromFloat(amount, currency); err != nil || amount < 0 {
+					return nil, fmt.Errorf("%w: invalid amount %v of method %s", ErrInvalidConfig, amount, method.Code)
+				}
+			}
+		}
+	}
+
+	if config.VolumetricDivisor <= 0 {
+		config.VolumetricDivisor = defaultVolumetricDivisor
+	}
+
+	return &Calculator{config: config, currency: strings.ToUpper(currency)}, nil
+}
+
+// LoadConfig reads the shipping config from a JSON file, without a file there are no methods
+func LoadConfig(path string) (Config, error) {
+	var config Config
+	if path == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+
+	err = json.Unmarshal(data, &config)
+	return config, err
+}
+
+// HasMethods reports whether any shipping method is configured
+func (c *Calculator) HasMethods() bool {
+	return len(c.config.Methods) > 0
+}
+
+// Quote returns the methods that ship the parcel to the destination with their prices
+func (c *Calculator) Quote(dest Destination, items []Item) []Quote {
+	quotes := []Quote{}
+	for _, method := range c.config.Methods {
+		if quote, ok := c.price(method, dest, items); ok {
+			quotes = append(quotes, quote)
+		}
+	}
+	return quotes
+}
+
+// Price returns the price of shipping the parcel to the destination with a method
+func (c *Calculator) Price(code string, dest Destination, items []Item) (*Quote, error) {
+	for _, method := range c.config.Methods {
+		if method.Code != code {
+			continue
+		}
+		if quote, ok := c.price(method, dest, items); ok {
+			return &quote, nil
+		}
+		break
+	}
+	return nil, ErrMethodUnavailable
+}
+
+func (c *Calculator) price(method Method, dest Destination, items []Item) (Quote, bool) {
+	weight, value := c.measure(items)
+
+	for _, rate := range method.Rates {
+		if rate.Zone != "" && !c.inZone(rate.Zone, dest) {
+			continue
+		}
+		if rate.MaxWeight > 0 && weight > rate.MaxWeight {
+			continue
+		}
+
+		quote := Quote{Code: method.Code, Name: method.Name, Amount: c.amount(rate.Amount)}
+		if rate.Type == RateWeight {
+			quote.Amount = quote.Amount.Add(c.amount(rate.PerKg).Mul(int64(math.Ceil(weight))))
+		}
+		if rate.FreeOver > 0 && value.Cmp(c.amount(rate.FreeOver)) >= 0 {
+			quote.Amount = money.Zero(c.currency)
+		}
+
+		return quote, true
+	}
+
+	return Quote{}, false
+}
+
+// measure returns the weight the parcel is charged as and the value of its items
+func (c *Calculator) measure(items []Item) (weight float64, value money.Money) {
+	value = money.Zero(c.currency)
+	for _, item := range items {
+		volumetric := item.Length * item.Width * item.Height / c.config.VolumetricDivisor
+		weight += math.Max(item.Weight, volumetric) * float64(item.Quantity)
+		value = value.Add(item.UnitPrice.Mul(int64(item.Quantity)))
+	}
+	return weight, value
+}
+
+// amount converts an amount of the config, they were checked to convert when the calculator was created
+func (c *Calculator) amount(amount float64) money.Money {
+	m, _ := money.FromFloat(amount, c.currency)
+	return m
+}
+
+func (c *Calculator) inZone(code string, dest Destination) bool {
+	for _, zone := range c.config.Zones {
+		if zone.Code != code {
+			continue
+		}
+		for _, d := range zone.Destinations {
+			country, region, hasRegion := strings.Cut(d, "-")
+			if strings.EqualFold(country, dest.Country) && (!hasRegion || strings.EqualFold(region, dest.Region)) {
+				return true
+			}
+		}
+	}
+	return false
+}
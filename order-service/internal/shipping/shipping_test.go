@@ -0,0 +1,131 @@
+package shipping_test
+
+import (
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/shipping"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func eur(cents int64) money.Money {
+	return money.New(cents, "EUR")
+}
+
+func newCalculator(t *testing.T) *shipping.Calculator {
+	calculator, err := shipping.NewCalculator(shipping.Config{
+		Zones: []shipping.Zone{
+			{Code: "eu", Destinations: []string{"DE", "FR"}},
+			{Code: "california", Destinations: []string{"US-CA"}},
+		},
+		Methods: []shipping.Method{
+			{Code: "standard", Name: "Standard", Rates: []shipping.Rate{
+				{Zone: "eu", Type: shipping.RateWeight, Amount: 4.9, PerKg: 1, FreeOver: 50},
+				{Type: shipping.RateFlat, Amount: 15},
+			}},
+			{Code: "express", Name: "Express", Rates: []shipping.Rate{
+				{Zone: "eu", Type: shipping.RateFlat, Amount: 12, MaxWeight: 10},
+			}},
+			{Code: "local", Name: "Local courier", Rates: []shipping.Rate{
+				{Zone: "california", Type: shipping.RateFlat, Amount: 3},
+			}},
+		},
+	}, "EUR")
+	require.NoError(t, err)
+	return calculator
+}
+
+func TestQuote(t *testing.T) {
+	calculator := newCalculator(t)
+
+	testCases := []struct {
+		name   string
+		dest   shipping.Destination
+		items  []shipping.Item
+		quotes []shipping.Quote
+	}{
+		{
+			name:  "Weight rounded up to whole kg",
+			dest:  shipping.Destination{Country: "DE"},
+			items: []shipping.Item{{Quantity: 2, UnitPrice: eur(1000), Weight: 1.2}},
+			quotes: []shipping.Quote{
+				{Code: "standard", Name: "Standard", Amount: eur(790)},
+				{Code: "express", Name: "Express", Amount: eur(1200)},
+			},
+		},
+		{
+			name:  "Free over the threshold",
+			dest:  shipping.Destination{Country: "fr"},
+			items: []shipping.Item{{Quantity: 3, UnitPrice: eur(2000), Weight: 1}},
+			quotes: []shipping.Quote{
+				{Code: "standard", Name: "Standard", Amount: eur(0)},
+				{Code: "express", Name: "Express", Amount: eur(1200)},
+			},
+		},
+		{
+			name:   "Bulky items charged by volume",
+			dest:   shipping.Destination{Country: "DE"},
+			items:  []shipping.Item{{Quantity: 1, UnitPrice: eur(1000), Weight: 1, Length: 50, Width: 40, Height: 30}},
+			quotes: []shipping.Quote{{Code: "standard", Name: "Standard", Amount: eur(1690)}},
+		},
+		{
+			name:  "Region of a zone",
+			dest:  shipping.Destination{Country: "US", Region: "CA"},
+			items: []shipping.Item{{Quantity: 1, UnitPrice: eur(1000), Weight: 1}},
+			quotes: []shipping.Quote{
+				{Code: "standard", Name: "Standard", Amount: eur(1500)},
+				{Code: "local", Name: "Local courier", Amount: eur(300)},
+			},
+		},
+		{
+			name:   "Outside of all zones",
+			dest:   shipping.Destination{Country: "US", Region: "NY"},
+			items:  []shipping.Item{{Quantity: 1, UnitPrice: eur(1000), Weight: 1}},
+			quotes: []shipping.Quote{{Code: "standard", Name: "Standard", Amount: eur(1500)}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.quotes, calculator.Quote(tc.dest, tc.items))
+		})
+	}
+}
+
+func TestPrice(t *testing.T) {
+	calculator := newCalculator(t)
+	items := []shipping.Item{{Quantity: 1, UnitPrice: eur(1000), Weight: 1}}
+
+	quote, err := calculator.Price("express", shipping.Destination{Country: "DE"}, items)
+	require.NoError(t, err)
+	assert.Equal(t, &shipping.Quote{Code: "express", Name: "Express", Amount: eur(1200)}, quote)
+
+	_, err = calculator.Price("express", shipping.Destination{Country: "US"}, items)
+	assert.ErrorIs(t, err, shipping.ErrMethodUnavailable)
+
+	_, err = calculator.Price("pigeon", shipping.Destination{Country: "DE"}, items)
+	assert.ErrorIs(t, err, shipping.ErrMethodUnavailable)
+}
+
+func TestNewCalculatorInvalidConfig(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config shipping.Config
+	}{
+		{name: "Unknown rate type", config: shipping.Config{Methods: []shipping.Method{{Code: "standard", Rates: []shipping.Rate{{Type: "table"}}}}}},
+		{name: "Unknown zone", config: shipping.Config{Methods: []shipping.Method{{Code: "standard", Rates: []shipping.Rate{{Zone: "eu", Type: shipping.RateFlat}}}}}},
+		{name: "Duplicate code", config: shipping.Config{Methods: []shipping.Method{{Code: "standard"}, {Code: "standard"}}}},
+		{name: "Negative amount", config: shipping.Config{Methods: []shipping.Method{{Code: "standard", Rates: []shipping.Rate{{Type: shipping.RateFlat, Amount: -5}}}}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := shipping.NewCalculator(tc.config, "EUR")
+			assert.ErrorIs(t, err, shipping.ErrInvalidConfig)
+		})
+	}
+
+	_, err := shipping.NewCalculator(shipping.Config{}, "euro")
+	assert.ErrorIs(t, err, shipping.ErrInvalidConfig)
+}
@@ -0,0 +1,50 @@
+package tax
+
+import (
+	"context"
+	"sync"
+)
+
+// FakeTaxName is the name of the tax of the fake engine
+const FakeTaxName = "Fake tax"
+
+// Fake is an engine for tests and local development that stands in for an external tax provider.
+// It applies one rate to every line and records the requests it gets, Err is returned instead
+// of a result when it is set.
+type Fake struct {
+	Rate             float64
+	PricesIncludeTax bool
+	Err              error
+
+	mu       sync.Mutex
+	requests []Request
+}
+
+func NewFake(rate float64) *Fake {
+	return &Fake{Rate: rate}
+}
+
+func (f *Fake) Calculate(ctx context.Context, req Request) (*Result, error) {
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	rules := []Rule{{Name: FakeTaxName, Country: req.Country, Rate: f.Rate}}
+
+	res := &Result{PricesIncludeTax: f.PricesIncludeTax, Breakdown: []Component{}}
+	for _, line := range req.Lines {
+		res.add(applyRates(line, rules, f.PricesIncludeTax))
+	}
+	return res, nil
+}
+
+// Requests returns the requests the engine got so far
+func (f *Fake) Requests() []Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Request(nil), f.requests...)
+}
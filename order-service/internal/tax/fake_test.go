@@ -0,0 +1,26 @@
+package tax_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/order-service/internal/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeCalculate(t *testing.T) {
+	fake := tax.NewFake(0.1)
+	req := tax.Request{OrderID: "order", Country: "DE", Lines: []tax.Line{{ProductID: 1, Quantity: 2, UnitPrice: usd(1000)}}}
+
+	res, err := fake.Calculate(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, []tax.Component{{Name: tax.FakeTaxName, Rate: 0.1, Taxable: usd(2000), Amount: usd(200)}}, res.Breakdown)
+	assert.Equal(t, usd(2200), res.Total)
+	assert.Equal(t, []tax.Request{req}, fake.Requests())
+
+	fake.Err = errors.New("provider unavailable")
+	_, err = fake.Calculate(context.Background(), req)
+	assert.EqualError(t, err, "provider unavailable")
+}
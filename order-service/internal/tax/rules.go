@@ -0,0 +1,146 @@
+package tax
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/NeGat1FF/e-commerce/money"
+)
+
+var ErrInvalidRule = errors.New("invalid tax rule")
+
+// Rule is the rate of a tax in a country, or in a region of it, for all products or those
+// of one tax category. Of the rules of a tax that match a line, the one for the region wins
+// over the one for the whole country and the one for the category over the one for all
+// categories. Taxes with different names add up, such as a federal and a state tax.
+type Rule struct {
+	Name     string  `json:"name"`
+	Country  string  `json:"country"`
+	Region   string  `json:"region,omitempty"`
+	Category string  `json:"category,omitempty"`
+	Rate     float64 `json:"rate"`
+}
+
+// Rules configure the rule engine:
+//
+//	{"prices_include_tax": true, "rules": [{"name": "VAT", "country": "DE", "rate": 0.19}, {"name": "VAT", "country": "DE", "category": "food", "rate": 0.07}]}
+type Rules struct {
+	// PricesIncludeTax is set when the listed prices are gross, the tax is then taken out of them
+	PricesIncludeTax bool   `json:"prices_include_tax"`
+	Rules            []Rule `json:"rules"`
+}
+
+// RuleEngine is the built-in engine, it taxes orders by the rules of the country and region they
+// are shipped to. Orders shipped where no rule applies are not taxed.
+type RuleEngine struct {
+	rules Rules
+}
+
+func NewRuleEngine(rules Rules) (*RuleEngine, error) {
+	for i, rule := range rules.Rules {
+		if rule.Name == "" || rule.Country == "" || rule.Rate < 0 || rule.Rate >= 1 {
+			return nil, fmt.Errorf("%w: rule %d needs a name, a country and a rate from 0 to 1", ErrInvalidRule, i)
+		}
+	}
+	return &RuleEngine{rules: rules}, nil
+}
+
+// LoadRules reads the rules from a JSON file, without a file there are no rules
+func LoadRules(path string) (Rules, error) {
+	var rules Rules
+	if path == "" {
+		return rules, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rules, err
+	}
+
+	err = json.Unmarshal(data, &rules)
+	return rules, err
+}
+
+func (e *RuleEngine) Calculate(ctx context.Context, req Request) (*Result, error) {
+	res := &Result{PricesIncludeTax: e.rules.PricesIncludeTax, Breakdown: []Component{}}
+	for _, line := range req.Lines {
+		res.add(applyRates(line, e.match(req.Country, req.Region, line.Category), e.rules.PricesIncludeTax))
+	}
+	return res, nil
+}
+
+// match returns the most specific rule of every tax that applies to a line
+func (e *RuleEngine) match(country, region, category string) []Rule {
+	var matched []Rule
+	for _, rule := range e.rules.Rules {
+		if !strings.EqualFold(rule.Country, country) ||
+			(rule.Region != "" && !strings.EqualFold(rule.Region, region)) ||
+			(rule.Category != "" && rule.Category != category) {
+			continue
+		}
+
+		found := false
+		for i := range matched {
+			if matched[i].Name == rule.Name {
+				if specificity(rule) > specificity(matched[i]) {
+					matched[i] = rule
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			matched = append(matched, rule)
+		}
+	}
+	return matched
+}
+
+func specificity(rule Rule) int {
+	s := 0
+	if rule.Region != "" {
+		s += 2
+	}
+	if rule.Category != "" {
+		s++
+	}
+	return s
+}
+
+// applyRates taxes a line at the rates of the rules. Each tax is calculated on the net amount,
+// for gross prices the rounding difference to the tax taken out of the price goes to the last one.
+// Taxes at a zero rate exempt the line and are left out of the components.
+func applyRates(line Line, rules []Rule, inclusive bool) (LineTax, []Component) {
+	amount := line.UnitPrice.Mul(int64(line.Quantity)).Sub(line.Discount)
+
+	lt := LineTax{ProductID: line.ProductID, Net: amount, Tax: money.Zero(amount.Currency)}
+	for _, rule := range rules {
+		lt.Rate += rule.Rate
+	}
+	if inclusive {
+		lt.Net = amount.DivRate(1 + lt.Rate)
+	}
+
+	var components []Component
+	for _, rule := range rules {
+		if rule.Rate == 0 {
+			continue
+		}
+		c := Component{Name: rule.Name, Rate: rule.Rate, Taxable: lt.Net, Amount: lt.Net.MulRate(rule.Rate)}
+		lt.Tax = lt.Tax.Add(c.Amount)
+		components = append(components, c)
+	}
+
+	if inclusive && len(components) > 0 {
+		last := &components[len(components)-1]
+		last.Amount = last.Amount.Add(amount.Sub(lt.Net).Sub(lt.Tax))
+		lt.Tax = amount.Sub(lt.Net)
+	}
+	lt.Total = lt.Net.Add(lt.Tax)
+
+	return lt, components
+}
@@ -0,0 +1,149 @@
+package tax_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/NeGat1FF/e-commerce/order-service/internal/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func usd(cents int64) money.Money {
+	return money.New(cents, "USD")
+}
+
+func TestRuleEngineExclusive(t *testing.T) {
+	engine, err := tax.NewRuleEngine(tax.Rules{Rules: []tax.Rule{
+		{Name: "VAT", Country: "DE", Rate: 0.19},
+		{Name: "VAT", Country: "DE", Category: "food", Rate: 0.07},
+	}})
+	require.NoError(t, err)
+
+	res, err := engine.Calculate(context.Background(), tax.Request{
+		Country: "de",
+		Lines: []tax.Line{
+			{ProductID: 1, Quantity: 2, UnitPrice: usd(1000)},
+			{ProductID: 2, Quantity: 1, UnitPrice: usd(500), Category: "food"},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []tax.LineTax{
+		{ProductID: 1, Rate: 0.19, Net: usd(2000), Tax: usd(380), Total: usd(2380)},
+		{ProductID: 2, Rate: 0.07, Net: usd(500), Tax: usd(35), Total: usd(535)},
+	}, res.Lines)
+	assert.Equal(t, []tax.Component{
+		{Name: "VAT", Rate: 0.19, Taxable: usd(2000), Amount: usd(380)},
+		{Name: "VAT", Rate: 0.07, Taxable: usd(500), Amount: usd(35)},
+	}, res.Breakdown)
+	assert.False(t, res.PricesIncludeTax)
+	assert.Equal(t, usd(2500), res.Subtotal)
+	assert.Equal(t, usd(415), res.Tax)
+	assert.Equal(t, usd(2915), res.Total)
+}
+
+func TestRuleEngineInclusive(t *testing.T) {
+	engine, err := tax.NewRuleEngine(tax.Rules{PricesIncludeTax: true, Rules: []tax.Rule{{Name: "VAT", Country: "DE", Rate: 0.19}}})
+	require.NoError(t, err)
+
+	res, err := engine.Calculate(context.Background(), tax.Request{
+		Country: "DE",
+		Lines:   []tax.Line{{ProductID: 1, Quantity: 1, UnitPrice: usd(999)}},
+	})
+	require.NoError(t, err)
+
+	// The tax is taken out of the gross price, rounding leaves it a cent above the net amount times the rate
+	assert.Equal(t, []tax.LineTax{{ProductID: 1, Rate: 0.19, Net: usd(839), Tax: usd(160), Total: usd(999)}}, res.Lines)
+	assert.Equal(t, []tax.Component{{Name: "VAT", Rate: 0.19, Taxable: usd(839), Amount: usd(160)}}, res.Breakdown)
+	assert.True(t, res.PricesIncludeTax)
+	assert.Equal(t, usd(999), res.Total)
+}
+
+func TestRuleEngineRegions(t *testing.T) {
+	engine, err := tax.NewRuleEngine(tax.Rules{Rules: []tax.Rule{
+		{Name: "GST", Country: "CA", Rate: 0.05},
+		{Name: "PST", Country: "CA", Region: "BC", Rate: 0.07},
+		{Name: "PST", Country: "CA", Region: "BC", Category: "food", Rate: 0},
+	}})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		region    string
+		category  string
+		tax       money.Money
+		breakdown []tax.Component
+	}{
+		{
+			name:   "Federal and provincial tax",
+			region: "BC",
+			tax:    usd(1200),
+			breakdown: []tax.Component{
+				{Name: "GST", Rate: 0.05, Taxable: usd(10000), Amount: usd(500)},
+				{Name: "PST", Rate: 0.07, Taxable: usd(10000), Amount: usd(700)},
+			},
+		},
+		{
+			name:      "Exempt from the provincial tax",
+			region:    "BC",
+			category:  "food",
+			tax:       usd(500),
+			breakdown: []tax.Component{{Name: "GST", Rate: 0.05, Taxable: usd(10000), Amount: usd(500)}},
+		},
+		{
+			name:      "Province without a tax",
+			region:    "ON",
+			tax:       usd(500),
+			breakdown: []tax.Component{{Name: "GST", Rate: 0.05, Taxable: usd(10000), Amount: usd(500)}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := engine.Calculate(context.Background(), tax.Request{
+				Country: "CA",
+				Region:  tc.region,
+				Lines:   []tax.Line{{ProductID: 1, Quantity: 1, UnitPrice: usd(10000), Category: tc.category}},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.tax, res.Tax)
+			assert.Equal(t, tc.breakdown, res.Breakdown)
+		})
+	}
+}
+
+func TestRuleEngineDiscount(t *testing.T) {
+	engine, err := tax.NewRuleEngine(tax.Rules{Rules: []tax.Rule{{Name: "VAT", Country: "DE", Rate: 0.2}}})
+	require.NoError(t, err)
+
+	res, err := engine.Calculate(context.Background(), tax.Request{
+		Country: "DE",
+		Lines:   []tax.Line{{ProductID: 1, Quantity: 2, UnitPrice: usd(1000), Discount: usd(500)}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []tax.LineTax{{ProductID: 1, Rate: 0.2, Net: usd(1500), Tax: usd(300), Total: usd(1800)}}, res.Lines)
+}
+
+func TestRuleEngineWithoutRules(t *testing.T) {
+	engine, err := tax.NewRuleEngine(tax.Rules{Rules: []tax.Rule{{Name: "VAT", Country: "DE", Rate: 0.19}}})
+	require.NoError(t, err)
+
+	res, err := engine.Calculate(context.Background(), tax.Request{
+		Country: "US",
+		Lines:   []tax.Line{{ProductID: 1, Quantity: 3, UnitPrice: usd(150)}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []tax.LineTax{{ProductID: 1, Net: usd(450), Tax: usd(0), Total: usd(450)}}, res.Lines)
+	assert.Empty(t, res.Breakdown)
+	assert.Equal(t, usd(0), res.Tax)
+	assert.Equal(t, usd(450), res.Total)
+}
+
+func TestNewRuleEngineInvalidRule(t *testing.T) {
+	_, err := tax.NewRuleEngine(tax.Rules{Rules: []tax.Rule{{Name: "VAT", Country: "DE", Rate: 19}}})
+	assert.ErrorIs(t, err, tax.ErrInvalidRule)
+}
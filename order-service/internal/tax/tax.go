@@ -0,0 +1,85 @@
+// Package tax calculates the taxes of orders. The built-in rule-based engine taxes orders by
+// country, region and tax category of the products, external tax providers get an adapter
+// that implements Engine.
+package tax
+
+import (
+	"context"
+
+	"github.com/NeGat1FF/e-commerce/money"
+)
+
+// Line is a line of an order, UnitPrice is the listed price which includes the tax if the
+// prices of the engine do. The tax is calculated on the amount left after the discount of the line.
+type Line struct {
+	ProductID int64
+	Quantity  int
+	UnitPrice money.Money
+	Discount  money.Money
+	// Category is the tax category of the product, empty for the standard rates
+	Category string
+}
+
+// Request is an order to calculate the taxes of, the address is where it is shipped to
+type Request struct {
+	OrderID string
+	Country string
+	Region  string
+	Lines   []Line
+}
+
+// LineTax is the tax of a line. Net and Total are the amounts of the whole line
+// without and with the tax, Rate is the combined rate of the taxes applied to it
+type LineTax struct {
+	ProductID int64
+	Rate      float64
+	Net       money.Money
+	Tax       money.Money
+	Total     money.Money
+}
+
+// Component is the total of one tax at one rate over the lines it was applied to
+type Component struct {
+	Name    string
+	Rate    float64
+	Taxable money.Money
+	Amount  money.Money
+}
+
+// Result holds the taxes of an order, Lines are in the order of the request
+type Result struct {
+	Lines            []LineTax
+	Breakdown        []Component
+	PricesIncludeTax bool
+	Subtotal         money.Money
+	Tax              money.Money
+	Total            money.Money
+}
+
+// Engine calculates the taxes of orders
+type Engine interface {
+	Calculate(ctx context.Context, req Request) (*Result, error)
+}
+
+// add adds the tax of a line to the result and the components of the taxes applied to it
+func (r *Result) add(line LineTax, components []Component) {
+	r.Lines = append(r.Lines, line)
+	r.Subtotal = r.Subtotal.Add(line.Net)
+	r.Tax = r.Tax.Add(line.Tax)
+	r.Total = r.Total.Add(line.Total)
+
+	for _, c := range components {
+		found := false
+		for i := range r.Breakdown {
+			if r.Breakdown[i].Name == c.Name && r.Breakdown[i].Rate == c.Rate {
+				r.Breakdown[i].Taxable = r.Breakdown[i].Taxable.Add(c.Taxable)
+				r.Breakdown[i].Amount = r.Breakdown[i].Amount.Add(c.Amount)
+				found = true
+				break
+			}
+		}
+		if !found {
+			r.Breakdown = append(r.Breakdown, c)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+package utils
+
+import "errors"
+
+// ErrInvalidToken is returned when the user service rejects an access token
+var ErrInvalidToken = errors.New("invalid token")
@@ -1,13 +1,21 @@
 package main
 
 import (
+	"log"
+	"net"
 	"net/http"
+	"time"
 
+	"github.com/NeGat1FF/e-commerce/idempotency"
 	"github.com/NeGat1FF/e-commerce/payment-service/internal/config"
 	"github.com/NeGat1FF/e-commerce/payment-service/internal/db"
 	"github.com/NeGat1FF/e-commerce/payment-service/internal/handlers"
 	"github.com/NeGat1FF/e-commerce/payment-service/internal/repo"
+	"github.com/NeGat1FF/e-commerce/payment-service/internal/server"
 	"github.com/NeGat1FF/e-commerce/payment-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/payment-service/proto"
+	"github.com/NeGat1FF/e-commerce/svcauth"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -23,8 +31,46 @@ func main() {
 	service := service.NewPaymentService(repo, cfg.StripeSecret)
 	handler := handlers.NewPaymentHandler(service, cfg.StripeWebhookSecret)
 
+	sqlDB, err := db.DB()
+	if err != nil {
+		panic(err)
+	}
+
+	// Retried payment requests get the response of the first one instead of another PaymentIntent,
+	// keys are scoped to the order
+	idem := idempotency.New(idempotency.NewPostgresStore(sqlDB), cfg.IdempotencyKeyTTL, func(r *http.Request) string {
+		return r.PathValue("id")
+	})
+	idem.StartPurge(time.Hour)
+
+	// The order service creates and follows the payments of its orders
+	serverOpts, err := svcauth.ServerOptions(svcauth.Config{
+		CertFile: cfg.TLSCertFile,
+		KeyFile:  cfg.TLSKeyFile,
+		CAFile:   cfg.TLSCAFile,
+	}, svcauth.Policy{
+		"/proto.PaymentService/*": {"order-service"},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	grpcServer := grpc.NewServer(serverOpts...)
+	proto.RegisterPaymentServiceServer(grpcServer, server.NewPaymentServer(service))
+
+	lis, err := net.Listen("tcp", ":50051")
+	if err != nil {
+		panic(err)
+	}
+
+	go func() {
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Println("failed to serve gRPC: ", err)
+		}
+	}()
+
 	mux := http.NewServeMux()
-	mux.HandleFunc("POST /api/v1/order/{id}/payment", handler.CreatePayment)
+	mux.HandleFunc("POST /api/v1/order/{id}/payment", idem.Wrap(handler.CreatePayment))
 	mux.HandleFunc("GET /api/v1/order/{id}/payment", handler.GetPaymentByOrderID)
 	mux.HandleFunc("GET /api/v1/payments/{id}", handler.GetPaymentByID)
 	mux.HandleFunc("POST /api/v1/webhook", handler.UpdatePaymentStatus)
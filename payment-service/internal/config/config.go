@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -11,6 +12,13 @@ type Config struct {
 	DatabaseURL         string
 	StripeSecret        string
 	StripeWebhookSecret string
+	// Certificate of the service for mutual TLS with the other services
+	TLSCertFile string
+	TLSKeyFile  string
+	TLSCAFile   string
+
+	// IdempotencyKeyTTL is how long responses to requests with an Idempotency-Key are kept
+	IdempotencyKeyTTL time.Duration
 }
 
 // LoadConfig reads configuration from config file and environment variables
@@ -21,6 +29,19 @@ func LoadConfig() *Config {
 		DatabaseURL:         os.Getenv("DATABASE_URL"),
 		StripeSecret:        os.Getenv("STRIPE_SECRET"),
 		StripeWebhookSecret: os.Getenv("STRIPE_WEBHOOK_SECRET"),
+		TLSCertFile:         os.Getenv("TLS_CERT_FILE"),
+		TLSKeyFile:          os.Getenv("TLS_KEY_FILE"),
+		TLSCAFile:           os.Getenv("TLS_CA_FILE"),
+		IdempotencyKeyTTL:   getDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
 	}
 	return &cfg
 }
+
+// getDuration parses a duration from the environment, falling back to the default if it is unset or invalid.
+func getDuration(key string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return d
+}
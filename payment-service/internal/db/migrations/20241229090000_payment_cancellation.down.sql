@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS payments_stripe_id_index;
+DROP INDEX IF EXISTS payments_order_id_index;
+
+UPDATE payments SET status = 3 WHERE status = 4;
+DELETE FROM payment_statuses WHERE id = 4;
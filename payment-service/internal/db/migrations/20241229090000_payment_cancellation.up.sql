@@ -0,0 +1,7 @@
+INSERT INTO payment_statuses (id, name) VALUES (4, 'CANCELLED');
+
+SELECT setval('payment_statuses_id_seq', (SELECT MAX(id) FROM payment_statuses));
+
+-- An order has at most one payment, retries of the order saga get the existing one
+CREATE UNIQUE INDEX payments_order_id_index ON payments(order_id);
+CREATE INDEX payments_stripe_id_index ON payments(stripe_id);
@@ -0,0 +1,12 @@
+CREATE TABLE refunds (
+    id UUID PRIMARY KEY,
+    payment_id UUID NOT NULL REFERENCES payments(id),
+    reference VARCHAR(255) NOT NULL,
+    stripe_id VARCHAR(255) NOT NULL,
+    amount DECIMAL(10, 2) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (payment_id, reference)
+);
+
+CREATE INDEX refunds_stripe_id_index ON refunds(stripe_id);
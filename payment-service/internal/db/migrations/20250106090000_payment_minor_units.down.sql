@@ -0,0 +1,5 @@
+ALTER TABLE refunds DROP COLUMN IF EXISTS currency;
+ALTER TABLE refunds ALTER COLUMN amount TYPE DECIMAL(10, 2) USING amount / 100.0;
+
+ALTER TABLE payments DROP COLUMN IF EXISTS currency;
+ALTER TABLE payments ALTER COLUMN amount TYPE DECIMAL(10, 2) USING amount / 100.0;
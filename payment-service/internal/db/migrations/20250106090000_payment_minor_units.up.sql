@@ -0,0 +1,8 @@
+-- Amounts are kept in the minor unit of their currency. All payments so far were made in USD.
+ALTER TABLE payments ALTER COLUMN amount TYPE BIGINT USING ROUND(amount * 100);
+ALTER TABLE payments ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE payments ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE refunds ALTER COLUMN amount TYPE BIGINT USING ROUND(amount * 100);
+ALTER TABLE refunds ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE refunds ALTER COLUMN currency DROP DEFAULT;
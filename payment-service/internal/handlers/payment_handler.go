@@ -5,7 +5,9 @@ import (
 	"io"
 	"net/http"
 
+	"github.com/NeGat1FF/e-commerce/money"
 	"github.com/NeGat1FF/e-commerce/payment-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/payment-service/internal/repo"
 	"github.com/NeGat1FF/e-commerce/payment-service/internal/service"
 	"github.com/google/uuid"
 	"github.com/stripe/stripe-go/v81"
@@ -36,6 +38,12 @@ func (ph *PaymentHandler) CreatePayment(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	req.Amount = money.New(req.Amount.Amount, req.Amount.Currency)
+	if _, err := money.Digits(req.Amount.Currency); err != nil || !req.Amount.IsPositive() {
+		http.Error(w, "amount must be a positive amount in minor units with an ISO currency code", http.StatusBadRequest)
+		return
+	}
+
 	stripe.Key = ph.stripeWebhookSecret
 	id, err := uuid.Parse(idStr)
 	if err != nil {
@@ -100,12 +108,41 @@ func (ph *PaymentHandler) UpdatePaymentStatus(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Refunds that do not succeed right away are settled later
+	if event.Type == "charge.refund.updated" {
+		var refund stripe.Refund
+		if err := json.Unmarshal(event.Data.Raw, &refund); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := ph.service.UpdateRefundStatus(r.Context(), &refund); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// The payment is found by the PaymentIntent the event is about, not the event itself
+	var intent stripe.PaymentIntent
+	if err := json.Unmarshal(event.Data.Raw, &intent); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	switch event.Type {
 	case "payment_intent.succeeded":
-		ph.service.UpdatePaymentStatus(r.Context(), event.ID, models.PaymentStatusSucceeded)
+		_, err = ph.service.UpdatePaymentStatus(r.Context(), intent.ID, models.PaymentStatusSucceeded)
 	case "payment_intent.payment_failed":
-		ph.service.UpdatePaymentStatus(r.Context(), event.ID, models.PaymentStatusFailed)
+		_, err = ph.service.UpdatePaymentStatus(r.Context(), intent.ID, models.PaymentStatusFailed)
+	case "payment_intent.canceled":
+		_, err = ph.service.UpdatePaymentStatus(r.Context(), intent.ID, models.PaymentStatusCancelled)
 	default:
 		return
 	}
+
+	// Stripe retries the event on an error
+	if err != nil && err != repo.ErrPaymentNotFound {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 }
@@ -3,21 +3,22 @@ package models
 import (
 	"time"
 
+	"github.com/NeGat1FF/e-commerce/money"
 	"github.com/google/uuid"
 	"github.com/stripe/stripe-go/v81"
 )
 
 type Payment struct {
-	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
-	StripeID  string    `json:"stripe_id"`
-	OrderID   uuid.UUID `json:"order_id" gorm:"type:uuid"`
-	Status    int       `json:"status"`
-	Amount    float64   `json:"amount"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey"`
+	StripeID  string      `json:"stripe_id"`
+	OrderID   uuid.UUID   `json:"order_id" gorm:"type:uuid"`
+	Status    int         `json:"status"`
+	Amount    money.Money `json:"amount" gorm:"embedded"`
+	CreatedAt time.Time   `json:"created_at"`
 }
 
 type CreatePaymentRequest struct {
-	Amount float64 `json:"amount"`
+	Amount money.Money `json:"amount"`
 }
 
 type CreatePaymentResponse struct {
@@ -30,4 +31,17 @@ const (
 	PaymentStatusPending
 	PaymentStatusSucceeded
 	PaymentStatusFailed
+	PaymentStatusCancelled
 )
+
+var paymentStatusNames = map[int]string{
+	PaymentStatusPending:   "PENDING",
+	PaymentStatusSucceeded: "SUCCEEDED",
+	PaymentStatusFailed:    "FAILED",
+	PaymentStatusCancelled: "CANCELLED",
+}
+
+// PaymentStatusName returns the name of a status as other services see it
+func PaymentStatusName(status int) string {
+	return paymentStatusNames[status]
+}
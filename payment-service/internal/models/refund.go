@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/google/uuid"
+)
+
+// Refund is a refund of part or all of a payment, Reference tells the refunds of a payment apart
+type Refund struct {
+	ID        uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey"`
+	PaymentID uuid.UUID   `json:"payment_id" gorm:"type:uuid"`
+	Reference string      `json:"reference"`
+	StripeID  string      `json:"stripe_id"`
+	Amount    money.Money `json:"amount" gorm:"embedded"`
+	Status    string      `json:"status"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Statuses of a refund
+const (
+	RefundStatusPending   = "PENDING"
+	RefundStatusSucceeded = "SUCCEEDED"
+	RefundStatusFailed    = "FAILED"
+)
@@ -2,11 +2,14 @@ package repo
 
 import (
 	"context"
+	"errors"
 
 	"github.com/NeGat1FF/e-commerce/payment-service/internal/models"
 	"gorm.io/gorm"
 )
 
+var ErrPaymentNotFound = errors.New("payment not found")
+
 type PaymentRepo struct {
 	db *gorm.DB
 }
@@ -34,17 +37,49 @@ func (pr *PaymentRepo) GetPaymentByID(ctx context.Context, id string) (*models.P
 func (pr *PaymentRepo) GetPaymentByOrderID(ctx context.Context, orderID string) (*models.Payment, error) {
 	payment := &models.Payment{}
 	err := pr.db.Model(&models.Payment{}).Where("order_id = ?", orderID).First(payment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPaymentNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 	return payment, nil
 }
 
-func (pr *PaymentRepo) UpdatePaymentStatus(ctx context.Context, id string, status int) (*models.Payment, error) {
-	tx := pr.db.Model(&models.Payment{}).Where("id = ?", id).Update("status", status).Error
-	if tx != nil {
-		return nil, tx
+// UpdatePaymentStatus updates the status of the payment of a Stripe PaymentIntent
+func (pr *PaymentRepo) UpdatePaymentStatus(ctx context.Context, stripeID string, status int) (*models.Payment, error) {
+	res := pr.db.Model(&models.Payment{}).Where("stripe_id = ?", stripeID).Update("status", status)
+	if res.Error != nil {
+		return nil, res.Error
 	}
 
-	return pr.GetPaymentByID(ctx, id)
+	if res.RowsAffected == 0 {
+		return nil, ErrPaymentNotFound
+	}
+
+	payment := &models.Payment{}
+	if err := pr.db.Model(&models.Payment{}).Where("stripe_id = ?", stripeID).First(payment).Error; err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// CreateRefund creates a new refund
+func (pr *PaymentRepo) CreateRefund(ctx context.Context, refund *models.Refund) error {
+	return pr.db.WithContext(ctx).Create(refund).Error
+}
+
+// GetRefunds retrieves the refunds of a payment
+func (pr *PaymentRepo) GetRefunds(ctx context.Context, paymentID string) ([]models.Refund, error) {
+	var refunds []models.Refund
+	err := pr.db.WithContext(ctx).Where("payment_id = ?", paymentID).Order("created_at").Find(&refunds).Error
+	if err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// UpdateRefundStatus updates the status of the refund of a Stripe Refund
+func (pr *PaymentRepo) UpdateRefundStatus(ctx context.Context, stripeID, status string) error {
+	return pr.db.WithContext(ctx).Model(&models.Refund{}).Where("stripe_id = ?", stripeID).Update("status", status).Error
 }
@@ -11,5 +11,8 @@ type PaymentRepoInterface interface {
 	CreatePayment(ctx context.Context, payment *models.Payment) error
 	GetPaymentByID(ctx context.Context, id string) (*models.Payment, error)
 	GetPaymentByOrderID(ctx context.Context, orderID string) (*models.Payment, error)
-	UpdatePaymentStatus(ctx context.Context, id string, status int) (*models.Payment, error)
+	UpdatePaymentStatus(ctx context.Context, stripeID string, status int) (*models.Payment, error)
+	CreateRefund(ctx context.Context, refund *models.Refund) error
+	GetRefunds(ctx context.Context, paymentID string) ([]models.Refund, error)
+	UpdateRefundStatus(ctx context.Context, stripeID, status string) error
 }
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/NeGat1FF/e-commerce/payment-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/payment-service/internal/repo"
+	"github.com/NeGat1FF/e-commerce/payment-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/payment-service/proto"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PaymentServer exposes payments to other services
+type PaymentServer struct {
+	proto.UnimplementedPaymentServiceServer
+
+	service *service.PaymentService
+}
+
+func NewPaymentServer(service *service.PaymentService) *PaymentServer {
+	return &PaymentServer{
+		service: service,
+	}
+}
+
+// CreatePayment creates the payment of an order, or returns the one it already has
+func (s *PaymentServer) CreatePayment(ctx context.Context, req *proto.CreatePaymentRequest) (*proto.Payment, error) {
+	orderID, err := uuid.Parse(req.OrderId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid order id")
+	}
+
+	amount, err := fromProto(req.Amount)
+	if err != nil || !amount.IsPositive() {
+		return nil, status.Error(codes.InvalidArgument, "invalid amount")
+	}
+
+	payment := &models.Payment{
+		OrderID: orderID,
+		Amount:  amount,
+	}
+
+	if _, err := s.service.CreatePayment(ctx, payment); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return toProto(payment), nil
+}
+
+// GetPayment returns the payment of an order
+func (s *PaymentServer) GetPayment(ctx context.Context, req *proto.GetPaymentRequest) (*proto.Payment, error) {
+	if uuid.Validate(req.OrderId) != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid order id")
+	}
+
+	payment, err := s.service.GetPaymentByOrderID(ctx, req.OrderId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProto(payment), nil
+}
+
+// CancelPayment cancels the pending payment of an order
+func (s *PaymentServer) CancelPayment(ctx context.Context, req *proto.CancelPaymentRequest) (*proto.Payment, error) {
+	if uuid.Validate(req.OrderId) != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid order id")
+	}
+
+	payment, err := s.service.CancelPayment(ctx, req.OrderId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProto(payment), nil
+}
+
+// RefundPayment refunds part or all of the succeeded payment of an order
+func (s *PaymentServer) RefundPayment(ctx context.Context, req *proto.RefundPaymentRequest) (*proto.Refund, error) {
+	if uuid.Validate(req.OrderId) != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid order id")
+	}
+
+	amount, err := fromProto(req.Amount)
+	if err != nil || !amount.IsPositive() {
+		return nil, status.Error(codes.InvalidArgument, "invalid amount")
+	}
+
+	if req.Reference == "" {
+		return nil, status.Error(codes.InvalidArgument, "reference is required")
+	}
+
+	refund, err := s.service.RefundPayment(ctx, req.OrderId, amount, req.Reference)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &proto.Refund{
+		Id:        refund.ID.String(),
+		OrderId:   req.OrderId,
+		Reference: refund.Reference,
+		Amount:    toProtoMoney(refund.Amount),
+		Status:    refund.Status,
+	}, nil
+}
+
+func toStatus(err error) error {
+	switch err {
+	case repo.ErrPaymentNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case service.ErrRefundCurrency:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case service.ErrPaymentSucceeded, service.ErrPaymentNotSucceeded, service.ErrRefundTooLarge:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func toProto(payment *models.Payment) *proto.Payment {
+	return &proto.Payment{
+		Id:      payment.ID.String(),
+		OrderId: payment.OrderID.String(),
+		Status:  models.PaymentStatusName(payment.Status),
+		Amount:  toProtoMoney(payment.Amount),
+	}
+}
+
+// fromProto reads an amount of another service, the currency must be a valid ISO code
+func fromProto(m *proto.Money) (money.Money, error) {
+	amount := money.New(m.GetAmount(), m.GetCurrency())
+	if _, err := money.Digits(amount.Currency); err != nil {
+		return money.Money{}, err
+	}
+	return amount, nil
+}
+
+func toProtoMoney(m money.Money) *proto.Money {
+	return &proto.Money{Amount: m.Amount, Currency: m.Currency}
+}
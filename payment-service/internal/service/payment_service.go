@@ -2,15 +2,27 @@ package service
 
 import (
 	"context"
+	"errors"
+	"strings"
 
+	"github.com/NeGat1FF/e-commerce/idempotency"
+	"github.com/NeGat1FF/e-commerce/money"
 	"github.com/google/uuid"
 	"github.com/stripe/stripe-go/v81"
 	"github.com/stripe/stripe-go/v81/paymentintent"
+	"github.com/stripe/stripe-go/v81/refund"
 
 	"github.com/NeGat1FF/e-commerce/payment-service/internal/models"
 	"github.com/NeGat1FF/e-commerce/payment-service/internal/repo"
 )
 
+var (
+	ErrPaymentSucceeded    = errors.New("payment already succeeded")
+	ErrPaymentNotSucceeded = errors.New("payment has not succeeded")
+	ErrRefundTooLarge      = errors.New("refund exceeds the amount left to refund")
+	ErrRefundCurrency      = errors.New("refund is not in the currency of the payment")
+)
+
 type PaymentService struct {
 	repo         repo.PaymentRepoInterface
 	stripeSecret string
@@ -23,9 +35,29 @@ func NewPaymentService(repo repo.PaymentRepoInterface, stripeSecret string) *Pay
 	}
 }
 
-// CreatePayment creates a new payment
+// CreatePayment creates the payment of an order. An order has at most one payment,
+// if it already has one its PaymentIntent is returned instead.
 func (ps *PaymentService) CreatePayment(ctx context.Context, payment *models.Payment) (*models.CreatePaymentResponse, error) {
-	intent, err := ps.createPaymentIntent(int64(payment.Amount))
+	existing, err := ps.repo.GetPaymentByOrderID(ctx, payment.OrderID.String())
+	if err != nil && err != repo.ErrPaymentNotFound {
+		return nil, err
+	}
+
+	if existing != nil {
+		stripe.Key = ps.stripeSecret
+		intent, err := paymentintent.Get(existing.StripeID, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		*payment = *existing
+		return &models.CreatePaymentResponse{
+			ID:            existing.ID.String(),
+			PaymentIntent: intent,
+		}, nil
+	}
+
+	intent, err := ps.createPaymentIntent(payment.OrderID.String(), payment.Amount, idempotency.KeyFromContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -56,19 +88,137 @@ func (ps *PaymentService) GetPaymentByOrderID(ctx context.Context, orderID strin
 	return ps.repo.GetPaymentByOrderID(ctx, orderID)
 }
 
-// UpdatePaymentStatus updates the status of a payment
-func (ps *PaymentService) UpdatePaymentStatus(ctx context.Context, id string, status int) (*models.Payment, error) {
-	return ps.repo.UpdatePaymentStatus(ctx, id, status)
+// UpdatePaymentStatus updates the status of the payment of a Stripe PaymentIntent
+func (ps *PaymentService) UpdatePaymentStatus(ctx context.Context, stripeID string, status int) (*models.Payment, error) {
+	return ps.repo.UpdatePaymentStatus(ctx, stripeID, status)
+}
+
+// CancelPayment cancels the pending payment of an order. Payments that failed or
+// were cancelled are returned as they are, ErrPaymentSucceeded is returned for a paid order.
+func (ps *PaymentService) CancelPayment(ctx context.Context, orderID string) (*models.Payment, error) {
+	payment, err := ps.repo.GetPaymentByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch payment.Status {
+	case models.PaymentStatusSucceeded:
+		return nil, ErrPaymentSucceeded
+	case models.PaymentStatusFailed, models.PaymentStatusCancelled:
+		return payment, nil
+	}
+
+	stripe.Key = ps.stripeSecret
+	intent, err := paymentintent.Cancel(payment.StripeID, nil)
+	if err != nil {
+		var stripeErr *stripe.Error
+		if errors.As(err, &stripeErr) && stripeErr.Code == stripe.ErrorCodePaymentIntentUnexpectedState {
+			// The intent succeeded before the webhook arrived
+			return nil, ErrPaymentSucceeded
+		}
+		return nil, err
+	}
+
+	if intent.Status != stripe.PaymentIntentStatusCanceled {
+		return nil, ErrPaymentSucceeded
+	}
+
+	return ps.repo.UpdatePaymentStatus(ctx, payment.StripeID, models.PaymentStatusCancelled)
 }
 
-func (ps *PaymentService) createPaymentIntent(amount int64) (*stripe.PaymentIntent, error) {
+// RefundPayment refunds an amount of the succeeded payment of an order. A refund with the
+// same reference as an earlier one returns the earlier refund, so refunds can be retried.
+func (ps *PaymentService) RefundPayment(ctx context.Context, orderID string, amount money.Money, reference string) (*models.Refund, error) {
+	payment, err := ps.repo.GetPaymentByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	refunds, err := ps.repo.GetRefunds(ctx, payment.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	refunded := money.Zero(payment.Amount.Currency)
+	for _, r := range refunds {
+		if r.Reference == reference {
+			return &r, nil
+		}
+		if r.Status != models.RefundStatusFailed {
+			refunded = refunded.Add(r.Amount)
+		}
+	}
+
+	if payment.Status != models.PaymentStatusSucceeded {
+		return nil, ErrPaymentNotSucceeded
+	}
+
+	if amount.Currency != payment.Amount.Currency {
+		return nil, ErrRefundCurrency
+	}
+
+	if amount.Cmp(payment.Amount.Sub(refunded)) > 0 {
+		return nil, ErrRefundTooLarge
+	}
+
+	params := &stripe.RefundParams{
+		PaymentIntent: stripe.String(payment.StripeID),
+		Amount:        stripe.Int64(amount.Amount), // in the minor unit of the currency of the payment
+	}
+	// Stripe returns the refund it created before if the refund is saved below
+	params.SetIdempotencyKey("refund-" + payment.ID.String() + "-" + reference)
+
+	stripe.Key = ps.stripeSecret
+	stripeRefund, err := refund.New(params)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &models.Refund{
+		ID:        uuid.New(),
+		PaymentID: payment.ID,
+		Reference: reference,
+		StripeID:  stripeRefund.ID,
+		Amount:    amount,
+		Status:    refundStatus(stripeRefund.Status),
+	}
+
+	if err := ps.repo.CreateRefund(ctx, r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// UpdateRefundStatus updates the status of a refund after Stripe settled it
+func (ps *PaymentService) UpdateRefundStatus(ctx context.Context, stripeRefund *stripe.Refund) error {
+	return ps.repo.UpdateRefundStatus(ctx, stripeRefund.ID, refundStatus(stripeRefund.Status))
+}
+
+func refundStatus(status stripe.RefundStatus) string {
+	switch status {
+	case stripe.RefundStatusSucceeded:
+		return models.RefundStatusSucceeded
+	case stripe.RefundStatusFailed, stripe.RefundStatusCanceled:
+		return models.RefundStatusFailed
+	default:
+		return models.RefundStatusPending
+	}
+}
+
+// createPaymentIntent creates a Stripe PaymentIntent, with an idempotency key a retry returns the intent created before.
+// The key sent to Stripe is scoped to the order, so the same client key for two orders creates two intents.
+func (ps *PaymentService) createPaymentIntent(orderID string, amount money.Money, idempotencyKey string) (*stripe.PaymentIntent, error) {
 	params := &stripe.PaymentIntentParams{
-		Amount:   stripe.Int64(amount), // in smallest unit (cents for USD)
-		Currency: stripe.String("usd"),
+		Amount:   stripe.Int64(amount.Amount), // in the minor unit of the currency (cents for USD)
+		Currency: stripe.String(strings.ToLower(amount.Currency)),
 		PaymentMethodTypes: stripe.StringSlice([]string{
 			"card", // Payment method
 		}),
 	}
+	if idempotencyKey != "" {
+		params.SetIdempotencyKey(orderID + ":" + idempotencyKey)
+	}
 	stripe.Key = ps.stripeSecret
 	return paymentintent.New(params)
 }
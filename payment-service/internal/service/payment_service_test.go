@@ -0,0 +1,434 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/idempotency"
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/NeGat1FF/e-commerce/payment-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/payment-service/internal/repo"
+	"github.com/NeGat1FF/e-commerce/payment-service/internal/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stripe/stripe-go/v81"
+)
+
+// fakeRepo keeps payments and refunds in memory
+type fakeRepo struct {
+	repo.PaymentRepoInterface
+	payments map[string]*models.Payment
+	refunds  []models.Refund
+}
+
+func newFakeRepo(payments ...*models.Payment) *fakeRepo {
+	r := &fakeRepo{payments: map[string]*models.Payment{}}
+	for _, p := range payments {
+		r.payments[p.OrderID.String()] = p
+	}
+	return r
+}
+
+func (r *fakeRepo) CreatePayment(ctx context.Context, payment *models.Payment) error {
+	copied := *payment
+	r.payments[payment.OrderID.String()] = &copied
+	return nil
+}
+
+func (r *fakeRepo) GetPaymentByOrderID(ctx context.Context, orderID string) (*models.Payment, error) {
+	payment, ok := r.payments[orderID]
+	if !ok {
+		return nil, repo.ErrPaymentNotFound
+	}
+	copied := *payment
+	return &copied, nil
+}
+
+func (r *fakeRepo) UpdatePaymentStatus(ctx context.Context, stripeID string, status int) (*models.Payment, error) {
+	for _, payment := range r.payments {
+		if payment.StripeID == stripeID {
+			payment.Status = status
+			copied := *payment
+			return &copied, nil
+		}
+	}
+	return nil, repo.ErrPaymentNotFound
+}
+
+func (r *fakeRepo) CreateRefund(ctx context.Context, refund *models.Refund) error {
+	r.refunds = append(r.refunds, *refund)
+	return nil
+}
+
+func (r *fakeRepo) GetRefunds(ctx context.Context, paymentID string) ([]models.Refund, error) {
+	var refunds []models.Refund
+	for _, refund := range r.refunds {
+		if refund.PaymentID.String() == paymentID {
+			refunds = append(refunds, refund)
+		}
+	}
+	return refunds, nil
+}
+
+// fakeStripe answers the Stripe API calls of the service. Like Stripe, a request with an
+// idempotency key that was seen before gets the response of the first request.
+type fakeStripe struct {
+	mu sync.Mutex
+	// cancelStatus is the status of an intent after it is cancelled, empty if it already succeeded
+	cancelStatus string
+	intents      []string
+	cancelled    []string
+	refunds      []int64
+	keys         []string
+	responses    map[string]string
+}
+
+func newFakeStripe(t *testing.T) *fakeStripe {
+	s := &fakeStripe{cancelStatus: "canceled", responses: map[string]string{}}
+
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+
+	stripe.SetBackend(stripe.APIBackend, stripe.GetBackendWithConfig(stripe.APIBackend, &stripe.BackendConfig{
+		URL:               stripe.String(server.URL),
+		MaxNetworkRetries: stripe.Int64(0),
+		LeveledLogger:     &stripe.LeveledLogger{Level: stripe.LevelNull},
+	}))
+	t.Cleanup(func() { stripe.SetBackend(stripe.APIBackend, nil) })
+
+	return s
+}
+
+func (s *fakeStripe) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := r.Header.Get("Idempotency-Key")
+	if key != "" {
+		s.keys = append(s.keys, key)
+		if response, ok := s.responses[key]; ok {
+			w.Write([]byte(response))
+			return
+		}
+	}
+
+	r.ParseForm()
+	var response string
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/payment_intents":
+		s.intents = append(s.intents, r.Form.Get("amount"))
+		response = fmt.Sprintf(`{"id":"pi_%d","object":"payment_intent","status":"requires_payment_method"}`, len(s.intents))
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/payment_intents/"):
+		response = fmt.Sprintf(`{"id":%q,"object":"payment_intent","status":"requires_payment_method"}`, strings.TrimPrefix(r.URL.Path, "/v1/payment_intents/"))
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/cancel"):
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/payment_intents/"), "/cancel")
+		s.cancelled = append(s.cancelled, id)
+		if s.cancelStatus == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"type":"invalid_request_error","code":"payment_intent_unexpected_state","message":"succeeded"}}`))
+			return
+		}
+		response = fmt.Sprintf(`{"id":%q,"object":"payment_intent","status":%q}`, id, s.cancelStatus)
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/refunds":
+		var amount int64
+		fmt.Sscan(r.Form.Get("amount"), &amount)
+		s.refunds = append(s.refunds, amount)
+		response = fmt.Sprintf(`{"id":"re_%d","object":"refund","amount":%d,"status":"succeeded"}`, len(s.refunds), amount)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	if key != "" {
+		s.responses[key] = response
+	}
+	w.Write([]byte(response))
+}
+
+// passStore lets every request through, so the key reaches the service on every retry
+type passStore struct{}
+
+func (passStore) Begin(ctx context.Context, record *idempotency.Record) (*idempotency.Record, bool, error) {
+	return nil, true, nil
+}
+
+func (passStore) TakeOver(ctx context.Context, stale, record *idempotency.Record) (bool, error) {
+	return true, nil
+}
+
+func (passStore) Complete(ctx context.Context, record *idempotency.Record) error {
+	return nil
+}
+
+func (passStore) Delete(ctx context.Context, record *idempotency.Record) error {
+	return nil
+}
+
+func (passStore) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+// createPayment creates the payment of an order in a request with the idempotency key
+func createPayment(t *testing.T, s *service.PaymentService, orderID uuid.UUID, key string) *models.CreatePaymentResponse {
+	var resp *models.CreatePaymentResponse
+	handler := idempotency.New(passStore{}, time.Hour, nil).Wrap(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		resp, err = s.CreatePayment(r.Context(), &models.Payment{OrderID: orderID, Amount: money.New(1000, "USD")})
+		require.NoError(t, err)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/order/"+orderID.String()+"/payment", strings.NewReader(`{}`))
+	if key != "" {
+		req.Header.Set(idempotency.Header, key)
+	}
+	handler(httptest.NewRecorder(), req)
+
+	require.NotNil(t, resp)
+	return resp
+}
+
+func TestCreatePaymentIdempotency(t *testing.T) {
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T, s *service.PaymentService, stripeAPI *fakeStripe)
+	}{
+		{
+			name: "Stripe key is scoped to the order",
+			testFunc: func(t *testing.T, s *service.PaymentService, stripeAPI *fakeStripe) {
+				first, second := uuid.New(), uuid.New()
+
+				a := createPayment(t, s, first, "key")
+				b := createPayment(t, s, second, "key")
+
+				assert.NotEqual(t, a.PaymentIntent.ID, b.PaymentIntent.ID, "the same client key for two orders creates two intents")
+				assert.Equal(t, []string{first.String() + ":key", second.String() + ":key"}, stripeAPI.keys)
+			},
+		},
+		{
+			name: "Retry gets the intent of the payment of the order",
+			testFunc: func(t *testing.T, s *service.PaymentService, stripeAPI *fakeStripe) {
+				orderID := uuid.New()
+
+				a := createPayment(t, s, orderID, "key")
+				b := createPayment(t, s, orderID, "other-key")
+
+				assert.Equal(t, a.ID, b.ID)
+				assert.Equal(t, a.PaymentIntent.ID, b.PaymentIntent.ID)
+				assert.Len(t, stripeAPI.intents, 1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stripeAPI := newFakeStripe(t)
+			tc.testFunc(t, service.NewPaymentService(newFakeRepo(), "sk_test"), stripeAPI)
+		})
+	}
+}
+
+func TestCancelPayment(t *testing.T) {
+	testCases := []struct {
+		name          string
+		status        int
+		cancelStatus  string
+		expectedError error
+		wantStatus    int
+		wantCancelled bool
+	}{
+		{
+			name:          "Pending payment is cancelled",
+			status:        models.PaymentStatusPending,
+			cancelStatus:  "canceled",
+			wantStatus:    models.PaymentStatusCancelled,
+			wantCancelled: true,
+		},
+		{
+			name:          "Pending payment that succeeded before the webhook arrived",
+			status:        models.PaymentStatusPending,
+			expectedError: service.ErrPaymentSucceeded,
+			wantStatus:    models.PaymentStatusPending,
+			wantCancelled: true,
+		},
+		{
+			name:          "Succeeded payment",
+			status:        models.PaymentStatusSucceeded,
+			expectedError: service.ErrPaymentSucceeded,
+			wantStatus:    models.PaymentStatusSucceeded,
+		},
+		{
+			name:       "Failed payment is returned as it is",
+			status:     models.PaymentStatusFailed,
+			wantStatus: models.PaymentStatusFailed,
+		},
+		{
+			name:       "Cancelled payment is returned as it is",
+			status:     models.PaymentStatusCancelled,
+			wantStatus: models.PaymentStatusCancelled,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stripeAPI := newFakeStripe(t)
+			stripeAPI.cancelStatus = tc.cancelStatus
+
+			payment := &models.Payment{ID: uuid.New(), StripeID: "pi_1", OrderID: uuid.New(), Status: tc.status, Amount: money.New(1000, "USD")}
+			r := newFakeRepo(payment)
+			s := service.NewPaymentService(r, "sk_test")
+
+			got, err := s.CancelPayment(context.Background(), payment.OrderID.String())
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.wantStatus, got.Status)
+			}
+
+			assert.Equal(t, tc.wantStatus, r.payments[payment.OrderID.String()].Status)
+			if tc.wantCancelled {
+				assert.Equal(t, []string{"pi_1"}, stripeAPI.cancelled)
+			} else {
+				assert.Empty(t, stripeAPI.cancelled, "Stripe is not asked to cancel a settled payment")
+			}
+		})
+	}
+}
+
+func TestRefundPayment(t *testing.T) {
+	refund := func(reference string, amount int64, status string) models.Refund {
+		return models.Refund{ID: uuid.New(), Reference: reference, StripeID: "re_" + reference, Amount: money.New(amount, "USD"), Status: status}
+	}
+
+	testCases := []struct {
+		name          string
+		status        int
+		refunds       []models.Refund
+		amount        money.Money
+		reference     string
+		expectedError error
+		wantRefunds   []int64
+		wantStripeID  string
+	}{
+		{
+			name:        "Whole amount",
+			status:      models.PaymentStatusSucceeded,
+			amount:      money.New(1000, "USD"),
+			reference:   "return-1",
+			wantRefunds: []int64{1000},
+		},
+		{
+			name:          "More than the amount",
+			status:        models.PaymentStatusSucceeded,
+			amount:        money.New(1001, "USD"),
+			reference:     "return-1",
+			expectedError: service.ErrRefundTooLarge,
+		},
+		{
+			name:        "Partial refunds up to the amount",
+			status:      models.PaymentStatusSucceeded,
+			refunds:     []models.Refund{refund("return-1", 600, models.RefundStatusSucceeded)},
+			amount:      money.New(400, "USD"),
+			reference:   "return-2",
+			wantRefunds: []int64{400},
+		},
+		{
+			name:          "Partial refunds adding up past the amount",
+			status:        models.PaymentStatusSucceeded,
+			refunds:       []models.Refund{refund("return-1", 600, models.RefundStatusSucceeded), refund("return-2", 300, models.RefundStatusPending)},
+			amount:        money.New(200, "USD"),
+			reference:     "return-3",
+			expectedError: service.ErrRefundTooLarge,
+		},
+		{
+			name:        "Failed refunds are not counted",
+			status:      models.PaymentStatusSucceeded,
+			refunds:     []models.Refund{refund("return-1", 600, models.RefundStatusFailed)},
+			amount:      money.New(1000, "USD"),
+			reference:   "return-2",
+			wantRefunds: []int64{1000},
+		},
+		{
+			name:         "Retried refund returns the earlier refund",
+			status:       models.PaymentStatusSucceeded,
+			refunds:      []models.Refund{refund("return-1", 600, models.RefundStatusSucceeded)},
+			amount:       money.New(600, "USD"),
+			reference:    "return-1",
+			wantStripeID: "re_return-1",
+		},
+		{
+			name:          "Other currency",
+			status:        models.PaymentStatusSucceeded,
+			amount:        money.New(500, "EUR"),
+			reference:     "return-1",
+			expectedError: service.ErrRefundCurrency,
+		},
+		{
+			name:          "Payment that has not succeeded",
+			status:        models.PaymentStatusPending,
+			amount:        money.New(500, "USD"),
+			reference:     "return-1",
+			expectedError: service.ErrPaymentNotSucceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stripeAPI := newFakeStripe(t)
+
+			payment := &models.Payment{ID: uuid.New(), StripeID: "pi_1", OrderID: uuid.New(), Status: tc.status, Amount: money.New(1000, "USD")}
+			r := newFakeRepo(payment)
+			for _, refund := range tc.refunds {
+				refund.PaymentID = payment.ID
+				r.refunds = append(r.refunds, refund)
+			}
+			s := service.NewPaymentService(r, "sk_test")
+
+			got, err := s.RefundPayment(context.Background(), payment.OrderID.String(), tc.amount, tc.reference)
+			assert.Equal(t, tc.wantRefunds, stripeAPI.refunds)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Len(t, r.refunds, len(tc.refunds), "no refund is saved")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.reference, got.Reference)
+			assert.Equal(t, tc.amount, got.Amount)
+			if tc.wantStripeID != "" {
+				assert.Equal(t, tc.wantStripeID, got.StripeID)
+				assert.Len(t, r.refunds, len(tc.refunds))
+			} else {
+				assert.Equal(t, models.RefundStatusSucceeded, got.Status)
+				assert.Len(t, r.refunds, len(tc.refunds)+1)
+			}
+		})
+	}
+}
+
+func TestRefundPaymentRetryAfterSaveFailed(t *testing.T) {
+	stripeAPI := newFakeStripe(t)
+
+	payment := &models.Payment{ID: uuid.New(), StripeID: "pi_1", OrderID: uuid.New(), Status: models.PaymentStatusSucceeded, Amount: money.New(1000, "USD")}
+	r := newFakeRepo(payment)
+	s := service.NewPaymentService(r, "sk_test")
+
+	first, err := s.RefundPayment(context.Background(), payment.OrderID.String(), money.New(500, "USD"), "return-1")
+	require.NoError(t, err)
+	// The refund was made at Stripe but is lost here, e.g. because saving it failed
+	r.refunds = nil
+
+	second, err := s.RefundPayment(context.Background(), payment.OrderID.String(), money.New(500, "USD"), "return-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, first.StripeID, second.StripeID, "Stripe returns the refund it made before")
+	assert.Equal(t, []int64{500}, stripeAPI.refunds)
+	require.Len(t, stripeAPI.keys, 2)
+	assert.Equal(t, stripeAPI.keys[0], stripeAPI.keys[1])
+}
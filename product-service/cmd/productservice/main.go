@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"net"
 
+	"github.com/NeGat1FF/e-commerce/authclient"
 	"github.com/NeGat1FF/e-commerce/product-service/internal/cache"
 	"github.com/NeGat1FF/e-commerce/product-service/internal/config"
 	"github.com/NeGat1FF/e-commerce/product-service/internal/db"
@@ -12,6 +14,7 @@ import (
 	"github.com/NeGat1FF/e-commerce/product-service/internal/service"
 	"github.com/NeGat1FF/e-commerce/product-service/pkg/logger"
 	"github.com/NeGat1FF/e-commerce/product-service/proto"
+	"github.com/NeGat1FF/e-commerce/svcauth"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
@@ -29,6 +32,16 @@ func main() {
 	}
 	repo := repository.NewMongoRepository(db.Database("product").Collection("products"))
 
+	reservationRepo := repository.NewMongoReservationRepository(db.Database("product").Collection("reservations"))
+	if err := reservationRepo.EnsureIndexes(context.Background()); err != nil {
+		panic(err)
+	}
+
+	restockRepo := repository.NewMongoRestockRepository(db.Database("product").Collection("restocks"))
+	if err := restockRepo.EnsureIndexes(context.Background()); err != nil {
+		panic(err)
+	}
+
 	opts, err := redis.ParseURL(config.CacheURL)
 	if err != nil {
 		panic(err)
@@ -45,9 +58,21 @@ func main() {
 	}
 
 	// Initialize the service
-	service := service.NewProductService(repo, mqClient, cache, config.MessageBrokerExchange)
+	stockService := service.NewStockService(repo, reservationRepo, restockRepo)
+	service := service.NewProductService(repo, mqClient, cache, config.MessageBrokerExchange, config.Currency)
+
+	tlsConfig := svcauth.Config{CertFile: config.TLSCertFile, KeyFile: config.TLSKeyFile, CAFile: config.TLSCAFile}
 
-	s := grpc.NewServer()
+	serverOpts, err := svcauth.ServerOptions(tlsConfig, svcauth.Policy{
+		"/proto.PriceService/GetPrice": {"order-service", "shopping-cart-service"},
+		"/proto.StockService/*":        {"order-service"},
+	})
+	if err != nil {
+		logger.Logger.Error("Failed to load service certificates", zap.Error(err))
+		panic(err)
+	}
+
+	s := grpc.NewServer(serverOpts...)
 
 	lis, err := net.Listen("tcp", ":50051")
 	if err != nil {
@@ -56,6 +81,7 @@ func main() {
 	}
 
 	proto.RegisterPriceServiceServer(s, service)
+	proto.RegisterStockServiceServer(s, stockService)
 
 	go func() {
 		logger.Logger.Info("Starting gRPC server")
@@ -65,6 +91,17 @@ func main() {
 		}
 	}()
 
+	creds, err := svcauth.DialOption(tlsConfig)
+	if err != nil {
+		panic(err)
+	}
+
+	userConn, err := grpc.NewClient(config.UserServiceURL, creds)
+	if err != nil {
+		panic(err)
+	}
+	auth := handlers.Auth(authclient.New(userConn, "product-service", authclient.DefaultCacheTTL), "products:write")
+
 	// Initialize the handlers
 	productHandler := handlers.NewProductHandler(service)
 
@@ -74,12 +111,12 @@ func main() {
 	ginServer.Use(gin.Recovery())
 
 	group := ginServer.Group("/api/v1/products")
-	group.POST("/", handlers.Auth(), handlers.ValidateProduct(), productHandler.CreateProduct)
-	group.PUT("/:id", handlers.Auth(), productHandler.UpdateProduct)
-	group.DELETE("/:id", handlers.Auth(), productHandler.DeleteProduct)
+	group.POST("/", auth, handlers.ValidateProduct(), productHandler.CreateProduct)
+	group.PUT("/:id", auth, productHandler.UpdateProduct)
+	group.DELETE("/:id", auth, productHandler.DeleteProduct)
 
-	group.POST("/:id/add-stock", handlers.Auth(), productHandler.AddStock)
-	group.POST("/:id/reduce-stock", handlers.Auth(), productHandler.ReduceStock)
+	group.POST("/:id/add-stock", auth, productHandler.AddStock)
+	group.POST("/:id/reduce-stock", auth, productHandler.ReduceStock)
 	group.GET("/:id/stock", productHandler.GetStock)
 
 	group.GET("/:id", productHandler.GetProductByID)
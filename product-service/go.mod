@@ -5,8 +5,10 @@ go 1.22.7
 toolchain go1.22.9
 
 require (
+	github.com/NeGat1FF/e-commerce/authclient v0.0.0
+	github.com/NeGat1FF/e-commerce/money v0.0.0
+	github.com/NeGat1FF/e-commerce/svcauth v0.0.0
 	github.com/gin-gonic/gin v1.10.0
-	github.com/golang-jwt/jwt/v5 v5.2.1
 	github.com/joho/godotenv v1.5.1
 	github.com/rabbitmq/amqp091-go v1.10.0
 	github.com/redis/go-redis/v9 v9.7.0
@@ -104,3 +106,9 @@ require (
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240903143218-8af14fe29dc1 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/NeGat1FF/e-commerce/authclient => ../authclient
+
+replace github.com/NeGat1FF/e-commerce/money => ../money
+
+replace github.com/NeGat1FF/e-commerce/svcauth => ../svcauth
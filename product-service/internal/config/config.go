@@ -13,6 +13,14 @@ type Config struct {
 	CacheURL              string
 	MessageBrokerURL      string
 	MessageBrokerExchange string
+	UserServiceURL        string
+	// Currency of the product prices, USD by default
+	Currency string
+
+	// Certificate of the service for mutual TLS with the other services
+	TLSCertFile string
+	TLSKeyFile  string
+	TLSCAFile   string
 }
 
 // LoadConfig reads configuration from config file and environment variables
@@ -25,6 +33,17 @@ func LoadConfig() *Config {
 		MessageBrokerURL:      os.Getenv("MESSAGE_BROKER_URL"),
 		MessageBrokerExchange: os.Getenv("MESSAGE_BROKER_EXCHANGE"),
 		CacheURL:              os.Getenv("CACHE_URL"),
+		UserServiceURL:        os.Getenv("USER_SERVICE"),
+		Currency:              os.Getenv("CURRENCY"),
+
+		TLSCertFile: os.Getenv("TLS_CERT_FILE"),
+		TLSKeyFile:  os.Getenv("TLS_KEY_FILE"),
+		TLSCAFile:   os.Getenv("TLS_CA_FILE"),
 	}
+
+	if cfg.Currency == "" {
+		cfg.Currency = "USD"
+	}
+
 	return &cfg
 }
@@ -1,14 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
-	"strings"
 	"time"
 
+	"github.com/NeGat1FF/e-commerce/authclient"
 	"github.com/NeGat1FF/e-commerce/product-service/internal/models"
 	"github.com/NeGat1FF/e-commerce/product-service/pkg/logger"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
 )
 
@@ -39,47 +39,40 @@ func ErrorHandling() gin.HandlerFunc {
 	}
 }
 
-// Auth middleware reads user role from JWT token and sets it to the context
-func Auth() gin.HandlerFunc {
+// Auth middleware checks with the user service that the token grants the permission
+func Auth(auth *authclient.Client, permission string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		header := ctx.GetHeader("Authorization")
-		if header == "" {
-			ctx.JSON(401, gin.H{
-				"error": "authorization header is required",
-			})
-			ctx.Abort()
-			return
-		}
-
-		tokenString := strings.Split(header, " ")[1]
-
-		claims := jwt.MapClaims{}
-
-		_, _, err := jwt.NewParser().ParseUnverified(tokenString, &claims)
+		token, err := authclient.BearerToken(ctx.GetHeader("Authorization"))
 		if err != nil {
 			ctx.JSON(401, gin.H{
-				"error": "failed to parse token",
+				"error": err.Error(),
 			})
 			ctx.Abort()
 			return
 		}
 
-		role, ok := claims["role"]
-		if !ok {
-			ctx.JSON(401, gin.H{
-				"error": "role not found in token",
-			})
+		claims, err := auth.CheckPermission(ctx.Request.Context(), token, permission)
+		if err != nil {
+			switch {
+			case errors.Is(err, authclient.ErrUnauthenticated):
+				ctx.JSON(401, gin.H{
+					"error": "invalid token",
+				})
+			case errors.Is(err, authclient.ErrForbidden):
+				ctx.JSON(403, gin.H{
+					"error": "unauthorized",
+				})
+			default:
+				logger.Logger.Error("Failed to check permission", zap.Error(err))
+				ctx.JSON(503, gin.H{
+					"error": "failed to validate token",
+				})
+			}
 			ctx.Abort()
 			return
 		}
 
-		if role != "admin" {
-			ctx.JSON(401, gin.H{
-				"error": "unauthorized",
-			})
-			ctx.Abort()
-			return
-		}
+		ctx.Set("userID", claims.UserID)
 		ctx.Next()
 	}
 }
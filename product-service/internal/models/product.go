@@ -1,10 +1,12 @@
 package models
 
-// Product represents the internal model of a product that includes quantity
+// Product represents the internal model of a product that includes quantity.
+// TaxCategory selects the tax rates of the product, without one it is taxed at the standard rates.
 type Product struct {
 	ID          int64          `json:"id" bson:"id"`
 	Name        string         `json:"name" bson:"name"`
 	Category    string         `json:"category" bson:"category"`
+	TaxCategory string         `json:"tax_category,omitempty" bson:"tax_category,omitempty"`
 	Price       float64        `json:"price" bson:"price"`
 	Description string         `json:"description" bson:"description"`
 	Quantity    int64          `json:"quantity" bson:"quantity"`
@@ -17,6 +19,7 @@ type UserProduct struct {
 	ID          int64          `json:"id" bson:"id"`
 	Name        string         `json:"name" bson:"name"`
 	Category    string         `json:"category" bson:"category"`
+	TaxCategory string         `json:"tax_category,omitempty" bson:"tax_category,omitempty"`
 	Price       float64        `json:"price" bson:"price"`
 	Description string         `json:"description" bson:"description"`
 	Images      []string       `json:"images" bson:"images"`
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Reservation statuses
+const (
+	ReservationReserving = "RESERVING"
+	ReservationReserved  = "RESERVED"
+	ReservationReleased  = "RELEASED"
+)
+
+// Reservation is the stock taken for an order, items are reserved one by one
+// so a reservation interrupted half way can be completed or released
+type Reservation struct {
+	OrderID   string         `json:"order_id" bson:"order_id"`
+	Items     []ReservedItem `json:"items" bson:"items"`
+	Status    string         `json:"status" bson:"status"`
+	CreatedAt time.Time      `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at" bson:"updated_at"`
+}
+
+// ReservedItem is a product of a reservation
+type ReservedItem struct {
+	ProductID int64 `json:"product_id" bson:"product_id"`
+	Quantity  int64 `json:"quantity" bson:"quantity"`
+	// Reserved is true once the quantity was taken off the stock
+	Reserved bool `json:"reserved" bson:"reserved"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Restock puts the items of a customer return back in stock, items are restocked
+// one by one so a restock interrupted half way is completed by a retry
+type Restock struct {
+	ReturnID  string          `json:"return_id" bson:"return_id"`
+	Items     []RestockedItem `json:"items" bson:"items"`
+	Completed bool            `json:"completed" bson:"completed"`
+	CreatedAt time.Time       `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at" bson:"updated_at"`
+}
+
+// RestockedItem is a product of a restock
+type RestockedItem struct {
+	ProductID int64 `json:"product_id" bson:"product_id"`
+	Quantity  int64 `json:"quantity" bson:"quantity"`
+	// Restocked is true once the quantity was added to the stock
+	Restocked bool `json:"restocked" bson:"restocked"`
+}
@@ -12,6 +12,7 @@ import (
 
 var ErrProductAlreadyExists = errors.New("product already exists")
 var ErrProductNotFound = errors.New("product not found")
+var ErrInsufficientStock = errors.New("insufficient stock")
 
 type MongoRepository struct {
 	coll *mongo.Collection
@@ -112,3 +113,15 @@ func (r *MongoRepository) ReduceStock(ctx context.Context, id int64, quantity in
 	_, err := r.coll.UpdateOne(ctx, bson.M{"id": id}, bson.M{"$inc": bson.M{"quantity": -quantity}})
 	return err
 }
+
+// ReserveStock decreases the stock quantity of a product if enough of it is in stock.
+func (r *MongoRepository) ReserveStock(ctx context.Context, id int64, quantity int64) error {
+	res, err := r.coll.UpdateOne(ctx, bson.M{"id": id, "quantity": bson.M{"$gte": quantity}}, bson.M{"$inc": bson.M{"quantity": -quantity}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrInsufficientStock
+	}
+	return nil
+}
@@ -32,4 +32,7 @@ type ProductRepository interface {
 
 	// ReduceStock decreases the stock quantity of a product.
 	ReduceStock(ctx context.Context, id int64, quantity int64) error
+
+	// ReserveStock decreases the stock quantity of a product if enough of it is in stock.
+	ReserveStock(ctx context.Context, id int64, quantity int64) error
 }
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/product-service/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrReservationExists = errors.New("reservation already exists")
+var ErrReservationNotFound = errors.New("reservation not found")
+
+type MongoReservationRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoReservationRepository(collection *mongo.Collection) *MongoReservationRepository {
+	return &MongoReservationRepository{
+		coll: collection,
+	}
+}
+
+// EnsureIndexes makes order IDs unique, an order has at most one reservation.
+func (r *MongoReservationRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.M{"order_id": 1},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+func (r *MongoReservationRepository) CreateReservation(ctx context.Context, reservation models.Reservation) error {
+	_, err := r.coll.InsertOne(ctx, reservation)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrReservationExists
+	}
+	return err
+}
+
+func (r *MongoReservationRepository) GetReservation(ctx context.Context, orderID string) (models.Reservation, error) {
+	var reservation models.Reservation
+
+	err := r.coll.FindOne(ctx, bson.M{"order_id": orderID}).Decode(&reservation)
+	if err == mongo.ErrNoDocuments {
+		return reservation, ErrReservationNotFound
+	}
+
+	return reservation, err
+}
+
+func (r *MongoReservationRepository) UpdateReservation(ctx context.Context, reservation models.Reservation) error {
+	res, err := r.coll.UpdateOne(ctx, bson.M{"order_id": reservation.OrderID}, bson.M{"$set": bson.M{
+		"items":      reservation.Items,
+		"status":     reservation.Status,
+		"updated_at": time.Now(),
+	}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrReservationNotFound
+	}
+	return nil
+}
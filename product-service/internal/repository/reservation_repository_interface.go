@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NeGat1FF/e-commerce/product-service/internal/models"
+)
+
+// ReservationRepository defines the methods to store the stock reservations of orders.
+type ReservationRepository interface {
+	// CreateReservation adds a reservation, ErrReservationExists is returned if the order already has one.
+	CreateReservation(ctx context.Context, reservation models.Reservation) error
+
+	// GetReservation retrieves the reservation of an order.
+	GetReservation(ctx context.Context, orderID string) (models.Reservation, error)
+
+	// UpdateReservation saves the items and status of a reservation.
+	UpdateReservation(ctx context.Context, reservation models.Reservation) error
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/product-service/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrRestockExists = errors.New("restock already exists")
+var ErrRestockNotFound = errors.New("restock not found")
+
+type MongoRestockRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoRestockRepository(collection *mongo.Collection) *MongoRestockRepository {
+	return &MongoRestockRepository{
+		coll: collection,
+	}
+}
+
+// EnsureIndexes makes return IDs unique, a return is restocked at most once.
+func (r *MongoRestockRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.M{"return_id": 1},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+func (r *MongoRestockRepository) CreateRestock(ctx context.Context, restock models.Restock) error {
+	_, err := r.coll.InsertOne(ctx, restock)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrRestockExists
+	}
+	return err
+}
+
+func (r *MongoRestockRepository) GetRestock(ctx context.Context, returnID string) (models.Restock, error) {
+	var restock models.Restock
+
+	err := r.coll.FindOne(ctx, bson.M{"return_id": returnID}).Decode(&restock)
+	if err == mongo.ErrNoDocuments {
+		return restock, ErrRestockNotFound
+	}
+
+	return restock, err
+}
+
+func (r *MongoRestockRepository) UpdateRestock(ctx context.Context, restock models.Restock) error {
+	res, err := r.coll.UpdateOne(ctx, bson.M{"return_id": restock.ReturnID}, bson.M{"$set": bson.M{
+		"items":      restock.Items,
+		"completed":  restock.Completed,
+		"updated_at": time.Now(),
+	}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrRestockNotFound
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NeGat1FF/e-commerce/product-service/internal/models"
+)
+
+// RestockRepository defines the methods to store the restocks of customer returns.
+type RestockRepository interface {
+	// CreateRestock adds a restock, ErrRestockExists is returned if the return already has one.
+	CreateRestock(ctx context.Context, restock models.Restock) error
+
+	// GetRestock retrieves the restock of a return.
+	GetRestock(ctx context.Context, returnID string) (models.Restock, error)
+
+	// UpdateRestock saves the items and completion of a restock.
+	UpdateRestock(ctx context.Context, restock models.Restock) error
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strconv"
 
+	"github.com/NeGat1FF/e-commerce/money"
 	"github.com/NeGat1FF/e-commerce/product-service/internal/cache"
 	messagequeue "github.com/NeGat1FF/e-commerce/product-service/internal/messageQueue"
 	"github.com/NeGat1FF/e-commerce/product-service/internal/models"
@@ -13,6 +14,8 @@ import (
 	"github.com/NeGat1FF/e-commerce/product-service/pkg/logger"
 	"github.com/NeGat1FF/e-commerce/product-service/proto"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var (
@@ -32,14 +35,17 @@ type ProductService struct {
 	messageQueue messagequeue.MessageQueue
 	cache        cache.Cache
 	exchangeName string
+	// currency of the product prices
+	currency string
 }
 
-func NewProductService(repo repository.ProductRepository, messageQueue messagequeue.MessageQueue, cache cache.Cache, exchangeName string) *ProductService {
+func NewProductService(repo repository.ProductRepository, messageQueue messagequeue.MessageQueue, cache cache.Cache, exchangeName, currency string) *ProductService {
 	return &ProductService{
 		repo:         repo,
 		messageQueue: messageQueue,
 		cache:        cache,
 		exchangeName: exchangeName,
+		currency:     currency,
 	}
 }
 
@@ -182,20 +188,65 @@ func (ps *ProductService) ReduceStock(ctx context.Context, id int64, quantity in
 	return nil
 }
 
+// GetPrice returns the current price and stock of a product to other services
 func (ps *ProductService) GetPrice(ctx context.Context, in *proto.PriceRequest) (*proto.PriceResponse, error) {
 	productID, err := strconv.ParseInt(in.ProductId, 10, 64)
 	if err != nil {
 		logger.Logger.Error("Failed to parse product id", zap.Error(err))
-		return nil, err
+		return nil, status.Error(codes.InvalidArgument, "invalid product id")
 	}
 
 	product, err := ps.GetProductByID(ctx, productID)
 	if err != nil {
 		logger.Logger.Error("Failed to get product by id", zap.Error(err))
+		if err == ErrProductNotFound {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, err
+	}
+
+	stock, err := ps.GetStock(ctx, productID)
+	if err != nil {
+		logger.Logger.Error("Failed to get stock", zap.Error(err))
+		return nil, err
+	}
+
+	price, err := money.FromFloat(product.Price, ps.currency)
+	if err != nil {
+		logger.Logger.Error("Failed to convert price", zap.Float64("price", product.Price), zap.Error(err))
 		return nil, err
 	}
 
 	return &proto.PriceResponse{
-		Price: fmt.Sprintf("%.2f", product.Price),
+		Price:       &proto.Money{Amount: price.Amount, Currency: price.Currency},
+		Stock:       stock,
+		TaxCategory: product.TaxCategory,
+		Category:    product.Category,
+		Weight:      numberAttribute(product.Attributes, "weight"),
+		Length:      numberAttribute(product.Attributes, "length"),
+		Width:       numberAttribute(product.Attributes, "width"),
+		Height:      numberAttribute(product.Attributes, "height"),
 	}, nil
 }
+
+// numberAttribute reads a numeric attribute of a product, 0 if it is not set or not a number
+func numberAttribute(attributes map[string]any, key string) float64 {
+	switch v := attributes[key].(type) {
+	case float64:
+		return v
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case int:
+		return float64(v)
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0
+		}
+		return f
+	default:
+		return 0
+	}
+}
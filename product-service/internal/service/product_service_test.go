@@ -11,6 +11,7 @@ import (
 	"github.com/NeGat1FF/e-commerce/product-service/internal/service"
 	"github.com/NeGat1FF/e-commerce/product-service/mocks"
 	"github.com/NeGat1FF/e-commerce/product-service/pkg/logger"
+	"github.com/NeGat1FF/e-commerce/product-service/proto"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -72,7 +73,7 @@ func TestCreateProduct(t *testing.T) {
 
 			tc.setupMocks(repository, cache, messageQueue)
 
-			productService := service.NewProductService(repository, messageQueue, cache, "test-exchange")
+			productService := service.NewProductService(repository, messageQueue, cache, "test-exchange", "USD")
 
 			err := productService.CreateProduct(context.Background(), tc.Product)
 
@@ -171,7 +172,7 @@ func TestUpdateProduct(t *testing.T) {
 
 			tc.setupMocks(repository, cache, messageQueue)
 
-			productService := service.NewProductService(repository, messageQueue, cache, "test-exchange")
+			productService := service.NewProductService(repository, messageQueue, cache, "test-exchange", "USD")
 
 			err := productService.UpdateProduct(context.Background(), 1, tc.updateFields)
 
@@ -253,7 +254,7 @@ func TestDeleteProduct(t *testing.T) {
 
 			tc.setupMocks(repository, cache, messageQueue)
 
-			productService := service.NewProductService(repository, messageQueue, cache, "test-exchange")
+			productService := service.NewProductService(repository, messageQueue, cache, "test-exchange", "USD")
 
 			err := productService.DeleteProduct(context.Background(), 1)
 
@@ -364,7 +365,7 @@ func TestGetProductByID(t *testing.T) {
 
 			tc.setupMocks(repository, cache)
 
-			productService := service.NewProductService(repository, nil, cache, "")
+			productService := service.NewProductService(repository, nil, cache, "", "USD")
 
 			product, err := productService.GetProductByID(context.Background(), tc.productID)
 
@@ -434,7 +435,7 @@ func TestGetProductByCategory(t *testing.T) {
 
 			tc.setupMocks(repository, cache)
 
-			productService := service.NewProductService(repository, nil, cache, "")
+			productService := service.NewProductService(repository, nil, cache, "", "USD")
 
 			products, err := productService.GetProductsByCategory(context.Background(), tc.category, tc.page, tc.limit)
 
@@ -485,7 +486,7 @@ func TestGetStock(t *testing.T) {
 
 			tc.setupMocks(repository)
 
-			productService := service.NewProductService(repository, nil, nil, "")
+			productService := service.NewProductService(repository, nil, nil, "", "USD")
 
 			stock, err := productService.GetStock(context.Background(), tc.productID)
 
@@ -497,6 +498,40 @@ func TestGetStock(t *testing.T) {
 	}
 }
 
+func TestGetPrice(t *testing.T) {
+	repository := &mocks.ProductRepository{}
+	cache := &mocks.Cache{}
+
+	repository.On("GetProductByID", mock.Anything, int64(1)).Return(models.UserProduct{
+		ID:          1,
+		Category:    "groceries",
+		Price:       100,
+		TaxCategory: "food",
+		Attributes:  map[string]any{"weight": 1.5, "length": int32(30), "width": "20", "height": "tall"},
+	}, nil)
+	repository.On("GetStock", mock.Anything, int64(1)).Return(int64(10), nil)
+	cache.On("Get", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(errors.New("failed to get product from cache"))
+	cache.On("Set", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("models.UserProduct")).Return(nil).Maybe()
+
+	logger.Init("info")
+
+	productService := service.NewProductService(repository, nil, cache, "", "USD")
+
+	res, err := productService.GetPrice(context.Background(), &proto.PriceRequest{ProductId: "1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10000), res.Price.Amount)
+	assert.Equal(t, "USD", res.Price.Currency)
+	assert.Equal(t, int64(10), res.Stock)
+	assert.Equal(t, "food", res.TaxCategory)
+	assert.Equal(t, "groceries", res.Category)
+	// Attributes that are not numbers are left out
+	assert.Equal(t, 1.5, res.Weight)
+	assert.Equal(t, 30.0, res.Length)
+	assert.Equal(t, 20.0, res.Width)
+	assert.Equal(t, 0.0, res.Height)
+}
+
 func TestAddStock(t *testing.T) {
 	testCases := []struct {
 		name          string
@@ -533,7 +568,7 @@ func TestAddStock(t *testing.T) {
 
 			tc.setupMocks(repository)
 
-			productService := service.NewProductService(repository, nil, nil, "")
+			productService := service.NewProductService(repository, nil, nil, "", "USD")
 
 			err := productService.AddStock(context.Background(), tc.productID, tc.quantity)
 
@@ -580,7 +615,7 @@ func TestReduceStock(t *testing.T) {
 
 			tc.setupMocks(repository)
 
-			productService := service.NewProductService(repository, nil, nil, "")
+			productService := service.NewProductService(repository, nil, nil, "", "USD")
 
 			err := productService.ReduceStock(context.Background(), tc.productID, tc.quantity)
 
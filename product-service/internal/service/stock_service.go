@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/product-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/product-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/product-service/pkg/logger"
+	"github.com/NeGat1FF/e-commerce/product-service/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrInsufficientStock   = errors.New("insufficient stock")
+	ErrReservationReleased = errors.New("reservation was released")
+)
+
+// StockService reserves stock for orders. Reserving and releasing are idempotent per order,
+// so the order service can retry them until they succeed.
+type StockService struct {
+	proto.UnimplementedStockServiceServer
+	products     repository.ProductRepository
+	reservations repository.ReservationRepository
+	restocks     repository.RestockRepository
+}
+
+func NewStockService(products repository.ProductRepository, reservations repository.ReservationRepository, restocks repository.RestockRepository) *StockService {
+	return &StockService{
+		products:     products,
+		reservations: reservations,
+		restocks:     restocks,
+	}
+}
+
+// Reserve takes the items of an order off the stock, all or none of them.
+// A reservation interrupted half way is completed by the next call.
+func (s *StockService) Reserve(ctx context.Context, orderID string, items []models.ReservedItem) error {
+	now := time.Now()
+	reservation := models.Reservation{
+		OrderID:   orderID,
+		Items:     items,
+		Status:    models.ReservationReserving,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	err := s.reservations.CreateReservation(ctx, reservation)
+	if err == repository.ErrReservationExists {
+		reservation, err = s.reservations.GetReservation(ctx, orderID)
+	}
+	if err != nil {
+		logger.Logger.Error("Failed to create reservation", zap.String("order_id", orderID), zap.Error(err))
+		return err
+	}
+
+	switch reservation.Status {
+	case models.ReservationReserved:
+		return nil
+	case models.ReservationReleased:
+		return ErrReservationReleased
+	}
+
+	for i, item := range reservation.Items {
+		if item.Reserved {
+			continue
+		}
+
+		err := s.products.ReserveStock(ctx, item.ProductID, item.Quantity)
+		if err == repository.ErrInsufficientStock {
+			logger.Logger.Info("Insufficient stock", zap.String("order_id", orderID), zap.Int64("product_id", item.ProductID))
+			if err := s.release(ctx, reservation); err != nil {
+				return err
+			}
+			return ErrInsufficientStock
+		}
+		if err != nil {
+			logger.Logger.Error("Failed to reserve stock", zap.String("order_id", orderID), zap.Error(err))
+			return err
+		}
+
+		reservation.Items[i].Reserved = true
+		if err := s.reservations.UpdateReservation(ctx, reservation); err != nil {
+			logger.Logger.Error("Failed to update reservation", zap.String("order_id", orderID), zap.Error(err))
+			return err
+		}
+	}
+
+	reservation.Status = models.ReservationReserved
+	if err := s.reservations.UpdateReservation(ctx, reservation); err != nil {
+		logger.Logger.Error("Failed to update reservation", zap.String("order_id", orderID), zap.Error(err))
+		return err
+	}
+
+	logger.Logger.Info("Stock reserved", zap.String("order_id", orderID))
+	return nil
+}
+
+// Release puts the reserved items of an order back in stock. Releasing an order without
+// a reservation leaves a released one behind, so a late reserve call fails.
+func (s *StockService) Release(ctx context.Context, orderID string) error {
+	reservation, err := s.reservations.GetReservation(ctx, orderID)
+	if err == repository.ErrReservationNotFound {
+		now := time.Now()
+		err = s.reservations.CreateReservation(ctx, models.Reservation{
+			OrderID:   orderID,
+			Status:    models.ReservationReleased,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+		if err != repository.ErrReservationExists {
+			return err
+		}
+
+		// Reserved in the meantime
+		reservation, err = s.reservations.GetReservation(ctx, orderID)
+	}
+	if err != nil {
+		logger.Logger.Error("Failed to get reservation", zap.String("order_id", orderID), zap.Error(err))
+		return err
+	}
+
+	if reservation.Status == models.ReservationReleased {
+		return nil
+	}
+
+	return s.release(ctx, reservation)
+}
+
+func (s *StockService) release(ctx context.Context, reservation models.Reservation) error {
+	for i, item := range reservation.Items {
+		if !item.Reserved {
+			continue
+		}
+
+		if err := s.products.AddStock(ctx, item.ProductID, item.Quantity); err != nil {
+			logger.Logger.Error("Failed to release stock", zap.String("order_id", reservation.OrderID), zap.Error(err))
+			return err
+		}
+
+		reservation.Items[i].Reserved = false
+		if err := s.reservations.UpdateReservation(ctx, reservation); err != nil {
+			logger.Logger.Error("Failed to update reservation", zap.String("order_id", reservation.OrderID), zap.Error(err))
+			return err
+		}
+	}
+
+	reservation.Status = models.ReservationReleased
+	if err := s.reservations.UpdateReservation(ctx, reservation); err != nil {
+		logger.Logger.Error("Failed to update reservation", zap.String("order_id", reservation.OrderID), zap.Error(err))
+		return err
+	}
+
+	logger.Logger.Info("Stock released", zap.String("order_id", reservation.OrderID))
+	return nil
+}
+
+// Restock adds the items of a customer return back to the stock. A return is restocked
+// once, a restock interrupted half way is completed by the next call.
+func (s *StockService) Restock(ctx context.Context, returnID string, items []models.RestockedItem) error {
+	now := time.Now()
+	restock := models.Restock{
+		ReturnID:  returnID,
+		Items:     items,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	err := s.restocks.CreateRestock(ctx, restock)
+	if err == repository.ErrRestockExists {
+		restock, err = s.restocks.GetRestock(ctx, returnID)
+	}
+	if err != nil {
+		logger.Logger.Error("Failed to create restock", zap.String("return_id", returnID), zap.Error(err))
+		return err
+	}
+
+	if restock.Completed {
+		return nil
+	}
+
+	for i, item := range restock.Items {
+		if item.Restocked {
+			continue
+		}
+
+		if err := s.products.AddStock(ctx, item.ProductID, item.Quantity); err != nil {
+			logger.Logger.Error("Failed to restock", zap.String("return_id", returnID), zap.Error(err))
+			return err
+		}
+
+		restock.Items[i].Restocked = true
+		if err := s.restocks.UpdateRestock(ctx, restock); err != nil {
+			logger.Logger.Error("Failed to update restock", zap.String("return_id", returnID), zap.Error(err))
+			return err
+		}
+	}
+
+	restock.Completed = true
+	if err := s.restocks.UpdateRestock(ctx, restock); err != nil {
+		logger.Logger.Error("Failed to update restock", zap.String("return_id", returnID), zap.Error(err))
+		return err
+	}
+
+	logger.Logger.Info("Return restocked", zap.String("return_id", returnID))
+	return nil
+}
+
+func (s *StockService) ReserveStock(ctx context.Context, in *proto.ReserveStockRequest) (*proto.ReserveStockResponse, error) {
+	if in.OrderId == "" || len(in.Items) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "order id and items are required")
+	}
+
+	items := make([]models.ReservedItem, 0, len(in.Items))
+	for _, item := range in.Items {
+		if item.Quantity < 1 {
+			return nil, status.Error(codes.InvalidArgument, "quantity must be positive")
+		}
+		items = append(items, models.ReservedItem{ProductID: item.ProductId, Quantity: item.Quantity})
+	}
+
+	err := s.Reserve(ctx, in.OrderId, items)
+	if err == ErrInsufficientStock || err == ErrReservationReleased {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.ReserveStockResponse{}, nil
+}
+
+func (s *StockService) ReleaseStock(ctx context.Context, in *proto.ReleaseStockRequest) (*proto.ReleaseStockResponse, error) {
+	if in.OrderId == "" {
+		return nil, status.Error(codes.InvalidArgument, "order id is required")
+	}
+
+	if err := s.Release(ctx, in.OrderId); err != nil {
+		return nil, err
+	}
+
+	return &proto.ReleaseStockResponse{}, nil
+}
+
+func (s *StockService) ReturnStock(ctx context.Context, in *proto.ReturnStockRequest) (*proto.ReturnStockResponse, error) {
+	if in.ReturnId == "" || len(in.Items) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "return id and items are required")
+	}
+
+	items := make([]models.RestockedItem, 0, len(in.Items))
+	for _, item := range in.Items {
+		if item.Quantity < 1 {
+			return nil, status.Error(codes.InvalidArgument, "quantity must be positive")
+		}
+		items = append(items, models.RestockedItem{ProductID: item.ProductId, Quantity: item.Quantity})
+	}
+
+	if err := s.Restock(ctx, in.ReturnId, items); err != nil {
+		return nil, err
+	}
+
+	return &proto.ReturnStockResponse{}, nil
+}
@@ -0,0 +1,249 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/product-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/product-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/product-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/product-service/mocks"
+	"github.com/NeGat1FF/e-commerce/product-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReserve(t *testing.T) {
+	testCases := []struct {
+		name          string
+		items         []models.ReservedItem
+		setupMocks    func(p *mocks.ProductRepository, r *mocks.ReservationRepository)
+		expectedError error
+	}{
+		{
+			name:  "Reserve stock success",
+			items: []models.ReservedItem{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 1}},
+			setupMocks: func(p *mocks.ProductRepository, r *mocks.ReservationRepository) {
+				r.On("CreateReservation", mock.Anything, mock.Anything).Return(nil)
+				p.On("ReserveStock", mock.Anything, int64(1), int64(2)).Return(nil)
+				p.On("ReserveStock", mock.Anything, int64(2), int64(1)).Return(nil)
+				r.On("UpdateReservation", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:  "Already reserved",
+			items: []models.ReservedItem{{ProductID: 1, Quantity: 2}},
+			setupMocks: func(p *mocks.ProductRepository, r *mocks.ReservationRepository) {
+				r.On("CreateReservation", mock.Anything, mock.Anything).Return(repository.ErrReservationExists)
+				r.On("GetReservation", mock.Anything, "order").Return(models.Reservation{
+					OrderID: "order",
+					Items:   []models.ReservedItem{{ProductID: 1, Quantity: 2, Reserved: true}},
+					Status:  models.ReservationReserved,
+				}, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:  "Resume an interrupted reservation",
+			items: []models.ReservedItem{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 1}},
+			setupMocks: func(p *mocks.ProductRepository, r *mocks.ReservationRepository) {
+				r.On("CreateReservation", mock.Anything, mock.Anything).Return(repository.ErrReservationExists)
+				r.On("GetReservation", mock.Anything, "order").Return(models.Reservation{
+					OrderID: "order",
+					Items:   []models.ReservedItem{{ProductID: 1, Quantity: 2, Reserved: true}, {ProductID: 2, Quantity: 1}},
+					Status:  models.ReservationReserving,
+				}, nil)
+				p.On("ReserveStock", mock.Anything, int64(2), int64(1)).Return(nil)
+				r.On("UpdateReservation", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:  "Reservation was released",
+			items: []models.ReservedItem{{ProductID: 1, Quantity: 2}},
+			setupMocks: func(p *mocks.ProductRepository, r *mocks.ReservationRepository) {
+				r.On("CreateReservation", mock.Anything, mock.Anything).Return(repository.ErrReservationExists)
+				r.On("GetReservation", mock.Anything, "order").Return(models.Reservation{
+					OrderID: "order",
+					Status:  models.ReservationReleased,
+				}, nil)
+			},
+			expectedError: service.ErrReservationReleased,
+		},
+		{
+			name:  "Insufficient stock releases the reserved items",
+			items: []models.ReservedItem{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 5}},
+			setupMocks: func(p *mocks.ProductRepository, r *mocks.ReservationRepository) {
+				r.On("CreateReservation", mock.Anything, mock.Anything).Return(nil)
+				p.On("ReserveStock", mock.Anything, int64(1), int64(2)).Return(nil)
+				p.On("ReserveStock", mock.Anything, int64(2), int64(5)).Return(repository.ErrInsufficientStock)
+				p.On("AddStock", mock.Anything, int64(1), int64(2)).Return(nil)
+				r.On("UpdateReservation", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedError: service.ErrInsufficientStock,
+		},
+		{
+			name:  "Failed to reserve stock",
+			items: []models.ReservedItem{{ProductID: 1, Quantity: 2}},
+			setupMocks: func(p *mocks.ProductRepository, r *mocks.ReservationRepository) {
+				r.On("CreateReservation", mock.Anything, mock.Anything).Return(nil)
+				p.On("ReserveStock", mock.Anything, int64(1), int64(2)).Return(errors.New("failed to reserve stock"))
+			},
+			expectedError: errors.New("failed to reserve stock"),
+		},
+	}
+
+	logger.Init("info")
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			products := &mocks.ProductRepository{}
+			reservations := &mocks.ReservationRepository{}
+
+			tc.setupMocks(products, reservations)
+
+			stockService := service.NewStockService(products, reservations, nil)
+
+			err := stockService.Reserve(context.Background(), "order", tc.items)
+
+			assert.Equal(t, tc.expectedError, err)
+
+			products.AssertExpectations(t)
+			reservations.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRelease(t *testing.T) {
+	testCases := []struct {
+		name          string
+		setupMocks    func(p *mocks.ProductRepository, r *mocks.ReservationRepository)
+		expectedError error
+	}{
+		{
+			name: "Release stock success",
+			setupMocks: func(p *mocks.ProductRepository, r *mocks.ReservationRepository) {
+				r.On("GetReservation", mock.Anything, "order").Return(models.Reservation{
+					OrderID: "order",
+					Items:   []models.ReservedItem{{ProductID: 1, Quantity: 2, Reserved: true}, {ProductID: 2, Quantity: 1}},
+					Status:  models.ReservationReserving,
+				}, nil)
+				p.On("AddStock", mock.Anything, int64(1), int64(2)).Return(nil)
+				r.On("UpdateReservation", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Already released",
+			setupMocks: func(p *mocks.ProductRepository, r *mocks.ReservationRepository) {
+				r.On("GetReservation", mock.Anything, "order").Return(models.Reservation{
+					OrderID: "order",
+					Status:  models.ReservationReleased,
+				}, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Release before reserve",
+			setupMocks: func(p *mocks.ProductRepository, r *mocks.ReservationRepository) {
+				r.On("GetReservation", mock.Anything, "order").Return(models.Reservation{}, repository.ErrReservationNotFound)
+				r.On("CreateReservation", mock.Anything, mock.MatchedBy(func(res models.Reservation) bool {
+					return res.Status == models.ReservationReleased
+				})).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Failed to add stock",
+			setupMocks: func(p *mocks.ProductRepository, r *mocks.ReservationRepository) {
+				r.On("GetReservation", mock.Anything, "order").Return(models.Reservation{
+					OrderID: "order",
+					Items:   []models.ReservedItem{{ProductID: 1, Quantity: 2, Reserved: true}},
+					Status:  models.ReservationReserved,
+				}, nil)
+				p.On("AddStock", mock.Anything, int64(1), int64(2)).Return(errors.New("failed to add stock"))
+			},
+			expectedError: errors.New("failed to add stock"),
+		},
+	}
+
+	logger.Init("info")
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			products := &mocks.ProductRepository{}
+			reservations := &mocks.ReservationRepository{}
+
+			tc.setupMocks(products, reservations)
+
+			stockService := service.NewStockService(products, reservations, nil)
+
+			err := stockService.Release(context.Background(), "order")
+
+			assert.Equal(t, tc.expectedError, err)
+
+			products.AssertExpectations(t)
+			reservations.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRestock(t *testing.T) {
+	testCases := []struct {
+		name          string
+		setupMocks    func(p *mocks.ProductRepository, r *mocks.RestockRepository)
+		expectedError error
+	}{
+		{
+			name: "Restock success",
+			setupMocks: func(p *mocks.ProductRepository, r *mocks.RestockRepository) {
+				r.On("CreateRestock", mock.Anything, mock.Anything).Return(nil)
+				p.On("AddStock", mock.Anything, int64(1), int64(2)).Return(nil)
+				r.On("UpdateRestock", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Already restocked",
+			setupMocks: func(p *mocks.ProductRepository, r *mocks.RestockRepository) {
+				r.On("CreateRestock", mock.Anything, mock.Anything).Return(repository.ErrRestockExists)
+				r.On("GetRestock", mock.Anything, "return").Return(models.Restock{
+					ReturnID:  "return",
+					Items:     []models.RestockedItem{{ProductID: 1, Quantity: 2, Restocked: true}},
+					Completed: true,
+				}, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Failed to add stock",
+			setupMocks: func(p *mocks.ProductRepository, r *mocks.RestockRepository) {
+				r.On("CreateRestock", mock.Anything, mock.Anything).Return(nil)
+				p.On("AddStock", mock.Anything, int64(1), int64(2)).Return(errors.New("failed to add stock"))
+			},
+			expectedError: errors.New("failed to add stock"),
+		},
+	}
+
+	logger.Init("info")
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			products := &mocks.ProductRepository{}
+			restocks := &mocks.RestockRepository{}
+
+			tc.setupMocks(products, restocks)
+
+			stockService := service.NewStockService(products, nil, restocks)
+
+			err := stockService.Restock(context.Background(), "return", []models.RestockedItem{{ProductID: 1, Quantity: 2}})
+
+			assert.Equal(t, tc.expectedError, err)
+
+			products.AssertExpectations(t)
+			restocks.AssertExpectations(t)
+		})
+	}
+}
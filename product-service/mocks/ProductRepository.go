@@ -135,6 +135,20 @@ func (_m *ProductRepository) ReduceStock(ctx context.Context, id int64, quantity
 	return r0
 }
 
+// ReserveStock provides a mock function with given fields: ctx, id, quantity
+func (_m *ProductRepository) ReserveStock(ctx context.Context, id int64, quantity int64) error {
+	ret := _m.Called(ctx, id, quantity)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, id, quantity)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateProduct provides a mock function with given fields: ctx, id, updateFields
 func (_m *ProductRepository) UpdateProduct(ctx context.Context, id int64, updateFields map[string]interface{}) error {
 	ret := _m.Called(ctx, id, updateFields)
@@ -1,15 +1,21 @@
 package main
 
 import (
+	"log"
+	"net"
+
+	"github.com/NeGat1FF/e-commerce/authclient"
 	"github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/config"
 	"github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/db"
 	"github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/handlers"
+	messagequeue "github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/messageQueue"
 	"github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/server"
 	"github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/service"
 	"github.com/NeGat1FF/e-commerce/shopping-cart-service/proto"
+	"github.com/NeGat1FF/e-commerce/svcauth"
 	"github.com/gin-gonic/gin"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 func main() {
@@ -20,32 +26,94 @@ func main() {
 		panic(err)
 	}
 
-	var opts []grpc.DialOption
-	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	tlsConfig := svcauth.Config{
+		CertFile: config.GetConfig().TLS_CERT_FILE,
+		KeyFile:  config.GetConfig().TLS_KEY_FILE,
+		CAFile:   config.GetConfig().TLS_CA_FILE,
+	}
+
+	creds, err := svcauth.DialOption(tlsConfig)
+	if err != nil {
+		panic(err)
+	}
+
+	grpcConn, err := grpc.NewClient(config.GetConfig().PRICE_SERVICE, creds)
+	if err != nil {
+		panic(err)
+	}
 
-	grpcConn, err := grpc.NewClient(config.GetConfig().PRICE_SERVICE, opts...)
+	userConn, err := grpc.NewClient(config.GetConfig().USER_SERVICE, creds)
 	if err != nil {
 		panic(err)
 	}
 
+	orderConn, err := grpc.NewClient(config.GetConfig().ORDER_SERVICE, creds)
+	if err != nil {
+		panic(err)
+	}
+
+	auth := authclient.New(userConn, "shopping-cart-service", authclient.DefaultCacheTTL)
+
 	repo := repository.NewShoppingCartRepo(db)
 	priceService := proto.NewPriceServiceClient(grpcConn)
+	promotionService := proto.NewPromotionServiceClient(orderConn)
 
-	service := service.NewCartService(repo, priceService)
+	cartService := service.NewCartService(repo, priceService, promotionService)
+
+	handler := handlers.NewCartHandler(cartService)
+
+	conn, err := messagequeue.ConnectRabbitMQ(config.GetConfig().MESSAGE_BROKER_URL)
+	if err != nil {
+		panic(err)
+	}
+
+	mqClient, err := messagequeue.NewRabbitMQClient(conn)
+	if err != nil {
+		panic(err)
+	}
+
+	// Drop carts of erased users and merge guest carts into accounts
+	err = service.NewUserEventsConsumer(mqClient, repo, config.GetConfig().USER_EVENTS_QUEUE).Start()
+	if err != nil {
+		panic(err)
+	}
+
+	// The order service turns carts into orders at checkout
+	serverOpts, err := svcauth.ServerOptions(tlsConfig, svcauth.Policy{
+		"/proto.CartService/GetCart":     {"order-service"},
+		"/proto.CartService/RemoveItems": {"order-service"},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	grpcServer := grpc.NewServer(serverOpts...)
+	proto.RegisterCartServiceServer(grpcServer, server.NewCartServer(cartService))
+
+	lis, err := net.Listen("tcp", ":50051")
+	if err != nil {
+		panic(err)
+	}
 
-	handler := handlers.NewCartHandler(service)
+	go func() {
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Println("failed to serve gRPC: ", err)
+		}
+	}()
 
 	ginServer := gin.Default()
 
 	group := ginServer.Group("/api/v1/cart")
 
-	group.Use(handlers.AuthMiddleware())
+	group.Use(handlers.AuthMiddleware(auth))
 
 	group.POST("", handler.AddItem)
 	group.PUT("/:itemID", handler.SetQuantity)
 	group.DELETE("/:itemID", handler.RemoveItem)
 	group.GET("", handler.GetItems)
 	group.DELETE("", handler.DeleteCart)
+	group.POST("/coupons", handler.AddCoupon)
+	group.DELETE("/coupons/:code", handler.RemoveCoupon)
 
 	err = ginServer.Run(":8080")
 	if err != nil {
@@ -8,8 +8,18 @@ import (
 
 type Config struct {
 	DB_URL        string
-	JWTSecret     string
 	PRICE_SERVICE string
+	USER_SERVICE  string
+	// The order service works out the discounts of coupons and promotions
+	ORDER_SERVICE string
+
+	// Certificate of the service for mutual TLS with the other services
+	TLS_CERT_FILE string
+	TLS_KEY_FILE  string
+	TLS_CA_FILE   string
+
+	MESSAGE_BROKER_URL string
+	USER_EVENTS_QUEUE  string
 }
 
 var config *Config
@@ -19,8 +29,16 @@ func InitConfig() {
 
 	config = &Config{
 		DB_URL:        os.Getenv("DATABASE_URL"),
-		JWTSecret:     os.Getenv("JWT_SECRET"),
 		PRICE_SERVICE: os.Getenv("PRICE_SERVICE"),
+		USER_SERVICE:  os.Getenv("USER_SERVICE"),
+		ORDER_SERVICE: os.Getenv("ORDER_SERVICE"),
+
+		TLS_CERT_FILE: os.Getenv("TLS_CERT_FILE"),
+		TLS_KEY_FILE:  os.Getenv("TLS_KEY_FILE"),
+		TLS_CA_FILE:   os.Getenv("TLS_CA_FILE"),
+
+		MESSAGE_BROKER_URL: os.Getenv("MESSAGE_BROKER_URL"),
+		USER_EVENTS_QUEUE:  os.Getenv("USER_EVENTS_QUEUE"),
 	}
 }
 
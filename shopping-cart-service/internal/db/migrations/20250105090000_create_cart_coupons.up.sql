@@ -0,0 +1,6 @@
+CREATE TABLE cart_coupons (
+    user_id UUID,
+    code VARCHAR(64),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, code)
+);
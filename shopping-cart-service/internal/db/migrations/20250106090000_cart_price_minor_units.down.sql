@@ -0,0 +1,7 @@
+ALTER TABLE cart ADD COLUMN price DECIMAL(10, 2);
+
+UPDATE cart SET price = price_amount / 100.0;
+
+ALTER TABLE cart ALTER COLUMN price SET NOT NULL;
+ALTER TABLE cart DROP COLUMN price_currency;
+ALTER TABLE cart DROP COLUMN price_amount;
@@ -0,0 +1,9 @@
+-- Prices are kept in the minor unit of their currency. All prices so far were in USD.
+ALTER TABLE cart ADD COLUMN price_amount BIGINT;
+ALTER TABLE cart ADD COLUMN price_currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+UPDATE cart SET price_amount = ROUND(price * 100);
+
+ALTER TABLE cart ALTER COLUMN price_amount SET NOT NULL;
+ALTER TABLE cart ALTER COLUMN price_currency DROP DEFAULT;
+ALTER TABLE cart DROP COLUMN price;
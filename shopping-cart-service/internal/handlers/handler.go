@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/models"
@@ -30,6 +31,10 @@ func (h *CartHandler) AddItem(c *gin.Context) {
 
 	response, err := h.service.AddItem(c, uid.(string), &item)
 	if err != nil {
+		if errors.Is(err, service.ErrCurrencyMismatch) {
+			c.JSON(409, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
@@ -115,3 +120,39 @@ func (h *CartHandler) DeleteCart(c *gin.Context) {
 
 	c.JSON(200, gin.H{"message": "Cart deleted"})
 }
+
+// AddCoupon enters a coupon code for the shopping cart
+func (h *CartHandler) AddCoupon(c *gin.Context) {
+	uid := c.MustGet("userID")
+
+	var req models.CouponRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	response, err := h.service.AddCoupon(c, uid.(string), req.Code)
+	if err != nil {
+		if errors.Is(err, service.ErrCouponRequired) || errors.Is(err, service.ErrInvalidCoupon) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, response)
+}
+
+// RemoveCoupon removes a coupon code from the shopping cart
+func (h *CartHandler) RemoveCoupon(c *gin.Context) {
+	uid := c.MustGet("userID")
+
+	response, err := h.service.RemoveCoupon(c, uid.(string), c.Param("code"))
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(200, response)
+}
@@ -1,31 +1,33 @@
 package handlers
 
 import (
-	"strings"
+	"errors"
 
-	"github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/config"
-	"github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/utils"
+	"github.com/NeGat1FF/e-commerce/authclient"
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(auth *authclient.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		auth := c.GetHeader("Authorization")
-		if auth == "" {
-			c.JSON(401, gin.H{"error": "Authorization header is required"})
+		jwt, err := authclient.BearerToken(c.GetHeader("Authorization"))
+		if err != nil {
+			c.JSON(401, gin.H{"error": err.Error()})
 			c.Abort()
 			return
 		}
 
-		jwt := strings.Split(auth, " ")[1]
-		claims, err := utils.ValidateToken(jwt, config.GetConfig().JWTSecret)
+		claims, err := auth.ValidateToken(c.Request.Context(), jwt)
 		if err != nil {
-			c.JSON(401, gin.H{"error": "Invalid token"})
+			if errors.Is(err, authclient.ErrUnauthenticated) {
+				c.JSON(401, gin.H{"error": "Invalid token"})
+			} else {
+				c.JSON(503, gin.H{"error": "Failed to validate token"})
+			}
 			c.Abort()
 			return
 		}
 
-		c.Set("userID", claims["uid"].(string))
+		c.Set("userID", claims.UserID)
 
 		c.Next()
 	}
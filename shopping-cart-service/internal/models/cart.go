@@ -1,21 +1,45 @@
 package models
 
+import "github.com/NeGat1FF/e-commerce/money"
+
 type Item struct {
-	ItemID   int64   `json:"id" gorm:"column:item_id"`
-	Quantity int     `json:"quantity" gorm:"column:quantity"`
-	Price    float64 `json:"price" gorm:"column:price"`
+	ItemID   int64       `json:"id" gorm:"column:item_id"`
+	Quantity int         `json:"quantity" gorm:"column:quantity"`
+	Price    money.Money `json:"price" gorm:"embedded;embeddedPrefix:price_"`
 }
 
 type GetCartResponse struct {
-	UserID     string  `json:"user_id"`
-	Items      []Item  `json:"items"`
-	TotalPrice float64 `json:"total_price"`
+	UserID     string      `json:"user_id"`
+	Items      []Item      `json:"items"`
+	TotalPrice money.Money `json:"total_price"`
+	// CouponCodes are the coupon codes entered for the cart, they are applied at checkout
+	CouponCodes []string `json:"coupon_codes"`
+	// Discounts are the promotions the cart gets, DiscountedPrice is the total price after them
+	Discounts       []Discount  `json:"discounts"`
+	Discount        money.Money `json:"discount"`
+	DiscountedPrice money.Money `json:"discounted_price"`
+	// InvalidCoupons are the entered codes that are unknown, expired or used up
+	InvalidCoupons []string `json:"invalid_coupons,omitempty"`
+}
+
+// Discount is what a promotion takes off the cart. Free shipping is shown without an amount,
+// the shipping cost is only known at checkout.
+type Discount struct {
+	PromotionID string      `json:"promotion_id"`
+	Code        string      `json:"code,omitempty"`
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Amount      money.Money `json:"amount"`
+}
+
+type CouponRequest struct {
+	Code string `json:"code"`
 }
 
 // Cart represents a shopping cart
 type Cart struct {
-	UserID   string  `gorm:"column:user_id;primaryKey"`
-	ItemID   int64   `gorm:"column:item_id;primaryKey"`
-	Quantity int     `gorm:"column:quantity;not null;default:1"`
-	Price    float64 `gorm:"column:price;not null;default:0"`
+	UserID   string      `gorm:"column:user_id;primaryKey"`
+	ItemID   int64       `gorm:"column:item_id;primaryKey"`
+	Quantity int         `gorm:"column:quantity;not null;default:1"`
+	Price    money.Money `gorm:"embedded;embeddedPrefix:price_"`
 }
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 
+	"github.com/NeGat1FF/e-commerce/money"
 	"github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/models"
 	"gorm.io/gorm"
 )
@@ -54,15 +55,15 @@ func (repo *ShoppingCartRepo) RemoveItem(ctx context.Context, userID string, ite
 	return repo.GetItems(ctx, userID)
 }
 
-// GetItems returns all items in the shopping cart
+// GetItems returns all items in the shopping cart and their total price
 func (repo *ShoppingCartRepo) GetItems(ctx context.Context, userID string) (models.GetCartResponse, error) {
 	tx := repo.db.WithContext(ctx).Raw(`
 	SELECT 
 		user_id,
 		item_id,
 		quantity,
-		price,
-    	SUM(quantity * price) OVER (PARTITION BY user_id) AS total_price
+		price_amount,
+		price_currency
 	FROM 
 		cart
 	WHERE 
@@ -81,36 +82,160 @@ func (repo *ShoppingCartRepo) GetItems(ctx context.Context, userID string) (mode
 
 	var response models.GetCartResponse
 	var items []models.Item
-	var totalPrice float64
+	var totalPrice money.Money
 
 	for rows.Next() {
 		var item models.Item
 		var currentUserID string
 
-		if err := rows.Scan(&currentUserID, &item.ItemID, &item.Quantity, &item.Price, &totalPrice); err != nil {
+		if err := rows.Scan(&currentUserID, &item.ItemID, &item.Quantity, &item.Price.Amount, &item.Price.Currency); err != nil {
 			return response, err
 		}
 
 		// Add item to the list
 		items = append(items, item)
+		totalPrice, err = money.Sum(totalPrice, item.Price.Mul(int64(item.Quantity)))
+		if err != nil {
+			return models.GetCartResponse{}, err
+		}
 		response.UserID = currentUserID
 	}
 
 	response.Items = items
 	response.TotalPrice = totalPrice
 
+	response.CouponCodes, err = repo.GetCoupons(ctx, userID)
+	if err != nil {
+		return response, err
+	}
+
 	return response, nil
 
 }
 
 func (repo *ShoppingCartRepo) DeleteCart(ctx context.Context, userID string) error {
-	tx := repo.db.WithContext(ctx).Exec(`
-	DELETE FROM cart
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+		DELETE FROM cart
+		WHERE user_id = ?
+		`, userID).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`
+		DELETE FROM cart_coupons
+		WHERE user_id = ?
+		`, userID).Error
+	})
+}
+
+// AddCoupon enters a coupon code for the shopping cart, entering it again is a no-op
+func (repo *ShoppingCartRepo) AddCoupon(ctx context.Context, userID, code string) error {
+	return repo.db.WithContext(ctx).Exec(`
+	INSERT INTO cart_coupons (user_id, code)
+	VALUES (?, ?)
+	ON CONFLICT (user_id, code) DO NOTHING
+	`, userID, code).Error
+}
+
+// RemoveCoupon removes a coupon code from the shopping cart
+func (repo *ShoppingCartRepo) RemoveCoupon(ctx context.Context, userID, code string) error {
+	return repo.db.WithContext(ctx).Exec(`
+	DELETE FROM cart_coupons
+	WHERE user_id = ? AND code = ?
+	`, userID, code).Error
+}
+
+// GetCoupons returns the coupon codes entered for the shopping cart in the order they were entered
+func (repo *ShoppingCartRepo) GetCoupons(ctx context.Context, userID string) ([]string, error) {
+	codes := []string{}
+
+	err := repo.db.WithContext(ctx).Raw(`
+	SELECT code
+	FROM cart_coupons
 	WHERE user_id = ?
-	`, userID)
-	if tx.Error != nil {
-		return tx.Error
+	ORDER BY created_at
+	`, userID).Scan(&codes).Error
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return codes, nil
+}
+
+// MergeCart moves all items of one cart into another, adding up quantities of items in both.
+// Running it again is a no-op as the source cart is empty afterwards.
+func (repo *ShoppingCartRepo) MergeCart(ctx context.Context, fromUserID, toUserID string) error {
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+		INSERT INTO cart (user_id, item_id, quantity, price_amount, price_currency)
+		SELECT ?, item_id, quantity, price_amount, price_currency
+		FROM cart
+		WHERE user_id = ?
+		ON CONFLICT (user_id, item_id) DO UPDATE
+		SET quantity = cart.quantity + EXCLUDED.quantity
+		`, toUserID, fromUserID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec(`
+		DELETE FROM cart
+		WHERE user_id = ?
+		`, fromUserID).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec(`
+		INSERT INTO cart_coupons (user_id, code, created_at)
+		SELECT ?, code, created_at
+		FROM cart_coupons
+		WHERE user_id = ?
+		ON CONFLICT (user_id, code) DO NOTHING
+		`, toUserID, fromUserID).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`
+		DELETE FROM cart_coupons
+		WHERE user_id = ?
+		`, fromUserID).Error
+	})
+}
+
+// RemoveItems takes the given quantities off the cart of a user and removes lines left without quantity,
+// and the coupon codes that were used
+func (repo *ShoppingCartRepo) RemoveItems(ctx context.Context, userID string, items []models.Item, couponCodes []string) error {
+	return repo.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, item := range items {
+			err := tx.Exec(`
+			UPDATE cart
+			SET quantity = quantity - ?
+			WHERE user_id = ? AND item_id = ?
+			`, item.Quantity, userID, item.ItemID).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		err := tx.Exec(`
+		DELETE FROM cart
+		WHERE user_id = ? AND quantity <= 0
+		`, userID).Error
+		if err != nil {
+			return err
+		}
+
+		if len(couponCodes) == 0 {
+			return nil
+		}
+
+		return tx.Exec(`
+		DELETE FROM cart_coupons
+		WHERE user_id = ? AND code IN ?
+		`, userID, couponCodes).Error
+	})
 }
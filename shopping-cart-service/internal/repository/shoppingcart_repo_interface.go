@@ -21,4 +21,20 @@ type ShoppingCartRepoInterface interface {
 
 	// DeleteCart deletes the shopping cart
 	DeleteCart(ctx context.Context, userID string) error
+
+	// AddCoupon enters a coupon code for the shopping cart
+	AddCoupon(ctx context.Context, userID, code string) error
+
+	// RemoveCoupon removes a coupon code from the shopping cart
+	RemoveCoupon(ctx context.Context, userID, code string) error
+
+	// GetCoupons returns the coupon codes entered for the shopping cart
+	GetCoupons(ctx context.Context, userID string) ([]string, error)
+
+	// RemoveItems takes the given quantities off the cart and removes lines left without quantity,
+	// and the coupon codes that were used
+	RemoveItems(ctx context.Context, userID string, items []models.Item, couponCodes []string) error
+
+	// MergeCart moves all items of one cart into another, adding up quantities of items in both
+	MergeCart(ctx context.Context, fromUserID, toUserID string) error
 }
@@ -0,0 +1,282 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ps "github.com/testcontainers/testcontainers-go/modules/postgres"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestContainer starts Postgres and creates the tables with the migrations of the service
+func setupTestContainer(ctx context.Context) (*gorm.DB, error) {
+	pgCon, err := ps.Run(ctx,
+		"postgres:latest",
+		ps.WithDatabase("test"),
+		ps.WithUsername("test"),
+		ps.WithPassword("test"),
+		ps.BasicWaitStrategies(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	connString, err := pgCon.Endpoint(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(postgres.Open(fmt.Sprintf("postgres://test:test@%s/test?sslmode=disable", connString)), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := filepath.Glob("../db/migrations/*.up.sql")
+	if err != nil {
+		return nil, err
+	}
+	for _, migration := range migrations {
+		sql, err := os.ReadFile(migration)
+		if err != nil {
+			return nil, err
+		}
+		if err := db.Exec(string(sql)).Error; err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(migration), err)
+		}
+	}
+
+	return db, nil
+}
+
+func TestGetItems(t *testing.T) {
+	ctx := context.Background()
+	db, err := setupTestContainer(ctx)
+	require.NoError(t, err)
+
+	repo := repository.NewShoppingCartRepo(db)
+
+	addItem := func(t *testing.T, userID string, itemID int64, quantity int, price money.Money) {
+		err := db.Table("cart").Create(&models.Cart{UserID: userID, ItemID: itemID, Quantity: quantity, Price: price}).Error
+		require.NoError(t, err)
+	}
+
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "Total price of the items",
+			testFunc: func(t *testing.T) {
+				userID := uuid.NewString()
+				addItem(t, userID, 1, 2, money.New(1999, "USD"))
+				addItem(t, userID, 2, 1, money.New(500, "USD"))
+
+				response, err := repo.GetItems(ctx, userID)
+				require.NoError(t, err)
+				assert.Len(t, response.Items, 2)
+				assert.Equal(t, money.New(4498, "USD"), response.TotalPrice)
+			},
+		},
+		{
+			name: "Items in different currencies are an error",
+			testFunc: func(t *testing.T) {
+				userID := uuid.NewString()
+				addItem(t, userID, 1, 1, money.New(1999, "USD"))
+				addItem(t, userID, 2, 1, money.New(1500, "EUR"))
+
+				assert.NotPanics(t, func() {
+					_, err := repo.GetItems(ctx, userID)
+					assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+				})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestMergeCart(t *testing.T) {
+	ctx := context.Background()
+	db, err := setupTestContainer(ctx)
+	require.NoError(t, err)
+
+	repo := repository.NewShoppingCartRepo(db)
+
+	addItem := func(t *testing.T, userID string, itemID int64, quantity int) {
+		_, err := repo.AddItem(ctx, &models.Cart{UserID: userID, ItemID: itemID, Quantity: quantity, Price: money.New(1000, "USD")})
+		require.NoError(t, err)
+	}
+
+	quantities := func(t *testing.T, userID string) map[int64]int {
+		response, err := repo.GetItems(ctx, userID)
+		require.NoError(t, err)
+
+		q := map[int64]int{}
+		for _, item := range response.Items {
+			q[item.ItemID] = item.Quantity
+		}
+		return q
+	}
+
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "Merge into an empty cart",
+			testFunc: func(t *testing.T) {
+				guest, user := uuid.NewString(), uuid.NewString()
+				addItem(t, guest, 1, 2)
+				addItem(t, guest, 2, 1)
+				require.NoError(t, repo.AddCoupon(ctx, guest, "WELCOME"))
+
+				err := repo.MergeCart(ctx, guest, user)
+				require.NoError(t, err)
+
+				assert.Equal(t, map[int64]int{1: 2, 2: 1}, quantities(t, user))
+				assert.Empty(t, quantities(t, guest))
+
+				codes, err := repo.GetCoupons(ctx, user)
+				require.NoError(t, err)
+				assert.Equal(t, []string{"WELCOME"}, codes)
+				codes, err = repo.GetCoupons(ctx, guest)
+				require.NoError(t, err)
+				assert.Empty(t, codes)
+			},
+		},
+		{
+			name: "Quantities of items in both carts are added up",
+			testFunc: func(t *testing.T) {
+				guest, user := uuid.NewString(), uuid.NewString()
+				addItem(t, guest, 1, 2)
+				addItem(t, guest, 2, 1)
+				addItem(t, user, 1, 3)
+				addItem(t, user, 3, 1)
+				require.NoError(t, repo.AddCoupon(ctx, guest, "WELCOME"))
+				require.NoError(t, repo.AddCoupon(ctx, user, "WELCOME"))
+
+				err := repo.MergeCart(ctx, guest, user)
+				require.NoError(t, err)
+
+				assert.Equal(t, map[int64]int{1: 5, 2: 1, 3: 1}, quantities(t, user))
+				codes, err := repo.GetCoupons(ctx, user)
+				require.NoError(t, err)
+				assert.Equal(t, []string{"WELCOME"}, codes)
+			},
+		},
+		{
+			name: "Merging again does not add the quantities twice",
+			testFunc: func(t *testing.T) {
+				guest, user := uuid.NewString(), uuid.NewString()
+				addItem(t, guest, 1, 2)
+				addItem(t, user, 1, 3)
+
+				err := repo.MergeCart(ctx, guest, user)
+				require.NoError(t, err)
+				err = repo.MergeCart(ctx, guest, user)
+				require.NoError(t, err)
+
+				assert.Equal(t, map[int64]int{1: 5}, quantities(t, user))
+			},
+		},
+		{
+			name: "Merging an empty cart leaves the cart as it is",
+			testFunc: func(t *testing.T) {
+				guest, user := uuid.NewString(), uuid.NewString()
+				addItem(t, user, 1, 3)
+
+				err := repo.MergeCart(ctx, guest, user)
+				require.NoError(t, err)
+
+				assert.Equal(t, map[int64]int{1: 3}, quantities(t, user))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestRemoveItems(t *testing.T) {
+	ctx := context.Background()
+	db, err := setupTestContainer(ctx)
+	require.NoError(t, err)
+
+	repo := repository.NewShoppingCartRepo(db)
+
+	newCart := func(t *testing.T, coupons ...string) string {
+		userID := uuid.NewString()
+		for itemID, quantity := range map[int64]int{1: 3, 2: 1} {
+			_, err := repo.AddItem(ctx, &models.Cart{UserID: userID, ItemID: itemID, Quantity: quantity, Price: money.New(1000, "USD")})
+			require.NoError(t, err)
+		}
+		for _, code := range coupons {
+			require.NoError(t, repo.AddCoupon(ctx, userID, code))
+		}
+		return userID
+	}
+
+	testCases := []struct {
+		name        string
+		coupons     []string
+		remove      []models.Item
+		usedCoupons []string
+		wantItems   map[int64]int
+		wantCoupons []string
+	}{
+		{
+			name:        "Ordered quantities and used coupons are removed",
+			coupons:     []string{"SAVE5", "WELCOME"},
+			remove:      []models.Item{{ItemID: 1, Quantity: 2}, {ItemID: 2, Quantity: 1}},
+			usedCoupons: []string{"SAVE5"},
+			wantItems:   map[int64]int{1: 1},
+			wantCoupons: []string{"WELCOME"},
+		},
+		{
+			name:        "Items ordered beyond the cart quantity are removed",
+			coupons:     []string{"SAVE5"},
+			remove:      []models.Item{{ItemID: 1, Quantity: 5}},
+			wantItems:   map[int64]int{2: 1},
+			wantCoupons: []string{"SAVE5"},
+		},
+		{
+			name:      "Items not in the cart are ignored",
+			remove:    []models.Item{{ItemID: 9, Quantity: 1}},
+			wantItems: map[int64]int{1: 3, 2: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			userID := newCart(t, tc.coupons...)
+
+			err := repo.RemoveItems(ctx, userID, tc.remove, tc.usedCoupons)
+			require.NoError(t, err)
+
+			response, err := repo.GetItems(ctx, userID)
+			require.NoError(t, err)
+			items := map[int64]int{}
+			for _, item := range response.Items {
+				items[item.ItemID] = item.Quantity
+			}
+			assert.Equal(t, tc.wantItems, items)
+			assert.ElementsMatch(t, tc.wantCoupons, response.CouponCodes)
+		})
+	}
+}
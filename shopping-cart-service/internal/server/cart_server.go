@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+
+	"github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/shopping-cart-service/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CartServer exposes shopping carts to other services
+type CartServer struct {
+	proto.UnimplementedCartServiceServer
+
+	service *service.CartService
+}
+
+func NewCartServer(service *service.CartService) *CartServer {
+	return &CartServer{
+		service: service,
+	}
+}
+
+// GetCart returns the shopping cart of a user
+func (s *CartServer) GetCart(ctx context.Context, req *proto.GetCartRequest) (*proto.Cart, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+
+	cart, err := s.service.GetCart(ctx, req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	res := &proto.Cart{UserId: req.UserId, CouponCodes: cart.CouponCodes}
+
+	for _, item := range cart.Items {
+		res.Items = append(res.Items, &proto.CartItem{
+			ProductId: item.ItemID,
+			Quantity:  int32(item.Quantity),
+			Price:     &proto.Money{Amount: item.Price.Amount, Currency: item.Price.Currency},
+		})
+	}
+
+	return res, nil
+}
+
+// RemoveItems takes the items of a placed order off the shopping cart of a user
+func (s *CartServer) RemoveItems(ctx context.Context, req *proto.RemoveItemsRequest) (*proto.RemoveItemsResponse, error) {
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+
+	items := make([]models.Item, 0, len(req.Items))
+	for _, item := range req.Items {
+		if item.Quantity < 1 {
+			return nil, status.Error(codes.InvalidArgument, "quantity must be positive")
+		}
+		items = append(items, models.Item{ItemID: item.ProductId, Quantity: int(item.Quantity)})
+	}
+
+	if err := s.service.RemoveItems(ctx, req.UserId, items, req.CouponCodes); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &proto.RemoveItemsResponse{}, nil
+}
@@ -2,23 +2,35 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strconv"
+	"log"
+	"slices"
+	"strings"
 
+	"github.com/NeGat1FF/e-commerce/money"
 	"github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/models"
 	"github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/repository"
 	"github.com/NeGat1FF/e-commerce/shopping-cart-service/proto"
 )
 
+var (
+	ErrCouponRequired   = errors.New("coupon code is required")
+	ErrInvalidCoupon    = errors.New("coupon code is invalid, expired or used up")
+	ErrCurrencyMismatch = errors.New("item is priced in another currency than the shopping cart")
+)
+
 type CartService struct {
-	repo         repository.ShoppingCartRepoInterface
-	priceService proto.PriceServiceClient
+	repo             repository.ShoppingCartRepoInterface
+	priceService     proto.PriceServiceClient
+	promotionService proto.PromotionServiceClient
 }
 
-func NewCartService(repo repository.ShoppingCartRepoInterface, priceService proto.PriceServiceClient) *CartService {
+func NewCartService(repo repository.ShoppingCartRepoInterface, priceService proto.PriceServiceClient, promotionService proto.PromotionServiceClient) *CartService {
 	return &CartService{
-		repo:         repo,
-		priceService: priceService,
+		repo:             repo,
+		priceService:     priceService,
+		promotionService: promotionService,
 	}
 }
 
@@ -29,10 +41,20 @@ func (s *CartService) AddItem(ctx context.Context, userID string, item *models.I
 		return models.GetCartResponse{}, err
 	}
 
-	price, err := strconv.ParseFloat(priceRes.Price, 64)
+	price := money.New(priceRes.Price.GetAmount(), priceRes.Price.GetCurrency())
+	if _, err := money.Digits(price.Currency); err != nil {
+		return models.GetCartResponse{}, err
+	}
+
+	current, err := s.repo.GetItems(ctx, userID)
 	if err != nil {
 		return models.GetCartResponse{}, err
 	}
+	for _, other := range current.Items {
+		if other.ItemID != item.ItemID && other.Price.Currency != price.Currency {
+			return models.GetCartResponse{}, ErrCurrencyMismatch
+		}
+	}
 
 	var cart models.Cart
 	cart.UserID = userID
@@ -45,7 +67,7 @@ func (s *CartService) AddItem(ctx context.Context, userID string, item *models.I
 		return models.GetCartResponse{}, err
 	}
 
-	return response, nil
+	return s.withDiscounts(ctx, response), nil
 }
 
 // SetQuantity sets the quantity of an item in the shopping cart
@@ -55,7 +77,7 @@ func (s *CartService) SetQuantity(ctx context.Context, userID string, itemID int
 		return models.GetCartResponse{}, err
 	}
 
-	return response, nil
+	return s.withDiscounts(ctx, response), nil
 }
 
 // RemoveItem removes an item from the shopping cart
@@ -65,15 +87,112 @@ func (s *CartService) RemoveItem(ctx context.Context, userID string, itemID int6
 		return models.GetCartResponse{}, err
 	}
 
-	return response, nil
+	return s.withDiscounts(ctx, response), nil
 }
 
-// GetItems returns all items in the shopping cart
+// GetItems returns all items in the shopping cart with the discounts it gets
 func (s *CartService) GetItems(ctx context.Context, userID string) (models.GetCartResponse, error) {
+	response, err := s.repo.GetItems(ctx, userID)
+	if err != nil {
+		return models.GetCartResponse{}, err
+	}
+
+	return s.withDiscounts(ctx, response), nil
+}
+
+// GetCart returns the items and coupon codes of the shopping cart, the order service works out
+// the discounts itself at checkout
+func (s *CartService) GetCart(ctx context.Context, userID string) (models.GetCartResponse, error) {
 	return s.repo.GetItems(ctx, userID)
 }
 
+// AddCoupon enters a coupon code for the shopping cart. Codes the order service rejects are not
+// kept, if it cannot be reached the code is kept and checked at checkout.
+func (s *CartService) AddCoupon(ctx context.Context, userID, code string) (models.GetCartResponse, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return models.GetCartResponse{}, ErrCouponRequired
+	}
+
+	if err := s.repo.AddCoupon(ctx, userID, code); err != nil {
+		return models.GetCartResponse{}, err
+	}
+
+	response, err := s.GetItems(ctx, userID)
+	if err != nil {
+		return models.GetCartResponse{}, err
+	}
+
+	if slices.Contains(response.InvalidCoupons, code) {
+		if err := s.repo.RemoveCoupon(ctx, userID, code); err != nil {
+			return models.GetCartResponse{}, err
+		}
+		return models.GetCartResponse{}, ErrInvalidCoupon
+	}
+
+	return response, nil
+}
+
+// RemoveCoupon removes a coupon code from the shopping cart
+func (s *CartService) RemoveCoupon(ctx context.Context, userID, code string) (models.GetCartResponse, error) {
+	if err := s.repo.RemoveCoupon(ctx, userID, strings.ToUpper(strings.TrimSpace(code))); err != nil {
+		return models.GetCartResponse{}, err
+	}
+
+	return s.GetItems(ctx, userID)
+}
+
+// withDiscounts adds the discounts the order service gives the cart. The cart is still shown
+// without them if the order service cannot be reached.
+func (s *CartService) withDiscounts(ctx context.Context, cart models.GetCartResponse) models.GetCartResponse {
+	cart.Discounts = []models.Discount{}
+	cart.DiscountedPrice = cart.TotalPrice
+	if len(cart.Items) == 0 {
+		return cart
+	}
+
+	req := &proto.PreviewDiscountsRequest{UserId: cart.UserID, CouponCodes: cart.CouponCodes}
+	for _, item := range cart.Items {
+		req.Items = append(req.Items, &proto.PreviewItem{
+			ProductId: item.ItemID,
+			Quantity:  int32(item.Quantity),
+			Price:     &proto.Money{Amount: item.Price.Amount, Currency: item.Price.Currency},
+		})
+	}
+
+	res, err := s.promotionService.PreviewDiscounts(ctx, req)
+	if err != nil {
+		log.Println("failed to preview discounts: ", err)
+		return cart
+	}
+
+	if res.Total.GetCurrency() != cart.TotalPrice.Currency {
+		log.Printf("discounts are in %s, the shopping cart is in %s", res.Total.GetCurrency(), cart.TotalPrice.Currency)
+		return cart
+	}
+
+	for _, d := range res.Discounts {
+		cart.Discounts = append(cart.Discounts, models.Discount{
+			PromotionID: d.PromotionId,
+			Code:        d.Code,
+			Name:        d.Name,
+			Type:        d.Type,
+			Amount:      money.New(d.Amount.GetAmount(), d.Amount.GetCurrency()),
+		})
+	}
+	cart.Discount = money.New(res.Total.GetAmount(), res.Total.GetCurrency())
+	cart.DiscountedPrice = cart.TotalPrice.Sub(cart.Discount)
+	cart.InvalidCoupons = res.InvalidCodes
+
+	return cart
+}
+
 // DeleteCart deletes the shopping cart
 func (s *CartService) DeleteCart(ctx context.Context, userID string) error {
 	return s.repo.DeleteCart(ctx, userID)
 }
+
+// RemoveItems takes the items of a placed order off the shopping cart with the coupon codes it used
+func (s *CartService) RemoveItems(ctx context.Context, userID string, items []models.Item, couponCodes []string) error {
+	return s.repo.RemoveItems(ctx, userID, items, couponCodes)
+}
@@ -0,0 +1,289 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/money"
+	"github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/shopping-cart-service/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// fakeRepo keeps shopping carts in memory
+type fakeRepo struct {
+	repository.ShoppingCartRepoInterface
+	items   map[string][]models.Item
+	coupons map[string][]string
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{items: map[string][]models.Item{}, coupons: map[string][]string{}}
+}
+
+func (r *fakeRepo) AddItem(ctx context.Context, cart *models.Cart) (models.GetCartResponse, error) {
+	item := models.Item{ItemID: cart.ItemID, Quantity: cart.Quantity, Price: cart.Price}
+
+	items := r.items[cart.UserID]
+	for i := range items {
+		if items[i].ItemID == item.ItemID {
+			items[i] = item
+			return r.GetItems(ctx, cart.UserID)
+		}
+	}
+	r.items[cart.UserID] = append(items, item)
+
+	return r.GetItems(ctx, cart.UserID)
+}
+
+func (r *fakeRepo) GetItems(ctx context.Context, userID string) (models.GetCartResponse, error) {
+	response := models.GetCartResponse{UserID: userID, Items: r.items[userID], CouponCodes: append([]string{}, r.coupons[userID]...)}
+	for _, item := range response.Items {
+		total, err := money.Sum(response.TotalPrice, item.Price.Mul(int64(item.Quantity)))
+		if err != nil {
+			return models.GetCartResponse{}, err
+		}
+		response.TotalPrice = total
+	}
+
+	return response, nil
+}
+
+func (r *fakeRepo) AddCoupon(ctx context.Context, userID, code string) error {
+	if !slices.Contains(r.coupons[userID], code) {
+		r.coupons[userID] = append(r.coupons[userID], code)
+	}
+	return nil
+}
+
+func (r *fakeRepo) RemoveCoupon(ctx context.Context, userID, code string) error {
+	r.coupons[userID] = slices.DeleteFunc(r.coupons[userID], func(c string) bool { return c == code })
+	return nil
+}
+
+// fakePrices prices products by their ID
+type fakePrices map[int64]money.Money
+
+func (p fakePrices) GetPrice(ctx context.Context, in *proto.PriceRequest, opts ...grpc.CallOption) (*proto.PriceResponse, error) {
+	id, err := strconv.ParseInt(in.ProductId, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	price := p[id]
+	return &proto.PriceResponse{Price: &proto.Money{Amount: price.Amount, Currency: price.Currency}, Stock: 10}, nil
+}
+
+// fakePromotions gives the discounts of the known coupon codes and rejects the others. The
+// discounts are in the currency of the cart unless another currency is set.
+type fakePromotions struct {
+	coupons  map[string]int64
+	currency string
+	err      error
+}
+
+func (p fakePromotions) PreviewDiscounts(ctx context.Context, in *proto.PreviewDiscountsRequest, opts ...grpc.CallOption) (*proto.PreviewDiscountsResponse, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	currency := p.currency
+	if currency == "" {
+		currency = in.Items[0].Price.Currency
+	}
+
+	res := &proto.PreviewDiscountsResponse{Total: &proto.Money{Currency: currency}}
+	for _, code := range in.CouponCodes {
+		amount, ok := p.coupons[code]
+		if !ok {
+			res.InvalidCodes = append(res.InvalidCodes, code)
+			continue
+		}
+		res.Discounts = append(res.Discounts, &proto.Discount{
+			PromotionId: "promotion-" + code,
+			Code:        code,
+			Name:        code,
+			Type:        "fixed_amount",
+			Amount:      &proto.Money{Amount: amount, Currency: currency},
+		})
+		res.Total.Amount += amount
+	}
+
+	return res, nil
+}
+
+func TestAddItemCurrency(t *testing.T) {
+	prices := fakePrices{
+		1: money.New(1999, "USD"),
+		2: money.New(500, "USD"),
+		3: money.New(1500, "EUR"),
+	}
+
+	testCases := []struct {
+		name      string
+		cart      []models.Item
+		itemID    int64
+		wantErr   error
+		wantTotal money.Money
+	}{
+		{
+			name:      "Empty cart takes the currency of the item",
+			itemID:    3,
+			wantTotal: money.New(1500, "EUR"),
+		},
+		{
+			name:      "Same currency",
+			cart:      []models.Item{{ItemID: 1, Quantity: 2, Price: money.New(1999, "USD")}},
+			itemID:    2,
+			wantTotal: money.New(4498, "USD"),
+		},
+		{
+			name:    "Other currency",
+			cart:    []models.Item{{ItemID: 1, Quantity: 2, Price: money.New(1999, "USD")}},
+			itemID:  3,
+			wantErr: service.ErrCurrencyMismatch,
+		},
+		{
+			name:      "Item that is the only one in the cart is priced again",
+			cart:      []models.Item{{ItemID: 3, Quantity: 1, Price: money.New(1800, "USD")}},
+			itemID:    3,
+			wantTotal: money.New(1500, "EUR"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newFakeRepo()
+			repo.items["user"] = tc.cart
+			s := service.NewCartService(repo, prices, fakePromotions{})
+
+			response, err := s.AddItem(context.Background(), "user", &models.Item{ItemID: tc.itemID, Quantity: 1})
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Equal(t, tc.cart, repo.items["user"], "the cart is left as it was")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantTotal, response.TotalPrice)
+		})
+	}
+}
+
+func TestGetItemsDiscounts(t *testing.T) {
+	items := []models.Item{{ItemID: 1, Quantity: 2, Price: money.New(1000, "USD")}}
+
+	testCases := []struct {
+		name               string
+		coupons            []string
+		promotions         fakePromotions
+		wantDiscount       money.Money
+		wantDiscounted     money.Money
+		wantDiscounts      int
+		wantInvalidCoupons []string
+	}{
+		{
+			name:           "Discount is taken off the total price",
+			coupons:        []string{"SAVE5"},
+			promotions:     fakePromotions{coupons: map[string]int64{"SAVE5": 500}},
+			wantDiscount:   money.New(500, "USD"),
+			wantDiscounted: money.New(1500, "USD"),
+			wantDiscounts:  1,
+		},
+		{
+			name:               "Rejected coupon is reported",
+			coupons:            []string{"SAVE5", "BOGUS"},
+			promotions:         fakePromotions{coupons: map[string]int64{"SAVE5": 500}},
+			wantDiscount:       money.New(500, "USD"),
+			wantDiscounted:     money.New(1500, "USD"),
+			wantDiscounts:      1,
+			wantInvalidCoupons: []string{"BOGUS"},
+		},
+		{
+			name:           "Cart is shown without discounts if the order service is down",
+			coupons:        []string{"SAVE5"},
+			promotions:     fakePromotions{err: errors.New("unavailable")},
+			wantDiscounted: money.New(2000, "USD"),
+		},
+		{
+			name:           "Discounts in another currency are ignored",
+			coupons:        []string{"SAVE5"},
+			promotions:     fakePromotions{coupons: map[string]int64{"SAVE5": 500}, currency: "EUR"},
+			wantDiscounted: money.New(2000, "USD"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newFakeRepo()
+			repo.items["user"] = items
+			repo.coupons["user"] = tc.coupons
+			s := service.NewCartService(repo, fakePrices{}, tc.promotions)
+
+			response, err := s.GetItems(context.Background(), "user")
+			require.NoError(t, err)
+			assert.Equal(t, money.New(2000, "USD"), response.TotalPrice)
+			assert.Equal(t, tc.wantDiscount, response.Discount)
+			assert.Equal(t, tc.wantDiscounted, response.DiscountedPrice)
+			assert.Len(t, response.Discounts, tc.wantDiscounts)
+			assert.Equal(t, tc.wantInvalidCoupons, response.InvalidCoupons)
+		})
+	}
+}
+
+func TestAddCoupon(t *testing.T) {
+	items := []models.Item{{ItemID: 1, Quantity: 2, Price: money.New(1000, "USD")}}
+
+	testCases := []struct {
+		name        string
+		code        string
+		promotions  fakePromotions
+		wantErr     error
+		wantCoupons []string
+	}{
+		{
+			name:        "Valid coupon is kept",
+			code:        " save5 ",
+			promotions:  fakePromotions{coupons: map[string]int64{"SAVE5": 500}},
+			wantCoupons: []string{"SAVE5"},
+		},
+		{
+			name:       "Rejected coupon is dropped",
+			code:       "BOGUS",
+			promotions: fakePromotions{coupons: map[string]int64{"SAVE5": 500}},
+			wantErr:    service.ErrInvalidCoupon,
+		},
+		{
+			name:        "Coupon is kept if the order service is down",
+			code:        "SAVE5",
+			promotions:  fakePromotions{err: errors.New("unavailable")},
+			wantCoupons: []string{"SAVE5"},
+		},
+		{
+			name:    "Empty code",
+			code:    " ",
+			wantErr: service.ErrCouponRequired,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newFakeRepo()
+			repo.items["user"] = items
+			s := service.NewCartService(repo, fakePrices{}, tc.promotions)
+
+			response, err := s.AddCoupon(context.Background(), "user", tc.code)
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.ElementsMatch(t, tc.wantCoupons, repo.coupons["user"])
+			if tc.wantErr == nil {
+				assert.Equal(t, tc.wantCoupons, response.CouponCodes)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	messagequeue "github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/messageQueue"
+	"github.com/NeGat1FF/e-commerce/shopping-cart-service/internal/repository"
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// UserDeletedEvent is published by the user service when a user's personal data is erased
+type UserDeletedEvent struct {
+	UserID string `json:"user_id"`
+}
+
+// UserMergedEvent is published by the user service when a guest logs into an existing account
+type UserMergedEvent struct {
+	GuestID string `json:"guest_id"`
+	UserID  string `json:"user_id"`
+}
+
+// UserEventsConsumer reacts to events published by the user service
+type UserEventsConsumer struct {
+	messageQueue *messagequeue.RabbitMQClient
+	repo         repository.ShoppingCartRepoInterface
+	queueName    string
+}
+
+func NewUserEventsConsumer(mq *messagequeue.RabbitMQClient, repo repository.ShoppingCartRepoInterface, queueName string) *UserEventsConsumer {
+	return &UserEventsConsumer{
+		messageQueue: mq,
+		repo:         repo,
+		queueName:    queueName,
+	}
+}
+
+// Start starts consuming user events in the background
+func (c *UserEventsConsumer) Start() error {
+	ch, err := c.messageQueue.GetConsumeChannel(c.queueName, "", false, false, false)
+	if err != nil {
+		return err
+	}
+	go c.processMessages(ch)
+	return nil
+}
+
+func (c *UserEventsConsumer) processMessages(ch <-chan amqp091.Delivery) {
+	for msg := range ch {
+		switch msg.RoutingKey {
+		case "user.deleted":
+			c.userDeleted(msg)
+		case "user.merged":
+			c.userMerged(msg)
+		default:
+			msg.Ack(false)
+		}
+	}
+}
+
+// userDeleted drops the cart of an erased user
+func (c *UserEventsConsumer) userDeleted(msg amqp091.Delivery) {
+	var event UserDeletedEvent
+	if err := json.Unmarshal(msg.Body, &event); err != nil || event.UserID == "" {
+		log.Println("failed to unmarshal user.deleted event: ", err)
+		msg.Reject(false)
+		return
+	}
+
+	if err := c.repo.DeleteCart(context.Background(), event.UserID); err != nil {
+		log.Println("failed to delete cart: ", err)
+		msg.Nack(false, !msg.Redelivered)
+		return
+	}
+
+	msg.Ack(false)
+}
+
+// userMerged moves the cart of a guest into the account the guest logged into
+func (c *UserEventsConsumer) userMerged(msg amqp091.Delivery) {
+	var event UserMergedEvent
+	if err := json.Unmarshal(msg.Body, &event); err != nil || event.GuestID == "" || event.UserID == "" {
+		log.Println("failed to unmarshal user.merged event: ", err)
+		msg.Reject(false)
+		return
+	}
+
+	if err := c.repo.MergeCart(context.Background(), event.GuestID, event.UserID); err != nil {
+		log.Println("failed to merge cart: ", err)
+		msg.Nack(false, !msg.Redelivered)
+		return
+	}
+
+	msg.Ack(false)
+}
@@ -0,0 +1,65 @@
+// Command devca writes a development CA and a certificate for every service:
+//
+//	go run ./cmd/devca -out ../certs
+//
+// The files are named ca.pem, <service>.pem and <service>-key.pem.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/svcauth/devca"
+)
+
+const defaultServices = "user-service,order-service,shopping-cart-service,product-service,notification-service,payment-service"
+
+func main() {
+	out := flag.String("out", "certs", "directory the files are written to")
+	services := flag.String("services", defaultServices, "comma separated names of the services")
+	hosts := flag.String("hosts", "localhost,127.0.0.1", "comma separated extra host names and IPs of every certificate")
+	validFor := flag.Duration("valid-for", 365*24*time.Hour, "validity of the certificates")
+	flag.Parse()
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		log.Fatalf("create %s: %v", *out, err)
+	}
+
+	ca, err := devca.New("e-commerce dev CA", *validFor)
+	if err != nil {
+		log.Fatalf("create CA: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(*out, "ca.pem"), ca.CertPEM, 0o644); err != nil {
+		log.Fatalf("write CA: %v", err)
+	}
+
+	extraHosts := splitList(*hosts)
+
+	for _, service := range splitList(*services) {
+		cert, err := ca.Issue(service, extraHosts, *validFor)
+		if err != nil {
+			log.Fatalf("issue certificate for %s: %v", service, err)
+		}
+
+		if err := cert.WriteFiles(*out, service); err != nil {
+			log.Fatalf("write certificate for %s: %v", service, err)
+		}
+
+		log.Printf("wrote certificate for %s", service)
+	}
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
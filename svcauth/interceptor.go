@@ -0,0 +1,111 @@
+package svcauth
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Policy lists the services allowed to call each method. Keys are full method names
+// such as "/proto.MailService/SendMail", or "/proto.MailService/*" for every method
+// of a service. Methods that are not listed can't be called by anyone.
+type Policy map[string][]string
+
+// Allowed reports whether the service may call the method.
+func (p Policy) Allowed(fullMethod, service string) bool {
+	if slices.Contains(p[fullMethod], service) {
+		return true
+	}
+
+	i := strings.LastIndex(fullMethod, "/")
+	if i < 0 {
+		return false
+	}
+
+	return slices.Contains(p[fullMethod[:i+1]+"*"], service)
+}
+
+type callerKey struct{}
+
+// Caller returns the name of the service that made the call, it is set by the interceptors.
+func Caller(ctx context.Context) (string, bool) {
+	service, ok := ctx.Value(callerKey{}).(string)
+	return service, ok
+}
+
+// peerService returns the common name of the verified client certificate of the call.
+func peerService(ctx context.Context) (string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "no peer")
+	}
+
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "connection is not authenticated with TLS")
+	}
+
+	if len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return "", status.Error(codes.Unauthenticated, "no verified client certificate")
+	}
+
+	service := info.State.VerifiedChains[0][0].Subject.CommonName
+	if service == "" {
+		return "", status.Error(codes.Unauthenticated, "client certificate has no common name")
+	}
+
+	return service, nil
+}
+
+func authorize(ctx context.Context, policy Policy, fullMethod string) (context.Context, error) {
+	service, err := peerService(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !policy.Allowed(fullMethod, service) {
+		return nil, status.Errorf(codes.PermissionDenied, "%s may not call %s", service, fullMethod)
+	}
+
+	return context.WithValue(ctx, callerKey{}, service), nil
+}
+
+// UnaryServerInterceptor rejects unary calls from services the policy doesn't allow.
+func UnaryServerInterceptor(policy Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authorize(ctx, policy, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor rejects streams from services the policy doesn't allow.
+func StreamServerInterceptor(policy Policy) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), policy, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// serverStream carries the context with the caller to the handler.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,181 @@
+package svcauth
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/svcauth/devca"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestPolicyAllowed(t *testing.T) {
+	policy := Policy{
+		"/proto.MailService/SendMail": {"user-service"},
+		"/proto.PriceService/*":       {"order-service", "shopping-cart-service"},
+	}
+
+	testCases := []struct {
+		name    string
+		method  string
+		service string
+		allowed bool
+	}{
+		{name: "Listed method", method: "/proto.MailService/SendMail", service: "user-service", allowed: true},
+		{name: "Listed method, other service", method: "/proto.MailService/SendMail", service: "order-service"},
+		{name: "Wildcard", method: "/proto.PriceService/GetPrice", service: "shopping-cart-service", allowed: true},
+		{name: "Wildcard, other service", method: "/proto.PriceService/GetPrice", service: "user-service"},
+		{name: "Unlisted method", method: "/proto.SmsService/SendSms", service: "user-service"},
+		{name: "Malformed method", method: "SendMail", service: "user-service"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.allowed, policy.Allowed(tc.method, tc.service))
+		})
+	}
+}
+
+// writeConfig writes the certificate of a service signed by the CA, and the CA, to a temporary directory.
+func writeConfig(t *testing.T, ca *devca.CA, service string) Config {
+	dir := t.TempDir()
+
+	cert, err := ca.Issue(service, []string{"localhost"}, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, cert.WriteFiles(dir, service))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ca.pem"), ca.CertPEM, 0o644))
+
+	return Config{
+		CertFile: filepath.Join(dir, service+".pem"),
+		KeyFile:  filepath.Join(dir, service+"-key.pem"),
+		CAFile:   filepath.Join(dir, "ca.pem"),
+	}
+}
+
+func TestServerAuthentication(t *testing.T) {
+	ca, err := devca.New("test CA", time.Hour)
+	require.NoError(t, err)
+
+	otherCA, err := devca.New("other CA", time.Hour)
+	require.NoError(t, err)
+
+	policy := Policy{
+		"/grpc.health.v1.Health/Check": {"order-service"},
+		"/grpc.health.v1.Health/*":     {"user-service"},
+	}
+
+	opts, err := ServerOptions(writeConfig(t, ca, "product-service"), policy)
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(opts...)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	dial := func(t *testing.T, creds credentials.TransportCredentials) healthpb.HealthClient {
+		conn, err := grpc.NewClient("passthrough:///localhost",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+			grpc.WithTransportCredentials(creds),
+		)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+
+		return healthpb.NewHealthClient(conn)
+	}
+
+	clientCreds := func(t *testing.T, ca *devca.CA, service string) credentials.TransportCredentials {
+		tlsConfig, err := ClientTLSConfig(writeConfig(t, ca, service))
+		require.NoError(t, err)
+		return credentials.NewTLS(tlsConfig)
+	}
+
+	// Trusts the server but presents no certificate of its own
+	noCertCreds := func(t *testing.T) credentials.TransportCredentials {
+		tlsConfig, err := ClientTLSConfig(writeConfig(t, ca, "unused"))
+		require.NoError(t, err)
+		return credentials.NewTLS(&tls.Config{RootCAs: tlsConfig.RootCAs, MinVersion: tls.VersionTLS13})
+	}
+
+	testCases := []struct {
+		name   string
+		creds  func(t *testing.T) credentials.TransportCredentials
+		stream bool
+		code   codes.Code
+	}{
+		{
+			name:  "Allowed method",
+			creds: func(t *testing.T) credentials.TransportCredentials { return clientCreds(t, ca, "order-service") },
+			code:  codes.OK,
+		},
+		{
+			name:   "Method not allowed for the service",
+			creds:  func(t *testing.T) credentials.TransportCredentials { return clientCreds(t, ca, "order-service") },
+			stream: true,
+			code:   codes.PermissionDenied,
+		},
+		{
+			name:   "Stream allowed by wildcard",
+			creds:  func(t *testing.T) credentials.TransportCredentials { return clientCreds(t, ca, "user-service") },
+			stream: true,
+			code:   codes.OK,
+		},
+		{
+			name:  "Unknown service",
+			creds: func(t *testing.T) credentials.TransportCredentials { return clientCreds(t, ca, "search-service") },
+			code:  codes.PermissionDenied,
+		},
+		{
+			name:  "Certificate of another CA",
+			creds: func(t *testing.T) credentials.TransportCredentials { return clientCreds(t, otherCA, "order-service") },
+			code:  codes.Unavailable,
+		},
+		{
+			name:  "No client certificate",
+			creds: noCertCreds,
+			code:  codes.Unavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := dial(t, tc.creds(t))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if tc.stream {
+				stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+				if err == nil {
+					_, err = stream.Recv()
+				}
+				assert.Equal(t, tc.code, status.Code(err), err)
+				return
+			}
+
+			_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+			assert.Equal(t, tc.code, status.Code(err), err)
+		})
+	}
+}
+
+func TestMissingCertificates(t *testing.T) {
+	_, err := ServerOptions(Config{}, Policy{})
+	assert.ErrorIs(t, err, ErrMissingCertificates)
+
+	_, err = DialOption(Config{CertFile: "cert.pem"})
+	assert.ErrorIs(t, err, ErrMissingCertificates)
+}
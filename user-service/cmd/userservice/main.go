@@ -1,10 +1,19 @@
 package main
 
 import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/svcauth"
 	"github.com/NeGat1FF/e-commerce/user-service/internal/config"
 	"github.com/NeGat1FF/e-commerce/user-service/internal/db"
 	"github.com/NeGat1FF/e-commerce/user-service/internal/handlers"
+	messagequeue "github.com/NeGat1FF/e-commerce/user-service/internal/messageQueue"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/oauth"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/password"
 	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/server"
 	"github.com/NeGat1FF/e-commerce/user-service/internal/service"
 	"github.com/NeGat1FF/e-commerce/user-service/pkg/logger"
 	"github.com/NeGat1FF/e-commerce/user-service/proto"
@@ -12,7 +21,6 @@ import (
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 func main() {
@@ -27,34 +35,178 @@ func main() {
 		logger.Logger.Fatal("Failed to connect to the database", zap.Error(err))
 	}
 
-	var opts []grpc.DialOption
-	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	tlsConfig := svcauth.Config{CertFile: config.TLSCertFile, KeyFile: config.TLSKeyFile, CAFile: config.TLSCAFile}
+
+	creds, err := svcauth.DialOption(tlsConfig)
+	if err != nil {
+		logger.Logger.Fatal("Failed to load service certificates", zap.Error(err))
+	}
 
-	conn, err := grpc.NewClient(config.NOTIFICATION_URL, opts...)
+	conn, err := grpc.NewClient(config.NOTIFICATION_URL, creds)
 	if err != nil {
 		logger.Logger.Fatal("Failed to connect to the notification service", zap.Error(err))
 	}
 
 	notifcationService := proto.NewMailServiceClient(conn)
+	smsService := proto.NewSmsServiceClient(conn)
+
+	orderConn, err := grpc.NewClient(config.ORDER_URL, creds)
+	if err != nil {
+		logger.Logger.Fatal("Failed to connect to the order service", zap.Error(err))
+	}
+
+	orderService := proto.NewOrderServiceClient(orderConn)
+
+	mqConn, err := messagequeue.ConnectRabbitMQ(config.MessageBrokerURL)
+	if err != nil {
+		logger.Logger.Fatal("Failed to connect to the message broker", zap.Error(err))
+	}
+	defer mqConn.Close()
+
+	mqClient, err := messagequeue.NewRabbitMQClient(mqConn)
+	if err != nil {
+		logger.Logger.Fatal("Failed to create message queue client", zap.Error(err))
+	}
+
+	providers := make(map[string]oauth.Provider)
+
+	if config.GoogleClientID != "" {
+		google, err := oauth.NewOIDCProvider(context.Background(), "google", "https://accounts.google.com", config.GoogleClientID, config.GoogleClientSecret, config.OAuthRedirectURL+"/google/callback")
+		if err != nil {
+			logger.Logger.Fatal("Failed to create Google provider", zap.Error(err))
+		}
+		providers[google.Name()] = google
+	}
+
+	if config.GitHubClientID != "" {
+		github := oauth.NewGitHubProvider(config.GitHubClientID, config.GitHubClientSecret, config.OAuthRedirectURL+"/github/callback")
+		providers[github.Name()] = github
+	}
+
+	// Any other OpenID Connect issuer, e.g. a local mock provider for development
+	if config.OIDCIssuerURL != "" {
+		provider, err := oauth.NewOIDCProvider(context.Background(), "oidc", config.OIDCIssuerURL, config.OIDCClientID, config.OIDCClientSecret, config.OAuthRedirectURL+"/oidc/callback")
+		if err != nil {
+			logger.Logger.Fatal("Failed to create OIDC provider", zap.Error(err))
+		}
+		providers[provider.Name()] = provider
+	}
+
+	hasher, err := password.NewDefaultHasher(config.PasswordHashAlgorithm)
+	if err != nil {
+		logger.Logger.Fatal("Failed to create password hasher", zap.Error(err))
+	}
+
+	var breached *password.BreachedList
+	if config.BreachedPasswordsFile != "" {
+		breached, err = password.LoadBreachedList(config.BreachedPasswordsFile)
+		if err != nil {
+			logger.Logger.Fatal("Failed to load breached passwords", zap.Error(err))
+		}
+		logger.Logger.Info("Loaded breached passwords", zap.Int("count", breached.Len()))
+	}
 
 	repo := repository.NewUserRepository(db)
-	service := service.NewUserService(repo, notifcationService, config.JWTSecret, config.Addr, config.Port)
-	handler := handlers.NewUserHandler(service)
+	activityRepo := repository.NewActivityRepository(db)
+	userService := service.NewUserService(repo, activityRepo, notifcationService, smsService, providers, hasher, password.NewPolicy(breached), mqClient, config.MessageBrokerExchange, config.JWTSecret, config.Addr, config.Port)
+	handler := handlers.NewUserHandler(userService)
+
+	addressRepo := repository.NewAddressRepository(db)
+	addressService := service.NewAddressService(addressRepo, config.JWTSecret)
+	addressHandler := handlers.NewAddressHandler(addressService)
+
+	prefsRepo := repository.NewPreferencesRepository(db)
+	prefsService := service.NewPreferencesService(prefsRepo, config.JWTSecret, config.Addr, config.Port)
+	prefsHandler := handlers.NewPreferencesHandler(prefsService)
+
+	privacyService := service.NewPrivacyService(repo, addressRepo, prefsRepo, orderService, mqClient, config.MessageBrokerExchange, config.JWTSecret, config.ErasureGracePeriod)
+	privacyHandler := handlers.NewPrivacyHandler(privacyService)
+
+	go privacyService.StartErasureWorker(context.Background(), time.Hour)
+
+	serverOpts, err := svcauth.ServerOptions(tlsConfig, svcauth.Policy{
+		"/proto.AuthService/*":             {"order-service", "shopping-cart-service", "product-service", "payment-service"},
+		"/proto.AddressService/GetAddress": {"order-service"},
+		"/proto.PreferencesService/*":      {"notification-service"},
+	})
+	if err != nil {
+		logger.Logger.Fatal("Failed to load service certificates", zap.Error(err))
+	}
+
+	grpcServer := grpc.NewServer(serverOpts...)
+	proto.RegisterAddressServiceServer(grpcServer, server.NewAddressServer(addressService))
+	proto.RegisterAuthServiceServer(grpcServer, server.NewAuthServer(service.NewAuthService(repo, config.JWTSecret)))
+	proto.RegisterPreferencesServiceServer(grpcServer, server.NewPreferencesServer(prefsService))
+
+	lis, err := net.Listen("tcp", ":50051")
+	if err != nil {
+		logger.Logger.Fatal("Failed to listen", zap.Error(err))
+	}
+
+	go func() {
+		logger.Logger.Info("Starting gRPC server")
+		err := grpcServer.Serve(lis)
+		if err != nil {
+			logger.Logger.Error("Failed to start gRPC server", zap.Error(err))
+		}
+	}()
 
 	ginServer := gin.Default()
+	ginServer.Use(handlers.ClientInfo())
 
 	route := ginServer.Group("/api/v1/user")
 
 	route.POST("/register", handler.Register)
 	route.POST("/login", handler.Login)
+	route.POST("/login/magic-link", handler.RequestMagicLink)
+	route.POST("/login/magic-link/verify", handler.LoginWithMagicLink)
+	route.POST("/login/phone", handler.RequestPhoneLogin)
+	route.POST("/login/phone/verify", handler.LoginWithPhone)
+	route.POST("/login/2fa", handler.LoginWithTwoFactor)
+	route.POST("/guest", handler.CreateGuest)
+	route.POST("/guest/upgrade", handler.UpgradeGuest)
 	route.POST("/refresh_token", handler.RefreshToken)
+	route.POST("/logout", handler.Logout)
 	route.POST("/resend_verification_email", handler.ResendVerificationEmail)
 	route.POST("/verify_email", handler.VerifyEmail)
 	route.POST("/forgot_password", handler.ForgotPassword)
 	route.POST("/reset_password", handler.ResetPassword)
+	route.POST("/change_password", handler.ChangePassword)
+
+	route.GET("/oauth/:provider/login", handler.OAuthLogin)
+	route.GET("/oauth/:provider/callback", handler.OAuthCallback)
+
+	route.GET("/activity", handler.GetActivity)
 
 	route.PATCH("/update", handler.UpdateUser)
-	route.DELETE("/delete", handler.DeleteUser)
+	route.POST("/change_email", handler.ChangeEmail)
+	route.POST("/confirm_email_change", handler.ConfirmEmailChange)
+	route.POST("/phone", handler.RequestPhoneVerification)
+	route.POST("/phone/verify", handler.VerifyPhone)
+	route.POST("/2fa/enable", handler.EnableTwoFactor)
+	route.POST("/2fa/code", handler.RequestTwoFactorCode)
+	route.POST("/2fa/disable", handler.DisableTwoFactor)
+	// Deleting the account goes through the erasure workflow
+	route.DELETE("/delete", privacyHandler.RequestErasure)
+
+	route.GET("/export", privacyHandler.ExportData)
+	route.POST("/erasure", privacyHandler.RequestErasure)
+	route.DELETE("/erasure", privacyHandler.CancelErasure)
+
+	route.GET("/preferences", prefsHandler.GetPreferences)
+	route.PUT("/preferences", prefsHandler.UpdatePreferences)
+	route.PUT("/preferences/consents", prefsHandler.SetConsent)
+	route.GET("/preferences/consents/history", prefsHandler.GetConsentHistory)
+
+	// Unsubscribe links work without logging in, the token is signed
+	route.GET("/unsubscribe", prefsHandler.DescribeUnsubscribe)
+	route.POST("/unsubscribe", prefsHandler.Unsubscribe)
+
+	route.GET("/addresses", addressHandler.GetAddresses)
+	route.POST("/addresses", addressHandler.CreateAddress)
+	route.GET("/addresses/:id", addressHandler.GetAddress)
+	route.PUT("/addresses/:id", addressHandler.UpdateAddress)
+	route.DELETE("/addresses/:id", addressHandler.DeleteAddress)
 
 	err = ginServer.Run(":8080")
 	if err != nil {
@@ -1,6 +1,9 @@
 package config
 
-import "os"
+import (
+	"os"
+	"time"
+)
 
 type Config struct {
 	Addr             string
@@ -8,6 +11,28 @@ type Config struct {
 	JWTSecret        string
 	DATABASE_URL     string
 	NOTIFICATION_URL string
+	ORDER_URL        string
+
+	// Certificate of the service for mutual TLS with the other services
+	TLSCertFile string
+	TLSKeyFile  string
+	TLSCAFile   string
+
+	MessageBrokerURL      string
+	MessageBrokerExchange string
+	ErasureGracePeriod    time.Duration
+
+	PasswordHashAlgorithm string
+	BreachedPasswordsFile string
+
+	OAuthRedirectURL   string
+	GoogleClientID     string
+	GoogleClientSecret string
+	GitHubClientID     string
+	GitHubClientSecret string
+	OIDCIssuerURL      string
+	OIDCClientID       string
+	OIDCClientSecret   string
 }
 
 func LoadConfig() *Config {
@@ -15,5 +40,35 @@ func LoadConfig() *Config {
 		JWTSecret:        os.Getenv("JWT_SECRET"),
 		DATABASE_URL:     os.Getenv("DATABASE_URL"),
 		NOTIFICATION_URL: os.Getenv("NOTIFICATION_SERVICE_URL"),
+		ORDER_URL:        os.Getenv("ORDER_SERVICE_URL"),
+
+		TLSCertFile: os.Getenv("TLS_CERT_FILE"),
+		TLSKeyFile:  os.Getenv("TLS_KEY_FILE"),
+		TLSCAFile:   os.Getenv("TLS_CA_FILE"),
+
+		MessageBrokerURL:      os.Getenv("MESSAGE_BROKER_URL"),
+		MessageBrokerExchange: os.Getenv("MESSAGE_BROKER_EXCHANGE"),
+		ErasureGracePeriod:    getDuration("ERASURE_GRACE_PERIOD", 30*24*time.Hour),
+
+		PasswordHashAlgorithm: os.Getenv("PASSWORD_HASH_ALGORITHM"),
+		BreachedPasswordsFile: os.Getenv("BREACHED_PASSWORDS_FILE"),
+
+		OAuthRedirectURL:   os.Getenv("OAUTH_REDIRECT_URL"),
+		GoogleClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
+		GoogleClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
+		GitHubClientID:     os.Getenv("GITHUB_CLIENT_ID"),
+		GitHubClientSecret: os.Getenv("GITHUB_CLIENT_SECRET"),
+		OIDCIssuerURL:      os.Getenv("OIDC_ISSUER_URL"),
+		OIDCClientID:       os.Getenv("OIDC_CLIENT_ID"),
+		OIDCClientSecret:   os.Getenv("OIDC_CLIENT_SECRET"),
+	}
+}
+
+// getDuration parses a duration from the environment, falling back to the default if it is unset or invalid.
+func getDuration(key string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return def
 	}
+	return d
 }
@@ -0,0 +1,23 @@
+CREATE TABLE addresses (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    label VARCHAR(64) NOT NULL,
+    full_name VARCHAR(255) NOT NULL,
+    line1 VARCHAR(255) NOT NULL,
+    line2 VARCHAR(255),
+    city VARCHAR(128) NOT NULL,
+    region VARCHAR(128),
+    postal_code VARCHAR(16),
+    country CHAR(2) NOT NULL,
+    phone VARCHAR(15),
+    default_shipping BOOLEAN NOT NULL DEFAULT FALSE,
+    default_billing BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX addresses_user_id_idx ON addresses(user_id);
+
+-- A user can have only one default shipping and one default billing address
+CREATE UNIQUE INDEX addresses_default_shipping_idx ON addresses(user_id) WHERE default_shipping;
+CREATE UNIQUE INDEX addresses_default_billing_idx ON addresses(user_id) WHERE default_billing;
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS erased_at;
+
+DROP TABLE IF EXISTS erasure_requests;
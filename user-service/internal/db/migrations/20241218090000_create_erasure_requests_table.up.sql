@@ -0,0 +1,11 @@
+CREATE TABLE erasure_requests (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL,
+    requested_at TIMESTAMP NOT NULL,
+    scheduled_for TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX erasure_requests_due_idx ON erasure_requests(scheduled_for) WHERE status = 'pending';
+
+ALTER TABLE users ADD COLUMN erased_at TIMESTAMP;
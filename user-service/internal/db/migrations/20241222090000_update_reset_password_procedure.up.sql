@@ -0,0 +1,31 @@
+-- Password hashes are salted, so comparing the new hash with the old one never matched.
+-- The same-password check is done by the service against the old hash instead.
+CREATE OR REPLACE FUNCTION reset_password(
+    reset_token TEXT,
+    new_password TEXT
+) RETURNS TEXT AS $$
+DECLARE
+    user_uid UUID;
+BEGIN
+    -- Step 1: Validate the token
+    SELECT user_id INTO user_uid
+    FROM password_resets
+    WHERE token = reset_token
+      AND expires_at > NOW();
+
+    IF NOT FOUND THEN
+        RETURN 'Invalid or expired reset token.';
+    END IF;
+
+    -- Step 2: Update the password
+    UPDATE users
+    SET password = new_password
+    WHERE id = user_uid;
+
+    -- Step 3: Delete the token after use
+    DELETE FROM password_resets WHERE token = reset_token;
+
+
+    RETURN 'Password updated successfully.';
+END;
+$$ LANGUAGE plpgsql;
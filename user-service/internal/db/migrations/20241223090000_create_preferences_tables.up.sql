@@ -0,0 +1,18 @@
+CREATE TABLE user_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    language VARCHAR(16) NOT NULL DEFAULT 'en',
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE consents (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel VARCHAR(16) NOT NULL,
+    category VARCHAR(32) NOT NULL,
+    granted BOOLEAN NOT NULL,
+    source VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX consents_user_id_channel_category_created_at_idx ON consents(user_id, channel, category, created_at DESC);
@@ -0,0 +1,3 @@
+DELETE FROM users WHERE role = 'guest';
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_required;
+ALTER TABLE users ALTER COLUMN email SET NOT NULL;
@@ -0,0 +1,3 @@
+-- Guests have no email until they upgrade to a full account
+ALTER TABLE users ALTER COLUMN email DROP NOT NULL;
+ALTER TABLE users ADD CONSTRAINT users_email_required CHECK (role = 'guest' OR email IS NOT NULL);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS otp_codes;
+
+ALTER TABLE users DROP COLUMN IF EXISTS two_factor_enabled;
+ALTER TABLE users DROP COLUMN IF EXISTS phone_verified;
+ALTER TABLE users ALTER COLUMN phone TYPE VARCHAR(15);
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ClientInfo puts the IP and user agent of the request into its context, security events are recorded with them.
+func ClientInfo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := service.ContextWithClient(c.Request.Context(), models.ClientInfo{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// GetActivity returns a page of the security events of the user, staff can pass user_id to read another user's events.
+func (h *UserHandler) GetActivity(c *gin.Context) {
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.service.GetActivity(c.Request.Context(), jwt, c.Query("user_id"), page, limit)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidJWT:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case service.ErrPermissionDenied:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case repository.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrInvalidPagination:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AddressHandler struct {
+	service *service.AddressService
+}
+
+func NewAddressHandler(service *service.AddressService) *AddressHandler {
+	return &AddressHandler{
+		service: service,
+	}
+}
+
+// CreateAddress adds an address to the address book.
+func (h *AddressHandler) CreateAddress(c *gin.Context) {
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	var address models.Address
+
+	if err := c.ShouldBindJSON(&address); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.service.CreateAddress(c.Request.Context(), jwt, &address)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, res)
+}
+
+// GetAddresses returns the address book.
+func (h *AddressHandler) GetAddresses(c *gin.Context) {
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.GetAddresses(c.Request.Context(), jwt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// GetAddress returns an address by ID.
+func (h *AddressHandler) GetAddress(c *gin.Context) {
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.GetAddress(c.Request.Context(), jwt, c.Param("id"))
+	if err != nil {
+		c.JSON(addressErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// UpdateAddress replaces an address.
+func (h *AddressHandler) UpdateAddress(c *gin.Context) {
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	var address models.Address
+
+	if err := c.ShouldBindJSON(&address); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.service.UpdateAddress(c.Request.Context(), jwt, c.Param("id"), &address)
+	if err != nil {
+		c.JSON(addressErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// DeleteAddress removes an address.
+func (h *AddressHandler) DeleteAddress(c *gin.Context) {
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteAddress(c.Request.Context(), jwt, c.Param("id")); err != nil {
+		c.JSON(addressErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Address deleted successfully"})
+}
+
+func addressErrorStatus(err error) int {
+	if err == repository.ErrAddressNotFound {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+// bearerToken reads the token from the Authorization header, writing an error response if it is missing.
+func bearerToken(c *gin.Context) (string, bool) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Authorization header is required"})
+		return "", false
+	}
+
+	return token, true
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// oauthStateCookie binds a social login to the browser that started it
+const oauthStateCookie = "oauth_state"
+
+// OAuthLogin redirects the user to the identity provider.
+func (h *UserHandler) OAuthLogin(c *gin.Context) {
+	url, state, err := h.service.OAuthLoginURL(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	setOAuthStateCookie(c, state, int(service.OAuthStateTTL.Seconds()))
+	c.Redirect(http.StatusFound, url)
+}
+
+// OAuthCallback completes the social login and returns the tokens.
+func (h *UserHandler) OAuthCallback(c *gin.Context) {
+	browserState, _ := c.Cookie(oauthStateCookie)
+	// The state is used up by the callback whatever its outcome
+	setOAuthStateCookie(c, "", -1)
+
+	if errMsg := c.Query("error"); errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+
+	accessToken, refreshToken, err := h.service.OAuthCallback(c.Request.Context(), c.Param("provider"), c.Query("code"), c.Query("state"), browserState)
+	if twoFactorRequired(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"access_token": accessToken, "refresh_token": refreshToken})
+}
+
+// setOAuthStateCookie sets the state cookie for the login and callback routes of the provider.
+// It is sent along with the redirect back from the provider, which is a top-level navigation.
+func setOAuthStateCookie(c *gin.Context, state string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    state,
+		Path:     path.Dir(c.Request.URL.Path),
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
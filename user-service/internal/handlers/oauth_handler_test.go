@@ -0,0 +1,135 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/handlers"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/oauth"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/user-service/mocks"
+	"github.com/NeGat1FF/e-commerce/user-service/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var errExchangeFailed = errors.New("exchange failed")
+
+// fakeProvider records the codes it is asked to exchange and rejects them
+type fakeProvider struct {
+	exchanged []string
+}
+
+func (p *fakeProvider) Name() string {
+	return "test"
+}
+
+func (p *fakeProvider) AuthCodeURL(state, nonce, verifier string) string {
+	return "https://provider.example/authorize?" + url.Values{"state": {state}}.Encode()
+}
+
+func (p *fakeProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*oauth.Identity, error) {
+	p.exchanged = append(p.exchanged, code)
+	return nil, errExchangeFailed
+}
+
+func newOAuthRouter(t *testing.T) (*gin.Engine, *mocks.UserRepositoryInterface, *fakeProvider) {
+	logger.Init("info")
+	gin.SetMode(gin.TestMode)
+
+	repo := mocks.NewUserRepositoryInterface(t)
+	provider := &fakeProvider{}
+	s := service.NewUserService(repo, nil, nil, nil, map[string]oauth.Provider{"test": provider}, nil, nil, nil, "", "secret", "", "")
+	handler := handlers.NewUserHandler(s)
+
+	router := gin.New()
+	route := router.Group("/api/v1/auth")
+	route.GET("/oauth/:provider/login", handler.OAuthLogin)
+	route.GET("/oauth/:provider/callback", handler.OAuthCallback)
+
+	return router, repo, provider
+}
+
+func stateCookie(t *testing.T, res *http.Response) *http.Cookie {
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == "oauth_state" {
+			return cookie
+		}
+	}
+	require.Fail(t, "no oauth_state cookie")
+	return nil
+}
+
+func TestOAuthLogin(t *testing.T) {
+	router, repo, _ := newOAuthRouter(t)
+	repo.On("CreateOAuthState", mock.Anything, mock.Anything).Return(nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/oauth/test/login", nil))
+	require.Equal(t, http.StatusFound, w.Code)
+
+	location, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+	state := location.Query().Get("state")
+	require.NotEmpty(t, state)
+
+	cookie := stateCookie(t, w.Result())
+	assert.Equal(t, state, cookie.Value)
+	assert.Equal(t, "/api/v1/auth/oauth/test", cookie.Path)
+	assert.Equal(t, int(service.OAuthStateTTL.Seconds()), cookie.MaxAge)
+	assert.True(t, cookie.HttpOnly)
+	assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+}
+
+func TestOAuthCallbackState(t *testing.T) {
+	testCases := []struct {
+		name          string
+		cookie        string
+		expectedError string
+		exchanged     []string
+	}{
+		{
+			name:          "State of this browser",
+			cookie:        "state",
+			expectedError: errExchangeFailed.Error(),
+			exchanged:     []string{"code"},
+		},
+		{
+			name:          "No state cookie",
+			expectedError: "invalid or expired oauth state",
+		},
+		{
+			name:          "State of another login",
+			cookie:        "other-state",
+			expectedError: "invalid or expired oauth state",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router, repo, provider := newOAuthRouter(t)
+			if tc.exchanged != nil {
+				repo.On("ConsumeOAuthState", mock.Anything, "state").Return(&models.OAuthState{State: "state", Provider: "test"}, nil)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oauth/test/callback?code=code&state=state", nil)
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "oauth_state", Value: tc.cookie})
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, `{"error":"`+tc.expectedError+`"}`, w.Body.String())
+			assert.Equal(t, tc.exchanged, provider.exchanged, "the code is only exchanged for the browser that started the login")
+			assert.Negative(t, stateCookie(t, w.Result()).MaxAge, "the state cookie is cleared")
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type phoneRequest struct {
+	Phone string `json:"phone" binding:"required"`
+	// Country of numbers given in national format
+	Country string `json:"country"`
+}
+
+// RequestPhoneVerification sends a verification code to a phone number.
+func (h *UserHandler) RequestPhoneVerification(c *gin.Context) {
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	var body phoneRequest
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.RequestPhoneVerification(c.Request.Context(), jwt, body.Phone, body.Country); err != nil {
+		c.JSON(phoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Verification code sent"})
+}
+
+// VerifyPhone confirms the phone number with the code sent to it.
+func (h *UserHandler) VerifyPhone(c *gin.Context) {
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.VerifyPhone(c.Request.Context(), jwt, body.Code); err != nil {
+		c.JSON(phoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Phone verified successfully"})
+}
+
+// RequestPhoneLogin sends a login code to a verified phone number.
+func (h *UserHandler) RequestPhoneLogin(c *gin.Context) {
+	var body phoneRequest
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.RequestPhoneLogin(c.Request.Context(), body.Phone, body.Country); err != nil {
+		c.JSON(phoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If an account uses this phone number, a login code has been sent"})
+}
+
+// LoginWithPhone logs in with a code sent to a verified phone number.
+func (h *UserHandler) LoginWithPhone(c *gin.Context) {
+	var body struct {
+		phoneRequest
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accessToken, refreshToken, err := h.service.LoginWithPhone(c.Request.Context(), body.Phone, body.Country, body.Code, guestToken(c))
+	if err != nil {
+		c.JSON(phoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"access_token": accessToken, "refresh_token": refreshToken})
+}
+
+// LoginWithTwoFactor finishes a login with the code sent to the phone.
+func (h *UserHandler) LoginWithTwoFactor(c *gin.Context) {
+	var body struct {
+		TwoFactorToken string `json:"two_factor_token" binding:"required"`
+		Code           string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accessToken, refreshToken, err := h.service.LoginWithTwoFactor(c.Request.Context(), body.TwoFactorToken, body.Code, guestToken(c))
+	if err != nil {
+		c.JSON(phoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"access_token": accessToken, "refresh_token": refreshToken})
+}
+
+// EnableTwoFactor turns on two-factor authentication with the verified phone.
+func (h *UserHandler) EnableTwoFactor(c *gin.Context) {
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.EnableTwoFactor(c.Request.Context(), jwt); err != nil {
+		c.JSON(phoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication enabled"})
+}
+
+// RequestTwoFactorCode sends a code to the verified phone.
+func (h *UserHandler) RequestTwoFactorCode(c *gin.Context) {
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RequestTwoFactorCode(c.Request.Context(), jwt); err != nil {
+		c.JSON(phoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Code sent"})
+}
+
+// DisableTwoFactor turns off two-factor authentication with a code sent to the phone.
+func (h *UserHandler) DisableTwoFactor(c *gin.Context) {
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.DisableTwoFactor(c.Request.Context(), jwt, body.Code); err != nil {
+		c.JSON(phoneErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// twoFactorRequired asks for the second factor if the login needs one, reporting whether it did.
+func twoFactorRequired(c *gin.Context, err error) bool {
+	var required *service.TwoFactorRequiredError
+	if !errors.As(err, &required) {
+		return false
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": required.Error(), "two_factor_token": required.Token})
+	return true
+}
+
+func phoneErrorStatus(err error) int {
+	switch err {
+	case service.ErrInvalidJWT:
+		return http.StatusUnauthorized
+	case repository.ErrUserWithPhoneExists:
+		return http.StatusConflict
+	case service.ErrOTPRecentlySent:
+		return http.StatusTooManyRequests
+	case service.ErrFailedToSendSms:
+		return http.StatusBadGateway
+	default:
+		return http.StatusBadRequest
+	}
+}
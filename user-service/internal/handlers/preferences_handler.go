@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type PreferencesHandler struct {
+	service *service.PreferencesService
+}
+
+func NewPreferencesHandler(service *service.PreferencesService) *PreferencesHandler {
+	return &PreferencesHandler{
+		service: service,
+	}
+}
+
+// GetPreferences returns the settings and consents of the user.
+func (h *PreferencesHandler) GetPreferences(c *gin.Context) {
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.GetPreferences(c.Request.Context(), jwt)
+	if err != nil {
+		c.JSON(preferencesErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// UpdatePreferences changes the language and currency of the user.
+func (h *PreferencesHandler) UpdatePreferences(c *gin.Context) {
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	var update models.PreferencesUpdate
+
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.service.UpdatePreferences(c.Request.Context(), jwt, &update)
+	if err != nil {
+		c.JSON(preferencesErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// SetConsent grants or withdraws consent for a channel and category.
+func (h *PreferencesHandler) SetConsent(c *gin.Context) {
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	var update models.ConsentUpdate
+
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.service.SetConsent(c.Request.Context(), jwt, &update)
+	if err != nil {
+		c.JSON(preferencesErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// GetConsentHistory returns every consent decision of the user.
+func (h *PreferencesHandler) GetConsentHistory(c *gin.Context) {
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.GetConsentHistory(c.Request.Context(), jwt)
+	if err != nil {
+		c.JSON(preferencesErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// DescribeUnsubscribe shows what the unsubscribe link withdraws. Link scanners
+// of mail providers open links, so opening it does not change anything.
+func (h *PreferencesHandler) DescribeUnsubscribe(c *gin.Context) {
+	res, err := h.service.DescribeUnsubscribe(c.Request.Context(), c.Query("token"))
+	if err != nil {
+		c.JSON(preferencesErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// Unsubscribe withdraws the consent of the unsubscribe link without logging in.
+func (h *PreferencesHandler) Unsubscribe(c *gin.Context) {
+	res, err := h.service.Unsubscribe(c.Request.Context(), c.Query("token"))
+	if err != nil {
+		c.JSON(preferencesErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func preferencesErrorStatus(err error) int {
+	switch err {
+	case service.ErrInvalidJWT:
+		return http.StatusUnauthorized
+	case service.ErrInvalidLanguage, service.ErrInvalidCurrency, service.ErrInvalidChannel, service.ErrInvalidCategory,
+		service.ErrNothingToUpdate, utils.ErrInvalidUnsubscribeToken:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type PrivacyHandler struct {
+	service *service.PrivacyService
+}
+
+func NewPrivacyHandler(service *service.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{
+		service: service,
+	}
+}
+
+// ExportData returns all data held about the user as a downloadable JSON document.
+func (h *PrivacyHandler) ExportData(c *gin.Context) {
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.ExportUserData(c.Request.Context(), jwt)
+	if err != nil {
+		if err == service.ErrInvalidJWT {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="user-data.json"`)
+	c.JSON(http.StatusOK, res)
+}
+
+// RequestErasure schedules the erasure of the user's data.
+func (h *PrivacyHandler) RequestErasure(c *gin.Context) {
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.RequestErasure(c.Request.Context(), jwt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, res)
+}
+
+// CancelErasure cancels a pending erasure request.
+func (h *PrivacyHandler) CancelErasure(c *gin.Context) {
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.CancelErasure(c.Request.Context(), jwt); err != nil {
+		if err == repository.ErrErasureNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Erasure request cancelled"})
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
 	"github.com/NeGat1FF/e-commerce/user-service/internal/service"
 	"github.com/gin-gonic/gin"
 )
@@ -37,7 +38,8 @@ func (h *UserHandler) Register(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "User registered successfully", "access_token": ac, "refresh_token": rf})
 }
 
-// Login logs in a user.
+// Login logs in a user. A guest logging in sends its own access token in the
+// Authorization header, so the guest's cart is merged into the account.
 func (h *UserHandler) Login(c *gin.Context) {
 	var user models.User
 
@@ -46,7 +48,45 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	accessToken, refreshToken, err := h.service.LoginUser(c.Request.Context(), &user)
+	accessToken, refreshToken, err := h.service.LoginUser(c.Request.Context(), &user, guestToken(c))
+	if twoFactorRequired(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"access_token": accessToken, "refresh_token": refreshToken})
+}
+
+// RequestMagicLink emails a passwordless login link.
+func (h *UserHandler) RequestMagicLink(c *gin.Context) {
+	var body struct {
+		Email string `json:"email" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.RequestMagicLink(c.Request.Context(), body.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If an account exists for this email, a login link has been sent"})
+}
+
+// LoginWithMagicLink logs in a user with a login link.
+func (h *UserHandler) LoginWithMagicLink(c *gin.Context) {
+	token := c.Request.URL.Query().Get("token")
+
+	accessToken, refreshToken, err := h.service.LoginWithMagicLink(c.Request.Context(), token, guestToken(c))
+	if twoFactorRequired(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -116,6 +156,38 @@ func (h *UserHandler) ResetPassword(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
 }
 
+// ChangePassword changes the password of the logged in user.
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	var body struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ChangePassword(c.Request.Context(), jwt, body.CurrentPassword, body.NewPassword); err != nil {
+		switch err {
+		case service.ErrInvalidJWT:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case service.ErrInvalidPassword:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
 // RefreshToken refreshes the access and refresh tokens.
 func (h *UserHandler) RefreshToken(c *gin.Context) {
 
@@ -137,24 +209,40 @@ func (h *UserHandler) RefreshToken(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"access_token": accessToken, "refresh_token": refreshToken})
 }
 
-// UpdateUser updates a user.
-func (h *UserHandler) UpdateUser(c *gin.Context) {
-	var user models.User
+// Logout revokes the session of the refresh token.
+func (h *UserHandler) Logout(c *gin.Context) {
+	var token struct {
+		RefreshToken string `json:"refresh_token"`
+	}
 
-	if err := c.ShouldBindJSON(&user); err != nil {
+	if err := c.ShouldBindJSON(&token); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	jwt := c.GetHeader("Authorization")
-	if jwt == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Authorization header is required"})
+	if err := h.service.Logout(c.Request.Context(), token.RefreshToken); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	jwt = strings.Split(jwt, " ")[1]
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// UpdateUser updates the profile of a user.
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	var update models.ProfileUpdate
 
-	if err := h.service.UpdateUser(c.Request.Context(), jwt, &user); err != nil {
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.UpdateUser(c.Request.Context(), jwt, &update); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -162,21 +250,91 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "User updated successfully"})
 }
 
-// DeleteUser deletes a user.
-func (h *UserHandler) DeleteUser(c *gin.Context) {
-	jwt := c.GetHeader("Authorization")
+// ChangeEmail requests a change of the email, it is applied once confirmed from the new address.
+func (h *UserHandler) ChangeEmail(c *gin.Context) {
+	var body struct {
+		Password string `json:"password" binding:"required"`
+		NewEmail string `json:"new_email" binding:"required"`
+	}
 
-	if jwt == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Authorization header is required"})
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	jwt = strings.Split(jwt, " ")[1]
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
 
-	if err := h.service.DeleteUser(c.Request.Context(), jwt); err != nil {
+	if err := h.service.RequestEmailChange(c.Request.Context(), jwt, body.Password, body.NewEmail); err != nil {
+		if err == service.ErrInvalidPassword {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
+	c.JSON(http.StatusAccepted, gin.H{"message": "Confirmation email sent to the new address"})
+}
+
+// ConfirmEmailChange confirms the change of the email.
+func (h *UserHandler) ConfirmEmailChange(c *gin.Context) {
+	token := c.Request.URL.Query().Get("token")
+
+	if err := h.service.ConfirmEmailChange(c.Request.Context(), token); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email changed successfully"})
+}
+
+// CreateGuest creates a guest identity for shopping without an account.
+func (h *UserHandler) CreateGuest(c *gin.Context) {
+	accessToken, refreshToken, err := h.service.CreateGuest(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"access_token": accessToken, "refresh_token": refreshToken})
+}
+
+// UpgradeGuest turns the guest into a full account, keeping its user ID.
+func (h *UserHandler) UpgradeGuest(c *gin.Context) {
+	jwt, ok := bearerToken(c)
+	if !ok {
+		return
+	}
+
+	var upgrade models.GuestUpgrade
+
+	if err := c.ShouldBindJSON(&upgrade); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accessToken, refreshToken, err := h.service.UpgradeGuest(c.Request.Context(), jwt, &upgrade)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidJWT:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case repository.ErrUserWithEmailExists:
+			// The guest should log into the existing account instead, which merges the cart
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"access_token": accessToken, "refresh_token": refreshToken})
+}
+
+// guestToken returns the optional token a guest sends while logging in.
+func guestToken(c *gin.Context) string {
+	token, _ := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	return strings.TrimSpace(token)
 }
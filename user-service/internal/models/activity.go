@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Security event types
+const (
+	EventLoginSucceeded  = "login_succeeded"
+	EventLoginFailed     = "login_failed"
+	EventTokenRefreshed  = "token_refreshed"
+	EventPasswordChanged = "password_changed"
+	EventPasswordReset   = "password_reset"
+	EventEmailVerified   = "email_verified"
+	EventSessionRevoked  = "session_revoked"
+)
+
+// Login methods
+const (
+	LoginMethodPassword  = "password"
+	LoginMethodMagicLink = "magic_link"
+	LoginMethodPhone     = "phone"
+	LoginMethodOAuth     = "oauth"
+	LoginMethodTwoFactor = "two_factor"
+)
+
+// SecurityEvent is an entry of the activity log of an account
+type SecurityEvent struct {
+	ID     uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID uuid.UUID `json:"-" gorm:"column:user_id;type:uuid;not null"`
+	Type   string    `json:"type" gorm:"type:varchar(32);not null"`
+	// Method is how the user logged in, only set for login events
+	Method    string `json:"method,omitempty" gorm:"type:varchar(32);not null;default:''"`
+	IP        string `json:"ip" gorm:"type:varchar(45);not null;default:''"`
+	UserAgent string `json:"user_agent" gorm:"type:varchar(512);not null;default:''"`
+	// DeviceID is the hash of the user agent, used to tell known devices apart
+	DeviceID  string    `json:"-" gorm:"column:device_id;type:varchar(64);not null;default:''"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ClientInfo describes where a request came from
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// ActivityPage is a page of the activity log, newest first
+type ActivityPage struct {
+	Events []SecurityEvent `json:"events"`
+	Page   int             `json:"page"`
+	Limit  int             `json:"limit"`
+	Total  int64           `json:"total"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Address represents a shipping or billing address of a user
+type Address struct {
+	ID              uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID          uuid.UUID `json:"user_id" gorm:"column:user_id;type:uuid;not null"`
+	Label           string    `json:"label" gorm:"type:varchar(64);not null;default:null"`
+	FullName        string    `json:"full_name" gorm:"column:full_name;type:varchar(255);not null;default:null"`
+	Line1           string    `json:"line1" gorm:"column:line1;type:varchar(255);not null;default:null"`
+	Line2           string    `json:"line2" gorm:"column:line2;type:varchar(255)"`
+	City            string    `json:"city" gorm:"type:varchar(128);not null;default:null"`
+	Region          string    `json:"region" gorm:"type:varchar(128)"`
+	PostalCode      string    `json:"postal_code" gorm:"column:postal_code;type:varchar(16)"`
+	Country         string    `json:"country" gorm:"type:char(2);not null;default:null"`
+	Phone           string    `json:"phone" gorm:"type:varchar(15)"`
+	DefaultShipping bool      `json:"default_shipping" gorm:"column:default_shipping;type:boolean;default:false"`
+	DefaultBilling  bool      `json:"default_billing" gorm:"column:default_billing;type:boolean;default:false"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
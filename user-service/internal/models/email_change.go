@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailChange is a pending change of a user's email, applied once the new address is confirmed
+type EmailChange struct {
+	UserID    uuid.UUID `gorm:"column:user_id;type:uuid;primaryKey"`
+	NewEmail  string    `gorm:"column:new_email;type:varchar(255);not null"`
+	TokenHash string    `gorm:"column:token_hash;type:varchar(64);not null"`
+	ExpiresAt time.Time `gorm:"column:expires_at;type:timestamp;not null"`
+}
+
+// ProfileUpdate holds the profile fields a user may change directly,
+// a nil field is left unchanged.
+type ProfileUpdate struct {
+	Name    *string `json:"first_name"`
+	Surname *string `json:"last_name"`
+}
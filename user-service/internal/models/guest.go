@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GuestUpgrade holds the details a guest provides to become a full account
+type GuestUpgrade struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Name     string `json:"first_name" binding:"required"`
+	Surname  string `json:"last_name"`
+}
+
+// UserUpgradedEvent is published when a guest becomes a full account, the user ID stays the same
+type UserUpgradedEvent struct {
+	UserID     uuid.UUID `json:"user_id"`
+	UpgradedAt time.Time `json:"upgraded_at"`
+}
+
+// UserMergedEvent is published when a guest logs into an existing account,
+// other services move the guest's data, e.g. the cart, over to the account
+type UserMergedEvent struct {
+	GuestID  uuid.UUID `json:"guest_id"`
+	UserID   uuid.UUID `json:"user_id"`
+	MergedAt time.Time `json:"merged_at"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity links a user to an account at an external identity provider
+type UserIdentity struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"column:user_id;type:uuid;not null"`
+	Provider  string    `json:"provider" gorm:"type:varchar(32);not null;uniqueIndex:user_identities_provider_subject_idx"`
+	Subject   string    `json:"subject" gorm:"type:varchar(255);not null;uniqueIndex:user_identities_provider_subject_idx"`
+	Email     string    `json:"email" gorm:"type:varchar(255);not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OAuthState holds the state, nonce and PKCE verifier of a pending social login
+type OAuthState struct {
+	State        string    `gorm:"type:varchar(64);primaryKey"`
+	Provider     string    `gorm:"type:varchar(32);not null"`
+	Nonce        string    `gorm:"type:varchar(64);not null"`
+	CodeVerifier string    `gorm:"column:code_verifier;type:varchar(128);not null"`
+	ExpiresAt    time.Time `gorm:"column:expires_at;type:timestamp;not null"`
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Channels a user can be contacted through
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelPush  = "push"
+)
+
+// Categories of messages that need the user's consent. Security and account
+// messages such as password resets are always sent and are not listed here.
+const (
+	CategoryMarketing = "marketing"
+	CategoryOrders    = "orders"
+)
+
+// Where a consent decision was made
+const (
+	ConsentSourceSettings    = "settings"
+	ConsentSourceUnsubscribe = "unsubscribe_link"
+)
+
+var (
+	Channels   = []string{ChannelEmail, ChannelSMS, ChannelPush}
+	Categories = []string{CategoryMarketing, CategoryOrders}
+)
+
+const (
+	DefaultLanguage = "en"
+	DefaultCurrency = "USD"
+)
+
+// UserPreferences holds the typed settings of a user
+type UserPreferences struct {
+	UserID    uuid.UUID `json:"-" gorm:"column:user_id;type:uuid;primaryKey"`
+	Language  string    `json:"language" gorm:"type:varchar(16);not null"`
+	Currency  string    `json:"currency" gorm:"type:char(3);not null"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PreferencesUpdate lists the settings a user can change, nil fields are left untouched
+type PreferencesUpdate struct {
+	Language *string `json:"language"`
+	Currency *string `json:"currency"`
+}
+
+// ConsentRecord is a single consent decision. Records are never updated, the
+// latest one for a channel and category is the one in effect.
+type ConsentRecord struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `json:"-" gorm:"column:user_id;type:uuid;not null"`
+	Channel   string    `json:"channel" gorm:"type:varchar(16);not null"`
+	Category  string    `json:"category" gorm:"type:varchar(32);not null"`
+	Granted   bool      `json:"granted" gorm:"not null"`
+	Source    string    `json:"source" gorm:"type:varchar(32);not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ConsentUpdate is a consent decision made by the user
+type ConsentUpdate struct {
+	Channel  string `json:"channel" binding:"required"`
+	Category string `json:"category" binding:"required"`
+	Granted  *bool  `json:"granted" binding:"required"`
+}
+
+// Consent is the consent in effect for a channel and category
+type Consent struct {
+	Channel   string     `json:"channel"`
+	Category  string     `json:"category"`
+	Granted   bool       `json:"granted"`
+	Source    string     `json:"source,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// PreferencesResponse is the settings of a user together with the consent in effect for every channel and category
+type PreferencesResponse struct {
+	Language string    `json:"language"`
+	Currency string    `json:"currency"`
+	Consents []Consent `json:"consents"`
+}
+
+// ConsentCheck tells other services whether they may contact the user
+type ConsentCheck struct {
+	Allowed        bool
+	UnsubscribeURL string
+	Language       string
+}
+
+// DefaultConsent is used when the user has not decided yet. Order updates
+// are part of the service, marketing needs an explicit opt-in.
+func DefaultConsent(category string) bool {
+	return category == CategoryOrders
+}
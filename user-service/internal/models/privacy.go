@@ -0,0 +1,72 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ErasureStatusPending   = "pending"
+	ErasureStatusCancelled = "cancelled"
+	ErasureStatusCompleted = "completed"
+)
+
+// ErasureRequest is a user's request to erase their personal data, it is
+// carried out once the grace period is over unless it is cancelled before.
+type ErasureRequest struct {
+	UserID       uuid.UUID  `json:"user_id" gorm:"column:user_id;type:uuid;primaryKey"`
+	Status       string     `json:"status" gorm:"type:varchar(16);not null"`
+	RequestedAt  time.Time  `json:"requested_at" gorm:"column:requested_at;type:timestamp;not null"`
+	ScheduledFor time.Time  `json:"scheduled_for" gorm:"column:scheduled_for;type:timestamp;not null"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty" gorm:"column:completed_at;type:timestamp"`
+}
+
+// UserDeletedEvent is published once a user's personal data has been erased
+type UserDeletedEvent struct {
+	UserID   uuid.UUID `json:"user_id"`
+	ErasedAt time.Time `json:"erased_at"`
+}
+
+// UserExport contains all data we hold about a user
+type UserExport struct {
+	Profile     ExportedProfile  `json:"profile"`
+	Sessions    []string         `json:"sessions"`
+	Identities  []UserIdentity   `json:"identities"`
+	Addresses   []Address        `json:"addresses"`
+	Preferences *UserPreferences `json:"preferences"`
+	Consents    []ConsentRecord  `json:"consents"`
+	Orders      []ExportedOrder  `json:"orders"`
+	ExportedAt  time.Time        `json:"exported_at"`
+}
+
+// ExportedProfile is the user profile without credentials
+type ExportedProfile struct {
+	ID            uuid.UUID `json:"id"`
+	Name          string    `json:"first_name"`
+	Surname       string    `json:"last_name"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	Phone         string    `json:"phone"`
+	PhoneVerified bool      `json:"phone_verified"`
+	TwoFactor     bool      `json:"two_factor_enabled"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ExportedOrder is an order collected from the order service
+type ExportedOrder struct {
+	ID              string              `json:"id"`
+	Status          string              `json:"status"`
+	Total           string              `json:"total"`
+	Items           []ExportedOrderItem `json:"items"`
+	ShippingAddress json.RawMessage     `json:"shipping_address,omitempty"`
+	CreatedAt       string              `json:"created_at"`
+	UpdatedAt       string              `json:"updated_at"`
+}
+
+type ExportedOrderItem struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int32 `json:"quantity"`
+}
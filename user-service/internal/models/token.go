@@ -11,3 +11,30 @@ type Token struct {
 	Token     string    `json:"token" gorm:"type:varchar(255);not null"`
 	ExpiresAt time.Time `json:"expires_at" gor:"column:expires_at;type:timestamp;not null"`
 }
+
+// MagicLink is a single-use passwordless login token, only its hash is stored
+type MagicLink struct {
+	TokenHash string     `gorm:"column:token_hash;type:varchar(64);primaryKey"`
+	UserID    uuid.UUID  `gorm:"column:user_id;type:uuid;not null"`
+	CreatedAt time.Time  `gorm:"column:created_at;type:timestamp;not null"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;type:timestamp;not null"`
+	UsedAt    *time.Time `gorm:"column:used_at;type:timestamp"`
+}
+
+// What a one-time code was sent for, a user has at most one pending code per purpose
+const (
+	OTPPurposeVerifyPhone = "verify_phone"
+	OTPPurposeLogin       = "login"
+	OTPPurposeTwoFactor   = "two_factor"
+)
+
+// OTPCode is a one-time code sent by SMS, only its keyed hash is stored
+type OTPCode struct {
+	UserID    uuid.UUID `gorm:"column:user_id;type:uuid;primaryKey"`
+	Purpose   string    `gorm:"column:purpose;type:varchar(16);primaryKey"`
+	Phone     string    `gorm:"column:phone;type:varchar(16);not null"`
+	CodeHash  string    `gorm:"column:code_hash;type:varchar(64);not null"`
+	Attempts  int       `gorm:"column:attempts;not null;default:0"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;not null"`
+	ExpiresAt time.Time `gorm:"column:expires_at;type:timestamp;not null"`
+}
@@ -7,16 +7,28 @@ import (
 	"github.com/lib/pq"
 )
 
+const (
+	RoleUser  = "user"
+	RoleStaff = "staff"
+	RoleAdmin = "admin"
+	// Guests can shop and check out but have no email or password until they upgrade
+	RoleGuest = "guest"
+)
+
 // User represents a user in the system
 type User struct {
 	ID            uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
 	Name          string         `json:"first_name" gorm:"type:varchar(255);not null;default:null"`
-	Surname       string         `json:"last_name" gorm:"type:varchar(255);not null;default:null"`
-	Email         string         `json:"email" gorm:"type:varchar(255);unique;not null;default:null"`
+	Surname       string         `json:"last_name" gorm:"type:varchar(255);default:null"`
+	Email         string         `json:"email" gorm:"type:varchar(255);unique;default:null"`
 	EmailVerified bool           `json:"email_verified" gorm:"column:email_verified;type:boolean;default:false"`
-	Phone         string         `json:"phone" gorm:"type:varchar(15);unique;default:null"`
-	Password      string         `json:"password" gorm:"type:varchar(255);not null;default:null"`
+	Phone         string         `json:"phone" gorm:"type:varchar(16);unique;default:null"`
+	PhoneVerified bool           `json:"phone_verified" gorm:"column:phone_verified;type:boolean;default:false"`
+	TwoFactor     bool           `json:"two_factor_enabled" gorm:"column:two_factor_enabled;type:boolean;default:false"`
+	Password      string         `json:"password" gorm:"type:varchar(255);default:null"`
 	RefreshTokens pq.StringArray `json:"refresh_tokens" gorm:"column:refresh_tokens;type:varchar(32)[];default:null"`
+	Role          string         `json:"-" gorm:"type:varchar(32);not null;default:user"`
+	Disabled      bool           `json:"-" gorm:"type:boolean;not null;default:false"`
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
 }
@@ -0,0 +1,112 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+const GitHubAPIURL = "https://api.github.com"
+
+// GitHubProvider is a plain OAuth2 provider, GitHub does not issue ID tokens
+// so the identity is read from its REST API instead.
+type GitHubProvider struct {
+	config *oauth2.Config
+	apiURL string
+}
+
+// NewGitHubProvider creates a new GitHub provider.
+func NewGitHubProvider(clientID, clientSecret, redirectURL string) *GitHubProvider {
+	return &GitHubProvider{
+		config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Endpoint:     github.Endpoint,
+			Scopes:       []string{"read:user", "user:email"},
+		},
+		apiURL: GitHubAPIURL,
+	}
+}
+
+// Name returns the provider name.
+func (p *GitHubProvider) Name() string {
+	return "github"
+}
+
+// AuthCodeURL returns the authorization URL with state and PKCE challenge, nonce is not supported by GitHub.
+func (p *GitHubProvider) AuthCodeURL(state, nonce, verifier string) string {
+	return p.config.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier))
+}
+
+// Exchange exchanges the code and reads the user and its primary verified email from the API.
+func (p *GitHubProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error) {
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, err
+	}
+
+	client := p.config.Client(ctx, token)
+
+	var user struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := p.get(client, "/user", &user); err != nil {
+		return nil, err
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.get(client, "/user/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Provider: p.Name(),
+		Subject:  strconv.FormatInt(user.ID, 10),
+	}
+	identity.GivenName, identity.FamilyName = splitName(user.Name)
+
+	for _, email := range emails {
+		if email.Primary && email.Verified {
+			identity.Email = strings.ToLower(email.Email)
+			identity.EmailVerified = true
+			break
+		}
+	}
+
+	if !identity.EmailVerified {
+		return nil, ErrNoVerifiedEmail
+	}
+
+	return identity, nil
+}
+
+func (p *GitHubProvider) get(client *http.Client, path string, res any) error {
+	req, err := http.NewRequest(http.MethodGet, p.apiURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ErrProviderResponse
+	}
+
+	return json.NewDecoder(resp.Body).Decode(res)
+}
@@ -0,0 +1,236 @@
+package oauth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/oauth"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+const (
+	testClientID = "test-client"
+	testKeyID    = "test-key"
+)
+
+// mockOIDCServer is a minimal OpenID Connect provider used to test the authorization-code flow.
+type mockOIDCServer struct {
+	*httptest.Server
+
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]mockAuthRequest
+}
+
+type mockAuthRequest struct {
+	challenge string
+	nonce     string
+	subject   string
+	email     string
+}
+
+func newMockOIDCServer(t *testing.T) *mockOIDCServer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	m := &mockOIDCServer{
+		key:   key,
+		codes: make(map[string]mockAuthRequest),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", m.discovery)
+	mux.HandleFunc("GET /jwks", m.jwks)
+	mux.HandleFunc("POST /token", m.token)
+
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+
+	return m
+}
+
+// authorize simulates the user signing in at the provider and returns the authorization code.
+func (m *mockOIDCServer) authorize(t *testing.T, authURL, subject, email string) string {
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+
+	q := u.Query()
+	require.Equal(t, "S256", q.Get("code_challenge_method"))
+
+	code := subject + "-code"
+
+	m.mu.Lock()
+	m.codes[code] = mockAuthRequest{
+		challenge: q.Get("code_challenge"),
+		nonce:     q.Get("nonce"),
+		subject:   subject,
+		email:     email,
+	}
+	m.mu.Unlock()
+
+	return code
+}
+
+func (m *mockOIDCServer) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]any{
+		"issuer":                                m.URL,
+		"authorization_endpoint":                m.URL + "/authorize",
+		"token_endpoint":                        m.URL + "/token",
+		"jwks_uri":                              m.URL + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (m *mockOIDCServer) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := m.key.PublicKey
+	json.NewEncoder(w).Encode(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": testKeyID,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func (m *mockOIDCServer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	m.mu.Lock()
+	req, ok := m.codes[r.Form.Get("code")]
+	delete(m.codes, r.Form.Get("code"))
+	m.mu.Unlock()
+
+	if !ok || oauth2.S256ChallengeFromVerifier(r.Form.Get("code_verifier")) != req.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            m.URL,
+		"aud":            testClientID,
+		"sub":            req.subject,
+		"nonce":          req.nonce,
+		"email":          req.email,
+		"email_verified": true,
+		"name":           "Jane Doe",
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Minute).Unix(),
+	})
+	idToken.Header["kid"] = testKeyID
+
+	signed, err := idToken.SignedString(m.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"access_token": "access-" + req.subject,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     signed,
+	})
+}
+
+func TestOIDCProviderExchange(t *testing.T) {
+	ctx := context.Background()
+	server := newMockOIDCServer(t)
+
+	provider, err := oauth.NewOIDCProvider(ctx, "mock", server.URL, testClientID, "secret", "http://localhost/callback")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "Exchange code for identity",
+			testFunc: func(t *testing.T) {
+				verifier := oauth2.GenerateVerifier()
+				code := server.authorize(t, provider.AuthCodeURL("state", "nonce", verifier), "user-1", "Jane@Example.com")
+
+				identity, err := provider.Exchange(ctx, code, verifier, "nonce")
+				require.NoError(t, err)
+
+				assert.Equal(t, "mock", identity.Provider)
+				assert.Equal(t, "user-1", identity.Subject)
+				assert.Equal(t, "jane@example.com", identity.Email)
+				assert.True(t, identity.EmailVerified)
+				assert.Equal(t, "Jane", identity.GivenName)
+				assert.Equal(t, "Doe", identity.FamilyName)
+			},
+		},
+		{
+			name: "Auth URL carries state and nonce",
+			testFunc: func(t *testing.T) {
+				u, err := url.Parse(provider.AuthCodeURL("some-state", "some-nonce", oauth2.GenerateVerifier()))
+				require.NoError(t, err)
+
+				assert.Equal(t, "some-state", u.Query().Get("state"))
+				assert.Equal(t, "some-nonce", u.Query().Get("nonce"))
+				assert.Equal(t, testClientID, u.Query().Get("client_id"))
+			},
+		},
+		{
+			name: "Nonce mismatch",
+			testFunc: func(t *testing.T) {
+				verifier := oauth2.GenerateVerifier()
+				code := server.authorize(t, provider.AuthCodeURL("state", "nonce", verifier), "user-2", "john@example.com")
+
+				identity, err := provider.Exchange(ctx, code, verifier, "other-nonce")
+				assert.ErrorIs(t, err, oauth.ErrNonceMismatch)
+				assert.Nil(t, identity)
+			},
+		},
+		{
+			name: "Wrong code verifier",
+			testFunc: func(t *testing.T) {
+				code := server.authorize(t, provider.AuthCodeURL("state", "nonce", oauth2.GenerateVerifier()), "user-3", "john@example.com")
+
+				identity, err := provider.Exchange(ctx, code, oauth2.GenerateVerifier(), "nonce")
+				assert.Error(t, err)
+				assert.Nil(t, identity)
+			},
+		},
+		{
+			name: "Code can be used only once",
+			testFunc: func(t *testing.T) {
+				verifier := oauth2.GenerateVerifier()
+				code := server.authorize(t, provider.AuthCodeURL("state", "nonce", verifier), "user-4", "john@example.com")
+
+				_, err := provider.Exchange(ctx, code, verifier, "nonce")
+				require.NoError(t, err)
+
+				_, err = provider.Exchange(ctx, code, verifier, "nonce")
+				assert.Error(t, err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
@@ -0,0 +1,36 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrNonceMismatch    = errors.New("id token nonce does not match")
+	ErrMissingIDToken   = errors.New("token response does not contain an id_token")
+	ErrNoVerifiedEmail  = errors.New("provider did not return a verified email")
+	ErrProviderResponse = errors.New("unexpected response from identity provider")
+)
+
+// Identity is the user information returned by an external identity provider.
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	GivenName     string
+	FamilyName    string
+}
+
+// Provider describes an external identity provider that supports the
+// authorization-code flow with PKCE.
+type Provider interface {
+	// Name returns the provider name used in routes and stored identities
+	Name() string
+
+	// AuthCodeURL returns the URL the user is redirected to in order to sign in
+	AuthCodeURL(state, nonce, verifier string) string
+
+	// Exchange exchanges the authorization code for the user's identity
+	Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error)
+}
@@ -0,0 +1,85 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+)
+
+// BreachedList is a local list of breached passwords, stored as SHA-1 hashes in the format
+// of the Pwned Passwords downloads: one "HASH:COUNT" or "HASH" per line. Like the range API,
+// hashes are grouped by their first 5 characters so a lookup only compares suffixes within one range.
+type BreachedList struct {
+	ranges map[string]map[string]struct{}
+	size   int
+}
+
+// LoadBreachedList reads a breached password list from a file.
+func LoadBreachedList(path string) (*BreachedList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return NewBreachedList(f)
+}
+
+// NewBreachedList reads a breached password list, invalid lines are skipped.
+func NewBreachedList(r io.Reader) (*BreachedList, error) {
+	list := &BreachedList{
+		ranges: make(map[string]map[string]struct{}),
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		hash, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		hash = strings.ToUpper(hash)
+
+		if len(hash) != sha1.Size*2 {
+			continue
+		}
+		if _, err := hex.DecodeString(hash); err != nil {
+			continue
+		}
+
+		prefix, suffix := hash[:5], hash[5:]
+		if list.ranges[prefix] == nil {
+			list.ranges[prefix] = make(map[string]struct{})
+		}
+		if _, ok := list.ranges[prefix][suffix]; !ok {
+			list.ranges[prefix][suffix] = struct{}{}
+			list.size++
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// Contains reports whether the password is in the list, a nil list contains nothing.
+func (b *BreachedList) Contains(password string) bool {
+	if b == nil {
+		return false
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	_, ok := b.ranges[hash[:5]][hash[5:]]
+	return ok
+}
+
+// Len returns the number of hashes in the list.
+func (b *BreachedList) Len() int {
+	if b == nil {
+		return 0
+	}
+	return b.size
+}
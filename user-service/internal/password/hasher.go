@@ -0,0 +1,96 @@
+// Package password hashes and verifies passwords and enforces the password policy.
+package password
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrUnknownAlgorithm = errors.New("unknown password hash algorithm")
+	ErrInvalidHash      = errors.New("invalid password hash")
+)
+
+// Algorithm is a password hashing algorithm, the hashes it produces
+// carry the algorithm and its parameters so they can be verified later.
+type Algorithm interface {
+	// Name returns the name of the algorithm.
+	Name() string
+
+	// Hash hashes a password.
+	Hash(password string) (string, error)
+
+	// Verify checks a password against a hash produced by the algorithm.
+	Verify(hash, password string) (bool, error)
+
+	// Handles reports whether the hash was produced by the algorithm.
+	Handles(hash string) bool
+
+	// NeedsRehash reports whether the hash was produced with weaker parameters than the current ones.
+	NeedsRehash(hash string) bool
+}
+
+// Hasher hashes new passwords with the preferred algorithm and verifies
+// hashes of any supported algorithm.
+type Hasher struct {
+	preferred  Algorithm
+	algorithms []Algorithm
+}
+
+// NewHasher creates a new hasher, new passwords are hashed with the preferred algorithm.
+func NewHasher(preferred Algorithm, others ...Algorithm) *Hasher {
+	return &Hasher{
+		preferred:  preferred,
+		algorithms: append([]Algorithm{preferred}, others...),
+	}
+}
+
+// NewDefaultHasher creates a hasher that prefers the named algorithm and supports argon2id and bcrypt.
+func NewDefaultHasher(name string) (*Hasher, error) {
+	argon := NewArgon2id(DefaultArgon2idParams)
+	bcrypt := NewBcrypt(DefaultBcryptCost)
+
+	switch strings.ToLower(name) {
+	case "", argon.Name():
+		return NewHasher(argon, bcrypt), nil
+	case bcrypt.Name():
+		return NewHasher(bcrypt, argon), nil
+	}
+
+	return nil, ErrUnknownAlgorithm
+}
+
+// Hash hashes a password with the preferred algorithm.
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+// Verify checks a password against a hash of any supported algorithm.
+func (h *Hasher) Verify(hash, password string) bool {
+	algorithm := h.algorithm(hash)
+	if algorithm == nil {
+		return false
+	}
+
+	ok, err := algorithm.Verify(hash, password)
+	return err == nil && ok
+}
+
+// NeedsRehash reports whether the hash should be replaced by one of the preferred algorithm and parameters.
+func (h *Hasher) NeedsRehash(hash string) bool {
+	if !h.preferred.Handles(hash) {
+		return true
+	}
+
+	return h.preferred.NeedsRehash(hash)
+}
+
+func (h *Hasher) algorithm(hash string) Algorithm {
+	for _, algorithm := range h.algorithms {
+		if algorithm.Handles(hash) {
+			return algorithm
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package password_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/password"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testArgon2idParams = password.Argon2idParams{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+func TestHasher(t *testing.T) {
+	argon := password.NewArgon2id(testArgon2idParams)
+	bcrypt := password.NewBcrypt(4)
+
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "Argon2id hash and verify",
+			testFunc: func(t *testing.T) {
+				hasher := password.NewHasher(argon, bcrypt)
+
+				hash, err := hasher.Hash("correct horse")
+				require.NoError(t, err)
+				assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+				assert.True(t, hasher.Verify(hash, "correct horse"))
+				assert.False(t, hasher.Verify(hash, "wrong horse"))
+				assert.False(t, hasher.NeedsRehash(hash))
+			},
+		},
+		{
+			name: "Same password gives different hashes",
+			testFunc: func(t *testing.T) {
+				hasher := password.NewHasher(argon)
+
+				first, err := hasher.Hash("correct horse")
+				require.NoError(t, err)
+				second, err := hasher.Hash("correct horse")
+				require.NoError(t, err)
+
+				assert.NotEqual(t, first, second)
+			},
+		},
+		{
+			name: "Bcrypt hash is verified and upgraded",
+			testFunc: func(t *testing.T) {
+				hash, err := bcrypt.Hash("correct horse")
+				require.NoError(t, err)
+
+				hasher := password.NewHasher(argon, bcrypt)
+				assert.True(t, hasher.Verify(hash, "correct horse"))
+				assert.True(t, hasher.NeedsRehash(hash))
+			},
+		},
+		{
+			name: "Weaker parameters need rehash",
+			testFunc: func(t *testing.T) {
+				weak, err := password.NewArgon2id(testArgon2idParams).Hash("correct horse")
+				require.NoError(t, err)
+
+				stronger := testArgon2idParams
+				stronger.Iterations = 2
+				hasher := password.NewHasher(password.NewArgon2id(stronger))
+
+				assert.True(t, hasher.Verify(weak, "correct horse"))
+				assert.True(t, hasher.NeedsRehash(weak))
+
+				lowCost, err := password.NewBcrypt(4).Hash("correct horse")
+				require.NoError(t, err)
+				assert.True(t, password.NewHasher(password.NewBcrypt(5)).NeedsRehash(lowCost))
+			},
+		},
+		{
+			name: "Unknown or malformed hash",
+			testFunc: func(t *testing.T) {
+				hasher := password.NewHasher(argon, bcrypt)
+
+				assert.False(t, hasher.Verify("", "correct horse"))
+				assert.False(t, hasher.Verify("plain", "plain"))
+				assert.False(t, hasher.Verify("$argon2id$v=19$m=1024$broken", "correct horse"))
+				assert.True(t, hasher.NeedsRehash("plain"))
+			},
+		},
+		{
+			name: "Default hasher",
+			testFunc: func(t *testing.T) {
+				_, err := password.NewDefaultHasher("argon2id")
+				assert.NoError(t, err)
+				_, err = password.NewDefaultHasher("bcrypt")
+				assert.NoError(t, err)
+				_, err = password.NewDefaultHasher("md5")
+				assert.ErrorIs(t, err, password.ErrUnknownAlgorithm)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	// SHA-1 of "Password1" and "qwerty123"
+	list := "70ccd9007338d6d81dd3b6271621b9cf9a97ea00:3\n" +
+		"5CEC175B165E3D5E62C9E13CE848EF6FEAC81BFF\n" +
+		"not a hash\n"
+
+	breached, err := password.NewBreachedList(strings.NewReader(list))
+	require.NoError(t, err)
+	assert.Equal(t, 2, breached.Len())
+
+	policy := password.NewPolicy(breached)
+
+	testCases := []struct {
+		name     string
+		password string
+		personal []string
+		err      error
+	}{
+		{name: "Strong password", password: "Tr0ub4dor&3"},
+		{name: "Too short", password: "aB1", err: password.ErrPasswordLength},
+		{name: "Too long", password: strings.Repeat("aB1", 22), err: password.ErrPasswordLength},
+		{name: "Single character class", password: "abcdefghij", err: password.ErrPasswordTooSimple},
+		{name: "Contains email", password: "janedoe2024", personal: []string{"JaneDoe@example.com"}, err: password.ErrPasswordPersonal},
+		{name: "Contains name", password: "iamjane2024", personal: []string{"Jane"}, err: password.ErrPasswordPersonal},
+		{name: "Short personal values are ignored", password: "jo-2024-xyz", personal: []string{"Jo"}},
+		{name: "Breached", password: "Password1", err: password.ErrPasswordBreached},
+		{name: "Breached without count", password: "qwerty123", err: password.ErrPasswordBreached},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.err, policy.Validate(tc.password, tc.personal...))
+		})
+	}
+
+	// Without a list no password is considered breached
+	assert.NoError(t, password.NewPolicy(nil).Validate("Password1"))
+}
@@ -0,0 +1,86 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ErrPasswordLength    = errors.New("password length must be between 8 and 64 characters")
+	ErrPasswordTooSimple = errors.New("password must contain at least two of: lowercase letters, uppercase letters, digits and symbols")
+	ErrPasswordPersonal  = errors.New("password must not contain your name or email")
+	ErrPasswordBreached  = errors.New("password has appeared in a data breach, choose a different one")
+)
+
+// Policy is the strength policy new passwords must satisfy.
+type Policy struct {
+	MinLength  int
+	MaxLength  int
+	MinClasses int
+	Breached   *BreachedList
+}
+
+// NewPolicy creates the default policy, breached may be nil.
+func NewPolicy(breached *BreachedList) *Policy {
+	return &Policy{
+		MinLength:  8,
+		MaxLength:  64,
+		MinClasses: 2,
+		Breached:   breached,
+	}
+}
+
+// Validate checks a new password, personal holds the user's own details
+// (email, names) the password must not contain.
+func (p *Policy) Validate(password string, personal ...string) error {
+	// The maximum is in bytes as bcrypt only uses the first 72 bytes
+	if utf8.RuneCountInString(password) < p.MinLength || len(password) > p.MaxLength {
+		return ErrPasswordLength
+	}
+
+	if characterClasses(password) < p.MinClasses {
+		return ErrPasswordTooSimple
+	}
+
+	lower := strings.ToLower(password)
+	for _, value := range personal {
+		// Only the local part of an email is meaningful
+		value, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(value)), "@")
+		if len(value) >= 3 && strings.Contains(lower, value) {
+			return ErrPasswordPersonal
+		}
+	}
+
+	if p.Breached.Contains(password) {
+		return ErrPasswordBreached
+	}
+
+	return nil
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, other bool
+
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	classes := 0
+	for _, ok := range []bool{lower, upper, digit, other} {
+		if ok {
+			classes++
+		}
+	}
+	return classes
+}
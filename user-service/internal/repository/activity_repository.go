@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"gorm.io/gorm"
+)
+
+type ActivityRepository struct {
+	db *gorm.DB
+}
+
+func NewActivityRepository(db *gorm.DB) *ActivityRepository {
+	return &ActivityRepository{
+		db: db,
+	}
+}
+
+// Record a security event
+func (r *ActivityRepository) AddEvent(ctx context.Context, event *models.SecurityEvent) error {
+	return r.db.WithContext(ctx).Table("security_events").Create(event).Error
+}
+
+// Get a page of the security events of a user, newest first, together with their total count
+func (r *ActivityRepository) GetEvents(ctx context.Context, userID string, offset, limit int) ([]models.SecurityEvent, int64, error) {
+	var total int64
+	tx := r.db.WithContext(ctx).Table("security_events").Where("user_id = ?", userID).Count(&total)
+	if tx.Error != nil {
+		return nil, 0, tx.Error
+	}
+
+	events := []models.SecurityEvent{}
+	tx = r.db.WithContext(ctx).Table("security_events").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&events)
+	if tx.Error != nil {
+		return nil, 0, tx.Error
+	}
+
+	return events, total, nil
+}
+
+// Get the devices a user has logged in from
+func (r *ActivityRepository) GetLoginDevices(ctx context.Context, userID string) ([]string, error) {
+	devices := []string{}
+	tx := r.db.WithContext(ctx).Table("security_events").
+		Where("user_id = ? AND type = ?", userID, models.EventLoginSucceeded).
+		Distinct().
+		Pluck("device_id", &devices)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return devices, nil
+}
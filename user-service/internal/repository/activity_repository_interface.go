@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+)
+
+type ActivityRepositoryInterface interface {
+	// Record a security event
+	AddEvent(ctx context.Context, event *models.SecurityEvent) error
+
+	// Get a page of the security events of a user, newest first, together with their total count
+	GetEvents(ctx context.Context, userID string, offset, limit int) ([]models.SecurityEvent, int64, error)
+
+	// Get the devices a user has logged in from
+	GetLoginDevices(ctx context.Context, userID string) ([]string, error)
+}
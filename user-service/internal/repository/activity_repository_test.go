@@ -0,0 +1,125 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivity(t *testing.T) {
+	ctx := context.Background()
+	db, err := setupTestContainer(ctx)
+	require.NoError(t, err)
+
+	repo := repository.NewActivityRepository(db)
+
+	newUser := func(t *testing.T) models.User {
+		user := models.User{
+			ID:       uuid.New(),
+			Name:     gofakeit.FirstName(),
+			Surname:  gofakeit.LastName(),
+			Email:    gofakeit.Email(),
+			Password: gofakeit.Password(true, true, true, true, true, 10),
+		}
+
+		err := db.Create(&user).Error
+		require.NoError(t, err)
+
+		return user
+	}
+
+	addEvent := func(t *testing.T, userID uuid.UUID, eventType, device string, createdAt time.Time) {
+		err := repo.AddEvent(context.Background(), &models.SecurityEvent{
+			ID:        uuid.New(),
+			UserID:    userID,
+			Type:      eventType,
+			IP:        gofakeit.IPv4Address(),
+			UserAgent: gofakeit.UserAgent(),
+			DeviceID:  device,
+			CreatedAt: createdAt,
+		})
+		require.NoError(t, err)
+	}
+
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "Events are paginated newest first",
+			testFunc: func(t *testing.T) {
+				user := newUser(t)
+				other := newUser(t)
+
+				now := time.Now()
+				addEvent(t, user.ID, models.EventLoginSucceeded, "a", now.Add(-3*time.Hour))
+				addEvent(t, user.ID, models.EventTokenRefreshed, "a", now.Add(-2*time.Hour))
+				addEvent(t, user.ID, models.EventSessionRevoked, "a", now.Add(-time.Hour))
+				addEvent(t, other.ID, models.EventLoginSucceeded, "b", now)
+
+				events, total, err := repo.GetEvents(context.Background(), user.ID.String(), 0, 2)
+				require.NoError(t, err)
+				assert.EqualValues(t, 3, total)
+				require.Len(t, events, 2)
+				assert.Equal(t, models.EventSessionRevoked, events[0].Type)
+				assert.Equal(t, models.EventTokenRefreshed, events[1].Type)
+
+				events, total, err = repo.GetEvents(context.Background(), user.ID.String(), 2, 2)
+				require.NoError(t, err)
+				assert.EqualValues(t, 3, total)
+				require.Len(t, events, 1)
+				assert.Equal(t, models.EventLoginSucceeded, events[0].Type)
+			},
+		},
+		{
+			name: "Login devices",
+			testFunc: func(t *testing.T) {
+				user := newUser(t)
+
+				now := time.Now()
+				addEvent(t, user.ID, models.EventLoginSucceeded, "a", now)
+				addEvent(t, user.ID, models.EventLoginSucceeded, "a", now)
+				addEvent(t, user.ID, models.EventLoginSucceeded, "b", now)
+				addEvent(t, user.ID, models.EventLoginFailed, "c", now)
+
+				devices, err := repo.GetLoginDevices(context.Background(), user.ID.String())
+				require.NoError(t, err)
+				assert.ElementsMatch(t, []string{"a", "b"}, devices)
+			},
+		},
+		{
+			name: "No events",
+			testFunc: func(t *testing.T) {
+				user := newUser(t)
+
+				events, total, err := repo.GetEvents(context.Background(), user.ID.String(), 0, 10)
+				require.NoError(t, err)
+				assert.Zero(t, total)
+				assert.Empty(t, events)
+
+				devices, err := repo.GetLoginDevices(context.Background(), user.ID.String())
+				require.NoError(t, err)
+				assert.Empty(t, devices)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		err = db.AutoMigrate(&models.User{})
+		require.NoError(t, err)
+		err = db.Table("security_events").AutoMigrate(&models.SecurityEvent{})
+		require.NoError(t, err)
+
+		t.Run(tc.name, tc.testFunc)
+
+		err = db.Migrator().DropTable("security_events", &models.User{})
+		require.NoError(t, err)
+	}
+}
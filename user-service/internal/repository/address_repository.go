@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAddressNotFound = errors.New("address not found")
+)
+
+type AddressRepository struct {
+	db *gorm.DB
+}
+
+func NewAddressRepository(db *gorm.DB) *AddressRepository {
+	return &AddressRepository{
+		db: db,
+	}
+}
+
+// Create a new address
+func (r *AddressRepository) CreateAddress(ctx context.Context, address *models.Address) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := clearDefaults(tx, address); err != nil {
+			return err
+		}
+
+		return tx.Create(address).Error
+	})
+}
+
+// Get an address of a user by ID
+func (r *AddressRepository) GetAddress(ctx context.Context, id, userID string) (*models.Address, error) {
+	address := &models.Address{}
+	tx := r.db.WithContext(ctx).First(address, "id = ? AND user_id = ?", id, userID)
+	if tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return nil, ErrAddressNotFound
+		}
+		return nil, tx.Error
+	}
+	return address, nil
+}
+
+// Get all addresses of a user
+func (r *AddressRepository) GetAddresses(ctx context.Context, userID string) ([]models.Address, error) {
+	addresses := []models.Address{}
+	tx := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&addresses)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return addresses, nil
+}
+
+// Update an address
+func (r *AddressRepository) UpdateAddress(ctx context.Context, address *models.Address) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := clearDefaults(tx, address); err != nil {
+			return err
+		}
+
+		res := tx.Model(&models.Address{}).
+			Where("id = ? AND user_id = ?", address.ID, address.UserID).
+			Select("label", "full_name", "line1", "line2", "city", "region", "postal_code", "country", "phone", "default_shipping", "default_billing", "updated_at").
+			Updates(address)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrAddressNotFound
+		}
+
+		return nil
+	})
+}
+
+// Delete an address
+func (r *AddressRepository) DeleteAddress(ctx context.Context, id, userID string) error {
+	tx := r.db.WithContext(ctx).Delete(&models.Address{}, "id = ? AND user_id = ?", id, userID)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if tx.RowsAffected == 0 {
+		return ErrAddressNotFound
+	}
+
+	return nil
+}
+
+// clearDefaults unsets the default flags of the user's other addresses
+// when the given address takes them over.
+func clearDefaults(tx *gorm.DB, address *models.Address) error {
+	if address.DefaultShipping {
+		err := tx.Model(&models.Address{}).
+			Where("user_id = ? AND id <> ? AND default_shipping", address.UserID, address.ID).
+			Update("default_shipping", false).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	if address.DefaultBilling {
+		err := tx.Model(&models.Address{}).
+			Where("user_id = ? AND id <> ? AND default_billing", address.UserID, address.ID).
+			Update("default_billing", false).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
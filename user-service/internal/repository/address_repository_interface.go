@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+)
+
+type AddressRepositoryInterface interface {
+	// Create a new address
+	CreateAddress(ctx context.Context, address *models.Address) error
+
+	// Get an address of a user by ID
+	GetAddress(ctx context.Context, id, userID string) (*models.Address, error)
+
+	// Get all addresses of a user
+	GetAddresses(ctx context.Context, userID string) ([]models.Address, error)
+
+	// Update an address
+	UpdateAddress(ctx context.Context, address *models.Address) error
+
+	// Delete an address
+	DeleteAddress(ctx context.Context, id, userID string) error
+}
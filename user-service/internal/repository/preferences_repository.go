@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrConsentNotFound = errors.New("consent not found")
+)
+
+type PreferencesRepository struct {
+	db *gorm.DB
+}
+
+func NewPreferencesRepository(db *gorm.DB) *PreferencesRepository {
+	return &PreferencesRepository{
+		db: db,
+	}
+}
+
+// Get the preferences of a user, defaults are returned if none are stored
+func (r *PreferencesRepository) GetPreferences(ctx context.Context, userID string) (*models.UserPreferences, error) {
+	prefs := &models.UserPreferences{}
+	tx := r.db.WithContext(ctx).Table("user_preferences").First(prefs, "user_id = ?", userID)
+	if tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return &models.UserPreferences{
+				UserID:   uuid.MustParse(userID),
+				Language: models.DefaultLanguage,
+				Currency: models.DefaultCurrency,
+			}, nil
+		}
+		return nil, tx.Error
+	}
+	return prefs, nil
+}
+
+// Create or replace the preferences of a user
+func (r *PreferencesRepository) SavePreferences(ctx context.Context, prefs *models.UserPreferences) error {
+	return r.db.WithContext(ctx).Table("user_preferences").Save(prefs).Error
+}
+
+// Record a consent decision
+func (r *PreferencesRepository) AddConsent(ctx context.Context, record *models.ConsentRecord) error {
+	return r.db.WithContext(ctx).Table("consents").Create(record).Error
+}
+
+// Get the latest consent decision for every channel and category the user decided on
+func (r *PreferencesRepository) GetConsents(ctx context.Context, userID string) ([]models.ConsentRecord, error) {
+	records := []models.ConsentRecord{}
+	tx := r.db.WithContext(ctx).Raw(`
+	SELECT DISTINCT ON (channel, category) *
+	FROM consents
+	WHERE user_id = ?
+	ORDER BY channel, category, created_at DESC;
+	`, userID).Scan(&records)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return records, nil
+}
+
+// Get the latest consent decision for a channel and category
+func (r *PreferencesRepository) GetConsent(ctx context.Context, userID, channel, category string) (*models.ConsentRecord, error) {
+	record := &models.ConsentRecord{}
+	tx := r.db.WithContext(ctx).Table("consents").
+		Where("user_id = ? AND channel = ? AND category = ?", userID, channel, category).
+		Order("created_at DESC").
+		First(record)
+	if tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return nil, ErrConsentNotFound
+		}
+		return nil, tx.Error
+	}
+	return record, nil
+}
+
+// Get all consent decisions of a user, newest first
+func (r *PreferencesRepository) GetConsentHistory(ctx context.Context, userID string) ([]models.ConsentRecord, error) {
+	records := []models.ConsentRecord{}
+	tx := r.db.WithContext(ctx).Table("consents").Where("user_id = ?", userID).Order("created_at DESC").Find(&records)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return records, nil
+}
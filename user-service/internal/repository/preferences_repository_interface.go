@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+)
+
+type PreferencesRepositoryInterface interface {
+	// Get the preferences of a user, defaults are returned if none are stored
+	GetPreferences(ctx context.Context, userID string) (*models.UserPreferences, error)
+
+	// Create or replace the preferences of a user
+	SavePreferences(ctx context.Context, prefs *models.UserPreferences) error
+
+	// Record a consent decision
+	AddConsent(ctx context.Context, record *models.ConsentRecord) error
+
+	// Get the latest consent decision for every channel and category the user decided on
+	GetConsents(ctx context.Context, userID string) ([]models.ConsentRecord, error)
+
+	// Get the latest consent decision for a channel and category
+	GetConsent(ctx context.Context, userID, channel, category string) (*models.ConsentRecord, error)
+
+	// Get all consent decisions of a user, newest first
+	GetConsentHistory(ctx context.Context, userID string) ([]models.ConsentRecord, error)
+}
@@ -0,0 +1,131 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferences(t *testing.T) {
+	ctx := context.Background()
+	db, err := setupTestContainer(ctx)
+	require.NoError(t, err)
+
+	repo := repository.NewPreferencesRepository(db)
+
+	newUser := func(t *testing.T) models.User {
+		user := models.User{
+			ID:       uuid.New(),
+			Name:     gofakeit.FirstName(),
+			Surname:  gofakeit.LastName(),
+			Email:    gofakeit.Email(),
+			Password: gofakeit.Password(true, true, true, true, true, 10),
+		}
+
+		err := db.Create(&user).Error
+		require.NoError(t, err)
+
+		return user
+	}
+
+	addConsent := func(t *testing.T, userID uuid.UUID, channel, category string, granted bool, createdAt time.Time) {
+		err := repo.AddConsent(context.Background(), &models.ConsentRecord{
+			ID:        uuid.New(),
+			UserID:    userID,
+			Channel:   channel,
+			Category:  category,
+			Granted:   granted,
+			Source:    models.ConsentSourceSettings,
+			CreatedAt: createdAt,
+		})
+		require.NoError(t, err)
+	}
+
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "Defaults without stored preferences",
+			testFunc: func(t *testing.T) {
+				user := newUser(t)
+
+				prefs, err := repo.GetPreferences(context.Background(), user.ID.String())
+				require.NoError(t, err)
+				assert.Equal(t, user.ID, prefs.UserID)
+				assert.Equal(t, models.DefaultLanguage, prefs.Language)
+				assert.Equal(t, models.DefaultCurrency, prefs.Currency)
+			},
+		},
+		{
+			name: "Save and update preferences",
+			testFunc: func(t *testing.T) {
+				user := newUser(t)
+
+				err := repo.SavePreferences(context.Background(), &models.UserPreferences{UserID: user.ID, Language: "de", Currency: "EUR"})
+				require.NoError(t, err)
+
+				err = repo.SavePreferences(context.Background(), &models.UserPreferences{UserID: user.ID, Language: "pl", Currency: "EUR"})
+				require.NoError(t, err)
+
+				prefs, err := repo.GetPreferences(context.Background(), user.ID.String())
+				require.NoError(t, err)
+				assert.Equal(t, "pl", prefs.Language)
+				assert.Equal(t, "EUR", prefs.Currency)
+			},
+		},
+		{
+			name: "Latest consent is in effect",
+			testFunc: func(t *testing.T) {
+				user := newUser(t)
+				now := time.Now()
+
+				addConsent(t, user.ID, models.ChannelEmail, models.CategoryMarketing, true, now.Add(-time.Hour))
+				addConsent(t, user.ID, models.ChannelEmail, models.CategoryMarketing, false, now)
+				addConsent(t, user.ID, models.ChannelSMS, models.CategoryOrders, true, now)
+
+				consent, err := repo.GetConsent(context.Background(), user.ID.String(), models.ChannelEmail, models.CategoryMarketing)
+				require.NoError(t, err)
+				assert.False(t, consent.Granted)
+
+				consents, err := repo.GetConsents(context.Background(), user.ID.String())
+				require.NoError(t, err)
+				assert.Len(t, consents, 2)
+
+				history, err := repo.GetConsentHistory(context.Background(), user.ID.String())
+				require.NoError(t, err)
+				assert.Len(t, history, 3)
+			},
+		},
+		{
+			name: "No consent recorded",
+			testFunc: func(t *testing.T) {
+				user := newUser(t)
+
+				_, err := repo.GetConsent(context.Background(), user.ID.String(), models.ChannelPush, models.CategoryOrders)
+				assert.ErrorIs(t, err, repository.ErrConsentNotFound)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		err = db.AutoMigrate(&models.User{})
+		require.NoError(t, err)
+		err = db.Table("user_preferences").AutoMigrate(&models.UserPreferences{})
+		require.NoError(t, err)
+		err = db.Table("consents").AutoMigrate(&models.ConsentRecord{})
+		require.NoError(t, err)
+
+		t.Run(tc.name, tc.testFunc)
+
+		err = db.Migrator().DropTable("consents", "user_preferences", &models.User{})
+		require.NoError(t, err)
+	}
+}
@@ -4,17 +4,26 @@ import (
 	"context"
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
 	"gorm.io/gorm"
 )
 
 var (
-	ErrUserNotFound         = errors.New("user not found")
-	ErrUserWithEmailExists  = errors.New("user with email already exists")
-	ErrUserWithPhoneExists  = errors.New("user with phone already exists")
-	ErrResetTokenInvalid    = errors.New("invalid or expired reset token")
-	ErrNewPasswordSameAsOld = errors.New("new password cannot be the same as the old password")
+	ErrUserNotFound             = errors.New("user not found")
+	ErrUserWithEmailExists      = errors.New("user with email already exists")
+	ErrUserWithPhoneExists      = errors.New("user with phone already exists")
+	ErrGuestNotFound            = errors.New("guest not found")
+	ErrResetTokenInvalid        = errors.New("invalid or expired reset token")
+	ErrVerificationTokenInvalid = errors.New("invalid or expired verification token")
+	ErrNewPasswordSameAsOld     = errors.New("new password cannot be the same as the old password")
+	ErrOAuthStateInvalid        = errors.New("invalid or expired oauth state")
+	ErrIdentityExists           = errors.New("identity is already linked to a user")
+	ErrErasureNotFound          = errors.New("no pending erasure request")
+	ErrEmailChangeInvalid       = errors.New("invalid or expired email change token")
+	ErrMagicLinkInvalid         = errors.New("invalid, expired or already used login link")
+	ErrOTPInvalid               = errors.New("invalid or expired code")
 )
 
 type UserRepository struct {
@@ -55,39 +64,37 @@ func (u *UserRepository) CreateVerificationToken(ctx context.Context, token *mod
 	return nil
 }
 
-func (u *UserRepository) VerifyEmail(ctx context.Context, token string) error {
-	u.db.Transaction(func(tx *gorm.DB) error {
-		tx = tx.WithContext(ctx).Exec(`
+func (u *UserRepository) VerifyEmail(ctx context.Context, token string) (string, error) {
+	var userID string
+
+	err := u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Raw(`
 	UPDATE users
 	SET email_verified = TRUE
 	FROM email_verifications
 	WHERE users.id = email_verifications.user_id
 		AND email_verifications.token = ?
-		AND email_verifications.expires_at > NOW();
-	`, token)
-		if tx.Error != nil {
-			return tx.Error
+		AND email_verifications.expires_at > NOW()
+	RETURNING users.id;
+	`, token).Scan(&userID)
+		if res.Error != nil {
+			return res.Error
 		}
 
-		if tx.RowsAffected == 0 {
-			return errors.New("invalid or expired token")
+		if userID == "" {
+			return ErrVerificationTokenInvalid
 		}
 
-		tx = tx.WithContext(ctx).Exec(`
+		return tx.Exec(`
 	DELETE FROM email_verifications
 	WHERE token = ?;
-	`, token)
-		if tx.Error != nil {
-			return tx.Error
-		}
-
-		return nil
-
-	},
-		nil,
-	)
+	`, token).Error
+	})
+	if err != nil {
+		return "", err
+	}
 
-	return nil
+	return userID, nil
 }
 
 func (u *UserRepository) AddRefreshToken(ctx context.Context, tokenHash, userId string) error {
@@ -231,6 +238,22 @@ func (u *UserRepository) GetUserByID(ctx context.Context, id string) (*models.Us
 	return user, nil
 }
 
+// Get the user of a valid reset password token
+func (u *UserRepository) GetUserByResetToken(ctx context.Context, token string) (*models.User, error) {
+	user := &models.User{}
+	tx := u.db.WithContext(ctx).
+		Joins("JOIN password_resets ON password_resets.user_id = users.id").
+		Where("password_resets.token = ? AND password_resets.expires_at > NOW()", token).
+		First(user)
+	if tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return nil, ErrResetTokenInvalid
+		}
+		return nil, tx.Error
+	}
+	return user, nil
+}
+
 // Get a user by email
 func (u *UserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	user := &models.User{}
@@ -258,6 +281,41 @@ func (u *UserRepository) UpdateUser(ctx context.Context, user *models.User) erro
 	return nil
 }
 
+// Update the given profile fields of a user
+func (u *UserRepository) UpdateProfile(ctx context.Context, id string, fields map[string]interface{}) error {
+	tx := u.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Updates(fields)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if tx.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// Turn a guest into a full account with the given email, password and name, its sessions are revoked
+func (u *UserRepository) UpgradeGuest(ctx context.Context, user *models.User) error {
+	tx := u.db.WithContext(ctx).Exec(`
+	UPDATE users
+	SET email = ?, password = ?, name = ?, surname = ?, role = ?, refresh_tokens = NULL, updated_at = NOW()
+	WHERE id = ? AND role = ?;
+	`, user.Email, user.Password, user.Name, user.Surname, models.RoleUser, user.ID, models.RoleGuest)
+	if tx.Error != nil {
+		if strings.Contains(tx.Error.Error(), "violates unique constraint") {
+			return ErrUserWithEmailExists
+		}
+		return tx.Error
+	}
+
+	if tx.RowsAffected == 0 {
+		return ErrGuestNotFound
+	}
+
+	return nil
+}
+
 // Delete a user
 func (u *UserRepository) DeleteUser(ctx context.Context, id string) error {
 	tx := u.db.WithContext(ctx).Delete(&models.User{}, "id = ?", id)
@@ -271,3 +329,382 @@ func (u *UserRepository) DeleteUser(ctx context.Context, id string) error {
 
 	return nil
 }
+
+// Create OAuth state of a pending social login
+func (u *UserRepository) CreateOAuthState(ctx context.Context, state *models.OAuthState) error {
+	tx := u.db.WithContext(ctx).Table("oauth_states").Create(state)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	return nil
+}
+
+// Consume OAuth state, it can only be used once
+func (u *UserRepository) ConsumeOAuthState(ctx context.Context, state string) (*models.OAuthState, error) {
+	var states []models.OAuthState
+
+	tx := u.db.WithContext(ctx).Raw(`
+	DELETE FROM oauth_states
+	WHERE state = ?
+	RETURNING state, provider, nonce, code_verifier, expires_at;
+	`, state).Scan(&states)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	if len(states) == 0 || states[0].ExpiresAt.Before(time.Now()) {
+		return nil, ErrOAuthStateInvalid
+	}
+
+	return &states[0], nil
+}
+
+// Get a user by external identity
+func (u *UserRepository) GetUserByIdentity(ctx context.Context, provider, subject string) (*models.User, error) {
+	user := &models.User{}
+	tx := u.db.WithContext(ctx).
+		Joins("JOIN user_identities ON user_identities.user_id = users.id").
+		Where("user_identities.provider = ? AND user_identities.subject = ?", provider, subject).
+		First(user)
+	if tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return nil, ErrUserNotFound
+		}
+		return nil, tx.Error
+	}
+	return user, nil
+}
+
+// Link an external identity to a user
+func (u *UserRepository) CreateIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	tx := u.db.WithContext(ctx).Create(identity)
+	if tx.Error != nil {
+		if strings.Contains(tx.Error.Error(), "violates unique constraint") {
+			return ErrIdentityExists
+		}
+		return tx.Error
+	}
+
+	return nil
+}
+
+// Create a new user together with its external identity
+func (u *UserRepository) CreateUserWithIdentity(ctx context.Context, user *models.User, identity *models.UserIdentity) error {
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		repo := NewUserRepository(tx)
+
+		if err := repo.CreateUser(ctx, user); err != nil {
+			return err
+		}
+
+		return repo.CreateIdentity(ctx, identity)
+	})
+}
+
+// Get external identities linked to a user
+func (u *UserRepository) GetIdentities(ctx context.Context, userId string) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	tx := u.db.WithContext(ctx).Where("user_id = ?", userId).Order("created_at").Find(&identities)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	return identities, nil
+}
+
+// Create or replace the erasure request of a user
+func (u *UserRepository) CreateErasureRequest(ctx context.Context, request *models.ErasureRequest) error {
+	tx := u.db.WithContext(ctx).Table("erasure_requests").Save(request)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	return nil
+}
+
+// Cancel a pending erasure request
+func (u *UserRepository) CancelErasureRequest(ctx context.Context, userId string) error {
+	tx := u.db.WithContext(ctx).Table("erasure_requests").
+		Where("user_id = ? AND status = ?", userId, models.ErasureStatusPending).
+		Update("status", models.ErasureStatusCancelled)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if tx.RowsAffected == 0 {
+		return ErrErasureNotFound
+	}
+
+	return nil
+}
+
+// Get pending erasure requests whose grace period is over
+func (u *UserRepository) GetDueErasureRequests(ctx context.Context) ([]models.ErasureRequest, error) {
+	var requests []models.ErasureRequest
+	tx := u.db.WithContext(ctx).Table("erasure_requests").
+		Where("status = ? AND scheduled_for <= ?", models.ErasureStatusPending, time.Now()).
+		Order("scheduled_for").
+		Find(&requests)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	return requests, nil
+}
+
+// Anonymise a user, the row is kept so that references from other services stay valid
+func (u *UserRepository) AnonymiseUser(ctx context.Context, userId string) error {
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Exec(`
+		UPDATE users
+		SET name = 'Deleted', surname = NULL, phone = NULL, phone_verified = FALSE, two_factor_enabled = FALSE,
+			password = NULL, refresh_tokens = NULL,
+			email = 'deleted-' || id || '@invalid', email_verified = FALSE,
+			erased_at = NOW(), updated_at = NOW()
+		WHERE id = ?;
+		`, userId)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrUserNotFound
+		}
+
+		for _, table := range []string{"user_identities", "addresses", "email_verifications", "password_resets", "email_changes", "magic_links", "user_preferences", "consents", "otp_codes", "security_events"} {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE user_id = ?", userId).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Mark an erasure request as completed
+func (u *UserRepository) CompleteErasureRequest(ctx context.Context, userId string) error {
+	tx := u.db.WithContext(ctx).Table("erasure_requests").
+		Where("user_id = ?", userId).
+		Updates(map[string]any{"status": models.ErasureStatusCompleted, "completed_at": time.Now()})
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if tx.RowsAffected == 0 {
+		return ErrErasureNotFound
+	}
+
+	return nil
+}
+
+// Create or replace the pending email change of a user
+func (u *UserRepository) CreateEmailChange(ctx context.Context, change *models.EmailChange) error {
+	tx := u.db.WithContext(ctx).Table("email_changes").Save(change)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	return nil
+}
+
+// Confirm a pending email change, the new email is applied and marked as verified.
+// Verification and login links sent to the old email are deleted with it.
+func (u *UserRepository) ConfirmEmailChange(ctx context.Context, tokenHash string) (*models.EmailChange, error) {
+	var change *models.EmailChange
+
+	err := u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var changes []models.EmailChange
+
+		res := tx.Raw(`
+		DELETE FROM email_changes
+		WHERE token_hash = ?
+		RETURNING user_id, new_email, token_hash, expires_at;
+		`, tokenHash).Scan(&changes)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if len(changes) == 0 || changes[0].ExpiresAt.Before(time.Now()) {
+			return ErrEmailChangeInvalid
+		}
+		change = &changes[0]
+
+		res = tx.Exec(`
+		UPDATE users
+		SET email = ?, email_verified = TRUE, updated_at = NOW()
+		WHERE id = ?;
+		`, change.NewEmail, change.UserID)
+		if res.Error != nil {
+			if strings.Contains(res.Error.Error(), "violates unique constraint") {
+				return ErrUserWithEmailExists
+			}
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrUserNotFound
+		}
+
+		// A verification link sent to the old address must not verify the new one
+		res = tx.Exec("DELETE FROM email_verifications WHERE user_id = ?", change.UserID)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		// Nor may a login link sent to the old address sign in to the account any more
+		return tx.Exec("DELETE FROM magic_links WHERE user_id = ? AND used_at IS NULL", change.UserID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// Create a magic link
+func (u *UserRepository) CreateMagicLink(ctx context.Context, link *models.MagicLink) error {
+	tx := u.db.WithContext(ctx).Table("magic_links").Create(link)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	return nil
+}
+
+// Count magic links created for a user since the given time
+func (u *UserRepository) CountMagicLinksSince(ctx context.Context, userId string, since time.Time) (int64, error) {
+	var count int64
+	tx := u.db.WithContext(ctx).Table("magic_links").Where("user_id = ? AND created_at >= ?", userId, since).Count(&count)
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+
+	return count, nil
+}
+
+// Consume a magic link and return its user, a link can only be used once.
+// Using it proves ownership of the email, so the email is marked as verified.
+func (u *UserRepository) ConsumeMagicLink(ctx context.Context, tokenHash string) (*models.User, error) {
+	var user *models.User
+
+	err := u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var userIds []string
+
+		res := tx.Raw(`
+		UPDATE magic_links
+		SET used_at = NOW()
+		WHERE token_hash = ? AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id;
+		`, tokenHash).Scan(&userIds)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if len(userIds) == 0 {
+			return ErrMagicLinkInvalid
+		}
+
+		res = tx.Exec("UPDATE users SET email_verified = TRUE WHERE id = ?", userIds[0])
+		if res.Error != nil {
+			return res.Error
+		}
+
+		var err error
+		user, err = NewUserRepository(tx).GetUserByID(ctx, userIds[0])
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Get a user by a verified phone number
+func (u *UserRepository) GetUserByPhone(ctx context.Context, phone string) (*models.User, error) {
+	user := &models.User{}
+	tx := u.db.WithContext(ctx).First(user, "phone = ? AND phone_verified", phone)
+	if tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return nil, ErrUserNotFound
+		}
+		return nil, tx.Error
+	}
+	return user, nil
+}
+
+// Create or replace the pending one-time code of a user for its purpose
+func (u *UserRepository) SaveOTP(ctx context.Context, otp *models.OTPCode) error {
+	tx := u.db.WithContext(ctx).Table("otp_codes").Save(otp)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	return nil
+}
+
+// Get the pending one-time code of a user for a purpose
+func (u *UserRepository) GetOTP(ctx context.Context, userId, purpose string) (*models.OTPCode, error) {
+	otp := &models.OTPCode{}
+	tx := u.db.WithContext(ctx).Table("otp_codes").First(otp, "user_id = ? AND purpose = ?", userId, purpose)
+	if tx.Error != nil {
+		if tx.Error == gorm.ErrRecordNotFound {
+			return nil, ErrOTPInvalid
+		}
+		return nil, tx.Error
+	}
+	return otp, nil
+}
+
+// Count an attempt on a pending, unexpired code and return it, unless the attempts are used up.
+// The attempt is counted before the code is compared, so concurrent guesses can't exceed the limit.
+func (u *UserRepository) UseOTPAttempt(ctx context.Context, userId, purpose string, maxAttempts int) (*models.OTPCode, error) {
+	var otps []models.OTPCode
+
+	tx := u.db.WithContext(ctx).Raw(`
+	UPDATE otp_codes
+	SET attempts = attempts + 1
+	WHERE user_id = ? AND purpose = ? AND expires_at > NOW() AND attempts < ?
+	RETURNING *;
+	`, userId, purpose, maxAttempts).Scan(&otps)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	if len(otps) == 0 {
+		return nil, ErrOTPInvalid
+	}
+
+	return &otps[0], nil
+}
+
+// Delete the pending one-time code of a user for a purpose
+func (u *UserRepository) DeleteOTP(ctx context.Context, userId, purpose string) error {
+	return u.db.WithContext(ctx).Exec("DELETE FROM otp_codes WHERE user_id = ? AND purpose = ?", userId, purpose).Error
+}
+
+// Set the phone of a user as verified. Unverified copies of the number held by other
+// users are released, otherwise anyone could block a number by entering it first.
+func (u *UserRepository) ConfirmPhone(ctx context.Context, userId, phone string) error {
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Exec("UPDATE users SET phone = NULL WHERE phone = ? AND NOT phone_verified AND id <> ?", phone, userId)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		res = tx.Exec("UPDATE users SET phone = ?, phone_verified = TRUE, updated_at = NOW() WHERE id = ?", phone, userId)
+		if res.Error != nil {
+			if strings.Contains(res.Error.Error(), "violates unique constraint") {
+				return ErrUserWithPhoneExists
+			}
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrUserNotFound
+		}
+
+		return nil
+	})
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
 )
@@ -19,14 +20,20 @@ type UserRepositoryInterface interface {
 	// Update a user
 	UpdateUser(ctx context.Context, user *models.User) error
 
+	// Update the given profile fields of a user
+	UpdateProfile(ctx context.Context, id string, fields map[string]interface{}) error
+
 	// Delete a user
 	DeleteUser(ctx context.Context, id string) error
 
+	// Turn a guest into a full account with the given email, password and name, its sessions are revoked
+	UpgradeGuest(ctx context.Context, user *models.User) error
+
 	// Create a verification token
 	CreateVerificationToken(ctx context.Context, token *models.Token) error
 
-	// Verify user email
-	VerifyEmail(ctx context.Context, token string) error
+	// Verify user email and return the ID of the user
+	VerifyEmail(ctx context.Context, token string) (string, error)
 
 	// Create reset password token
 	CreateResetPasswordToken(ctx context.Context, token *models.Token) error
@@ -43,6 +50,75 @@ type UserRepositoryInterface interface {
 	// Delete refresh token
 	DeleteRefreshToken(ctx context.Context, tokenHash, userId string) error
 
+	// Get the user of a valid reset password token
+	GetUserByResetToken(ctx context.Context, token string) (*models.User, error)
+
 	// Reset user password
 	ResetPassword(ctx context.Context, token, password string) error
+
+	// Create OAuth state of a pending social login
+	CreateOAuthState(ctx context.Context, state *models.OAuthState) error
+
+	// Consume OAuth state, it can only be used once
+	ConsumeOAuthState(ctx context.Context, state string) (*models.OAuthState, error)
+
+	// Get a user by external identity
+	GetUserByIdentity(ctx context.Context, provider, subject string) (*models.User, error)
+
+	// Link an external identity to a user
+	CreateIdentity(ctx context.Context, identity *models.UserIdentity) error
+
+	// Create a new user together with its external identity
+	CreateUserWithIdentity(ctx context.Context, user *models.User, identity *models.UserIdentity) error
+
+	// Get external identities linked to a user
+	GetIdentities(ctx context.Context, userId string) ([]models.UserIdentity, error)
+
+	// Create or replace the erasure request of a user
+	CreateErasureRequest(ctx context.Context, request *models.ErasureRequest) error
+
+	// Cancel a pending erasure request
+	CancelErasureRequest(ctx context.Context, userId string) error
+
+	// Get pending erasure requests whose grace period is over
+	GetDueErasureRequests(ctx context.Context) ([]models.ErasureRequest, error)
+
+	// Anonymise a user and delete its personal data
+	AnonymiseUser(ctx context.Context, userId string) error
+
+	// Mark an erasure request as completed
+	CompleteErasureRequest(ctx context.Context, userId string) error
+
+	// Create or replace the pending email change of a user
+	CreateEmailChange(ctx context.Context, change *models.EmailChange) error
+
+	// Confirm a pending email change
+	ConfirmEmailChange(ctx context.Context, tokenHash string) (*models.EmailChange, error)
+
+	// Create a magic link
+	CreateMagicLink(ctx context.Context, link *models.MagicLink) error
+
+	// Count magic links created for a user since the given time
+	CountMagicLinksSince(ctx context.Context, userId string, since time.Time) (int64, error)
+
+	// Consume a magic link and return its user
+	ConsumeMagicLink(ctx context.Context, tokenHash string) (*models.User, error)
+
+	// Get a user by a verified phone number
+	GetUserByPhone(ctx context.Context, phone string) (*models.User, error)
+
+	// Create or replace the pending one-time code of a user for its purpose
+	SaveOTP(ctx context.Context, otp *models.OTPCode) error
+
+	// Get the pending one-time code of a user for a purpose
+	GetOTP(ctx context.Context, userId, purpose string) (*models.OTPCode, error)
+
+	// Count an attempt on a pending, unexpired code and return it, unless the attempts are used up
+	UseOTPAttempt(ctx context.Context, userId, purpose string, maxAttempts int) (*models.OTPCode, error)
+
+	// Delete the pending one-time code of a user for a purpose
+	DeleteOTP(ctx context.Context, userId, purpose string) error
+
+	// Set the phone of a user as verified
+	ConfirmPhone(ctx context.Context, userId, phone string) error
 }
@@ -3,13 +3,17 @@ package repository_test
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
 	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -49,6 +53,27 @@ func setupTestContainer(ctx context.Context) (*gorm.DB, error) {
 	return db, nil
 }
 
+// migrate creates the tables with the migrations of the service, for tests of queries that
+// touch more tables than their models
+func migrate(db *gorm.DB) error {
+	migrations, err := filepath.Glob("../db/migrations/*.up.sql")
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		sql, err := os.ReadFile(migration)
+		if err != nil {
+			return err
+		}
+		if err := db.Exec(string(sql)).Error; err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(migration), err)
+		}
+	}
+
+	return nil
+}
+
 func TestCreateUser(t *testing.T) {
 
 	ctx := context.Background()
@@ -478,3 +503,739 @@ func TestDeleteUser(t *testing.T) {
 		require.NoError(t, err)
 	}
 }
+
+func TestUpdateProfile(t *testing.T) {
+	ctx := context.Background()
+	db, err := setupTestContainer(ctx)
+	require.NoError(t, err)
+
+	repo := repository.NewUserRepository(db)
+
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "Update whitelisted fields",
+			testFunc: func(t *testing.T) {
+				user := models.User{
+					ID:       uuid.New(),
+					Name:     gofakeit.FirstName(),
+					Surname:  gofakeit.LastName(),
+					Email:    gofakeit.Email(),
+					Password: gofakeit.Password(true, true, true, true, true, 10),
+				}
+
+				err := db.Create(&user).Error
+				require.NoError(t, err)
+
+				err = repo.UpdateProfile(context.Background(), user.ID.String(), map[string]interface{}{"name": "Jane", "surname": ""})
+				require.NoError(t, err)
+
+				var userFromDB models.User
+				err = db.First(&userFromDB, "id = ?", user.ID).Error
+				require.NoError(t, err)
+
+				assert.Equal(t, "Jane", userFromDB.Name)
+				assert.Equal(t, "", userFromDB.Surname)
+				assert.Equal(t, user.Email, userFromDB.Email)
+				assert.Equal(t, user.Password, userFromDB.Password)
+			},
+		},
+		{
+			name: "Update non-existent user",
+			testFunc: func(t *testing.T) {
+				err := repo.UpdateProfile(context.Background(), uuid.New().String(), map[string]interface{}{"name": "Jane"})
+				assert.ErrorIs(t, err, repository.ErrUserNotFound)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		err = db.AutoMigrate(&models.User{})
+		require.NoError(t, err)
+
+		t.Run(tc.name, tc.testFunc)
+
+		err = db.Migrator().DropTable(&models.User{})
+		require.NoError(t, err)
+	}
+}
+
+func TestConfirmEmailChange(t *testing.T) {
+	ctx := context.Background()
+	db, err := setupTestContainer(ctx)
+	require.NoError(t, err)
+
+	repo := repository.NewUserRepository(db)
+
+	newUser := func(t *testing.T) models.User {
+		user := models.User{
+			ID:       uuid.New(),
+			Name:     gofakeit.FirstName(),
+			Surname:  gofakeit.LastName(),
+			Email:    gofakeit.Email(),
+			Password: gofakeit.Password(true, true, true, true, true, 10),
+		}
+
+		err := db.Create(&user).Error
+		require.NoError(t, err)
+
+		return user
+	}
+
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "Confirm email change",
+			testFunc: func(t *testing.T) {
+				user := newUser(t)
+				newEmail := gofakeit.Email()
+
+				err := repo.CreateEmailChange(context.Background(), &models.EmailChange{
+					UserID:    user.ID,
+					NewEmail:  newEmail,
+					TokenHash: "hash",
+					ExpiresAt: time.Now().Add(time.Hour),
+				})
+				require.NoError(t, err)
+
+				change, err := repo.ConfirmEmailChange(context.Background(), "hash")
+				require.NoError(t, err)
+				assert.Equal(t, user.ID, change.UserID)
+
+				var userFromDB models.User
+				err = db.First(&userFromDB, "id = ?", user.ID).Error
+				require.NoError(t, err)
+
+				assert.Equal(t, newEmail, userFromDB.Email)
+				assert.True(t, userFromDB.EmailVerified)
+
+				// The token can only be used once
+				_, err = repo.ConfirmEmailChange(context.Background(), "hash")
+				assert.ErrorIs(t, err, repository.ErrEmailChangeInvalid)
+			},
+		},
+		{
+			name: "Login links sent to the old email are deleted",
+			testFunc: func(t *testing.T) {
+				user := newUser(t)
+				other := newUser(t)
+
+				for _, link := range []models.MagicLink{
+					{TokenHash: "unused", UserID: user.ID},
+					{TokenHash: "used", UserID: user.ID},
+					{TokenHash: "other", UserID: other.ID},
+				} {
+					link.CreatedAt = time.Now()
+					link.ExpiresAt = time.Now().Add(time.Hour)
+					require.NoError(t, repo.CreateMagicLink(context.Background(), &link))
+				}
+				_, err := repo.ConsumeMagicLink(context.Background(), "used")
+				require.NoError(t, err)
+
+				err = repo.CreateEmailChange(context.Background(), &models.EmailChange{
+					UserID:    user.ID,
+					NewEmail:  gofakeit.Email(),
+					TokenHash: "links",
+					ExpiresAt: time.Now().Add(time.Hour),
+				})
+				require.NoError(t, err)
+
+				_, err = repo.ConfirmEmailChange(context.Background(), "links")
+				require.NoError(t, err)
+
+				_, err = repo.ConsumeMagicLink(context.Background(), "unused")
+				assert.ErrorIs(t, err, repository.ErrMagicLinkInvalid)
+
+				var hashes []string
+				err = db.Table("magic_links").Order("token_hash").Pluck("token_hash", &hashes).Error
+				require.NoError(t, err)
+				assert.Equal(t, []string{"other", "used"}, hashes, "used links and the links of other users are kept")
+			},
+		},
+		{
+			name: "Expired token",
+			testFunc: func(t *testing.T) {
+				user := newUser(t)
+
+				err := repo.CreateEmailChange(context.Background(), &models.EmailChange{
+					UserID:    user.ID,
+					NewEmail:  gofakeit.Email(),
+					TokenHash: "expired",
+					ExpiresAt: time.Now().Add(-time.Hour),
+				})
+				require.NoError(t, err)
+
+				_, err = repo.ConfirmEmailChange(context.Background(), "expired")
+				assert.ErrorIs(t, err, repository.ErrEmailChangeInvalid)
+			},
+		},
+		{
+			name: "Email taken in the meantime",
+			testFunc: func(t *testing.T) {
+				user := newUser(t)
+				other := newUser(t)
+
+				err := repo.CreateEmailChange(context.Background(), &models.EmailChange{
+					UserID:    user.ID,
+					NewEmail:  other.Email,
+					TokenHash: "taken",
+					ExpiresAt: time.Now().Add(time.Hour),
+				})
+				require.NoError(t, err)
+
+				_, err = repo.ConfirmEmailChange(context.Background(), "taken")
+				assert.ErrorIs(t, err, repository.ErrUserWithEmailExists)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		err = db.AutoMigrate(&models.User{})
+		require.NoError(t, err)
+		err = db.Table("email_changes").AutoMigrate(&models.EmailChange{})
+		require.NoError(t, err)
+		err = db.Table("email_verifications").AutoMigrate(&models.Token{})
+		require.NoError(t, err)
+		err = db.Table("magic_links").AutoMigrate(&models.MagicLink{})
+		require.NoError(t, err)
+
+		t.Run(tc.name, tc.testFunc)
+
+		err = db.Migrator().DropTable("email_changes", "email_verifications", "magic_links", &models.User{})
+		require.NoError(t, err)
+	}
+}
+
+func TestConsumeMagicLink(t *testing.T) {
+	ctx := context.Background()
+	db, err := setupTestContainer(ctx)
+	require.NoError(t, err)
+
+	repo := repository.NewUserRepository(db)
+
+	newLink := func(t *testing.T, tokenHash string, expiresAt time.Time) models.User {
+		user := models.User{
+			ID:       uuid.New(),
+			Name:     gofakeit.FirstName(),
+			Surname:  gofakeit.LastName(),
+			Email:    gofakeit.Email(),
+			Password: gofakeit.Password(true, true, true, true, true, 10),
+		}
+
+		err := db.Create(&user).Error
+		require.NoError(t, err)
+
+		err = repo.CreateMagicLink(context.Background(), &models.MagicLink{
+			TokenHash: tokenHash,
+			UserID:    user.ID,
+			CreatedAt: time.Now(),
+			ExpiresAt: expiresAt,
+		})
+		require.NoError(t, err)
+
+		return user
+	}
+
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "Consume magic link once",
+			testFunc: func(t *testing.T) {
+				user := newLink(t, "hash", time.Now().Add(time.Minute))
+
+				userFromLink, err := repo.ConsumeMagicLink(context.Background(), "hash")
+				require.NoError(t, err)
+				assert.Equal(t, user.ID, userFromLink.ID)
+				assert.True(t, userFromLink.EmailVerified)
+
+				_, err = repo.ConsumeMagicLink(context.Background(), "hash")
+				assert.ErrorIs(t, err, repository.ErrMagicLinkInvalid)
+			},
+		},
+		{
+			name: "Expired magic link",
+			testFunc: func(t *testing.T) {
+				newLink(t, "expired", time.Now().Add(-time.Minute))
+
+				_, err := repo.ConsumeMagicLink(context.Background(), "expired")
+				assert.ErrorIs(t, err, repository.ErrMagicLinkInvalid)
+			},
+		},
+		{
+			name: "Count recent magic links",
+			testFunc: func(t *testing.T) {
+				user := newLink(t, "first", time.Now().Add(time.Minute))
+
+				count, err := repo.CountMagicLinksSince(context.Background(), user.ID.String(), time.Now().Add(-time.Minute))
+				require.NoError(t, err)
+				assert.Equal(t, int64(1), count)
+
+				count, err = repo.CountMagicLinksSince(context.Background(), user.ID.String(), time.Now().Add(time.Minute))
+				require.NoError(t, err)
+				assert.Equal(t, int64(0), count)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		err = db.AutoMigrate(&models.User{})
+		require.NoError(t, err)
+		err = db.Table("magic_links").AutoMigrate(&models.MagicLink{})
+		require.NoError(t, err)
+
+		t.Run(tc.name, tc.testFunc)
+
+		err = db.Migrator().DropTable("magic_links", &models.User{})
+		require.NoError(t, err)
+	}
+}
+
+func TestUpgradeGuest(t *testing.T) {
+	ctx := context.Background()
+	db, err := setupTestContainer(ctx)
+	require.NoError(t, err)
+
+	repo := repository.NewUserRepository(db)
+
+	newGuest := func(t *testing.T) models.User {
+		guest := models.User{
+			ID:            uuid.New(),
+			Name:          "Guest",
+			Role:          models.RoleGuest,
+			RefreshTokens: []string{"guest-session"},
+		}
+
+		err := repo.CreateUser(context.Background(), &guest)
+		require.NoError(t, err)
+
+		return guest
+	}
+
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "Upgrade guest",
+			testFunc: func(t *testing.T) {
+				guest := newGuest(t)
+				guest.Email = gofakeit.Email()
+				guest.Password = "hash"
+				guest.Name = gofakeit.FirstName()
+
+				err := repo.UpgradeGuest(context.Background(), &guest)
+				require.NoError(t, err)
+
+				user, err := repo.GetUserByID(context.Background(), guest.ID.String())
+				require.NoError(t, err)
+				assert.Equal(t, guest.Email, user.Email)
+				assert.Equal(t, models.RoleUser, user.Role)
+				assert.Empty(t, user.RefreshTokens)
+
+				err = repo.UpgradeGuest(context.Background(), &guest)
+				assert.ErrorIs(t, err, repository.ErrGuestNotFound)
+			},
+		},
+		{
+			name: "Email already taken",
+			testFunc: func(t *testing.T) {
+				existing := models.User{
+					ID:       uuid.New(),
+					Name:     gofakeit.FirstName(),
+					Email:    gofakeit.Email(),
+					Password: "hash",
+				}
+				err := repo.CreateUser(context.Background(), &existing)
+				require.NoError(t, err)
+
+				guest := newGuest(t)
+				guest.Email = existing.Email
+				guest.Password = "hash"
+
+				err = repo.UpgradeGuest(context.Background(), &guest)
+				assert.ErrorIs(t, err, repository.ErrUserWithEmailExists)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		err = db.AutoMigrate(&models.User{})
+		require.NoError(t, err)
+
+		t.Run(tc.name, tc.testFunc)
+
+		err = db.Migrator().DropTable(&models.User{})
+		require.NoError(t, err)
+	}
+}
+
+func TestOTPCodes(t *testing.T) {
+	ctx := context.Background()
+	db, err := setupTestContainer(ctx)
+	require.NoError(t, err)
+
+	repo := repository.NewUserRepository(db)
+
+	newUser := func(t *testing.T, phone string, verified bool) models.User {
+		user := models.User{
+			ID:            uuid.New(),
+			Name:          gofakeit.FirstName(),
+			Email:         gofakeit.Email(),
+			Password:      "hash",
+			Phone:         phone,
+			PhoneVerified: verified,
+		}
+
+		err := db.Create(&user).Error
+		require.NoError(t, err)
+
+		return user
+	}
+
+	newOTP := func(t *testing.T, user models.User, phone string, expiresAt time.Time) {
+		err := repo.SaveOTP(context.Background(), &models.OTPCode{
+			UserID:    user.ID,
+			Purpose:   models.OTPPurposeVerifyPhone,
+			Phone:     phone,
+			CodeHash:  "hash",
+			CreatedAt: time.Now(),
+			ExpiresAt: expiresAt,
+		})
+		require.NoError(t, err)
+	}
+
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "Attempts are limited",
+			testFunc: func(t *testing.T) {
+				user := newUser(t, "", false)
+				newOTP(t, user, "+14155552671", time.Now().Add(time.Minute))
+
+				for i := 1; i <= 3; i++ {
+					otp, err := repo.UseOTPAttempt(context.Background(), user.ID.String(), models.OTPPurposeVerifyPhone, 3)
+					require.NoError(t, err)
+					assert.Equal(t, i, otp.Attempts)
+				}
+
+				_, err := repo.UseOTPAttempt(context.Background(), user.ID.String(), models.OTPPurposeVerifyPhone, 3)
+				assert.ErrorIs(t, err, repository.ErrOTPInvalid)
+			},
+		},
+		{
+			name: "Expired code",
+			testFunc: func(t *testing.T) {
+				user := newUser(t, "", false)
+				newOTP(t, user, "+14155552671", time.Now().Add(-time.Minute))
+
+				_, err := repo.UseOTPAttempt(context.Background(), user.ID.String(), models.OTPPurposeVerifyPhone, 3)
+				assert.ErrorIs(t, err, repository.ErrOTPInvalid)
+			},
+		},
+		{
+			name: "Confirm phone releases unverified copies",
+			testFunc: func(t *testing.T) {
+				squatter := newUser(t, "+14155552672", false)
+				user := newUser(t, "", false)
+
+				err := repo.ConfirmPhone(context.Background(), user.ID.String(), "+14155552672")
+				require.NoError(t, err)
+
+				found, err := repo.GetUserByPhone(context.Background(), "+14155552672")
+				require.NoError(t, err)
+				assert.Equal(t, user.ID, found.ID)
+
+				squatter2, err := repo.GetUserByID(context.Background(), squatter.ID.String())
+				require.NoError(t, err)
+				assert.Empty(t, squatter2.Phone)
+			},
+		},
+		{
+			name: "Verified phone of another user",
+			testFunc: func(t *testing.T) {
+				newUser(t, "+14155552673", true)
+				user := newUser(t, "", false)
+
+				err := repo.ConfirmPhone(context.Background(), user.ID.String(), "+14155552673")
+				assert.ErrorIs(t, err, repository.ErrUserWithPhoneExists)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		err = db.AutoMigrate(&models.User{})
+		require.NoError(t, err)
+		err = db.Table("otp_codes").AutoMigrate(&models.OTPCode{})
+		require.NoError(t, err)
+
+		t.Run(tc.name, tc.testFunc)
+
+		err = db.Migrator().DropTable("otp_codes", &models.User{})
+		require.NoError(t, err)
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	ctx := context.Background()
+	db, err := setupTestContainer(ctx)
+	require.NoError(t, err)
+
+	repo := repository.NewUserRepository(db)
+
+	newUser := func(t *testing.T, token string, expiresAt time.Time) models.User {
+		user := models.User{
+			ID:       uuid.New(),
+			Name:     gofakeit.FirstName(),
+			Email:    gofakeit.Email(),
+			Password: "hash",
+		}
+
+		err := db.Create(&user).Error
+		require.NoError(t, err)
+
+		err = repo.CreateVerificationToken(context.Background(), &models.Token{UserID: user.ID, Token: token, ExpiresAt: expiresAt})
+		require.NoError(t, err)
+
+		return user
+	}
+
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "Valid token",
+			testFunc: func(t *testing.T) {
+				user := newUser(t, "token", time.Now().Add(time.Hour))
+
+				userID, err := repo.VerifyEmail(context.Background(), "token")
+				require.NoError(t, err)
+				assert.Equal(t, user.ID.String(), userID)
+
+				found, err := repo.GetUserByID(context.Background(), user.ID.String())
+				require.NoError(t, err)
+				assert.True(t, found.EmailVerified)
+
+				_, err = repo.VerifyEmail(context.Background(), "token")
+				assert.ErrorIs(t, err, repository.ErrVerificationTokenInvalid)
+			},
+		},
+		{
+			name: "Expired token",
+			testFunc: func(t *testing.T) {
+				user := newUser(t, "token", time.Now().Add(-time.Hour))
+
+				_, err := repo.VerifyEmail(context.Background(), "token")
+				assert.ErrorIs(t, err, repository.ErrVerificationTokenInvalid)
+
+				found, err := repo.GetUserByID(context.Background(), user.ID.String())
+				require.NoError(t, err)
+				assert.False(t, found.EmailVerified)
+			},
+		},
+		{
+			name: "Unknown token",
+			testFunc: func(t *testing.T) {
+				_, err := repo.VerifyEmail(context.Background(), "unknown")
+				assert.ErrorIs(t, err, repository.ErrVerificationTokenInvalid)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		err = db.AutoMigrate(&models.User{})
+		require.NoError(t, err)
+		err = db.Table("email_verifications").AutoMigrate(&models.Token{})
+		require.NoError(t, err)
+
+		t.Run(tc.name, tc.testFunc)
+
+		err = db.Migrator().DropTable("email_verifications", &models.User{})
+		require.NoError(t, err)
+	}
+}
+
+func TestErasure(t *testing.T) {
+	ctx := context.Background()
+	db, err := setupTestContainer(ctx)
+	require.NoError(t, err)
+	require.NoError(t, migrate(db))
+
+	repo := repository.NewUserRepository(db)
+	addressRepo := repository.NewAddressRepository(db)
+	prefsRepo := repository.NewPreferencesRepository(db)
+
+	newUser := func(t *testing.T) models.User {
+		user := models.User{
+			ID:            uuid.New(),
+			Name:          gofakeit.FirstName(),
+			Surname:       gofakeit.LastName(),
+			Email:         gofakeit.Email(),
+			Password:      "hash",
+			Phone:         "+1555" + gofakeit.Numerify("#######"),
+			PhoneVerified: true,
+			TwoFactor:     true,
+			RefreshTokens: []string{"session"},
+		}
+
+		err := db.Create(&user).Error
+		require.NoError(t, err)
+
+		return user
+	}
+
+	requestErasure := func(t *testing.T, userID uuid.UUID, scheduledFor time.Time) {
+		err := repo.CreateErasureRequest(context.Background(), &models.ErasureRequest{
+			UserID:       userID,
+			Status:       models.ErasureStatusPending,
+			RequestedAt:  scheduledFor.Add(-30 * 24 * time.Hour),
+			ScheduledFor: scheduledFor,
+		})
+		require.NoError(t, err)
+	}
+
+	dueUserIDs := func(t *testing.T) []uuid.UUID {
+		requests, err := repo.GetDueErasureRequests(context.Background())
+		require.NoError(t, err)
+
+		var ids []uuid.UUID
+		for _, r := range requests {
+			ids = append(ids, r.UserID)
+		}
+		return ids
+	}
+
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "Request is due once the grace period is over",
+			testFunc: func(t *testing.T) {
+				due := newUser(t)
+				waiting := newUser(t)
+				requestErasure(t, due.ID, time.Now().Add(-24*time.Hour))
+				requestErasure(t, waiting.ID, time.Now().Add(24*time.Hour))
+
+				ids := dueUserIDs(t)
+				assert.Contains(t, ids, due.ID)
+				assert.NotContains(t, ids, waiting.ID)
+			},
+		},
+		{
+			name: "Cancelled request is never due",
+			testFunc: func(t *testing.T) {
+				user := newUser(t)
+				requestErasure(t, user.ID, time.Now().Add(-24*time.Hour))
+
+				err := repo.CancelErasureRequest(context.Background(), user.ID.String())
+				require.NoError(t, err)
+				assert.NotContains(t, dueUserIDs(t), user.ID)
+
+				err = repo.CancelErasureRequest(context.Background(), user.ID.String())
+				assert.ErrorIs(t, err, repository.ErrErasureNotFound)
+			},
+		},
+		{
+			name: "Completed request is not due again",
+			testFunc: func(t *testing.T) {
+				user := newUser(t)
+				requestErasure(t, user.ID, time.Now().Add(-24*time.Hour))
+
+				err := repo.CompleteErasureRequest(context.Background(), user.ID.String())
+				require.NoError(t, err)
+				assert.NotContains(t, dueUserIDs(t), user.ID)
+			},
+		},
+		{
+			name: "Anonymise clears the personal data",
+			testFunc: func(t *testing.T) {
+				user := newUser(t)
+				other := newUser(t)
+
+				for _, u := range []models.User{user, other} {
+					err := addressRepo.CreateAddress(context.Background(), &models.Address{
+						ID:       uuid.New(),
+						UserID:   u.ID,
+						Label:    "Home",
+						FullName: u.Name + " " + u.Surname,
+						Line1:    gofakeit.Street(),
+						City:     gofakeit.City(),
+						Country:  "US",
+					})
+					require.NoError(t, err)
+				}
+				err := prefsRepo.SavePreferences(context.Background(), &models.UserPreferences{UserID: user.ID, Language: "en", Currency: "USD"})
+				require.NoError(t, err)
+				err = prefsRepo.AddConsent(context.Background(), &models.ConsentRecord{
+					ID:       uuid.New(),
+					UserID:   user.ID,
+					Channel:  "email",
+					Category: "marketing",
+					Granted:  true,
+					Source:   "settings",
+				})
+				require.NoError(t, err)
+
+				err = repo.AnonymiseUser(context.Background(), user.ID.String())
+				require.NoError(t, err)
+
+				var row struct {
+					Name          string
+					Surname       *string
+					Email         string
+					EmailVerified bool
+					Phone         *string
+					PhoneVerified bool
+					TwoFactor     bool `gorm:"column:two_factor_enabled"`
+					Password      *string
+					RefreshTokens pq.StringArray
+					ErasedAt      *time.Time
+				}
+				err = db.Raw("SELECT * FROM users WHERE id = ?", user.ID).Scan(&row).Error
+				require.NoError(t, err)
+
+				assert.Equal(t, "Deleted", row.Name)
+				assert.Nil(t, row.Surname)
+				assert.Equal(t, "deleted-"+user.ID.String()+"@invalid", row.Email)
+				assert.False(t, row.EmailVerified)
+				assert.Nil(t, row.Phone)
+				assert.False(t, row.PhoneVerified)
+				assert.False(t, row.TwoFactor)
+				assert.Nil(t, row.Password)
+				assert.Empty(t, row.RefreshTokens)
+				assert.NotNil(t, row.ErasedAt)
+
+				addresses, err := addressRepo.GetAddresses(context.Background(), user.ID.String())
+				require.NoError(t, err)
+				assert.Empty(t, addresses)
+				consents, err := prefsRepo.GetConsentHistory(context.Background(), user.ID.String())
+				require.NoError(t, err)
+				assert.Empty(t, consents)
+
+				addresses, err = addressRepo.GetAddresses(context.Background(), other.ID.String())
+				require.NoError(t, err)
+				assert.Len(t, addresses, 1, "other users keep their data")
+			},
+		},
+		{
+			name: "Anonymise unknown user",
+			testFunc: func(t *testing.T) {
+				err := repo.AnonymiseUser(context.Background(), uuid.NewString())
+				assert.ErrorIs(t, err, repository.ErrUserNotFound)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
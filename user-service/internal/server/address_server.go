@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/user-service/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AddressServer exposes the address book to other services.
+type AddressServer struct {
+	proto.UnimplementedAddressServiceServer
+
+	service *service.AddressService
+}
+
+func NewAddressServer(service *service.AddressService) *AddressServer {
+	return &AddressServer{
+		service: service,
+	}
+}
+
+// GetAddress returns an address by ID if it belongs to the given user.
+func (s *AddressServer) GetAddress(ctx context.Context, req *proto.GetAddressRequest) (*proto.Address, error) {
+	address, err := s.service.GetUserAddress(ctx, req.AddressId, req.UserId)
+	if err != nil {
+		if err == repository.ErrAddressNotFound {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &proto.Address{
+		Id:         address.ID.String(),
+		UserId:     address.UserID.String(),
+		Label:      address.Label,
+		FullName:   address.FullName,
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		Region:     address.Region,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+		Phone:      address.Phone,
+	}, nil
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/user-service/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AuthServer lets other services validate tokens and look up users.
+type AuthServer struct {
+	proto.UnimplementedAuthServiceServer
+
+	service *service.AuthService
+}
+
+func NewAuthServer(service *service.AuthService) *AuthServer {
+	return &AuthServer{
+		service: service,
+	}
+}
+
+// ValidateToken reports whether an access token is valid, a rejected token is not an error.
+func (s *AuthServer) ValidateToken(ctx context.Context, req *proto.ValidateTokenRequest) (*proto.ValidateTokenResponse, error) {
+	info, err := s.service.ValidateToken(ctx, req.Token, req.Audience)
+	if err != nil {
+		if service.IsTokenError(err) {
+			return &proto.ValidateTokenResponse{Valid: false, Reason: err.Error()}, nil
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &proto.ValidateTokenResponse{
+		Valid:     true,
+		UserId:    info.UserID,
+		Role:      info.Role,
+		ExpiresAt: info.ExpiresAt.Unix(),
+	}, nil
+}
+
+// CheckPermission reports whether the user of an access token has the permission.
+func (s *AuthServer) CheckPermission(ctx context.Context, req *proto.CheckPermissionRequest) (*proto.CheckPermissionResponse, error) {
+	info, err := s.service.CheckPermission(ctx, req.Token, req.Permission, req.Audience)
+	if err != nil {
+		if err == service.ErrPermissionDenied {
+			return &proto.CheckPermissionResponse{
+				Allowed:   false,
+				UserId:    info.UserID,
+				Role:      info.Role,
+				ExpiresAt: info.ExpiresAt.Unix(),
+				Reason:    err.Error(),
+			}, nil
+		}
+		if service.IsTokenError(err) {
+			return &proto.CheckPermissionResponse{Allowed: false, Reason: err.Error()}, nil
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &proto.CheckPermissionResponse{
+		Allowed:   true,
+		UserId:    info.UserID,
+		Role:      info.Role,
+		ExpiresAt: info.ExpiresAt.Unix(),
+	}, nil
+}
+
+// GetUser returns the public profile of a user.
+func (s *AuthServer) GetUser(ctx context.Context, req *proto.GetUserRequest) (*proto.User, error) {
+	user, err := s.service.GetUser(ctx, req.UserId)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &proto.User{
+		Id:            user.ID.String(),
+		FirstName:     user.Name,
+		LastName:      user.Surname,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		Role:          user.Role,
+		Disabled:      user.Disabled,
+	}, nil
+}
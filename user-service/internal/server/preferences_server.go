@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/user-service/proto"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PreferencesServer lets other services look up settings and consent of users before contacting them.
+type PreferencesServer struct {
+	proto.UnimplementedPreferencesServiceServer
+
+	service *service.PreferencesService
+}
+
+func NewPreferencesServer(service *service.PreferencesService) *PreferencesServer {
+	return &PreferencesServer{
+		service: service,
+	}
+}
+
+// GetPreferences returns the settings of a user and the consent in effect for every channel and category.
+func (s *PreferencesServer) GetPreferences(ctx context.Context, req *proto.GetPreferencesRequest) (*proto.Preferences, error) {
+	if _, err := uuid.Parse(req.UserId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+
+	prefs, err := s.service.GetUserPreferences(ctx, req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	res := &proto.Preferences{
+		UserId:   req.UserId,
+		Language: prefs.Language,
+		Currency: prefs.Currency,
+		Consents: make([]*proto.Consent, 0, len(prefs.Consents)),
+	}
+
+	for _, consent := range prefs.Consents {
+		res.Consents = append(res.Consents, &proto.Consent{
+			Channel:  consent.Channel,
+			Category: consent.Category,
+			Granted:  consent.Granted,
+		})
+	}
+
+	return res, nil
+}
+
+// CheckConsent reports whether a message of the category may be sent to the user over the channel.
+func (s *PreferencesServer) CheckConsent(ctx context.Context, req *proto.CheckConsentRequest) (*proto.CheckConsentResponse, error) {
+	if _, err := uuid.Parse(req.UserId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user id")
+	}
+
+	check, err := s.service.CheckConsent(ctx, req.UserId, req.Channel, req.Category)
+	if err != nil {
+		if err == service.ErrInvalidChannel || err == service.ErrInvalidCategory {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &proto.CheckConsentResponse{
+		Allowed:        check.Allowed,
+		UnsubscribeUrl: check.UnsubscribeURL,
+		Language:       check.Language,
+	}, nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/utils"
+	"github.com/NeGat1FF/e-commerce/user-service/pkg/logger"
+	"github.com/NeGat1FF/e-commerce/user-service/proto"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxActivityPageSize bounds the number of events returned at once.
+const maxActivityPageSize = 100
+
+// ActivityReadPermission lets support staff read the activity log of any user.
+const ActivityReadPermission = "users:read"
+
+var ErrInvalidPagination = errors.New("page must be at least 1 and limit between 1 and 100")
+
+type clientInfoKey struct{}
+
+// ContextWithClient returns a context carrying where the request came from, security events are recorded with it.
+func ContextWithClient(ctx context.Context, client models.ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, client)
+}
+
+func clientFromContext(ctx context.Context) models.ClientInfo {
+	client, _ := ctx.Value(clientInfoKey{}).(models.ClientInfo)
+	return client
+}
+
+// GetActivity returns a page of the security events of the user. Staff with the
+// users:read permission can read the events of another user by passing its ID.
+func (s *UserService) GetActivity(ctx context.Context, jwt, userID string, page, limit int) (*models.ActivityPage, error) {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if page < 1 || limit < 1 || limit > maxActivityPageSize {
+		return nil, ErrInvalidPagination
+	}
+
+	if userID != "" && userID != uid {
+		requester, err := s.repo.GetUserByID(ctx, uid)
+		if err != nil {
+			logger.Logger.Error("Failed to get user by ID", zap.String("id", uid), zap.Error(err))
+			return nil, err
+		}
+
+		if !HasPermission(requester.Role, ActivityReadPermission) {
+			return nil, ErrPermissionDenied
+		}
+
+		if uuid.Validate(userID) != nil {
+			return nil, repository.ErrUserNotFound
+		}
+
+		if _, err := s.repo.GetUserByID(ctx, userID); err != nil {
+			return nil, err
+		}
+
+		uid = userID
+	}
+
+	events, total, err := s.activity.GetEvents(ctx, uid, (page-1)*limit, limit)
+	if err != nil {
+		logger.Logger.Error("Failed to get security events", zap.String("id", uid), zap.Error(err))
+		return nil, err
+	}
+
+	return &models.ActivityPage{
+		Events: events,
+		Page:   page,
+		Limit:  limit,
+		Total:  total,
+	}, nil
+}
+
+// recordEvent adds a security event for the client of the request, a failure is only logged.
+func (s *UserService) recordEvent(ctx context.Context, userID uuid.UUID, eventType, method string) {
+	client := clientFromContext(ctx)
+
+	event := &models.SecurityEvent{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Type:      eventType,
+		Method:    method,
+		IP:        client.IP,
+		UserAgent: truncate(client.UserAgent, 512),
+		DeviceID:  deviceID(client),
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.activity.AddEvent(ctx, event); err != nil {
+		logger.Logger.Error("Failed to record security event", zap.String("id", userID.String()), zap.String("type", eventType), zap.Error(err))
+	}
+}
+
+// recordLogin adds a login event and alerts the user by email when the device was
+// not used to log in before. The first login of an account is not alerted.
+func (s *UserService) recordLogin(ctx context.Context, user *models.User, method string) {
+	devices, err := s.activity.GetLoginDevices(ctx, user.ID.String())
+	if err != nil {
+		logger.Logger.Error("Failed to get login devices", zap.String("id", user.ID.String()), zap.Error(err))
+	}
+
+	s.recordEvent(ctx, user.ID, models.EventLoginSucceeded, method)
+
+	client := clientFromContext(ctx)
+	if err != nil || len(devices) == 0 || slices.Contains(devices, deviceID(client)) || user.Email == "" {
+		return
+	}
+
+	res, err := s.mailService.SendMail(context.Background(), &proto.MailRequest{
+		To:   []string{user.Email},
+		Type: proto.NotificationType_NEW_DEVICE_LOGIN,
+		Data: map[string]string{
+			"Time":      time.Now().UTC().Format(time.RFC1123),
+			"IP":        client.IP,
+			"UserAgent": client.UserAgent,
+		},
+	})
+	if err != nil || !res.Success {
+		logger.Logger.Error("Failed to send new device alert", zap.String("id", user.ID.String()), zap.Error(err))
+	}
+}
+
+// deviceID tells devices apart by their user agent, the IP is left out as it changes between networks.
+func deviceID(client models.ClientInfo) string {
+	return utils.HashToken(client.UserAgent)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
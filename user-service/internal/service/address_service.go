@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/utils"
+	"github.com/NeGat1FF/e-commerce/user-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidAddress = errors.New("label, full_name, line1, city and country are required")
+)
+
+// AddressService manages the address book of users.
+type AddressService struct {
+	repo      repository.AddressRepositoryInterface
+	jwtSecret string
+}
+
+// NewAddressService creates a new address service.
+func NewAddressService(repo repository.AddressRepositoryInterface, jwtSecret string) *AddressService {
+	return &AddressService{
+		repo:      repo,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// CreateAddress adds an address to the user's address book.
+func (s *AddressService) CreateAddress(ctx context.Context, jwt string, address *models.Address) (*models.Address, error) {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateAddress(address); err != nil {
+		return nil, err
+	}
+
+	address.ID = uuid.New()
+	address.UserID = uuid.MustParse(uid)
+
+	existing, err := s.repo.GetAddresses(ctx, uid)
+	if err != nil {
+		logger.Logger.Error("Failed to get addresses", zap.String("user_id", uid), zap.Error(err))
+		return nil, err
+	}
+
+	// The first address is used for both shipping and billing
+	if len(existing) == 0 {
+		address.DefaultShipping = true
+		address.DefaultBilling = true
+	}
+
+	err = s.repo.CreateAddress(ctx, address)
+	if err != nil {
+		logger.Logger.Error("Failed to create address", zap.String("user_id", uid), zap.Error(err))
+		return nil, err
+	}
+
+	return address, nil
+}
+
+// GetAddresses returns all addresses of the user.
+func (s *AddressService) GetAddresses(ctx context.Context, jwt string) ([]models.Address, error) {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetAddresses(ctx, uid)
+}
+
+// GetAddress returns an address of the user by ID.
+func (s *AddressService) GetAddress(ctx context.Context, jwt, id string) (*models.Address, error) {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetUserAddress(ctx, id, uid)
+}
+
+// GetUserAddress returns an address by ID if it belongs to the user.
+func (s *AddressService) GetUserAddress(ctx context.Context, id, userID string) (*models.Address, error) {
+	if uuid.Validate(id) != nil || uuid.Validate(userID) != nil {
+		return nil, repository.ErrAddressNotFound
+	}
+
+	return s.repo.GetAddress(ctx, id, userID)
+}
+
+// UpdateAddress replaces an address of the user.
+func (s *AddressService) UpdateAddress(ctx context.Context, jwt, id string, address *models.Address) (*models.Address, error) {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	addressID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, repository.ErrAddressNotFound
+	}
+
+	if err := validateAddress(address); err != nil {
+		return nil, err
+	}
+
+	address.ID = addressID
+	address.UserID = uuid.MustParse(uid)
+
+	err = s.repo.UpdateAddress(ctx, address)
+	if err != nil {
+		logger.Logger.Error("Failed to update address", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	return s.repo.GetAddress(ctx, id, uid)
+}
+
+// DeleteAddress removes an address from the user's address book.
+func (s *AddressService) DeleteAddress(ctx context.Context, jwt, id string) error {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return err
+	}
+
+	if uuid.Validate(id) != nil {
+		return repository.ErrAddressNotFound
+	}
+
+	err = s.repo.DeleteAddress(ctx, id, uid)
+	if err != nil {
+		logger.Logger.Error("Failed to delete address", zap.String("id", id), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// validateAddress checks the required fields and normalizes the country and postal code.
+func validateAddress(address *models.Address) error {
+	address.Label = strings.TrimSpace(address.Label)
+	address.FullName = strings.TrimSpace(address.FullName)
+	address.Line1 = strings.TrimSpace(address.Line1)
+	address.City = strings.TrimSpace(address.City)
+
+	if address.Label == "" || address.FullName == "" || address.Line1 == "" || address.City == "" {
+		return ErrInvalidAddress
+	}
+
+	country, err := utils.NormalizeCountry(address.Country)
+	if err != nil {
+		return err
+	}
+	address.Country = country
+
+	postalCode, err := utils.NormalizePostalCode(country, address.PostalCode)
+	if err != nil {
+		return err
+	}
+	address.PostalCode = postalCode
+
+	return nil
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"github.com/NeGat1FF/e-commerce/user-service/internal/utils"
+	"github.com/NeGat1FF/e-commerce/user-service/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// userIDFromJWT validates an access token and returns the user ID from its claims.
+func userIDFromJWT(jwt, secret string) (string, error) {
+	claims, err := utils.ParseAccessToken(jwt, secret, utils.Audience)
+	if err != nil {
+		logger.Logger.Error("Failed to validate JWT", zap.Error(err))
+		return "", ErrInvalidJWT
+	}
+
+	return claims.Subject, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/utils"
+	"github.com/NeGat1FF/e-commerce/user-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrSessionRevoked   = errors.New("session has been revoked")
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// rolePermissions lists the permissions granted to each role, "*" grants all permissions.
+var rolePermissions = map[string][]string{
+	models.RoleUser:  {},
+	models.RoleStaff: {"orders:read", "orders:write", "products:write", "users:read"},
+	models.RoleAdmin: {"*"},
+}
+
+// HasPermission reports whether the role grants the permission.
+func HasPermission(role, permission string) bool {
+	permissions := rolePermissions[role]
+	return slices.Contains(permissions, "*") || slices.Contains(permissions, permission)
+}
+
+// TokenInfo describes a valid access token.
+type TokenInfo struct {
+	UserID    string
+	Role      string
+	ExpiresAt time.Time
+}
+
+// AuthService validates access tokens on behalf of other services.
+type AuthService struct {
+	repo      repository.UserRepositoryInterface
+	jwtSecret string
+}
+
+// NewAuthService creates a new auth service.
+func NewAuthService(repo repository.UserRepositoryInterface, jwtSecret string) *AuthService {
+	return &AuthService{
+		repo:      repo,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// ValidateToken checks the signature and claims of an access token issued for the audience,
+// and that its user is not disabled and its session has not been revoked.
+// An empty audience stands for the user service.
+func (s *AuthService) ValidateToken(ctx context.Context, token, audience string) (*TokenInfo, error) {
+	if audience == "" {
+		audience = utils.Audience
+	}
+
+	claims, err := utils.ParseAccessToken(token, s.jwtSecret, audience)
+	if err != nil {
+		return nil, ErrInvalidJWT
+	}
+
+	uid, sid := claims.Subject, claims.SessionID
+
+	user, err := s.repo.GetUserByID(ctx, uid)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			return nil, ErrInvalidJWT
+		}
+		logger.Logger.Error("Failed to get user by ID", zap.String("id", uid), zap.Error(err))
+		return nil, err
+	}
+
+	if user.Disabled {
+		return nil, ErrUserDisabled
+	}
+
+	if !slices.Contains(user.RefreshTokens, sid) {
+		return nil, ErrSessionRevoked
+	}
+
+	return &TokenInfo{
+		UserID:    uid,
+		Role:      user.Role,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+// CheckPermission validates the token and checks that the current role of its user grants the permission.
+func (s *AuthService) CheckPermission(ctx context.Context, token, permission, audience string) (*TokenInfo, error) {
+	info, err := s.ValidateToken(ctx, token, audience)
+	if err != nil {
+		return nil, err
+	}
+
+	if !HasPermission(info.Role, permission) {
+		return info, ErrPermissionDenied
+	}
+
+	return info, nil
+}
+
+// GetUser returns a user by ID.
+func (s *AuthService) GetUser(ctx context.Context, id string) (*models.User, error) {
+	if uuid.Validate(id) != nil {
+		return nil, repository.ErrUserNotFound
+	}
+
+	return s.repo.GetUserByID(ctx, id)
+}
+
+// IsTokenError reports whether the error means the token was rejected, as opposed to a failure to check it.
+func IsTokenError(err error) bool {
+	return err == ErrInvalidJWT || err == ErrSessionRevoked || err == ErrUserDisabled
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/utils"
+	"github.com/NeGat1FF/e-commerce/user-service/pkg/logger"
+	"github.com/NeGat1FF/e-commerce/user-service/proto"
+	"go.uber.org/zap"
+)
+
+const emailChangeTTL = time.Hour * 24
+
+var (
+	ErrInvalidPassword = errors.New("invalid password")
+	ErrSameEmail       = errors.New("new email is the same as the current one")
+)
+
+// RequestEmailChange starts a change of the user's email. The current password is required,
+// a confirmation link is sent to the new address and a notice to the current one.
+func (s *UserService) RequestEmailChange(ctx context.Context, jwt, password, newEmail string) error {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return err
+	}
+
+	newEmail = strings.TrimSpace(newEmail)
+	if _, err := mail.ParseAddress(newEmail); err != nil {
+		return ErrInvalidEmail
+	}
+
+	user, err := s.repo.GetUserByID(ctx, uid)
+	if err != nil {
+		logger.Logger.Error("Failed to get user by ID", zap.String("id", uid), zap.Error(err))
+		return err
+	}
+
+	// Users who signed up with a social login have no password to confirm with
+	if user.Password == "" || !s.passwords.Verify(user.Password, password) {
+		return ErrInvalidPassword
+	}
+
+	if strings.EqualFold(user.Email, newEmail) {
+		return ErrSameEmail
+	}
+
+	_, err = s.repo.GetUserByEmail(ctx, newEmail)
+	if err == nil {
+		return repository.ErrUserWithEmailExists
+	}
+	if err != repository.ErrUserNotFound {
+		logger.Logger.Error("Failed to get user by email", zap.Error(err))
+		return err
+	}
+
+	token, err := utils.GenerateToken(128)
+	if err != nil {
+		logger.Logger.Error("Failed to generate token", zap.Error(err))
+		return err
+	}
+
+	err = s.repo.CreateEmailChange(ctx, &models.EmailChange{
+		UserID:    user.ID,
+		NewEmail:  newEmail,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(emailChangeTTL),
+	})
+	if err != nil {
+		logger.Logger.Error("Failed to create email change", zap.String("id", uid), zap.Error(err))
+		return err
+	}
+
+	res, err := s.mailService.SendMail(context.Background(), &proto.MailRequest{
+		To:   []string{newEmail},
+		Type: proto.NotificationType_EMAIL_CHANGE_CONFIRMATION,
+		Data: map[string]string{
+			"Link":       fmt.Sprintf("http://%s:%s/api/v1/user/confirm_email_change?token=%s", s.addr, s.port, token),
+			"Expiration": fmt.Sprintf("%v hours", emailChangeTTL.Hours()),
+		},
+	})
+	if err != nil || !res.Success {
+		logger.Logger.Error("Failed to send email", zap.Error(err))
+		return ErrInvalidFaildToSendEmail
+	}
+
+	res, err = s.mailService.SendMail(context.Background(), &proto.MailRequest{
+		To:   []string{user.Email},
+		Type: proto.NotificationType_EMAIL_CHANGE_NOTICE,
+		Data: map[string]string{
+			"NewEmail": newEmail,
+		},
+	})
+	if err != nil || !res.Success {
+		// The change can still be confirmed, the notice is informational
+		logger.Logger.Error("Failed to send email change notice", zap.String("id", uid), zap.Error(err))
+	}
+
+	logger.Logger.Info("Email change requested", zap.String("id", uid))
+	return nil
+}
+
+// ConfirmEmailChange applies a pending email change.
+func (s *UserService) ConfirmEmailChange(ctx context.Context, token string) error {
+	change, err := s.repo.ConfirmEmailChange(ctx, utils.HashToken(token))
+	if err != nil {
+		logger.Logger.Error("Failed to confirm email change", zap.Error(err))
+		return err
+	}
+
+	logger.Logger.Info("Email changed", zap.String("id", change.UserID.String()))
+	return nil
+}
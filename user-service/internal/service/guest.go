@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/utils"
+	"github.com/NeGat1FF/e-commerce/user-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	UserUpgradedRoutingKey = "user.upgraded"
+	UserMergedRoutingKey   = "user.merged"
+)
+
+// Guests have no way to log in again, so their session lives longer than a regular one
+const guestRefreshTokenTTL = time.Hour * 24 * 30
+
+var (
+	ErrNotGuest = errors.New("account is not a guest account")
+)
+
+// refreshTokenTTL returns how long a refresh token of the role is valid.
+func refreshTokenTTL(role string) time.Duration {
+	if role == models.RoleGuest {
+		return guestRefreshTokenTTL
+	}
+	return time.Hour * 24 * 7
+}
+
+// CreateGuest creates a guest identity and returns its access and refresh tokens.
+// Guest tokens carry the guest role, which has no permissions beyond the guest's own cart and orders.
+func (s *UserService) CreateGuest(ctx context.Context) (string, string, error) {
+	user := &models.User{
+		ID:   uuid.New(),
+		Name: "Guest",
+		Role: models.RoleGuest,
+	}
+
+	access, refresh, err := s.GenerateAccesssAndRefreshTokens(user.ID.String(), models.RoleGuest, time.Hour*1, guestRefreshTokenTTL)
+	if err != nil {
+		logger.Logger.Error("Failed to generate access and refresh tokens", zap.Error(err))
+		return "", "", ErrInternalServer
+	}
+
+	user.RefreshTokens = append(user.RefreshTokens, utils.HashToken(refresh))
+
+	err = s.repo.CreateUser(ctx, user)
+	if err != nil {
+		logger.Logger.Error("Failed to create guest", zap.Error(err))
+		return "", "", err
+	}
+
+	logger.Logger.Info("Guest created", zap.String("id", user.ID.String()))
+	return access, refresh, nil
+}
+
+// UpgradeGuest turns the guest into a full account with an email and password, keeping
+// the same user ID so the cart and orders stay with it. Guest sessions are revoked and
+// new tokens are returned.
+func (s *UserService) UpgradeGuest(ctx context.Context, jwt string, upgrade *models.GuestUpgrade) (string, string, error) {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return "", "", err
+	}
+
+	user, err := s.repo.GetUserByID(ctx, uid)
+	if err != nil {
+		logger.Logger.Error("Failed to get user by ID", zap.String("id", uid), zap.Error(err))
+		return "", "", err
+	}
+
+	if user.Role != models.RoleGuest {
+		return "", "", ErrNotGuest
+	}
+
+	email := strings.ToLower(strings.TrimSpace(upgrade.Email))
+	if _, err := mail.ParseAddress(email); err != nil {
+		return "", "", ErrInvalidEmail
+	}
+
+	name := strings.TrimSpace(upgrade.Name)
+	surname := strings.TrimSpace(upgrade.Surname)
+	if name == "" || len(name) > 255 || len(surname) > 255 {
+		return "", "", ErrInvalidName
+	}
+
+	if err := s.policy.Validate(upgrade.Password, email, name, surname); err != nil {
+		return "", "", err
+	}
+
+	hashedPass, err := s.passwords.Hash(upgrade.Password)
+	if err != nil {
+		logger.Logger.Error("Failed to hash password", zap.Error(err))
+		return "", "", err
+	}
+
+	user.Email = email
+	user.Password = hashedPass
+	user.Name = name
+	user.Surname = surname
+
+	err = s.repo.UpgradeGuest(ctx, user)
+	if err != nil {
+		if err == repository.ErrGuestNotFound {
+			return "", "", ErrNotGuest
+		}
+		if err != repository.ErrUserWithEmailExists {
+			logger.Logger.Error("Failed to upgrade guest", zap.String("id", uid), zap.Error(err))
+		}
+		return "", "", err
+	}
+
+	user.Role = models.RoleUser
+	user.RefreshTokens = nil
+
+	logger.Logger.Info("Guest upgraded", zap.String("id", uid))
+
+	event := models.UserUpgradedEvent{UserID: user.ID, UpgradedAt: time.Now().UTC()}
+	if err := s.mq.PublishMessage(ctx, s.exchange, UserUpgradedRoutingKey, event); err != nil {
+		logger.Logger.Error("Failed to publish user upgraded event", zap.String("id", uid), zap.Error(err))
+	}
+
+	// The account is usable right away, the address is confirmed the same way as on registration
+	if err := s.sendVerificationEmail(ctx, user.ID, user.Email); err != nil {
+		logger.Logger.Error("Failed to send verification email", zap.String("id", uid), zap.Error(err))
+	}
+
+	return s.issueTokens(ctx, user)
+}
+
+// mergeGuest hands the data of the guest over to the account the guest just logged into
+// and removes the guest. It is best effort, the login succeeds even if the merge fails.
+func (s *UserService) mergeGuest(ctx context.Context, guestToken string, user *models.User) {
+	if guestToken == "" {
+		return
+	}
+
+	guestID, err := userIDFromJWT(guestToken, s.jwtSecret)
+	if err != nil || guestID == user.ID.String() {
+		return
+	}
+
+	guest, err := s.repo.GetUserByID(ctx, guestID)
+	if err != nil {
+		logger.Logger.Error("Failed to get guest by ID", zap.String("id", guestID), zap.Error(err))
+		return
+	}
+
+	// Only guests are merged, logging into another account never merges two accounts
+	if guest.Role != models.RoleGuest {
+		return
+	}
+
+	// The guest is kept if the event is not published, so its cart is not lost
+	event := models.UserMergedEvent{GuestID: guest.ID, UserID: user.ID, MergedAt: time.Now().UTC()}
+	if err := s.mq.PublishMessage(ctx, s.exchange, UserMergedRoutingKey, event); err != nil {
+		logger.Logger.Error("Failed to publish user merged event", zap.String("guest_id", guestID), zap.Error(err))
+		return
+	}
+
+	if err := s.repo.DeleteUser(ctx, guestID); err != nil {
+		logger.Logger.Error("Failed to delete merged guest", zap.String("guest_id", guestID), zap.Error(err))
+		return
+	}
+
+	logger.Logger.Info("Guest merged", zap.String("guest_id", guestID), zap.String("user_id", user.ID.String()))
+}
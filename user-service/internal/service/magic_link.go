@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/utils"
+	"github.com/NeGat1FF/e-commerce/user-service/pkg/logger"
+	"github.com/NeGat1FF/e-commerce/user-service/proto"
+	"go.uber.org/zap"
+)
+
+const (
+	magicLinkTTL = time.Minute * 15
+
+	// At most magicLinkRateLimit links are sent to a user per magicLinkRateWindow
+	magicLinkRateLimit  = 3
+	magicLinkRateWindow = time.Minute * 15
+)
+
+// RequestMagicLink emails a single-use login link. To not reveal which emails have an account,
+// it succeeds without sending anything for unknown or disabled users and when the rate limit is hit.
+func (s *UserService) RequestMagicLink(ctx context.Context, email string) error {
+	user, err := s.repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if err == repository.ErrUserNotFound {
+			logger.Logger.Info("Magic link requested for unknown email")
+			return nil
+		}
+		logger.Logger.Error("Failed to get user by email", zap.Error(err))
+		return err
+	}
+
+	if user.Disabled {
+		logger.Logger.Info("Magic link requested for disabled user", zap.String("id", user.ID.String()))
+		return nil
+	}
+
+	count, err := s.repo.CountMagicLinksSince(ctx, user.ID.String(), time.Now().Add(-magicLinkRateWindow))
+	if err != nil {
+		logger.Logger.Error("Failed to count magic links", zap.String("id", user.ID.String()), zap.Error(err))
+		return err
+	}
+
+	if count >= magicLinkRateLimit {
+		logger.Logger.Warn("Magic link rate limit reached", zap.String("id", user.ID.String()))
+		return nil
+	}
+
+	token, err := utils.GenerateToken(128)
+	if err != nil {
+		logger.Logger.Error("Failed to generate token", zap.Error(err))
+		return err
+	}
+
+	now := time.Now()
+	err = s.repo.CreateMagicLink(ctx, &models.MagicLink{
+		TokenHash: utils.HashToken(token),
+		UserID:    user.ID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(magicLinkTTL),
+	})
+	if err != nil {
+		logger.Logger.Error("Failed to create magic link", zap.String("id", user.ID.String()), zap.Error(err))
+		return err
+	}
+
+	res, err := s.mailService.SendMail(context.Background(), &proto.MailRequest{
+		To:   []string{user.Email},
+		Type: proto.NotificationType_MAGIC_LINK,
+		Data: map[string]string{
+			"Link":       fmt.Sprintf("http://%s:%s/api/v1/user/login/magic-link/verify?token=%s", s.addr, s.port, token),
+			"Expiration": fmt.Sprintf("%v minutes", magicLinkTTL.Minutes()),
+		},
+	})
+	if err != nil || !res.Success {
+		logger.Logger.Error("Failed to send email", zap.Error(err))
+		return ErrInvalidFaildToSendEmail
+	}
+
+	return nil
+}
+
+// LoginWithMagicLink redeems a login link and returns access and refresh tokens.
+// If guestToken belongs to a guest, its data is merged into the account.
+func (s *UserService) LoginWithMagicLink(ctx context.Context, token, guestToken string) (string, string, error) {
+	if token == "" {
+		return "", "", repository.ErrMagicLinkInvalid
+	}
+
+	user, err := s.repo.ConsumeMagicLink(ctx, utils.HashToken(token))
+	if err != nil {
+		if err != repository.ErrMagicLinkInvalid {
+			logger.Logger.Error("Failed to consume magic link", zap.Error(err))
+		}
+		return "", "", err
+	}
+
+	return s.completeLogin(ctx, user, models.LoginMethodMagicLink, guestToken)
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/oauth"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/utils"
+	"github.com/NeGat1FF/e-commerce/user-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+// OAuthStateTTL is how long a social login can take from start to callback.
+const OAuthStateTTL = time.Minute * 10
+
+// OAuthLoginURL starts a social login and returns the provider's authorization URL and the
+// state, which the browser has to present again in the callback.
+func (s *UserService) OAuthLoginURL(ctx context.Context, providerName string) (string, string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+
+	state, err := utils.GenerateToken(64)
+	if err != nil {
+		logger.Logger.Error("Failed to generate state", zap.Error(err))
+		return "", "", ErrInternalServer
+	}
+
+	nonce, err := utils.GenerateToken(64)
+	if err != nil {
+		logger.Logger.Error("Failed to generate nonce", zap.Error(err))
+		return "", "", ErrInternalServer
+	}
+
+	oauthState := &models.OAuthState{
+		State:        state,
+		Provider:     provider.Name(),
+		Nonce:        nonce,
+		CodeVerifier: oauth2.GenerateVerifier(),
+		ExpiresAt:    time.Now().Add(OAuthStateTTL),
+	}
+
+	err = s.repo.CreateOAuthState(ctx, oauthState)
+	if err != nil {
+		logger.Logger.Error("Failed to create oauth state", zap.Error(err))
+		return "", "", ErrInternalServer
+	}
+
+	return provider.AuthCodeURL(oauthState.State, oauthState.Nonce, oauthState.CodeVerifier), oauthState.State, nil
+}
+
+// OAuthCallback finishes a social login and returns access and refresh tokens. The state of
+// the callback must be the one the browser got when it started the login.
+func (s *UserService) OAuthCallback(ctx context.Context, providerName, code, state, browserState string) (string, string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+
+	// Otherwise an attacker could send the victim the callback of a login the attacker started,
+	// signing the victim in to the attacker's account
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		logger.Logger.Error("OAuth state was not issued to this browser", zap.String("provider", providerName))
+		return "", "", repository.ErrOAuthStateInvalid
+	}
+
+	oauthState, err := s.repo.ConsumeOAuthState(ctx, state)
+	if err != nil {
+		logger.Logger.Error("Failed to consume oauth state", zap.Error(err))
+		return "", "", repository.ErrOAuthStateInvalid
+	}
+
+	if oauthState.Provider != provider.Name() {
+		logger.Logger.Error("OAuth state was issued for another provider", zap.String("provider", oauthState.Provider))
+		return "", "", repository.ErrOAuthStateInvalid
+	}
+
+	identity, err := provider.Exchange(ctx, code, oauthState.CodeVerifier, oauthState.Nonce)
+	if err != nil {
+		logger.Logger.Error("Failed to exchange authorization code", zap.String("provider", providerName), zap.Error(err))
+		return "", "", err
+	}
+
+	user, err := s.repo.GetUserByIdentity(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		return s.completeLogin(ctx, user, models.LoginMethodOAuth, "")
+	}
+	if err != repository.ErrUserNotFound {
+		logger.Logger.Error("Failed to get user by identity", zap.Error(err))
+		return "", "", err
+	}
+
+	if !identity.EmailVerified || identity.Email == "" {
+		return "", "", oauth.ErrNoVerifiedEmail
+	}
+
+	userIdentity := &models.UserIdentity{
+		ID:       uuid.New(),
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	}
+
+	user, err = s.repo.GetUserByEmail(ctx, identity.Email)
+	switch err {
+	case nil:
+		// Only link to accounts that proved they own the email, otherwise whoever
+		// registered the address first would keep access to the account.
+		if !user.EmailVerified {
+			return "", "", ErrUnverifiedAccountLink
+		}
+
+		userIdentity.UserID = user.ID
+
+		err = s.repo.CreateIdentity(ctx, userIdentity)
+		if err != nil {
+			logger.Logger.Error("Failed to link identity", zap.Error(err))
+			return "", "", err
+		}
+
+		return s.completeLogin(ctx, user, models.LoginMethodOAuth, "")
+	case repository.ErrUserNotFound:
+		return s.registerOAuthUser(ctx, identity, userIdentity)
+	default:
+		logger.Logger.Error("Failed to get user by email", zap.Error(err))
+		return "", "", err
+	}
+}
+
+// registerOAuthUser creates a new passwordless user from an external identity and signs it in like any other login.
+func (s *UserService) registerOAuthUser(ctx context.Context, identity *oauth.Identity, userIdentity *models.UserIdentity) (string, string, error) {
+	logger.Logger.Info("Creating new user from external identity", zap.String("provider", identity.Provider))
+
+	user := &models.User{
+		ID:            uuid.New(),
+		Name:          identity.GivenName,
+		Surname:       identity.FamilyName,
+		Email:         identity.Email,
+		EmailVerified: true,
+		Role:          models.RoleUser,
+	}
+
+	if user.Name == "" {
+		user.Name, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	userIdentity.UserID = user.ID
+
+	err := s.repo.CreateUserWithIdentity(ctx, user, userIdentity)
+	if err != nil {
+		logger.Logger.Error("Failed to create user with identity", zap.Error(err))
+		return "", "", err
+	}
+
+	return s.completeLogin(ctx, user, models.LoginMethodOAuth, "")
+}
+
+// issueTokens issues the same access and refresh tokens as a password login.
+func (s *UserService) issueTokens(ctx context.Context, user *models.User) (string, string, error) {
+	if user.Disabled {
+		return "", "", ErrUserDisabled
+	}
+
+	access, refresh, err := s.GenerateAccesssAndRefreshTokens(user.ID.String(), user.Role, time.Hour*1, refreshTokenTTL(user.Role))
+	if err != nil {
+		logger.Logger.Error("Failed to generate access and refresh tokens", zap.Error(err))
+		return "", "", err
+	}
+
+	err = s.repo.AddRefreshToken(ctx, utils.HashToken(refresh), user.ID.String())
+	if err != nil {
+		logger.Logger.Error("Failed to add refresh token", zap.Error(err))
+		return "", "", err
+	}
+
+	return access, refresh, nil
+}
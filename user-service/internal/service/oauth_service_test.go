@@ -0,0 +1,85 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/oauth"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/utils"
+	"github.com/NeGat1FF/e-commerce/user-service/mocks"
+	"github.com/NeGat1FF/e-commerce/user-service/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// identityProvider signs everyone in as the same verified identity
+type identityProvider struct {
+	identity *oauth.Identity
+}
+
+func (p *identityProvider) Name() string {
+	return p.identity.Provider
+}
+
+func (p *identityProvider) AuthCodeURL(state, nonce, verifier string) string {
+	return "https://provider.example/authorize"
+}
+
+func (p *identityProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*oauth.Identity, error) {
+	return p.identity, nil
+}
+
+func TestOAuthCallbackRegistersUser(t *testing.T) {
+	logger.Init("info")
+
+	identity := &oauth.Identity{
+		Provider:      "test",
+		Subject:       "subject",
+		Email:         "jane@example.com",
+		EmailVerified: true,
+		GivenName:     "Jane",
+		FamilyName:    "Doe",
+	}
+
+	repo := mocks.NewUserRepositoryInterface(t)
+	activity := mocks.NewActivityRepositoryInterface(t)
+	s := service.NewUserService(repo, activity, nil, nil, map[string]oauth.Provider{"test": &identityProvider{identity: identity}}, nil, nil, nil, "", testSecret, "", "")
+
+	var created *models.User
+	repo.On("ConsumeOAuthState", mock.Anything, "state").Return(&models.OAuthState{State: "state", Provider: "test"}, nil)
+	repo.On("GetUserByIdentity", mock.Anything, "test", "subject").Return(nil, repository.ErrUserNotFound)
+	repo.On("GetUserByEmail", mock.Anything, "jane@example.com").Return(nil, repository.ErrUserNotFound)
+	repo.On("CreateUserWithIdentity", mock.Anything, mock.Anything, mock.MatchedBy(func(i *models.UserIdentity) bool {
+		return i.Provider == "test" && i.Subject == "subject"
+	})).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*models.User)
+	}).Return(nil)
+
+	var session, sessionUser string
+	repo.On("AddRefreshToken", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		session, sessionUser = args.String(1), args.String(2)
+	}).Return(nil)
+
+	// The login is recorded like any other, the first login of an account is not alerted
+	activity.On("GetLoginDevices", mock.Anything, mock.Anything).Return([]string{}, nil)
+	activity.On("AddEvent", mock.Anything, mock.MatchedBy(func(e *models.SecurityEvent) bool {
+		return e.Type == models.EventLoginSucceeded && e.Method == models.LoginMethodOAuth
+	})).Return(nil)
+
+	access, refresh, err := s.OAuthCallback(context.Background(), "test", "code", "state", "state")
+	require.NoError(t, err)
+	assert.NotEmpty(t, access)
+
+	require.NotNil(t, created)
+	assert.Equal(t, "Jane", created.Name)
+	assert.Equal(t, models.RoleUser, created.Role)
+	assert.True(t, created.EmailVerified)
+	assert.Empty(t, created.RefreshTokens, "the session is added after the user is created")
+
+	assert.Equal(t, utils.HashToken(refresh), session)
+	assert.Equal(t, created.ID.String(), sessionUser)
+}
@@ -0,0 +1,388 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/utils"
+	"github.com/NeGat1FF/e-commerce/user-service/pkg/logger"
+	"github.com/NeGat1FF/e-commerce/user-service/proto"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	otpLength         = 6
+	otpTTL            = time.Minute * 10
+	otpMaxAttempts    = 5
+	otpResendInterval = time.Minute
+	twoFactorTokenTTL = time.Minute * 5
+)
+
+var (
+	ErrPhoneNotVerified     = errors.New("verify a phone number first")
+	ErrPhoneAlreadyVerified = errors.New("phone number is already verified")
+	ErrTwoFactorEnabled     = errors.New("disable two-factor authentication before changing the phone number")
+	ErrOTPRecentlySent      = errors.New("a code was sent recently, try again in a minute")
+	ErrFailedToSendSms      = errors.New("failed to send sms")
+)
+
+// TwoFactorRequiredError is returned by logins of users with two-factor authentication,
+// the login is finished with the code sent to their phone and the token.
+type TwoFactorRequiredError struct {
+	Token string
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return "two-factor authentication required"
+}
+
+// RequestPhoneVerification sends a code to the phone number, the number is set once the code is confirmed.
+// Numbers without a country code are read in the national format of the country.
+func (s *UserService) RequestPhoneVerification(ctx context.Context, jwt, phone, country string) error {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return err
+	}
+
+	phone, err = utils.NormalizePhone(phone, country)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetUserByID(ctx, uid)
+	if err != nil {
+		logger.Logger.Error("Failed to get user by ID", zap.String("id", uid), zap.Error(err))
+		return err
+	}
+
+	// Otherwise whoever holds a session could move the second factor to their own phone
+	if user.TwoFactor {
+		return ErrTwoFactorEnabled
+	}
+
+	if user.PhoneVerified && user.Phone == phone {
+		return ErrPhoneAlreadyVerified
+	}
+
+	owner, err := s.repo.GetUserByPhone(ctx, phone)
+	if err == nil && owner.ID != user.ID {
+		return repository.ErrUserWithPhoneExists
+	}
+	if err != nil && err != repository.ErrUserNotFound {
+		logger.Logger.Error("Failed to get user by phone", zap.Error(err))
+		return err
+	}
+
+	return s.sendOTP(ctx, user.ID, models.OTPPurposeVerifyPhone, phone, proto.SmsType_PHONE_VERIFICATION)
+}
+
+// VerifyPhone confirms the code sent to the phone number and sets the number as verified.
+func (s *UserService) VerifyPhone(ctx context.Context, jwt, code string) error {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return err
+	}
+
+	otp, err := s.checkOTP(ctx, uid, models.OTPPurposeVerifyPhone, code)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.ConfirmPhone(ctx, uid, otp.Phone)
+	if err != nil {
+		if err != repository.ErrUserWithPhoneExists {
+			logger.Logger.Error("Failed to confirm phone", zap.String("id", uid), zap.Error(err))
+		}
+		return err
+	}
+
+	logger.Logger.Info("Phone verified", zap.String("id", uid))
+	return nil
+}
+
+// RequestPhoneLogin sends a login code to a verified phone number. Unknown numbers
+// are not reported, so the endpoint can't be used to find out who has an account.
+func (s *UserService) RequestPhoneLogin(ctx context.Context, phone, country string) error {
+	phone, err := utils.NormalizePhone(phone, country)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetUserByPhone(ctx, phone)
+	if err != nil {
+		if err != repository.ErrUserNotFound {
+			logger.Logger.Error("Failed to get user by phone", zap.Error(err))
+			return err
+		}
+		return nil
+	}
+
+	if user.Disabled {
+		return nil
+	}
+
+	err = s.sendOTP(ctx, user.ID, models.OTPPurposeLogin, phone, proto.SmsType_LOGIN_CODE)
+	if err != nil && err != ErrOTPRecentlySent {
+		return err
+	}
+
+	return nil
+}
+
+// LoginWithPhone logs in with a code sent to a verified phone number.
+// If guestToken belongs to a guest, its data is merged into the account.
+func (s *UserService) LoginWithPhone(ctx context.Context, phone, country, code, guestToken string) (string, string, error) {
+	phone, err := utils.NormalizePhone(phone, country)
+	if err != nil {
+		return "", "", err
+	}
+
+	user, err := s.repo.GetUserByPhone(ctx, phone)
+	if err != nil {
+		if err != repository.ErrUserNotFound {
+			logger.Logger.Error("Failed to get user by phone", zap.Error(err))
+			return "", "", err
+		}
+		return "", "", repository.ErrOTPInvalid
+	}
+
+	otp, err := s.checkOTP(ctx, user.ID.String(), models.OTPPurposeLogin, code)
+	if err != nil {
+		if err == repository.ErrOTPInvalid {
+			s.recordEvent(ctx, user.ID, models.EventLoginFailed, models.LoginMethodPhone)
+		}
+		return "", "", err
+	}
+
+	// The number may have moved to another account since the code was sent
+	if otp.Phone != user.Phone {
+		return "", "", repository.ErrOTPInvalid
+	}
+
+	access, refresh, err := s.issueTokens(ctx, user)
+	if err != nil {
+		return "", "", err
+	}
+
+	s.recordLogin(ctx, user, models.LoginMethodPhone)
+	s.mergeGuest(ctx, guestToken, user)
+
+	return access, refresh, nil
+}
+
+// EnableTwoFactor requires a code sent to the verified phone on every password or magic link login.
+func (s *UserService) EnableTwoFactor(ctx context.Context, jwt string) error {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetUserByID(ctx, uid)
+	if err != nil {
+		logger.Logger.Error("Failed to get user by ID", zap.String("id", uid), zap.Error(err))
+		return err
+	}
+
+	if !user.PhoneVerified {
+		return ErrPhoneNotVerified
+	}
+
+	return s.repo.UpdateProfile(ctx, uid, map[string]interface{}{"two_factor_enabled": true})
+}
+
+// RequestTwoFactorCode sends a code to the verified phone, it is needed to disable two-factor authentication.
+func (s *UserService) RequestTwoFactorCode(ctx context.Context, jwt string) error {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetUserByID(ctx, uid)
+	if err != nil {
+		logger.Logger.Error("Failed to get user by ID", zap.String("id", uid), zap.Error(err))
+		return err
+	}
+
+	if !user.PhoneVerified {
+		return ErrPhoneNotVerified
+	}
+
+	return s.sendOTP(ctx, user.ID, models.OTPPurposeTwoFactor, user.Phone, proto.SmsType_TWO_FACTOR_CODE)
+}
+
+// DisableTwoFactor turns two-factor authentication off after confirming a code sent to the phone.
+func (s *UserService) DisableTwoFactor(ctx context.Context, jwt, code string) error {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.checkOTP(ctx, uid, models.OTPPurposeTwoFactor, code); err != nil {
+		return err
+	}
+
+	return s.repo.UpdateProfile(ctx, uid, map[string]interface{}{"two_factor_enabled": false})
+}
+
+// LoginWithTwoFactor finishes a login with the token of the first step and the code sent to the phone.
+// If guestToken belongs to a guest, its data is merged into the account.
+func (s *UserService) LoginWithTwoFactor(ctx context.Context, token, code, guestToken string) (string, string, error) {
+	claims, err := utils.ParseTwoFactorToken(token, s.jwtSecret)
+	if err != nil {
+		return "", "", ErrInvalidJWT
+	}
+
+	uid := claims.Subject
+
+	if _, err := s.checkOTP(ctx, uid, models.OTPPurposeTwoFactor, code); err != nil {
+		if err == repository.ErrOTPInvalid {
+			s.recordEvent(ctx, uuid.MustParse(uid), models.EventLoginFailed, models.LoginMethodTwoFactor)
+		}
+		return "", "", err
+	}
+
+	user, err := s.repo.GetUserByID(ctx, uid)
+	if err != nil {
+		logger.Logger.Error("Failed to get user by ID", zap.String("id", uid), zap.Error(err))
+		return "", "", err
+	}
+
+	access, refresh, err := s.issueTokens(ctx, user)
+	if err != nil {
+		return "", "", err
+	}
+
+	s.recordLogin(ctx, user, models.LoginMethodTwoFactor)
+	s.mergeGuest(ctx, guestToken, user)
+
+	return access, refresh, nil
+}
+
+// completeLogin issues tokens once the first factor is verified, or starts the
+// second step for users with two-factor authentication.
+func (s *UserService) completeLogin(ctx context.Context, user *models.User, method, guestToken string) (string, string, error) {
+	if user.TwoFactor && user.PhoneVerified {
+		if user.Disabled {
+			return "", "", ErrUserDisabled
+		}
+		return "", "", s.startTwoFactor(ctx, user)
+	}
+
+	access, refresh, err := s.issueTokens(ctx, user)
+	if err != nil {
+		return "", "", err
+	}
+
+	s.recordLogin(ctx, user, method)
+	s.mergeGuest(ctx, guestToken, user)
+
+	return access, refresh, nil
+}
+
+// startTwoFactor sends a code to the phone and returns the error carrying the token of the second step.
+func (s *UserService) startTwoFactor(ctx context.Context, user *models.User) error {
+	err := s.sendOTP(ctx, user.ID, models.OTPPurposeTwoFactor, user.Phone, proto.SmsType_TWO_FACTOR_CODE)
+	// A code sent by a previous attempt is still valid
+	if err != nil && err != ErrOTPRecentlySent {
+		return err
+	}
+
+	token, err := utils.GenerateJWT(&utils.TwoFactorClaims{
+		RegisteredClaims: utils.NewRegisteredClaims(user.ID.String(), twoFactorTokenTTL, utils.Audience),
+		Type:             utils.TokenTypeTwoFactor,
+	}, s.jwtSecret)
+	if err != nil {
+		logger.Logger.Error("Failed to generate two-factor token", zap.Error(err))
+		return ErrInternalServer
+	}
+
+	return &TwoFactorRequiredError{Token: token}
+}
+
+// sendOTP replaces the pending code of the purpose with a new one and sends it by SMS.
+func (s *UserService) sendOTP(ctx context.Context, userID uuid.UUID, purpose, phone string, smsType proto.SmsType) error {
+	uid := userID.String()
+
+	existing, err := s.repo.GetOTP(ctx, uid, purpose)
+	if err == nil && time.Since(existing.CreatedAt) < otpResendInterval {
+		return ErrOTPRecentlySent
+	}
+	if err != nil && err != repository.ErrOTPInvalid {
+		logger.Logger.Error("Failed to get one-time code", zap.String("id", uid), zap.Error(err))
+		return err
+	}
+
+	code, err := utils.GenerateNumericCode(otpLength)
+	if err != nil {
+		logger.Logger.Error("Failed to generate one-time code", zap.Error(err))
+		return ErrInternalServer
+	}
+
+	now := time.Now()
+	otp := &models.OTPCode{
+		UserID:    userID,
+		Purpose:   purpose,
+		Phone:     phone,
+		CodeHash:  s.hashOTP(uid, purpose, code),
+		CreatedAt: now,
+		ExpiresAt: now.Add(otpTTL),
+	}
+
+	err = s.repo.SaveOTP(ctx, otp)
+	if err != nil {
+		logger.Logger.Error("Failed to save one-time code", zap.String("id", uid), zap.Error(err))
+		return err
+	}
+
+	res, err := s.smsService.SendSms(ctx, &proto.SmsRequest{
+		To:   phone,
+		Type: smsType,
+		Data: map[string]string{
+			"Code":       code,
+			"Expiration": "10 minutes",
+		},
+	})
+
+	if err != nil || !res.Success {
+		logger.Logger.Error("Failed to send sms", zap.String("id", uid), zap.Error(err))
+		return ErrFailedToSendSms
+	}
+
+	return nil
+}
+
+// checkOTP counts an attempt on the pending code of the purpose and consumes it if the code matches.
+func (s *UserService) checkOTP(ctx context.Context, uid, purpose, code string) (*models.OTPCode, error) {
+	if _, err := uuid.Parse(uid); err != nil {
+		return nil, repository.ErrOTPInvalid
+	}
+
+	otp, err := s.repo.UseOTPAttempt(ctx, uid, purpose, otpMaxAttempts)
+	if err != nil {
+		if err != repository.ErrOTPInvalid {
+			logger.Logger.Error("Failed to use one-time code attempt", zap.String("id", uid), zap.Error(err))
+		}
+		return nil, err
+	}
+
+	if !hmac.Equal([]byte(s.hashOTP(uid, purpose, code)), []byte(otp.CodeHash)) {
+		return nil, repository.ErrOTPInvalid
+	}
+
+	if err := s.repo.DeleteOTP(ctx, uid, purpose); err != nil {
+		logger.Logger.Error("Failed to delete one-time code", zap.String("id", uid), zap.Error(err))
+		return nil, err
+	}
+
+	return otp, nil
+}
+
+// hashOTP binds the code to its user and purpose, so a code can't be replayed for another purpose.
+func (s *UserService) hashOTP(uid, purpose, code string) string {
+	return utils.HashCode(s.jwtSecret, uid+":"+purpose+":"+code)
+}
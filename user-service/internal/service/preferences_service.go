@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/utils"
+	"github.com/NeGat1FF/e-commerce/user-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidLanguage = errors.New("language must be a language tag such as en or en-US")
+	ErrInvalidCurrency = errors.New("currency must be a three letter ISO 4217 code")
+	ErrInvalidChannel  = errors.New("unknown channel")
+	ErrInvalidCategory = errors.New("unknown consent category")
+)
+
+var (
+	languageRegex = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+	currencyRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// PreferencesService manages settings and communication consent of users.
+type PreferencesService struct {
+	repo      repository.PreferencesRepositoryInterface
+	jwtSecret string
+	addr      string
+	port      string
+}
+
+// NewPreferencesService creates a new preferences service.
+func NewPreferencesService(repo repository.PreferencesRepositoryInterface, jwtSecret, addr, port string) *PreferencesService {
+	return &PreferencesService{
+		repo:      repo,
+		jwtSecret: jwtSecret,
+		addr:      addr,
+		port:      port,
+	}
+}
+
+// GetPreferences returns the settings and consents of the user.
+func (s *PreferencesService) GetPreferences(ctx context.Context, jwt string) (*models.PreferencesResponse, error) {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetUserPreferences(ctx, uid)
+}
+
+// GetUserPreferences returns the settings of a user together with the consent
+// in effect for every channel and category, defaults included.
+func (s *PreferencesService) GetUserPreferences(ctx context.Context, uid string) (*models.PreferencesResponse, error) {
+	prefs, err := s.repo.GetPreferences(ctx, uid)
+	if err != nil {
+		logger.Logger.Error("Failed to get preferences", zap.String("user_id", uid), zap.Error(err))
+		return nil, err
+	}
+
+	records, err := s.repo.GetConsents(ctx, uid)
+	if err != nil {
+		logger.Logger.Error("Failed to get consents", zap.String("user_id", uid), zap.Error(err))
+		return nil, err
+	}
+
+	res := &models.PreferencesResponse{
+		Language: prefs.Language,
+		Currency: prefs.Currency,
+		Consents: make([]models.Consent, 0, len(models.Channels)*len(models.Categories)),
+	}
+
+	for _, channel := range models.Channels {
+		for _, category := range models.Categories {
+			consent := models.Consent{
+				Channel:  channel,
+				Category: category,
+				Granted:  models.DefaultConsent(category),
+			}
+
+			for _, record := range records {
+				if record.Channel == channel && record.Category == category {
+					consent.Granted = record.Granted
+					consent.Source = record.Source
+					consent.UpdatedAt = &record.CreatedAt
+					break
+				}
+			}
+
+			res.Consents = append(res.Consents, consent)
+		}
+	}
+
+	return res, nil
+}
+
+// UpdatePreferences changes the language and currency of the user.
+func (s *PreferencesService) UpdatePreferences(ctx context.Context, jwt string, update *models.PreferencesUpdate) (*models.PreferencesResponse, error) {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Language == nil && update.Currency == nil {
+		return nil, ErrNothingToUpdate
+	}
+
+	prefs, err := s.repo.GetPreferences(ctx, uid)
+	if err != nil {
+		logger.Logger.Error("Failed to get preferences", zap.String("user_id", uid), zap.Error(err))
+		return nil, err
+	}
+
+	if update.Language != nil {
+		if !languageRegex.MatchString(*update.Language) {
+			return nil, ErrInvalidLanguage
+		}
+		prefs.Language = *update.Language
+	}
+
+	if update.Currency != nil {
+		currency := strings.ToUpper(*update.Currency)
+		if !currencyRegex.MatchString(currency) {
+			return nil, ErrInvalidCurrency
+		}
+		prefs.Currency = currency
+	}
+
+	prefs.UpdatedAt = time.Now()
+
+	err = s.repo.SavePreferences(ctx, prefs)
+	if err != nil {
+		logger.Logger.Error("Failed to save preferences", zap.String("user_id", uid), zap.Error(err))
+		return nil, err
+	}
+
+	return s.GetUserPreferences(ctx, uid)
+}
+
+// SetConsent records a consent decision the user made in their settings.
+func (s *PreferencesService) SetConsent(ctx context.Context, jwt string, update *models.ConsentUpdate) (*models.Consent, error) {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.recordConsent(ctx, uid, update.Channel, update.Category, *update.Granted, models.ConsentSourceSettings)
+}
+
+// GetConsentHistory returns every consent decision of the user, newest first.
+func (s *PreferencesService) GetConsentHistory(ctx context.Context, jwt string) ([]models.ConsentRecord, error) {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetConsentHistory(ctx, uid)
+}
+
+// DescribeUnsubscribe returns the consent an unsubscribe token would withdraw without changing it.
+func (s *PreferencesService) DescribeUnsubscribe(ctx context.Context, token string) (*models.Consent, error) {
+	claims, err := utils.ParseUnsubscribeToken(s.jwtSecret, token)
+	if err != nil {
+		return nil, err
+	}
+
+	granted, err := s.consentGranted(ctx, claims.UserID, claims.Channel, claims.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Consent{
+		Channel:  claims.Channel,
+		Category: claims.Category,
+		Granted:  granted,
+	}, nil
+}
+
+// Unsubscribe withdraws the consent named by the token, it does not require the user to log in.
+func (s *PreferencesService) Unsubscribe(ctx context.Context, token string) (*models.Consent, error) {
+	claims, err := utils.ParseUnsubscribeToken(s.jwtSecret, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.recordConsent(ctx, claims.UserID, claims.Channel, claims.Category, false, models.ConsentSourceUnsubscribe)
+}
+
+// CheckConsent reports whether a message of the category may be sent to the user
+// over the channel, together with the link the user can unsubscribe with.
+func (s *PreferencesService) CheckConsent(ctx context.Context, uid, channel, category string) (*models.ConsentCheck, error) {
+	if err := validateConsent(channel, category); err != nil {
+		return nil, err
+	}
+
+	granted, err := s.consentGranted(ctx, uid, channel, category)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs, err := s.repo.GetPreferences(ctx, uid)
+	if err != nil {
+		logger.Logger.Error("Failed to get preferences", zap.String("user_id", uid), zap.Error(err))
+		return nil, err
+	}
+
+	return &models.ConsentCheck{
+		Allowed:        granted,
+		UnsubscribeURL: s.UnsubscribeURL(uid, channel, category),
+		Language:       prefs.Language,
+	}, nil
+}
+
+// UnsubscribeURL returns the link that withdraws the consent without logging in.
+func (s *PreferencesService) UnsubscribeURL(uid, channel, category string) string {
+	token := utils.GenerateUnsubscribeToken(s.jwtSecret, utils.UnsubscribeClaims{
+		UserID:   uid,
+		Channel:  channel,
+		Category: category,
+	})
+
+	return fmt.Sprintf("http://%s:%s/api/v1/user/unsubscribe?token=%s", s.addr, s.port, token)
+}
+
+func (s *PreferencesService) consentGranted(ctx context.Context, uid, channel, category string) (bool, error) {
+	record, err := s.repo.GetConsent(ctx, uid, channel, category)
+	if err != nil {
+		if err == repository.ErrConsentNotFound {
+			return models.DefaultConsent(category), nil
+		}
+		logger.Logger.Error("Failed to get consent", zap.String("user_id", uid), zap.Error(err))
+		return false, err
+	}
+
+	return record.Granted, nil
+}
+
+func (s *PreferencesService) recordConsent(ctx context.Context, uid, channel, category string, granted bool, source string) (*models.Consent, error) {
+	if err := validateConsent(channel, category); err != nil {
+		return nil, err
+	}
+
+	record := &models.ConsentRecord{
+		ID:        uuid.New(),
+		UserID:    uuid.MustParse(uid),
+		Channel:   channel,
+		Category:  category,
+		Granted:   granted,
+		Source:    source,
+		CreatedAt: time.Now(),
+	}
+
+	err := s.repo.AddConsent(ctx, record)
+	if err != nil {
+		logger.Logger.Error("Failed to add consent", zap.String("user_id", uid), zap.Error(err))
+		return nil, err
+	}
+
+	return &models.Consent{
+		Channel:   record.Channel,
+		Category:  record.Category,
+		Granted:   record.Granted,
+		Source:    record.Source,
+		UpdatedAt: &record.CreatedAt,
+	}, nil
+}
+
+func validateConsent(channel, category string) error {
+	if !slices.Contains(models.Channels, channel) {
+		return ErrInvalidChannel
+	}
+
+	if !slices.Contains(models.Categories, category) {
+		return ErrInvalidCategory
+	}
+
+	return nil
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	messagequeue "github.com/NeGat1FF/e-commerce/user-service/internal/messageQueue"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/user-service/pkg/logger"
+	"github.com/NeGat1FF/e-commerce/user-service/proto"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const UserDeletedRoutingKey = "user.deleted"
+
+// PrivacyService handles data export and erasure requests of users.
+type PrivacyService struct {
+	repo         repository.UserRepositoryInterface
+	addressRepo  repository.AddressRepositoryInterface
+	prefsRepo    repository.PreferencesRepositoryInterface
+	orderService proto.OrderServiceClient
+	messageQueue messagequeue.MessageQueue
+	exchangeName string
+	jwtSecret    string
+	gracePeriod  time.Duration
+}
+
+// NewPrivacyService creates a new privacy service.
+func NewPrivacyService(repo repository.UserRepositoryInterface, addressRepo repository.AddressRepositoryInterface, prefsRepo repository.PreferencesRepositoryInterface, orderService proto.OrderServiceClient, messageQueue messagequeue.MessageQueue, exchangeName, jwtSecret string, gracePeriod time.Duration) *PrivacyService {
+	return &PrivacyService{
+		repo:         repo,
+		addressRepo:  addressRepo,
+		prefsRepo:    prefsRepo,
+		orderService: orderService,
+		messageQueue: messageQueue,
+		exchangeName: exchangeName,
+		jwtSecret:    jwtSecret,
+		gracePeriod:  gracePeriod,
+	}
+}
+
+// ExportUserData collects all data held about the user, including their orders.
+func (s *PrivacyService) ExportUserData(ctx context.Context, jwt string) (*models.UserExport, error) {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetUserByID(ctx, uid)
+	if err != nil {
+		logger.Logger.Error("Failed to get user by ID", zap.String("id", uid), zap.Error(err))
+		return nil, err
+	}
+
+	identities, err := s.repo.GetIdentities(ctx, uid)
+	if err != nil {
+		logger.Logger.Error("Failed to get identities", zap.String("id", uid), zap.Error(err))
+		return nil, err
+	}
+
+	addresses, err := s.addressRepo.GetAddresses(ctx, uid)
+	if err != nil {
+		logger.Logger.Error("Failed to get addresses", zap.String("id", uid), zap.Error(err))
+		return nil, err
+	}
+
+	prefs, err := s.prefsRepo.GetPreferences(ctx, uid)
+	if err != nil {
+		logger.Logger.Error("Failed to get preferences", zap.String("id", uid), zap.Error(err))
+		return nil, err
+	}
+
+	consents, err := s.prefsRepo.GetConsentHistory(ctx, uid)
+	if err != nil {
+		logger.Logger.Error("Failed to get consent history", zap.String("id", uid), zap.Error(err))
+		return nil, err
+	}
+
+	orders, err := s.getOrders(ctx, uid)
+	if err != nil {
+		logger.Logger.Error("Failed to get orders", zap.String("id", uid), zap.Error(err))
+		return nil, err
+	}
+
+	return &models.UserExport{
+		Profile: models.ExportedProfile{
+			ID:            user.ID,
+			Name:          user.Name,
+			Surname:       user.Surname,
+			Email:         user.Email,
+			EmailVerified: user.EmailVerified,
+			Phone:         user.Phone,
+			PhoneVerified: user.PhoneVerified,
+			TwoFactor:     user.TwoFactor,
+			CreatedAt:     user.CreatedAt,
+			UpdatedAt:     user.UpdatedAt,
+		},
+		// Only hashes of refresh tokens are stored, one per active session
+		Sessions:    append([]string{}, user.RefreshTokens...),
+		Identities:  identities,
+		Addresses:   addresses,
+		Preferences: prefs,
+		Consents:    consents,
+		Orders:      orders,
+		ExportedAt:  time.Now().UTC(),
+	}, nil
+}
+
+func (s *PrivacyService) getOrders(ctx context.Context, uid string) ([]models.ExportedOrder, error) {
+	res, err := s.orderService.GetUserOrders(ctx, &proto.UserOrdersRequest{UserId: uid})
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]models.ExportedOrder, 0, len(res.GetOrders()))
+	for _, o := range res.GetOrders() {
+		order := models.ExportedOrder{
+			ID:        o.GetId(),
+			Status:    o.GetStatus(),
+			Total:     o.GetTotal(),
+			Items:     make([]models.ExportedOrderItem, 0, len(o.GetItems())),
+			CreatedAt: o.GetCreatedAt(),
+			UpdatedAt: o.GetUpdatedAt(),
+		}
+
+		if o.GetShippingAddress() != "" {
+			order.ShippingAddress = json.RawMessage(o.GetShippingAddress())
+		}
+
+		for _, item := range o.GetItems() {
+			order.Items = append(order.Items, models.ExportedOrderItem{
+				ProductID: item.GetProductId(),
+				Quantity:  item.GetQuantity(),
+			})
+		}
+
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+// RequestErasure schedules the erasure of the user's data after the grace period.
+func (s *PrivacyService) RequestErasure(ctx context.Context, jwt string) (*models.ErasureRequest, error) {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	request := &models.ErasureRequest{
+		UserID:       uuid.MustParse(uid),
+		Status:       models.ErasureStatusPending,
+		RequestedAt:  now,
+		ScheduledFor: now.Add(s.gracePeriod),
+	}
+
+	if err := s.repo.CreateErasureRequest(ctx, request); err != nil {
+		logger.Logger.Error("Failed to create erasure request", zap.String("id", uid), zap.Error(err))
+		return nil, err
+	}
+
+	logger.Logger.Info("Erasure requested", zap.String("id", uid), zap.Time("scheduled_for", request.ScheduledFor))
+	return request, nil
+}
+
+// CancelErasure cancels a pending erasure request.
+func (s *PrivacyService) CancelErasure(ctx context.Context, jwt string) error {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.CancelErasureRequest(ctx, uid); err != nil {
+		if err != repository.ErrErasureNotFound {
+			logger.Logger.Error("Failed to cancel erasure request", zap.String("id", uid), zap.Error(err))
+		}
+		return err
+	}
+
+	logger.Logger.Info("Erasure cancelled", zap.String("id", uid))
+	return nil
+}
+
+// ProcessDueErasures anonymises users whose grace period is over and
+// notifies other services so they can remove their data too.
+func (s *PrivacyService) ProcessDueErasures(ctx context.Context) error {
+	requests, err := s.repo.GetDueErasureRequests(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, request := range requests {
+		uid := request.UserID.String()
+
+		if err := s.repo.AnonymiseUser(ctx, uid); err != nil && err != repository.ErrUserNotFound {
+			logger.Logger.Error("Failed to anonymise user", zap.String("id", uid), zap.Error(err))
+			continue
+		}
+
+		// The request stays pending if publishing fails, so it is retried on the next run
+		event := models.UserDeletedEvent{UserID: request.UserID, ErasedAt: time.Now().UTC()}
+		if err := s.messageQueue.PublishMessage(ctx, s.exchangeName, UserDeletedRoutingKey, event); err != nil {
+			logger.Logger.Error("Failed to publish user deleted event", zap.String("id", uid), zap.Error(err))
+			continue
+		}
+
+		if err := s.repo.CompleteErasureRequest(ctx, uid); err != nil {
+			logger.Logger.Error("Failed to complete erasure request", zap.String("id", uid), zap.Error(err))
+			continue
+		}
+
+		logger.Logger.Info("User data erased", zap.String("id", uid))
+	}
+
+	return nil
+}
+
+// StartErasureWorker runs ProcessDueErasures periodically until the context is done.
+func (s *PrivacyService) StartErasureWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.ProcessDueErasures(ctx); err != nil {
+			logger.Logger.Error("Failed to process erasure requests", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
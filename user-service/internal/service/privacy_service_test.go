@@ -0,0 +1,277 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/utils"
+	"github.com/NeGat1FF/e-commerce/user-service/mocks"
+	"github.com/NeGat1FF/e-commerce/user-service/pkg/logger"
+	"github.com/NeGat1FF/e-commerce/user-service/proto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSecret      = "secret"
+	testExchange    = "users"
+	testGracePeriod = 30 * 24 * time.Hour
+)
+
+type privacyMocks struct {
+	users     *mocks.UserRepositoryInterface
+	addresses *mocks.AddressRepositoryInterface
+	prefs     *mocks.PreferencesRepositoryInterface
+	orders    *mocks.OrderServiceClient
+	queue     *mocks.MessageQueue
+}
+
+func newPrivacyService(t *testing.T) (*service.PrivacyService, privacyMocks) {
+	logger.Init("info")
+
+	m := privacyMocks{
+		users:     mocks.NewUserRepositoryInterface(t),
+		addresses: mocks.NewAddressRepositoryInterface(t),
+		prefs:     mocks.NewPreferencesRepositoryInterface(t),
+		orders:    mocks.NewOrderServiceClient(t),
+		queue:     mocks.NewMessageQueue(t),
+	}
+
+	s := service.NewPrivacyService(m.users, m.addresses, m.prefs, m.orders, m.queue, testExchange, testSecret, testGracePeriod)
+	return s, m
+}
+
+func accessToken(t *testing.T, userID uuid.UUID) string {
+	claims := &utils.AccessClaims{
+		RegisteredClaims: utils.NewRegisteredClaims(userID.String(), time.Minute, utils.AccessAudience...),
+		Type:             utils.TokenTypeAccess,
+		Role:             models.RoleUser,
+		SessionID:        "session",
+	}
+
+	token, err := utils.GenerateJWT(claims, testSecret)
+	require.NoError(t, err)
+
+	return token
+}
+
+func TestExportUserData(t *testing.T) {
+	userID := uuid.New()
+	user := &models.User{
+		ID:            userID,
+		Name:          "Jane",
+		Surname:       "Doe",
+		Email:         "jane@example.com",
+		Password:      "hash",
+		RefreshTokens: []string{"session"},
+	}
+	addresses := []models.Address{{ID: uuid.New(), UserID: userID, Line1: "1 Main St", City: "Springfield", Country: "US"}}
+	prefs := &models.UserPreferences{UserID: userID, Language: "en", Currency: "USD"}
+	consents := []models.ConsentRecord{{ID: uuid.New(), UserID: userID, Channel: "email", Category: "marketing", Granted: true}}
+	orders := &proto.UserOrdersResponse{Orders: []*proto.Order{{
+		Id:              "order-1",
+		Status:          "DELIVERED",
+		Total:           "19.99",
+		Items:           []*proto.OrderItem{{ProductId: 7, Quantity: 2}},
+		ShippingAddress: `{"city":"Springfield"}`,
+	}}}
+
+	testCases := []struct {
+		name          string
+		token         string
+		setupMocks    func(m privacyMocks)
+		expectedError error
+	}{
+		{
+			name:  "Export contains the addresses, preferences and orders",
+			token: accessToken(t, userID),
+			setupMocks: func(m privacyMocks) {
+				m.users.On("GetUserByID", mock.Anything, userID.String()).Return(user, nil)
+				m.users.On("GetIdentities", mock.Anything, userID.String()).Return([]models.UserIdentity{}, nil)
+				m.addresses.On("GetAddresses", mock.Anything, userID.String()).Return(addresses, nil)
+				m.prefs.On("GetPreferences", mock.Anything, userID.String()).Return(prefs, nil)
+				m.prefs.On("GetConsentHistory", mock.Anything, userID.String()).Return(consents, nil)
+				m.orders.On("GetUserOrders", mock.Anything, &proto.UserOrdersRequest{UserId: userID.String()}).Return(orders, nil)
+			},
+		},
+		{
+			name:          "Invalid token",
+			token:         "invalid",
+			setupMocks:    func(m privacyMocks) {},
+			expectedError: service.ErrInvalidJWT,
+		},
+		{
+			name:  "Order service unavailable",
+			token: accessToken(t, userID),
+			setupMocks: func(m privacyMocks) {
+				m.users.On("GetUserByID", mock.Anything, userID.String()).Return(user, nil)
+				m.users.On("GetIdentities", mock.Anything, userID.String()).Return([]models.UserIdentity{}, nil)
+				m.addresses.On("GetAddresses", mock.Anything, userID.String()).Return(addresses, nil)
+				m.prefs.On("GetPreferences", mock.Anything, userID.String()).Return(prefs, nil)
+				m.prefs.On("GetConsentHistory", mock.Anything, userID.String()).Return(consents, nil)
+				m.orders.On("GetUserOrders", mock.Anything, mock.Anything).Return(nil, errors.New("unavailable"))
+			},
+			expectedError: errors.New("unavailable"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, m := newPrivacyService(t)
+			tc.setupMocks(m)
+
+			export, err := s.ExportUserData(context.Background(), tc.token)
+			if tc.expectedError != nil {
+				assert.ErrorContains(t, err, tc.expectedError.Error())
+				assert.Nil(t, export)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, userID, export.Profile.ID)
+			assert.Equal(t, user.Email, export.Profile.Email)
+			assert.Equal(t, []string{"session"}, export.Sessions)
+			assert.Equal(t, addresses, export.Addresses)
+			assert.Equal(t, prefs, export.Preferences)
+			assert.Equal(t, consents, export.Consents)
+			require.Len(t, export.Orders, 1)
+			assert.Equal(t, "order-1", export.Orders[0].ID)
+			assert.Equal(t, []models.ExportedOrderItem{{ProductID: 7, Quantity: 2}}, export.Orders[0].Items)
+			assert.JSONEq(t, `{"city":"Springfield"}`, string(export.Orders[0].ShippingAddress))
+
+			body, err := json.Marshal(export)
+			require.NoError(t, err)
+			assert.NotContains(t, string(body), user.Password, "credentials are not exported")
+		})
+	}
+}
+
+func TestRequestErasure(t *testing.T) {
+	userID := uuid.New()
+	s, m := newPrivacyService(t)
+
+	m.users.On("CreateErasureRequest", mock.Anything, mock.MatchedBy(func(r *models.ErasureRequest) bool {
+		return r.UserID == userID && r.Status == models.ErasureStatusPending
+	})).Return(nil)
+
+	request, err := s.RequestErasure(context.Background(), accessToken(t, userID))
+	require.NoError(t, err)
+	assert.Equal(t, testGracePeriod, request.ScheduledFor.Sub(request.RequestedAt), "erasure waits for the grace period")
+	assert.WithinDuration(t, time.Now(), request.RequestedAt, time.Minute)
+}
+
+func TestCancelErasure(t *testing.T) {
+	userID := uuid.New()
+
+	testCases := []struct {
+		name          string
+		repoError     error
+		expectedError error
+	}{
+		{name: "Pending request is cancelled"},
+		{name: "No pending request", repoError: repository.ErrErasureNotFound, expectedError: repository.ErrErasureNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, m := newPrivacyService(t)
+			m.users.On("CancelErasureRequest", mock.Anything, userID.String()).Return(tc.repoError)
+
+			err := s.CancelErasure(context.Background(), accessToken(t, userID))
+			assert.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestProcessDueErasures(t *testing.T) {
+	first := models.ErasureRequest{UserID: uuid.New(), Status: models.ErasureStatusPending}
+	second := models.ErasureRequest{UserID: uuid.New(), Status: models.ErasureStatusPending}
+
+	deleted := func(userID uuid.UUID) interface{} {
+		return mock.MatchedBy(func(e models.UserDeletedEvent) bool { return e.UserID == userID })
+	}
+
+	testCases := []struct {
+		name       string
+		setupMocks func(m privacyMocks)
+		check      func(t *testing.T, m privacyMocks)
+	}{
+		{
+			name: "Nothing is erased before the grace period is over",
+			setupMocks: func(m privacyMocks) {
+				m.users.On("GetDueErasureRequests", mock.Anything).Return([]models.ErasureRequest{}, nil)
+			},
+			check: func(t *testing.T, m privacyMocks) {
+				m.users.AssertNotCalled(t, "AnonymiseUser", mock.Anything, mock.Anything)
+				m.queue.AssertNotCalled(t, "PublishMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "Due request is erased and completed",
+			setupMocks: func(m privacyMocks) {
+				m.users.On("GetDueErasureRequests", mock.Anything).Return([]models.ErasureRequest{first}, nil)
+				m.users.On("AnonymiseUser", mock.Anything, first.UserID.String()).Return(nil)
+				m.queue.On("PublishMessage", mock.Anything, testExchange, service.UserDeletedRoutingKey, deleted(first.UserID)).Return(nil)
+				m.users.On("CompleteErasureRequest", mock.Anything, first.UserID.String()).Return(nil)
+			},
+			check: func(t *testing.T, m privacyMocks) {},
+		},
+		{
+			name: "User that is already gone is completed",
+			setupMocks: func(m privacyMocks) {
+				m.users.On("GetDueErasureRequests", mock.Anything).Return([]models.ErasureRequest{first}, nil)
+				m.users.On("AnonymiseUser", mock.Anything, first.UserID.String()).Return(repository.ErrUserNotFound)
+				m.queue.On("PublishMessage", mock.Anything, testExchange, service.UserDeletedRoutingKey, deleted(first.UserID)).Return(nil)
+				m.users.On("CompleteErasureRequest", mock.Anything, first.UserID.String()).Return(nil)
+			},
+			check: func(t *testing.T, m privacyMocks) {},
+		},
+		{
+			name: "Failed publish leaves the request pending",
+			setupMocks: func(m privacyMocks) {
+				m.users.On("GetDueErasureRequests", mock.Anything).Return([]models.ErasureRequest{first, second}, nil)
+				m.users.On("AnonymiseUser", mock.Anything, first.UserID.String()).Return(nil)
+				m.queue.On("PublishMessage", mock.Anything, testExchange, service.UserDeletedRoutingKey, deleted(first.UserID)).Return(errors.New("broker down"))
+				m.users.On("AnonymiseUser", mock.Anything, second.UserID.String()).Return(nil)
+				m.queue.On("PublishMessage", mock.Anything, testExchange, service.UserDeletedRoutingKey, deleted(second.UserID)).Return(nil)
+				m.users.On("CompleteErasureRequest", mock.Anything, second.UserID.String()).Return(nil)
+			},
+			check: func(t *testing.T, m privacyMocks) {
+				m.users.AssertNotCalled(t, "CompleteErasureRequest", mock.Anything, first.UserID.String())
+			},
+		},
+		{
+			name: "Failed anonymisation leaves the request pending",
+			setupMocks: func(m privacyMocks) {
+				m.users.On("GetDueErasureRequests", mock.Anything).Return([]models.ErasureRequest{first, second}, nil)
+				m.users.On("AnonymiseUser", mock.Anything, first.UserID.String()).Return(errors.New("connection reset"))
+				m.users.On("AnonymiseUser", mock.Anything, second.UserID.String()).Return(nil)
+				m.queue.On("PublishMessage", mock.Anything, testExchange, service.UserDeletedRoutingKey, deleted(second.UserID)).Return(nil)
+				m.users.On("CompleteErasureRequest", mock.Anything, second.UserID.String()).Return(nil)
+			},
+			check: func(t *testing.T, m privacyMocks) {
+				m.queue.AssertNotCalled(t, "PublishMessage", mock.Anything, testExchange, service.UserDeletedRoutingKey, deleted(first.UserID))
+				m.users.AssertNotCalled(t, "CompleteErasureRequest", mock.Anything, first.UserID.String())
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, m := newPrivacyService(t)
+			tc.setupMocks(m)
+
+			err := s.ProcessDueErasures(context.Background())
+			require.NoError(t, err)
+			tc.check(t, m)
+		})
+	}
+}
@@ -5,9 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"net/mail"
+	"strings"
 	"time"
 
+	messagequeue "github.com/NeGat1FF/e-commerce/user-service/internal/messageQueue"
 	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/oauth"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/password"
 	"github.com/NeGat1FF/e-commerce/user-service/internal/repository"
 	"github.com/NeGat1FF/e-commerce/user-service/internal/utils"
 	"github.com/NeGat1FF/e-commerce/user-service/pkg/logger"
@@ -21,25 +25,43 @@ var (
 	ErrInvalidEmailOrPassword  = errors.New("invalid email or password")
 	ErrInternalServer          = errors.New("internal server error")
 	ErrInvalidEmail            = errors.New("invalid email")
-	ErrInvalidPasswordLength   = errors.New("password length must be between 8 and 64 characters")
 	ErrInvalidFaildToSendEmail = errors.New("failed to send email")
 	ErrEmailAlreadyVerified    = errors.New("email is already verified")
+	ErrUnknownProvider         = errors.New("unknown identity provider")
+	ErrUnverifiedAccountLink   = errors.New("verify the email of your account before signing in with this provider")
+	ErrInvalidName             = errors.New("name must be between 1 and 255 characters")
+	ErrNothingToUpdate         = errors.New("no fields to update")
+	ErrUserDisabled            = errors.New("user account is disabled")
 )
 
 // UserService describes the service.
 type UserService struct {
 	repo        repository.UserRepositoryInterface
+	activity    repository.ActivityRepositoryInterface
 	mailService proto.MailServiceClient
+	smsService  proto.SmsServiceClient
+	providers   map[string]oauth.Provider
+	passwords   *password.Hasher
+	policy      *password.Policy
+	mq          messagequeue.MessageQueue
+	exchange    string
 	jwtSecret   string
 	addr        string
 	port        string
 }
 
 // NewUserService creates a new user service.
-func NewUserService(repo repository.UserRepositoryInterface, mailService proto.MailServiceClient, jwtSecret string, addr string, port string) *UserService {
+func NewUserService(repo repository.UserRepositoryInterface, activity repository.ActivityRepositoryInterface, mailService proto.MailServiceClient, smsService proto.SmsServiceClient, providers map[string]oauth.Provider, passwords *password.Hasher, policy *password.Policy, mq messagequeue.MessageQueue, exchange string, jwtSecret string, addr string, port string) *UserService {
 	return &UserService{
 		repo:        repo,
+		activity:    activity,
 		mailService: mailService,
+		smsService:  smsService,
+		providers:   providers,
+		passwords:   passwords,
+		policy:      policy,
+		mq:          mq,
+		exchange:    exchange,
 		jwtSecret:   jwtSecret,
 		addr:        addr,
 		port:        port,
@@ -47,30 +69,32 @@ func NewUserService(repo repository.UserRepositoryInterface, mailService proto.M
 }
 
 // GenerateAccesssAndRefreshTokens generates access and refresh tokens.
+// The access token carries the hash of its refresh token as session ID, so it is revoked together with the session.
 func (s *UserService) GenerateAccesssAndRefreshTokens(userID, role string, accessExp, refreshExp time.Duration) (string, string, error) {
-	accessClaims := map[string]interface{}{
-		"uid":  userID,
-		"type": "access",
-		"role": role,
-		"exp":  time.Now().Add(accessExp).Unix(),
+	refreshClaims := &utils.RefreshClaims{
+		RegisteredClaims: utils.NewRegisteredClaims(userID, refreshExp, utils.Audience),
+		Type:             utils.TokenTypeRefresh,
+		Role:             role,
 	}
+	// The hash of the refresh token is its session, so tokens issued in the same second must differ
+	refreshClaims.ID = uuid.NewString()
 
-	refreshClaims := map[string]interface{}{
-		"uid":  userID,
-		"type": "refresh",
-		"role": role,
-		"exp":  time.Now().Add(refreshExp).Unix(),
-	}
-
-	accsessToken, err := utils.GenerateJWT(accessClaims, s.jwtSecret)
+	refreshToken, err := utils.GenerateJWT(refreshClaims, s.jwtSecret)
 	if err != nil {
-		logger.Logger.Error("Failed to generate access token", zap.Error(err))
+		logger.Logger.Error("Failed to generate refresh token", zap.Error(err))
 		return "", "", ErrInternalServer
 	}
 
-	refreshToken, err := utils.GenerateJWT(refreshClaims, s.jwtSecret)
+	accessClaims := &utils.AccessClaims{
+		RegisteredClaims: utils.NewRegisteredClaims(userID, accessExp, utils.AccessAudience...),
+		Type:             utils.TokenTypeAccess,
+		Role:             role,
+		SessionID:        utils.HashToken(refreshToken),
+	}
+
+	accsessToken, err := utils.GenerateJWT(accessClaims, s.jwtSecret)
 	if err != nil {
-		logger.Logger.Error("Failed to generate refresh token", zap.Error(err))
+		logger.Logger.Error("Failed to generate access token", zap.Error(err))
 		return "", "", ErrInternalServer
 	}
 
@@ -88,20 +112,32 @@ func (s *UserService) RegisterUser(ctx context.Context, user *models.User) (stri
 		return "", "", ErrInvalidEmail
 	}
 
-	// Check if password length is between 8 and 64 characters
-	if len(user.Password) < 8 || len(user.Password) > 64 {
-		return "", "", ErrInvalidPasswordLength
+	if err := s.policy.Validate(user.Password, user.Email, user.Name, user.Surname); err != nil {
+		return "", "", err
 	}
 
-	hashedPass, err := utils.HashPassword(user.Password)
+	hashedPass, err := s.passwords.Hash(user.Password)
 	if err != nil {
 		logger.Logger.Error("Failed to hash password", zap.Error(err))
 		return "", "", err
 	}
 
+	// The phone is only stored here, it is verified with a code later
+	if user.Phone != "" {
+		user.Phone, err = utils.NormalizePhone(user.Phone, "")
+		if err != nil {
+			return "", "", err
+		}
+	}
+
 	user.Password = hashedPass
+	user.Role = models.RoleUser
+	user.Disabled = false
+	user.EmailVerified = false
+	user.PhoneVerified = false
+	user.TwoFactor = false
 
-	access, refresh, err := s.GenerateAccesssAndRefreshTokens(user.ID.String(), "user", time.Hour*1, time.Hour*24*7)
+	access, refresh, err := s.GenerateAccesssAndRefreshTokens(user.ID.String(), models.RoleUser, time.Hour*1, time.Hour*24*7)
 	if err != nil {
 		logger.Logger.Error("Failed to generate access and refresh tokens", zap.Error(err))
 		return "", "", ErrInternalServer
@@ -116,62 +152,19 @@ func (s *UserService) RegisterUser(ctx context.Context, user *models.User) (stri
 	}
 	logger.Logger.Info("User created successfully")
 
-	token, err := utils.GenerateToken(128)
-	if err != nil {
-		logger.Logger.Error("Failed to generate token", zap.Error(err))
-		return "", "", err
-	}
-
-	// Create verification token
-	verToken := &models.Token{
-		UserID:    user.ID,
-		Token:     token,
-		ExpiresAt: time.Now().Add(time.Hour * 24),
-	}
-
-	err = s.repo.CreateVerificationToken(ctx, verToken)
-	if err != nil {
-		logger.Logger.Error("Failed to create verification token", zap.Error(err))
+	if err := s.sendVerificationEmail(ctx, user.ID, user.Email); err != nil {
 		return "", "", err
 	}
 
-	// Send email with verification token
-	res, err := s.mailService.SendMail(context.Background(), &proto.MailRequest{
-		To:   []string{user.Email},
-		Type: proto.NotificationType_EMAIL_CONFIRMATION,
-		Data: map[string]string{
-			"Link": fmt.Sprintf("http://%s:%s/api/v1/user/verify_email?token=%s", s.addr, s.port, token),
-		},
-	})
-
-	if err != nil || !res.Success {
-		logger.Logger.Error("Failed to send email", zap.Error(err))
-		return "", "", ErrInvalidFaildToSendEmail
-	}
-
 	return access, refresh, nil
 }
 
 func (s *UserService) ResendVerificationEmail(ctx context.Context, jwt string) error {
-	token, err := utils.GenerateToken(128)
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
 	if err != nil {
-		logger.Logger.Error("Failed to generate token", zap.Error(err))
 		return err
 	}
 
-	claims, err := utils.ValidateJWT(jwt, s.jwtSecret)
-	if err != nil {
-		logger.Logger.Error("Failed to validate JWT", zap.Error(err))
-		return ErrInvalidJWT
-	}
-
-	if utils.ValidateClaims(claims, "access") {
-		logger.Logger.Error("Invalid JWT claims")
-		return ErrInvalidJWT
-	}
-
-	uid := claims["uid"].(string)
-
 	// Get user by ID and check if email is already verified
 	user, err := s.repo.GetUserByID(ctx, uid)
 	if err != nil {
@@ -183,9 +176,24 @@ func (s *UserService) ResendVerificationEmail(ctx context.Context, jwt string) e
 		return ErrEmailAlreadyVerified
 	}
 
+	if user.Email == "" {
+		return ErrInvalidEmail
+	}
+
+	return s.sendVerificationEmail(ctx, user.ID, user.Email)
+}
+
+// sendVerificationEmail creates a verification token and emails the link to confirm the address.
+func (s *UserService) sendVerificationEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	token, err := utils.GenerateToken(128)
+	if err != nil {
+		logger.Logger.Error("Failed to generate token", zap.Error(err))
+		return err
+	}
+
 	// Create verification token
 	verToken := &models.Token{
-		UserID:    uuid.MustParse(uid),
+		UserID:    userID,
 		Token:     token,
 		ExpiresAt: time.Now().Add(time.Hour * 24),
 	}
@@ -198,7 +206,7 @@ func (s *UserService) ResendVerificationEmail(ctx context.Context, jwt string) e
 
 	// Send email with verification token
 	res, err := s.mailService.SendMail(context.Background(), &proto.MailRequest{
-		To:   []string{user.Email},
+		To:   []string{email},
 		Type: proto.NotificationType_EMAIL_CONFIRMATION,
 		Data: map[string]string{
 			"Link": fmt.Sprintf("http://%s:%s/api/v1/user/verify_email?token=%s", s.addr, s.port, token),
@@ -213,11 +221,16 @@ func (s *UserService) ResendVerificationEmail(ctx context.Context, jwt string) e
 }
 
 func (s *UserService) VerifyEmail(ctx context.Context, token string) error {
-	err := s.repo.VerifyEmail(ctx, token)
+	uid, err := s.repo.VerifyEmail(ctx, token)
 	if err != nil {
-		logger.Logger.Error("Failed to verify email", zap.Error(err))
+		if err != repository.ErrVerificationTokenInvalid {
+			logger.Logger.Error("Failed to verify email", zap.Error(err))
+		}
 		return err
 	}
+
+	s.recordEvent(ctx, uuid.MustParse(uid), models.EventEmailVerified, "")
+
 	return nil
 }
 
@@ -264,12 +277,25 @@ func (s *UserService) RequestResetPassword(ctx context.Context, email string) er
 	return nil
 }
 
-func (s *UserService) ResetPassword(ctx context.Context, token, password string) error {
-	if len(password) < 8 || len(password) > 64 {
-		return ErrInvalidPasswordLength
+func (s *UserService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	user, err := s.repo.GetUserByResetToken(ctx, token)
+	if err != nil {
+		if err != repository.ErrResetTokenInvalid {
+			logger.Logger.Error("Failed to get user by reset token", zap.Error(err))
+		}
+		return err
+	}
+
+	if err := s.policy.Validate(newPassword, user.Email, user.Name, user.Surname); err != nil {
+		return err
 	}
 
-	hashedPass, err := utils.HashPassword(password)
+	// Hashes are salted, so the new password is compared against the old hash rather than hash to hash
+	if user.Password != "" && s.passwords.Verify(user.Password, newPassword) {
+		return repository.ErrNewPasswordSameAsOld
+	}
+
+	hashedPass, err := s.passwords.Hash(newPassword)
 	if err != nil {
 		logger.Logger.Error("Failed to hash password", zap.Error(err))
 		return err
@@ -281,66 +307,119 @@ func (s *UserService) ResetPassword(ctx context.Context, token, password string)
 		return err
 	}
 
+	s.recordEvent(ctx, user.ID, models.EventPasswordReset, "")
+
+	return nil
+}
+
+// ChangePassword replaces the password of the user after checking the current one.
+func (s *UserService) ChangePassword(ctx context.Context, jwt, currentPassword, newPassword string) error {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repo.GetUserByID(ctx, uid)
+	if err != nil {
+		logger.Logger.Error("Failed to get user by ID", zap.String("id", uid), zap.Error(err))
+		return err
+	}
+
+	// Users who signed up with a social login set a password with a reset instead
+	if user.Password == "" || !s.passwords.Verify(user.Password, currentPassword) {
+		return ErrInvalidPassword
+	}
+
+	if err := s.policy.Validate(newPassword, user.Email, user.Name, user.Surname); err != nil {
+		return err
+	}
+
+	if s.passwords.Verify(user.Password, newPassword) {
+		return repository.ErrNewPasswordSameAsOld
+	}
+
+	hash, err := s.passwords.Hash(newPassword)
+	if err != nil {
+		logger.Logger.Error("Failed to hash password", zap.Error(err))
+		return err
+	}
+
+	if err := s.repo.UpdateProfile(ctx, uid, map[string]interface{}{"password": hash}); err != nil {
+		logger.Logger.Error("Failed to change password", zap.String("id", uid), zap.Error(err))
+		return err
+	}
+
+	s.recordEvent(ctx, user.ID, models.EventPasswordChanged, "")
+
 	return nil
 }
 
-func (s *UserService) LoginUser(ctx context.Context, user *models.User) (string, string, error) {
+// LoginUser logs in with email and password. If guestToken belongs to a guest, its data is merged into the account.
+func (s *UserService) LoginUser(ctx context.Context, user *models.User, guestToken string) (string, string, error) {
 	localUser, err := s.repo.GetUserByEmail(ctx, user.Email)
 	if err != nil {
 		return "", "", ErrInvalidEmailOrPassword
 	}
 
-	if !utils.ComparePasswords(localUser.Password, user.Password) {
+	if localUser.Password == "" || !s.passwords.Verify(localUser.Password, user.Password) {
+		s.recordEvent(ctx, localUser.ID, models.EventLoginFailed, models.LoginMethodPassword)
 		return "", "", ErrInvalidEmailOrPassword
 	}
 
-	access, refresh, err := s.GenerateAccesssAndRefreshTokens(localUser.ID.String(), "user", time.Hour*1, time.Hour*24*7)
-	if err != nil {
-		logger.Logger.Error("Failed to generate access and refresh tokens", zap.Error(err))
-		return "", "", err
+	if s.passwords.NeedsRehash(localUser.Password) {
+		s.rehashPassword(ctx, localUser.ID.String(), user.Password)
 	}
 
-	err = s.repo.AddRefreshToken(ctx, utils.HashToken(refresh), localUser.ID.String())
+	return s.completeLogin(ctx, localUser, models.LoginMethodPassword, guestToken)
+}
+
+// rehashPassword upgrades the stored hash to the preferred algorithm and parameters,
+// a failure is only logged as the old hash still works.
+func (s *UserService) rehashPassword(ctx context.Context, id, plain string) {
+	hash, err := s.passwords.Hash(plain)
 	if err != nil {
-		logger.Logger.Error("Failed to add refresh token", zap.Error(err))
-		return "", "", err
+		logger.Logger.Error("Failed to rehash password", zap.String("id", id), zap.Error(err))
+		return
 	}
 
-	return access, refresh, nil
+	if err := s.repo.UpdateProfile(ctx, id, map[string]interface{}{"password": hash}); err != nil {
+		logger.Logger.Error("Failed to store rehashed password", zap.String("id", id), zap.Error(err))
+		return
+	}
+
+	logger.Logger.Info("Password rehashed", zap.String("id", id))
 }
 
 func (s *UserService) RefreshTokens(ctx context.Context, jwt string) (string, string, error) {
-	claims, err := utils.ValidateJWT(jwt, s.jwtSecret)
+	claims, err := utils.ParseRefreshToken(jwt, s.jwtSecret)
 	if err != nil {
 		logger.Logger.Error("Failed to validate JWT", zap.Error(err))
-		return "", "", ErrInvalidJWT
-	}
-
-	if utils.ValidateClaims(claims, "refresh") {
-		logger.Logger.Error("Invalid JWT claims")
 
-		// Check if refresh token is expired
-		if claims.VerifyExpiresAt(time.Now().Unix(), true) {
-			logger.Logger.Error("Refresh token is expired")
-			s.repo.DeleteRefreshToken(ctx, utils.HashToken(jwt), claims["uid"].(string))
+		// The session of an expired refresh token can't be used anymore
+		if err == utils.ErrTokenExpired {
+			s.repo.DeleteRefreshToken(ctx, utils.HashToken(jwt), claims.Subject)
 		}
 
 		return "", "", ErrInvalidJWT
 	}
 
-	user, err := s.repo.GetUserByID(ctx, claims["uid"].(string))
+	user, err := s.repo.GetUserByID(ctx, claims.Subject)
 	if err != nil {
 		logger.Logger.Error("Failed to get user by ID", zap.Error(err))
 		return "", "", err
 	}
 
+	if user.Disabled {
+		return "", "", ErrUserDisabled
+	}
+
 	err = s.repo.VerifyRefreshToken(ctx, utils.HashToken(jwt), user.ID.String())
 	if err != nil {
 		logger.Logger.Error("Failed to verify refresh token", zap.Error(err))
 		return "", "", err
 	}
 
-	access, refresh, err := s.GenerateAccesssAndRefreshTokens(claims["uid"].(string), "user", time.Hour*1, time.Hour*24*7)
+	access, refresh, err := s.GenerateAccesssAndRefreshTokens(user.ID.String(), user.Role, time.Hour*1, refreshTokenTTL(user.Role))
 	if err != nil {
 		logger.Logger.Error("Failed to generate access and refresh tokens", zap.Error(err))
 		return "", "", ErrInternalServer
@@ -352,70 +431,68 @@ func (s *UserService) RefreshTokens(ctx context.Context, jwt string) (string, st
 		return "", "", err
 	}
 
+	s.recordEvent(ctx, user.ID, models.EventTokenRefreshed, "")
+
 	return access, refresh, nil
 }
 
-// UpdateUser updates a user.
-func (s *UserService) UpdateUser(ctx context.Context, jwt string, user *models.User) error {
-	claims, err := utils.ValidateJWT(jwt, s.jwtSecret)
+// UpdateUser updates the whitelisted profile fields of a user.
+func (s *UserService) UpdateUser(ctx context.Context, jwt string, update *models.ProfileUpdate) error {
+	uid, err := userIDFromJWT(jwt, s.jwtSecret)
 	if err != nil {
-		logger.Logger.Error("Failed to validate JWT", zap.Error(err))
-		return ErrInvalidJWT
+		return err
 	}
 
-	if utils.ValidateClaims(claims, "access") {
-		logger.Logger.Error("Invalid JWT claims")
-		return ErrInvalidJWT
-	}
+	fields := make(map[string]interface{})
 
-	uid := claims["uid"].(string)
+	if update.Name != nil {
+		name := strings.TrimSpace(*update.Name)
+		if name == "" || len(name) > 255 {
+			return ErrInvalidName
+		}
+		fields["name"] = name
+	}
 
-	userID, err := uuid.Parse(uid)
-	if err != nil {
-		logger.Logger.Error("Failed to parse user ID", zap.String("id", uid), zap.Error(err))
-		return err
+	if update.Surname != nil {
+		surname := strings.TrimSpace(*update.Surname)
+		if len(surname) > 255 {
+			return ErrInvalidName
+		}
+		fields["surname"] = surname
 	}
 
-	user.ID = userID
+	if len(fields) == 0 {
+		return ErrNothingToUpdate
+	}
 
-	err = s.repo.UpdateUser(ctx, user)
+	err = s.repo.UpdateProfile(ctx, uid, fields)
 	if err != nil {
 		if err == repository.ErrUserNotFound {
 			logger.Logger.Error("User not found by ID", zap.String("id", uid))
 			return err
 		}
-		logger.Logger.Error("Failed to update user", zap.String("id", user.ID.String()), zap.Error(err))
+		logger.Logger.Error("Failed to update user", zap.String("id", uid), zap.Error(err))
 		return err
 	}
 	return nil
 }
 
-// DeleteUser deletes a user by ID.
-func (s *UserService) DeleteUser(ctx context.Context, jwt string) error {
-	logger.Logger.Info("Deleting user by ID")
-
-	claims, err := utils.ValidateJWT(jwt, s.jwtSecret)
+// Logout revokes the session of a refresh token, access tokens issued with it are rejected from then on.
+func (s *UserService) Logout(ctx context.Context, jwt string) error {
+	claims, err := utils.ParseRefreshToken(jwt, s.jwtSecret)
 	if err != nil {
-		logger.Logger.Error("Failed to validate JWT", zap.Error(err))
 		return ErrInvalidJWT
 	}
 
-	if utils.ValidateClaims(claims, "access") {
-		logger.Logger.Error("Invalid JWT claims")
-		return ErrInvalidJWT
-	}
+	uid := claims.Subject
 
-	id := claims["uid"].(string)
-
-	err = s.repo.DeleteUser(ctx, id)
+	err = s.repo.DeleteRefreshToken(ctx, utils.HashToken(jwt), uid)
 	if err != nil {
-		if err == repository.ErrUserNotFound {
-			logger.Logger.Error("User not found by ID", zap.String("id", id))
-			return err
-		}
-		logger.Logger.Error("Failed to delete user by ID", zap.String("id", id), zap.Error(err))
+		logger.Logger.Error("Failed to delete refresh token", zap.String("id", uid), zap.Error(err))
 		return err
 	}
-	logger.Logger.Info("User deleted successfully")
+
+	s.recordEvent(ctx, uuid.MustParse(uid), models.EventSessionRevoked, "")
+
 	return nil
 }
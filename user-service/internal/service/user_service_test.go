@@ -0,0 +1,31 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/models"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/service"
+	"github.com/NeGat1FF/e-commerce/user-service/internal/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokensOfEveryLoginAreTheirOwnSession(t *testing.T) {
+	s := service.NewUserService(nil, nil, nil, nil, nil, nil, nil, nil, "", testSecret, "", "")
+	userID := uuid.NewString()
+
+	// Logins in the same second get different sessions
+	firstAccess, firstRefresh, err := s.GenerateAccesssAndRefreshTokens(userID, models.RoleUser, time.Hour, time.Hour)
+	require.NoError(t, err)
+	secondAccess, secondRefresh, err := s.GenerateAccesssAndRefreshTokens(userID, models.RoleUser, time.Hour, time.Hour)
+	require.NoError(t, err)
+	assert.NotEqual(t, firstRefresh, secondRefresh)
+
+	for access, refresh := range map[string]string{firstAccess: firstRefresh, secondAccess: secondRefresh} {
+		claims, err := utils.ParseAccessToken(access, testSecret, utils.Audience)
+		require.NoError(t, err)
+		assert.Equal(t, utils.HashToken(refresh), claims.SessionID)
+	}
+}
@@ -1,20 +1,124 @@
 package utils
 
 import (
+	"errors"
 	"time"
 
-	"github.com/golang-jwt/jwt"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
-func GenerateJWT(claims map[string]interface{}, secretKey string) (string, error) {
-	jwtClaims := jwt.MapClaims{}
+// Issuer is the issuer of every token signed by the user service.
+const Issuer = "user-service"
 
-	for key, value := range claims {
-		jwtClaims[key] = value
+// Audience of the user service, refresh and two-factor tokens are only accepted by it.
+const Audience = "user-service"
+
+// Leeway is the clock skew allowed when checking exp, nbf and iat.
+const Leeway = 30 * time.Second
+
+// Token types.
+const (
+	TokenTypeAccess    = "access"
+	TokenTypeRefresh   = "refresh"
+	TokenTypeTwoFactor = "2fa"
+)
+
+// AccessAudience lists the services an access token is accepted by.
+var AccessAudience = []string{
+	"user-service",
+	"order-service",
+	"shopping-cart-service",
+	"product-service",
+	"payment-service",
+}
+
+var (
+	ErrInvalidToken   = errors.New("invalid token")
+	ErrTokenExpired   = errors.New("token is expired")
+	ErrWrongTokenType = errors.New("wrong token type")
+)
+
+// AccessClaims are the claims of an access token, the user ID is the subject.
+type AccessClaims struct {
+	jwt.RegisteredClaims
+	Type string `json:"type"`
+	Role string `json:"role"`
+	// SessionID is the hash of the refresh token the access token was issued with
+	SessionID string `json:"sid"`
+}
+
+// Validate is called by the parser once the registered claims are verified.
+func (c *AccessClaims) Validate() error {
+	if c.Type != TokenTypeAccess {
+		return ErrWrongTokenType
+	}
+
+	if c.SessionID == "" {
+		return ErrInvalidToken
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwtClaims)
+	return validateSubject(c.Subject)
+}
+
+// RefreshClaims are the claims of a refresh token, the user ID is the subject.
+type RefreshClaims struct {
+	jwt.RegisteredClaims
+	Type string `json:"type"`
+	Role string `json:"role"`
+}
+
+// Validate is called by the parser once the registered claims are verified.
+func (c *RefreshClaims) Validate() error {
+	if c.Type != TokenTypeRefresh {
+		return ErrWrongTokenType
+	}
+
+	return validateSubject(c.Subject)
+}
+
+// TwoFactorClaims are the claims of the token of the second login step.
+type TwoFactorClaims struct {
+	jwt.RegisteredClaims
+	Type string `json:"type"`
+}
+
+// Validate is called by the parser once the registered claims are verified.
+func (c *TwoFactorClaims) Validate() error {
+	if c.Type != TokenTypeTwoFactor {
+		return ErrWrongTokenType
+	}
+
+	return validateSubject(c.Subject)
+}
+
+func validateSubject(sub string) error {
+	if uuid.Validate(sub) != nil {
+		return ErrInvalidToken
+	}
+
+	return nil
+}
+
+// NewRegisteredClaims returns the registered claims of a new token for the user.
+// There is no denylist of token IDs, revocation is per session: a refresh token is revoked by removing
+// its session, and the access tokens issued with it are rejected together with the session.
+func NewRegisteredClaims(userID string, ttl time.Duration, audience ...string) jwt.RegisteredClaims {
+	now := time.Now()
+
+	return jwt.RegisteredClaims{
+		Issuer:    Issuer,
+		Subject:   userID,
+		Audience:  audience,
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		NotBefore: jwt.NewNumericDate(now),
+		IssuedAt:  jwt.NewNumericDate(now),
+	}
+}
+
+// GenerateJWT signs the claims with HS256.
+func GenerateJWT(claims jwt.Claims, secretKey string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	signedToken, err := token.SignedString([]byte(secretKey))
 	if err != nil {
 		return "", err
@@ -23,34 +127,61 @@ func GenerateJWT(claims map[string]interface{}, secretKey string) (string, error
 	return signedToken, nil
 }
 
-func ValidateJWT(tokenString string, secretKey string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secretKey), nil
-	})
-	if err != nil {
+// ParseAccessToken validates an access token for the audience and returns its claims.
+func ParseAccessToken(tokenString, secretKey, audience string) (*AccessClaims, error) {
+	claims := &AccessClaims{}
+	if err := parseJWT(tokenString, secretKey, audience, claims); err != nil {
 		return nil, err
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || !token.Valid {
+	return claims, nil
+}
+
+// ParseRefreshToken validates a refresh token and returns its claims.
+// The claims of an expired token are returned together with ErrTokenExpired, so its session can be removed.
+func ParseRefreshToken(tokenString, secretKey string) (*RefreshClaims, error) {
+	claims := &RefreshClaims{}
+	if err := parseJWT(tokenString, secretKey, Audience, claims); err != nil {
+		if err == ErrTokenExpired && claims.Validate() == nil {
+			return claims, err
+		}
 		return nil, err
 	}
 
 	return claims, nil
 }
 
-func ValidateClaims(claims jwt.MapClaims, tokenType string) bool {
-	if claims["type"] != tokenType {
-		return false
+// ParseTwoFactorToken validates the token of the second login step and returns its claims.
+func ParseTwoFactorToken(tokenString, secretKey string) (*TwoFactorClaims, error) {
+	claims := &TwoFactorClaims{}
+	if err := parseJWT(tokenString, secretKey, Audience, claims); err != nil {
+		return nil, err
 	}
 
-	if claims.VerifyExpiresAt(time.Now().Unix(), true) {
-		return false
-	}
+	return claims, nil
+}
 
-	if _, ok := claims["uid"]; !ok {
-		return false
+func parseJWT(tokenString, secretKey, audience string, claims jwt.Claims) error {
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secretKey), nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(Issuer),
+		jwt.WithAudience(audience),
+		jwt.WithLeeway(Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, jwt.ErrTokenExpired):
+			return ErrTokenExpired
+		case errors.Is(err, ErrWrongTokenType):
+			return ErrWrongTokenType
+		default:
+			return ErrInvalidToken
+		}
 	}
 
-	return uuid.Validate(claims["uid"].(string)) == nil
+	return nil
 }
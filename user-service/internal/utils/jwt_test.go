@@ -0,0 +1,137 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "secret"
+
+func signToken(t *testing.T, claims jwt.Claims, method jwt.SigningMethod, key interface{}) string {
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	require.NoError(t, err)
+	return token
+}
+
+func accessClaims(userID string, ttl time.Duration, audience ...string) *utils.AccessClaims {
+	return &utils.AccessClaims{
+		RegisteredClaims: utils.NewRegisteredClaims(userID, ttl, audience...),
+		Type:             utils.TokenTypeAccess,
+		Role:             "user",
+		SessionID:        "session",
+	}
+}
+
+func TestParseAccessToken(t *testing.T) {
+	userID := uuid.NewString()
+
+	valid, err := utils.GenerateJWT(accessClaims(userID, time.Hour, utils.AccessAudience...), testSecret)
+	require.NoError(t, err)
+
+	expired := accessClaims(userID, -time.Hour, utils.AccessAudience...)
+
+	// Expired a moment ago, within the allowed clock skew
+	skewed := accessClaims(userID, -utils.Leeway/2, utils.AccessAudience...)
+
+	notYetValid := accessClaims(userID, time.Hour, utils.AccessAudience...)
+	notYetValid.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Hour))
+
+	refresh := &utils.RefreshClaims{
+		RegisteredClaims: utils.NewRegisteredClaims(userID, time.Hour, utils.AccessAudience...),
+		Type:             utils.TokenTypeRefresh,
+	}
+
+	otherIssuer := accessClaims(userID, time.Hour, utils.AccessAudience...)
+	otherIssuer.Issuer = "other-service"
+
+	noExpiry := accessClaims(userID, time.Hour, utils.AccessAudience...)
+	noExpiry.ExpiresAt = nil
+
+	noSession := accessClaims(userID, time.Hour, utils.AccessAudience...)
+	noSession.SessionID = ""
+
+	testCases := []struct {
+		name     string
+		token    string
+		audience string
+		err      error
+	}{
+		{name: "Valid token", token: valid, audience: utils.Audience},
+		{name: "Valid token for another service", token: valid, audience: "order-service"},
+		{name: "Within leeway", token: signToken(t, skewed, jwt.SigningMethodHS256, []byte(testSecret)), audience: utils.Audience},
+		{name: "Expired token", token: signToken(t, expired, jwt.SigningMethodHS256, []byte(testSecret)), audience: utils.Audience, err: utils.ErrTokenExpired},
+		{name: "Not yet valid", token: signToken(t, notYetValid, jwt.SigningMethodHS256, []byte(testSecret)), audience: utils.Audience, err: utils.ErrInvalidToken},
+		{name: "Wrong type", token: signToken(t, refresh, jwt.SigningMethodHS256, []byte(testSecret)), audience: utils.Audience, err: utils.ErrWrongTokenType},
+		{name: "Wrong audience", token: valid, audience: "search-service", err: utils.ErrInvalidToken},
+		{name: "Wrong issuer", token: signToken(t, otherIssuer, jwt.SigningMethodHS256, []byte(testSecret)), audience: utils.Audience, err: utils.ErrInvalidToken},
+		{name: "Missing expiry", token: signToken(t, noExpiry, jwt.SigningMethodHS256, []byte(testSecret)), audience: utils.Audience, err: utils.ErrInvalidToken},
+		{name: "Missing session", token: signToken(t, noSession, jwt.SigningMethodHS256, []byte(testSecret)), audience: utils.Audience, err: utils.ErrInvalidToken},
+		{name: "Invalid subject", token: signToken(t, accessClaims("admin", time.Hour, utils.Audience), jwt.SigningMethodHS256, []byte(testSecret)), audience: utils.Audience, err: utils.ErrInvalidToken},
+		{name: "Wrong secret", token: signToken(t, accessClaims(userID, time.Hour, utils.Audience), jwt.SigningMethodHS256, []byte("other")), audience: utils.Audience, err: utils.ErrInvalidToken},
+		{name: "Unsigned token", token: signToken(t, accessClaims(userID, time.Hour, utils.Audience), jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType), audience: utils.Audience, err: utils.ErrInvalidToken},
+		{name: "Malformed token", token: "not.a.token", audience: utils.Audience, err: utils.ErrInvalidToken},
+		{name: "Uid claim instead of sub", token: signToken(t, jwt.MapClaims{"uid": userID, "type": "access", "sid": "session", "exp": time.Now().Add(time.Hour).Unix()}, jwt.SigningMethodHS256, []byte(testSecret)), audience: utils.Audience, err: utils.ErrInvalidToken},
+		{name: "Empty token", token: "", audience: utils.Audience, err: utils.ErrInvalidToken},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			claims, err := utils.ParseAccessToken(tc.token, testSecret, tc.audience)
+			assert.Equal(t, tc.err, err)
+			if tc.err != nil {
+				assert.Nil(t, claims)
+				return
+			}
+
+			require.NotNil(t, claims)
+			assert.Equal(t, userID, claims.Subject)
+			assert.Equal(t, "user", claims.Role)
+			assert.Equal(t, "session", claims.SessionID)
+			assert.Empty(t, claims.ID, "tokens are revoked by their session, not by an ID")
+		})
+	}
+}
+
+func TestParseRefreshToken(t *testing.T) {
+	userID := uuid.NewString()
+
+	refreshClaims := func(ttl time.Duration, audience ...string) *utils.RefreshClaims {
+		return &utils.RefreshClaims{
+			RegisteredClaims: utils.NewRegisteredClaims(userID, ttl, audience...),
+			Type:             utils.TokenTypeRefresh,
+			Role:             "user",
+		}
+	}
+
+	testCases := []struct {
+		name       string
+		claims     jwt.Claims
+		err        error
+		withClaims bool
+	}{
+		{name: "Valid token", claims: refreshClaims(time.Hour, utils.Audience), withClaims: true},
+		{name: "Expired token returns its claims", claims: refreshClaims(-time.Hour, utils.Audience), err: utils.ErrTokenExpired, withClaims: true},
+		{name: "Wrong type", claims: accessClaims(userID, time.Hour, utils.Audience), err: utils.ErrWrongTokenType},
+		{name: "Wrong audience", claims: refreshClaims(time.Hour, "order-service"), err: utils.ErrInvalidToken},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			claims, err := utils.ParseRefreshToken(signToken(t, tc.claims, jwt.SigningMethodHS256, []byte(testSecret)), testSecret)
+			assert.Equal(t, tc.err, err)
+			if !tc.withClaims {
+				assert.Nil(t, claims)
+				return
+			}
+
+			require.NotNil(t, claims)
+			assert.Equal(t, userID, claims.Subject)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+)
+
+// GenerateNumericCode generates a random code of the given number of digits, leading zeros included.
+func GenerateNumericCode(digits int) (string, error) {
+	if digits <= 0 {
+		return "", fmt.Errorf("digits must be greater than 0")
+	}
+
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
+
+// HashCode hashes a short code with a secret key. Short codes are easy to brute
+// force, so unlike tokens they are not stored as plain SHA-256 hashes.
+func HashCode(secret, code string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidPhone = errors.New("phone must be an international number such as +14155552671")
+)
+
+// E.164: a plus sign and up to 15 digits, country codes never start with 0
+var e164Regex = regexp.MustCompile(`^\+[1-9]\d{6,14}$`)
+
+// Calling codes of the countries we ship to most, used for numbers given in national format.
+var callingCodes = map[string]string{
+	"US": "1",
+	"CA": "1",
+	"GB": "44",
+	"DE": "49",
+	"FR": "33",
+	"IT": "39",
+	"ES": "34",
+	"UA": "380",
+	"PL": "48",
+	"NL": "31",
+	"AU": "61",
+	"JP": "81",
+	"IN": "91",
+	"BR": "55",
+}
+
+// NormalizePhone converts a phone number to E.164. Numbers without a country code are
+// read in the national format of the country, which may be empty if no country is known.
+func NormalizePhone(phone, country string) (string, error) {
+	phone = strings.TrimSpace(phone)
+
+	// Drop the separators people commonly type
+	phone = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, phone)
+
+	switch {
+	case strings.HasPrefix(phone, "+"):
+	case strings.HasPrefix(phone, "00"):
+		phone = "+" + phone[2:]
+	default:
+		code, ok := callingCodes[strings.ToUpper(country)]
+		if !ok {
+			return "", ErrInvalidPhone
+		}
+
+		// The trunk prefix is dialled only within the country, in NANP it is part of the number
+		if code != "1" {
+			phone = strings.TrimPrefix(phone, "0")
+		} else {
+			phone = strings.TrimPrefix(phone, "1")
+		}
+
+		phone = "+" + code + phone
+	}
+
+	if !e164Regex.MatchString(phone) {
+		return "", ErrInvalidPhone
+	}
+
+	return phone, nil
+}
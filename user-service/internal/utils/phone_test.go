@@ -0,0 +1,52 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	testCases := []struct {
+		name     string
+		phone    string
+		country  string
+		expected string
+		err      error
+	}{
+		{name: "E.164", phone: "+14155552671", expected: "+14155552671"},
+		{name: "International with separators", phone: "+44 (20) 7946-0958", expected: "+442079460958"},
+		{name: "International with 00 prefix", phone: "0048 512 345 678", expected: "+48512345678"},
+		{name: "National with trunk prefix", phone: "020 7946 0958", country: "GB", expected: "+442079460958"},
+		{name: "National NANP", phone: "(415) 555-2671", country: "US", expected: "+14155552671"},
+		{name: "National NANP with leading 1", phone: "1 415 555 2671", country: "us", expected: "+14155552671"},
+		{name: "National without country", phone: "4155552671", err: utils.ErrInvalidPhone},
+		{name: "National of unknown country", phone: "0701234567", country: "SE", err: utils.ErrInvalidPhone},
+		{name: "Too long", phone: "+1234567890123456", err: utils.ErrInvalidPhone},
+		{name: "Too short", phone: "+12345", err: utils.ErrInvalidPhone},
+		{name: "Letters", phone: "+1415CALLNOW", err: utils.ErrInvalidPhone},
+		{name: "Country code starting with 0", phone: "+0123456789", err: utils.ErrInvalidPhone},
+		{name: "Empty", phone: "", country: "US", err: utils.ErrInvalidPhone},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			phone, err := utils.NormalizePhone(tc.phone, tc.country)
+			assert.Equal(t, tc.err, err)
+			assert.Equal(t, tc.expected, phone)
+		})
+	}
+}
+
+func TestGenerateNumericCode(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		code, err := utils.GenerateNumericCode(6)
+		require.NoError(t, err)
+		assert.Regexp(t, `^\d{6}$`, code)
+	}
+
+	_, err := utils.GenerateNumericCode(0)
+	assert.Error(t, err)
+}
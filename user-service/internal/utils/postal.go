@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidCountry    = errors.New("country must be an ISO 3166-1 alpha-2 code")
+	ErrInvalidPostalCode = errors.New("invalid postal code for country")
+)
+
+var countryCodeRegex = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// Postal code formats of the countries we ship to most.
+var postalCodeRegex = map[string]*regexp.Regexp{
+	"US": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+	"CA": regexp.MustCompile(`^[A-Z]\d[A-Z] ?\d[A-Z]\d$`),
+	"GB": regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"FR": regexp.MustCompile(`^\d{5}$`),
+	"IT": regexp.MustCompile(`^\d{5}$`),
+	"ES": regexp.MustCompile(`^\d{5}$`),
+	"UA": regexp.MustCompile(`^\d{5}$`),
+	"PL": regexp.MustCompile(`^\d{2}-\d{3}$`),
+	"NL": regexp.MustCompile(`^\d{4} ?[A-Z]{2}$`),
+	"AU": regexp.MustCompile(`^\d{4}$`),
+	"JP": regexp.MustCompile(`^\d{3}-?\d{4}$`),
+	"IN": regexp.MustCompile(`^\d{6}$`),
+	"BR": regexp.MustCompile(`^\d{5}-?\d{3}$`),
+}
+
+// Countries that do not use postal codes.
+var noPostalCode = map[string]bool{
+	"AE": true,
+	"HK": true,
+	"QA": true,
+}
+
+// Fallback for countries without a known format.
+var genericPostalCodeRegex = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 -]{1,9}$`)
+
+// NormalizeCountry converts a country code to upper case and validates it.
+func NormalizeCountry(country string) (string, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if !countryCodeRegex.MatchString(country) {
+		return "", ErrInvalidCountry
+	}
+	return country, nil
+}
+
+// NormalizePostalCode converts a postal code to upper case and validates it for the country.
+func NormalizePostalCode(country, postalCode string) (string, error) {
+	postalCode = strings.ToUpper(strings.TrimSpace(postalCode))
+
+	if noPostalCode[country] {
+		if postalCode != "" {
+			return "", ErrInvalidPostalCode
+		}
+		return "", nil
+	}
+
+	re, ok := postalCodeRegex[country]
+	if !ok {
+		re = genericPostalCodeRegex
+	}
+
+	if !re.MatchString(postalCode) {
+		return "", ErrInvalidPostalCode
+	}
+
+	return postalCode, nil
+}
@@ -0,0 +1,47 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePostalCode(t *testing.T) {
+	testCases := []struct {
+		name       string
+		country    string
+		postalCode string
+		expected   string
+		err        error
+	}{
+		{name: "US ZIP", country: "US", postalCode: "94105", expected: "94105"},
+		{name: "US ZIP+4", country: "US", postalCode: "94105-1234", expected: "94105-1234"},
+		{name: "US too short", country: "US", postalCode: "9410", err: utils.ErrInvalidPostalCode},
+		{name: "GB lower case", country: "GB", postalCode: "sw1a 1aa", expected: "SW1A 1AA"},
+		{name: "CA without space", country: "CA", postalCode: "K1A0B1", expected: "K1A0B1"},
+		{name: "PL with dash", country: "PL", postalCode: "00-950", expected: "00-950"},
+		{name: "PL without dash", country: "PL", postalCode: "00950", err: utils.ErrInvalidPostalCode},
+		{name: "Country without postal codes", country: "AE", postalCode: "", expected: ""},
+		{name: "Postal code where none is used", country: "AE", postalCode: "12345", err: utils.ErrInvalidPostalCode},
+		{name: "Unknown country", country: "SE", postalCode: "114 55", expected: "114 55"},
+		{name: "Unknown country empty", country: "SE", postalCode: "", err: utils.ErrInvalidPostalCode},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			postalCode, err := utils.NormalizePostalCode(tc.country, tc.postalCode)
+			assert.Equal(t, tc.err, err)
+			assert.Equal(t, tc.expected, postalCode)
+		})
+	}
+}
+
+func TestNormalizeCountry(t *testing.T) {
+	country, err := utils.NormalizeCountry(" us ")
+	assert.NoError(t, err)
+	assert.Equal(t, "US", country)
+
+	_, err = utils.NormalizeCountry("USA")
+	assert.Equal(t, utils.ErrInvalidCountry, err)
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+)
+
+// UnsubscribeClaims identifies the consent an unsubscribe token withdraws.
+type UnsubscribeClaims struct {
+	UserID   string
+	Channel  string
+	Category string
+}
+
+// GenerateUnsubscribeToken signs the claims so they can be put into a link.
+// The token is not stored and does not expire, so links in old messages keep working.
+func GenerateUnsubscribeToken(secret string, claims UnsubscribeClaims) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(claims.UserID + ":" + claims.Channel + ":" + claims.Category))
+	return payload + "." + signUnsubscribe(secret, payload)
+}
+
+// ParseUnsubscribeToken verifies the signature of the token and returns its claims.
+func ParseUnsubscribeToken(secret, token string) (*UnsubscribeClaims, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(signUnsubscribe(secret, payload))) {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+
+	parts := strings.Split(string(decoded), ":")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+
+	return &UnsubscribeClaims{
+		UserID:   parts[0],
+		Channel:  parts[1],
+		Category: parts[2],
+	}, nil
+}
+
+// signUnsubscribe prefixes the payload so the signature can't be reused for other purposes of the same secret.
+func signUnsubscribe(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("unsubscribe:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
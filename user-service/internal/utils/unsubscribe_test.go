@@ -0,0 +1,47 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/NeGat1FF/e-commerce/user-service/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnsubscribeToken(t *testing.T) {
+	claims := utils.UnsubscribeClaims{
+		UserID:   "5f0e4c1a-8b7d-4a55-9a0f-2a3f1f8e9c11",
+		Channel:  "email",
+		Category: "marketing",
+	}
+
+	token := utils.GenerateUnsubscribeToken("secret", claims)
+
+	testCases := []struct {
+		name   string
+		secret string
+		token  string
+		err    error
+	}{
+		{name: "Valid token", secret: "secret", token: token},
+		{name: "Wrong secret", secret: "other", token: token, err: utils.ErrInvalidUnsubscribeToken},
+		{name: "Tampered payload", secret: "secret", token: "x" + token, err: utils.ErrInvalidUnsubscribeToken},
+		{name: "Tampered signature", secret: "secret", token: token + "x", err: utils.ErrInvalidUnsubscribeToken},
+		{name: "Missing signature", secret: "secret", token: "abc", err: utils.ErrInvalidUnsubscribeToken},
+		{name: "Empty token", secret: "secret", token: "", err: utils.ErrInvalidUnsubscribeToken},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, err := utils.ParseUnsubscribeToken(tc.secret, tc.token)
+			assert.Equal(t, tc.err, err)
+			if tc.err != nil {
+				assert.Nil(t, parsed)
+				return
+			}
+
+			require.NotNil(t, parsed)
+			assert.Equal(t, claims, *parsed)
+		})
+	}
+}